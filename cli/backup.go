@@ -1,23 +1,46 @@
 package cli
 
 import (
+	"os"
+	"strings"
+
+	"github.com/NethermindEth/eigenlayer/cli/prompter"
 	"github.com/NethermindEth/eigenlayer/pkg/daemon"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
 
-func BackupCmd(d daemon.Daemon) *cobra.Command {
-	var instanceId string
+func BackupCmd(d daemon.Daemon, p prompter.Prompter) *cobra.Command {
+	var (
+		instanceId     string
+		options        daemon.BackupOptions
+		encrypt        bool
+		passphraseFile string
+	)
 	cmd := cobra.Command{
 		Use:   "backup <instance-id>",
 		Short: "Backup an instance",
-		Long:  "Backup an instance saving the data into a tarball file. To list backups, use 'eigenlayer backup ls'",
+		Long:  "Backup an instance saving its data compressed. The data is split in chunks, and only the chunks that are not stored by previous backups of the instance are stored, so consecutive backups only store the data that changed. The progress of each volume is shown while it is backed up, and the backup can be canceled with Ctrl+C, removing the partial backup. With --encrypt or --passphrase-file, the data is encrypted with a passphrase, which is required to restore the backup. The running services of the instance are stopped while their volumes are backed up and started again afterwards, or paused and unpaused with --consistency pause for a shorter downtime. The volumes to back up are selected with --include and --exclude, as <service> or <service>:<volume>, where <volume> is the volume name or its mount target, and --config-only backs up the instance configuration without any volume. To list backups, use 'eigenlayer backup ls'",
 		Args:  cobra.MinimumNArgs(1),
 		PreRun: func(cmd *cobra.Command, args []string) {
 			instanceId = args[0]
 		},
-		RunE: func(cmd *cobra.Command, args []string) error {
-			backupId, err := d.Backup(instanceId)
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			if passphraseFile != "" {
+				options.Passphrase, err = readPassphraseFile(passphraseFile)
+			} else if encrypt {
+				options.Passphrase, err = newPassphrase(p)
+			}
+			if err != nil {
+				return err
+			}
+			if bar := newBackupProgressBar(); bar != nil {
+				options.Progress = bar.Update
+				defer bar.Done()
+			}
+			ctx, stop := interruptContext(cmd.Context())
+			defer stop()
+			backupId, err := d.Backup(ctx, instanceId, options)
 			if err != nil {
 				return err
 			}
@@ -26,9 +49,64 @@ func BackupCmd(d daemon.Daemon) *cobra.Command {
 		},
 	}
 
-	// Add ls subcommand
-	lsCmd := BackupLsCmd(d)
-	cmd.AddCommand(lsCmd)
+	cmd.Flags().StringVar(&options.Compression, "compression", "zstd", "compression of the backup data: zstd, gzip or none.")
+	cmd.Flags().StringVar(&options.Consistency, "consistency", "stop", "how the running services are quiesced while their volumes are backed up: stop or pause.")
+	cmd.Flags().StringSliceVar(&options.Include, "include", nil, "volumes to back up, as <service> or <service>:<volume>. All the volumes are backed up by default.")
+	cmd.Flags().StringSliceVar(&options.Exclude, "exclude", nil, "volumes not to back up, as <service> or <service>:<volume>.")
+	cmd.Flags().BoolVar(&options.ConfigOnly, "config-only", false, "back up the instance configuration without any volume.")
+	cmd.MarkFlagsMutuallyExclusive("config-only", "include")
+	cmd.MarkFlagsMutuallyExclusive("config-only", "exclude")
+	cmd.Flags().BoolVar(&encrypt, "encrypt", false, "encrypt the backup data with a passphrase, asked interactively.")
+	cmd.Flags().StringVar(&passphraseFile, "passphrase-file", "", "encrypt the backup data with the passphrase in the given file.")
+
+	// Add subcommands
+	cmd.AddCommand(
+		BackupLsCmd(d),
+		BackupPushCmd(d),
+		BackupPullCmd(d),
+		BackupImportCmd(d),
+		BackupExportCmd(d, p),
+		BackupVerifyCmd(d, p),
+		BackupScheduleCmd(d),
+		BackupRunScheduledCmd(d),
+		BackupPruneCmd(d),
+	)
 
 	return &cmd
 }
+
+// newPassphrase asks for a new backup passphrase twice, to make sure it has no
+// typos.
+func newPassphrase(p prompter.Prompter) (string, error) {
+	passphrase, err := p.InputHiddenString("Enter the passphrase to encrypt the backup:", "The passphrase is required to restore the backup", validatePassphrase)
+	if err != nil {
+		return "", err
+	}
+	confirmation, err := p.InputHiddenString("Confirm the passphrase:", "", validatePassphrase)
+	if err != nil {
+		return "", err
+	}
+	if passphrase != confirmation {
+		return "", ErrPassphraseMismatch
+	}
+	return passphrase, nil
+}
+
+// readPassphraseFile reads a backup passphrase from the first line of the
+// given file.
+func readPassphraseFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	passphrase, _, _ := strings.Cut(string(data), "\n")
+	passphrase = strings.TrimSuffix(passphrase, "\r")
+	return passphrase, validatePassphrase(passphrase)
+}
+
+func validatePassphrase(passphrase string) error {
+	if passphrase == "" {
+		return ErrEmptyPassphrase
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"errors"
+
+	"github.com/NethermindEth/eigenlayer/cli/prompter"
+	"github.com/NethermindEth/eigenlayer/pkg/daemon"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+func BackupExportCmd(d daemon.Daemon, p prompter.Prompter) *cobra.Command {
+	var (
+		backupId       string
+		path           string
+		passphrase     string
+		passphraseFile string
+	)
+	cmd := cobra.Command{
+		Use:   "export <backup-id> <file>",
+		Short: "Export a backup as a tar file",
+		Long:  "Export a backup as a single tar file, which can be copied to another host and imported there with 'eigenlayer backup import <file>'. The tar file is read from the stored backup data. The data of encrypted backups is decrypted with the passphrase read from the --passphrase-file file, or asked interactively, and the exported tar file is not encrypted.",
+		Args:  cobra.ExactArgs(2),
+		PreRun: func(cmd *cobra.Command, args []string) {
+			backupId = args[0]
+			path = args[1]
+		},
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			if passphraseFile != "" {
+				passphrase, err = readPassphraseFile(passphraseFile)
+				if err != nil {
+					return err
+				}
+			}
+			err = d.BackupExport(backupId, path, passphrase)
+			if errors.Is(err, daemon.ErrBackupEncrypted) && passphraseFile == "" {
+				passphrase, err = p.InputHiddenString("Enter the passphrase to decrypt the backup:", "", validatePassphrase)
+				if err != nil {
+					return err
+				}
+				err = d.BackupExport(backupId, path, passphrase)
+			}
+			if err != nil {
+				return err
+			}
+			log.Infof("Backup %s exported to %s", backupId, path)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&passphraseFile, "passphrase-file", "", "read the passphrase of encrypted backups from the given file")
+	return &cmd
+}
@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/NethermindEth/eigenlayer/cli/mocks"
+	prompterMock "github.com/NethermindEth/eigenlayer/cli/prompter/mocks"
+	"github.com/NethermindEth/eigenlayer/pkg/daemon"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackupExport(t *testing.T) {
+	passphraseFile := filepath.Join(t.TempDir(), "passphrase")
+	require.NoError(t, os.WriteFile(passphraseFile, []byte("secret\n"), 0o600))
+
+	tc := []struct {
+		name   string
+		args   []string
+		err    error
+		mocker func(d *mocks.MockDaemon, p *prompterMock.MockPrompter)
+	}{
+		{
+			name: "missing file",
+			args: []string{"backup-id"},
+			err:  errors.New("accepts 2 arg(s), received 1"),
+		},
+		{
+			name: "daemon export error",
+			args: []string{"backup-id", "/tmp/backup.tar"},
+			err:  daemon.ErrBackupNotFound,
+			mocker: func(d *mocks.MockDaemon, p *prompterMock.MockPrompter) {
+				d.EXPECT().BackupExport("backup-id", "/tmp/backup.tar", "").Return(daemon.ErrBackupNotFound)
+			},
+		},
+		{
+			name: "daemon export success",
+			args: []string{"backup-id", "/tmp/backup.tar"},
+			mocker: func(d *mocks.MockDaemon, p *prompterMock.MockPrompter) {
+				d.EXPECT().BackupExport("backup-id", "/tmp/backup.tar", "").Return(nil)
+			},
+		},
+		{
+			name: "encrypted backup, passphrase file",
+			args: []string{"backup-id", "/tmp/backup.tar", "--passphrase-file", passphraseFile},
+			mocker: func(d *mocks.MockDaemon, p *prompterMock.MockPrompter) {
+				d.EXPECT().BackupExport("backup-id", "/tmp/backup.tar", "secret").Return(nil)
+			},
+		},
+		{
+			name: "encrypted backup, passphrase prompt",
+			args: []string{"backup-id", "/tmp/backup.tar"},
+			mocker: func(d *mocks.MockDaemon, p *prompterMock.MockPrompter) {
+				gomock.InOrder(
+					d.EXPECT().BackupExport("backup-id", "/tmp/backup.tar", "").Return(daemon.ErrBackupEncrypted),
+					p.EXPECT().InputHiddenString("Enter the passphrase to decrypt the backup:", "", gomock.Any()).Return("secret", nil),
+					d.EXPECT().BackupExport("backup-id", "/tmp/backup.tar", "secret").Return(nil),
+				)
+			},
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			d := mocks.NewMockDaemon(ctrl)
+			p := prompterMock.NewMockPrompter(ctrl)
+
+			if tt.mocker != nil {
+				tt.mocker(d, p)
+			}
+
+			cmd := BackupCmd(d, p)
+
+			cmd.SetArgs(append([]string{"export"}, tt.args...))
+			err := cmd.Execute()
+
+			if tt.err != nil && !errors.Is(err, tt.err) {
+				assert.EqualError(t, err, tt.err.Error())
+			} else if tt.err == nil {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package cli
+
+import (
+	"github.com/NethermindEth/eigenlayer/pkg/daemon"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+func BackupImportCmd(d daemon.Daemon) *cobra.Command {
+	var path string
+	cmd := cobra.Command{
+		Use:   "import <file>",
+		Short: "Import a backup tar file",
+		Long:  "Import a backup tar file, like a backup exported with 'eigenlayer backup export' on another host, to the local backups, so it can be restored. The file is validated and stored like the local backups, and it is not modified. To restore the backup without replacing the installed instance, use 'eigenlayer restore --tag <tag> <backup-id>'.",
+		Args:  cobra.ExactArgs(1),
+		PreRun: func(cmd *cobra.Command, args []string) {
+			path = args[0]
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			info, err := d.BackupImport(path)
+			if err != nil {
+				return err
+			}
+			log.Infof("Backup of instance %s imported with id: %s", info.Instance, info.Id)
+			return nil
+		},
+	}
+	return &cmd
+}
@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/NethermindEth/eigenlayer/cli/mocks"
+	"github.com/NethermindEth/eigenlayer/pkg/daemon"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackupImport(t *testing.T) {
+	tc := []struct {
+		name   string
+		args   []string
+		err    error
+		mocker func(d *mocks.MockDaemon)
+	}{
+		{
+			name: "missing file",
+			args: []string{},
+			err:  errors.New("accepts 1 arg(s), received 0"),
+		},
+		{
+			name: "daemon import error",
+			args: []string{"/tmp/backup.tar"},
+			err:  assert.AnError,
+			mocker: func(d *mocks.MockDaemon) {
+				d.EXPECT().BackupImport("/tmp/backup.tar").Return(daemon.BackupInfo{}, assert.AnError)
+			},
+		},
+		{
+			name: "daemon import success",
+			args: []string{"/tmp/backup.tar"},
+			mocker: func(d *mocks.MockDaemon) {
+				d.EXPECT().BackupImport("/tmp/backup.tar").Return(daemon.BackupInfo{Id: "backup-id", Instance: "mock-avs-default"}, nil)
+			},
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			d := mocks.NewMockDaemon(ctrl)
+
+			if tt.mocker != nil {
+				tt.mocker(d)
+			}
+
+			cmd := BackupCmd(d, nil)
+
+			cmd.SetArgs(append([]string{"import"}, tt.args...))
+			err := cmd.Execute()
+
+			if tt.err != nil {
+				assert.EqualError(t, err, tt.err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
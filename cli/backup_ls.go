@@ -16,7 +16,7 @@ func BackupLsCmd(d daemon.Daemon) *cobra.Command {
 	cmd := cobra.Command{
 		Use:   "ls",
 		Short: "List backups",
-		Long:  "List backups showing all backups and their details.",
+		Long:  "List backups showing all backups and their details. SIZE is the size of the backed up data, and STORED is the size of the data stored by the backup, which does not include the data already stored by previous backups of the same instance.",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			backups, err := d.BackupList()
 			if err != nil {
@@ -44,13 +44,14 @@ func sortBackupsByTimestamp(backups []daemon.BackupInfo) {
 
 func printBackupTable(backups []daemon.BackupInfo, out io.Writer) {
 	w := tabwriter.NewWriter(out, 0, 0, 4, ' ', 0)
-	fmt.Fprintln(w, "ID\tAVS Instance ID\tVERSION\tCOMMIT\tTIMESTAMP\tSIZE\tURL\t")
+	fmt.Fprintln(w, "ID\tAVS Instance ID\tVERSION\tCOMMIT\tTIMESTAMP\tSIZE\tSTORED\tURL\t")
 	for _, b := range backups {
 		fmt.Fprintln(w, backupTableItem{
 			id:        b.Id,
 			instance:  b.Instance,
 			timestamp: b.Timestamp.Format(time.DateTime),
 			size:      datasize.Size(b.SizeBytes).String(),
+			stored:    datasize.Size(b.StoredSizeBytes).String(),
 			version:   b.Version,
 			commit:    b.Commit,
 			url:       b.Url,
@@ -64,6 +65,7 @@ type backupTableItem struct {
 	instance  string
 	timestamp string
 	size      string
+	stored    string
 	version   string
 	commit    string
 	url       string
@@ -77,5 +79,5 @@ type backupTableItem struct {
 // }
 
 func (b backupTableItem) String() string {
-	return fmt.Sprintf("%s\t%s\t%s\t%s\t%s\t%s\t%s\t", b.id, b.instance, b.version, b.commit, b.timestamp, b.size, b.url)
+	return fmt.Sprintf("%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t", b.id, b.instance, b.version, b.commit, b.timestamp, b.size, b.stored, b.url)
 }
@@ -24,7 +24,7 @@ func TestBackupLs(t *testing.T) {
 			name:   "no backups",
 			err:    nil,
 			stdErr: nil,
-			stdOut: []byte("ID    AVS Instance ID    VERSION    COMMIT    TIMESTAMP    SIZE    URL    \n"),
+			stdOut: []byte("ID    AVS Instance ID    VERSION    COMMIT    TIMESTAMP    SIZE    STORED    URL    \n"),
 			mocker: func(d *mocks.MockDaemon) {
 				d.EXPECT().BackupList().Return([]daemon.BackupInfo{}, nil)
 			},
@@ -34,29 +34,31 @@ func TestBackupLs(t *testing.T) {
 			err:    nil,
 			stdErr: nil,
 			stdOut: []byte(
-				"ID                                          AVS Instance ID     VERSION    COMMIT                                      TIMESTAMP              SIZE     URL                                              \n" +
-					"7ba32f630af2cede1388b5712d6ef3ac63175bae    mock-avs-second     v5.5.1     d5af645fffb93e8263b099082a4f512e1917d0af    2023-10-04 07:12:19    10KiB    https://github.com/NethermindEth/mock-avs-pkg    \n" +
-					"33de69fe9225b95c8fb909cb418e5102970c8d73    mock-avs-default    v5.5.0     a3406616b848164358fdd24465b8eecda5f5ae34    2023-10-03 21:18:36    10KiB    https://github.com/NethermindEth/mock-avs-pkg    \n",
+				"ID                                          AVS Instance ID     VERSION    COMMIT                                      TIMESTAMP              SIZE     STORED    URL                                              \n" +
+					"7ba32f630af2cede1388b5712d6ef3ac63175bae    mock-avs-second     v5.5.1     d5af645fffb93e8263b099082a4f512e1917d0af    2023-10-04 07:12:19    10KiB    2KiB      https://github.com/NethermindEth/mock-avs-pkg    \n" +
+					"33de69fe9225b95c8fb909cb418e5102970c8d73    mock-avs-default    v5.5.0     a3406616b848164358fdd24465b8eecda5f5ae34    2023-10-03 21:18:36    10KiB    10KiB     https://github.com/NethermindEth/mock-avs-pkg    \n",
 			),
 			mocker: func(d *mocks.MockDaemon) {
 				d.EXPECT().BackupList().Return([]daemon.BackupInfo{
 					{
-						Id:        "33de69fe9225b95c8fb909cb418e5102970c8d73",
-						Instance:  "mock-avs-default",
-						Version:   "v5.5.0",
-						Commit:    "a3406616b848164358fdd24465b8eecda5f5ae34",
-						Timestamp: time.Date(2023, 10, 3, 21, 18, 36, 0, time.UTC),
-						SizeBytes: 10240,
-						Url:       "https://github.com/NethermindEth/mock-avs-pkg",
+						Id:              "33de69fe9225b95c8fb909cb418e5102970c8d73",
+						Instance:        "mock-avs-default",
+						Version:         "v5.5.0",
+						Commit:          "a3406616b848164358fdd24465b8eecda5f5ae34",
+						Timestamp:       time.Date(2023, 10, 3, 21, 18, 36, 0, time.UTC),
+						SizeBytes:       10240,
+						StoredSizeBytes: 10240,
+						Url:             "https://github.com/NethermindEth/mock-avs-pkg",
 					},
 					{
-						Id:        "7ba32f630af2cede1388b5712d6ef3ac63175bae",
-						Instance:  "mock-avs-second",
-						Version:   "v5.5.1",
-						Commit:    "d5af645fffb93e8263b099082a4f512e1917d0af",
-						Timestamp: time.Date(2023, 10, 4, 7, 12, 19, 0, time.UTC),
-						SizeBytes: 10240,
-						Url:       "https://github.com/NethermindEth/mock-avs-pkg",
+						Id:              "7ba32f630af2cede1388b5712d6ef3ac63175bae",
+						Instance:        "mock-avs-second",
+						Version:         "v5.5.1",
+						Commit:          "d5af645fffb93e8263b099082a4f512e1917d0af",
+						Timestamp:       time.Date(2023, 10, 4, 7, 12, 19, 0, time.UTC),
+						SizeBytes:       10240,
+						StoredSizeBytes: 2048,
+						Url:             "https://github.com/NethermindEth/mock-avs-pkg",
 					},
 				}, nil)
 			},
@@ -0,0 +1,36 @@
+package cli
+
+import (
+	"github.com/NethermindEth/eigenlayer/pkg/daemon"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+func BackupPruneCmd(d daemon.Daemon) *cobra.Command {
+	var options daemon.PruneBackupsOptions
+	cmd := cobra.Command{
+		Use:   "prune [<instance-id>...]",
+		Short: "Prune backups with their retention policies",
+		Long:  "Remove the scheduled backups not kept by the retention policies of the backup schedules, and the backup data not used by the remaining backups. If no instance is given, the backups of all the instances with a backup schedule are pruned. Backups not created by a schedule, like manual or pre-update backups, are never pruned. With --dry-run, the backups that would be removed are listed, and nothing is removed.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			options.InstanceIds = args
+			pruned, err := d.PruneBackups(options)
+			if err != nil {
+				return err
+			}
+			if len(pruned) == 0 {
+				log.Info("No backups to prune")
+				return nil
+			}
+			if options.DryRun {
+				log.Infof("%d backups would be removed:", len(pruned))
+			} else {
+				log.Infof("%d backups removed:", len(pruned))
+			}
+			printBackupTable(pruned, cmd.OutOrStdout())
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&options.DryRun, "dry-run", false, "list the backups that would be removed without removing them.")
+	return &cmd
+}
@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/NethermindEth/eigenlayer/cli/mocks"
+	"github.com/NethermindEth/eigenlayer/pkg/daemon"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackupPrune(t *testing.T) {
+	pruned := []daemon.BackupInfo{
+		{
+			Id:        "33de69fe9225b95c8fb909cb418e5102970c8d73",
+			Instance:  "mock-avs-default",
+			Version:   "v5.5.0",
+			Timestamp: time.Date(2023, 10, 3, 21, 18, 36, 0, time.UTC),
+			SizeBytes: 10240,
+		},
+	}
+	tc := []struct {
+		name    string
+		args    []string
+		err     error
+		wantOut bool
+		mocker  func(d *mocks.MockDaemon)
+	}{
+		{
+			name:    "prune all",
+			args:    []string{},
+			wantOut: true,
+			mocker: func(d *mocks.MockDaemon) {
+				d.EXPECT().PruneBackups(daemon.PruneBackupsOptions{InstanceIds: []string{}}).Return(pruned, nil)
+			},
+		},
+		{
+			name:    "dry run",
+			args:    []string{"mock-avs-default", "--dry-run"},
+			wantOut: true,
+			mocker: func(d *mocks.MockDaemon) {
+				d.EXPECT().PruneBackups(daemon.PruneBackupsOptions{InstanceIds: []string{"mock-avs-default"}, DryRun: true}).Return(pruned, nil)
+			},
+		},
+		{
+			name: "nothing to prune",
+			args: []string{"mock-avs-default"},
+			mocker: func(d *mocks.MockDaemon) {
+				d.EXPECT().PruneBackups(daemon.PruneBackupsOptions{InstanceIds: []string{"mock-avs-default"}}).Return(nil, nil)
+			},
+		},
+		{
+			name: "missing schedule",
+			args: []string{"mock-avs-default"},
+			err:  daemon.ErrBackupScheduleNotFound,
+			mocker: func(d *mocks.MockDaemon) {
+				d.EXPECT().PruneBackups(daemon.PruneBackupsOptions{InstanceIds: []string{"mock-avs-default"}}).Return(nil, daemon.ErrBackupScheduleNotFound)
+			},
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			d := mocks.NewMockDaemon(ctrl)
+			tt.mocker(d)
+
+			var out bytes.Buffer
+			cmd := BackupPruneCmd(d)
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(&out)
+			err := cmd.Execute()
+
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			if tt.wantOut {
+				assert.Contains(t, out.String(), "33de69fe9225b95c8fb909cb418e5102970c8d73    mock-avs-default")
+			} else {
+				assert.Empty(t, out.String())
+			}
+		})
+	}
+}
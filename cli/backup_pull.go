@@ -0,0 +1,28 @@
+package cli
+
+import (
+	"github.com/NethermindEth/eigenlayer/pkg/daemon"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+func BackupPullCmd(d daemon.Daemon) *cobra.Command {
+	var backupId, location string
+	cmd := cobra.Command{
+		Use:   "pull <backup-id> <store>",
+		Short: "Pull a backup from a backup store",
+		Long:  "Pull a backup from a backup store to the local backups, so it can be restored. See 'eigenlayer backup push --help' for the supported stores. Only the backup data missing locally is downloaded. To restore a backup directly from a backup store, use 'eigenlayer restore --from <store> <backup-id>'.",
+		Args:  cobra.ExactArgs(2),
+		PreRun: func(cmd *cobra.Command, args []string) {
+			backupId, location = args[0], args[1]
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := d.BackupPull(backupId, location); err != nil {
+				return err
+			}
+			log.Infof("Backup %s pulled from %s", backupId, location)
+			return nil
+		},
+	}
+	return &cmd
+}
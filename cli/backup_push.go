@@ -0,0 +1,28 @@
+package cli
+
+import (
+	"github.com/NethermindEth/eigenlayer/pkg/daemon"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+func BackupPushCmd(d daemon.Daemon) *cobra.Command {
+	var backupId, location string
+	cmd := cobra.Command{
+		Use:   "push <backup-id> <store>",
+		Short: "Push a backup to a backup store",
+		Long:  "Push a local backup to a backup store, so it survives the loss of the node disk. The store is a local directory path, like a mounted network share, an S3-compatible bucket like 's3://bucket/prefix?endpoint=http://localhost:9000', or an SFTP server directory like 'sftp://user@host:22/path'. S3 credentials are loaded from the AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY environment variables or the AWS shared credentials file. SFTP connections authenticate with the SSH agent or the keys in ~/.ssh, and check the server against ~/.ssh/known_hosts. Only the backup data missing in the store is uploaded.",
+		Args:  cobra.ExactArgs(2),
+		PreRun: func(cmd *cobra.Command, args []string) {
+			backupId, location = args[0], args[1]
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := d.BackupPush(backupId, location); err != nil {
+				return err
+			}
+			log.Infof("Backup %s pushed to %s", backupId, location)
+			return nil
+		},
+	}
+	return &cmd
+}
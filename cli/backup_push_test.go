@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/NethermindEth/eigenlayer/cli/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackupPush(t *testing.T) {
+	tc := []struct {
+		name   string
+		args   []string
+		err    error
+		mocker func(d *mocks.MockDaemon)
+	}{
+		{
+			name: "missing store",
+			args: []string{"backup-id"},
+			err:  errors.New("accepts 2 arg(s), received 1"),
+		},
+		{
+			name: "daemon push error",
+			args: []string{"backup-id", "s3://bucket/backups"},
+			err:  assert.AnError,
+			mocker: func(d *mocks.MockDaemon) {
+				d.EXPECT().BackupPush("backup-id", "s3://bucket/backups").Return(assert.AnError)
+			},
+		},
+		{
+			name: "daemon push success",
+			args: []string{"backup-id", "sftp://user@host/backups"},
+			mocker: func(d *mocks.MockDaemon) {
+				d.EXPECT().BackupPush("backup-id", "sftp://user@host/backups").Return(nil)
+			},
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			d := mocks.NewMockDaemon(ctrl)
+
+			if tt.mocker != nil {
+				tt.mocker(d)
+			}
+
+			cmd := BackupCmd(d, nil)
+
+			cmd.SetArgs(append([]string{"push"}, tt.args...))
+			err := cmd.Execute()
+
+			if tt.err != nil {
+				assert.EqualError(t, err, tt.err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
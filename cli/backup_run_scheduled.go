@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/NethermindEth/eigenlayer/pkg/daemon"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+func BackupRunScheduledCmd(d daemon.Daemon) *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "run-scheduled",
+		Short: "Run the scheduled backups",
+		Long:  "Run the backups that are due according to the backup schedules, and prune the backups of each backed up instance with its retention policy. Backups missed since the last run are run once. This command is meant to be run periodically, for instance every few minutes by a systemd timer or a cron job. It fails if any scheduled backup fails.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			results, err := d.RunScheduledBackups()
+			if err != nil {
+				return err
+			}
+			if len(results) == 0 {
+				log.Info("No scheduled backups are due")
+				return nil
+			}
+			var failed int
+			for _, r := range results {
+				if r.BackupId != "" {
+					log.Infof("Backup of instance %s created with id: %s", r.InstanceId, r.BackupId)
+				}
+				if len(r.Pruned) > 0 {
+					log.Infof("Pruned %d backups of instance %s", len(r.Pruned), r.InstanceId)
+				}
+				if r.Err != nil {
+					log.Errorf("Scheduled backup of instance %s failed: %v", r.InstanceId, r.Err)
+					failed++
+				}
+			}
+			if failed > 0 {
+				return fmt.Errorf("%w: %d of %d", ErrScheduledBackupsFailed, failed, len(results))
+			}
+			return nil
+		},
+	}
+	return &cmd
+}
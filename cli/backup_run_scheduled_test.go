@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/NethermindEth/eigenlayer/cli/mocks"
+	"github.com/NethermindEth/eigenlayer/pkg/daemon"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackupRunScheduled(t *testing.T) {
+	tc := []struct {
+		name   string
+		err    error
+		mocker func(d *mocks.MockDaemon)
+	}{
+		{
+			name: "no backups due",
+			mocker: func(d *mocks.MockDaemon) {
+				d.EXPECT().RunScheduledBackups().Return(nil, nil)
+			},
+		},
+		{
+			name: "backups created",
+			mocker: func(d *mocks.MockDaemon) {
+				d.EXPECT().RunScheduledBackups().Return([]daemon.ScheduledBackupResult{
+					{InstanceId: "mock-avs-default", BackupId: "backup-id", Pruned: []daemon.BackupInfo{{Id: "old-backup-id"}}},
+					{InstanceId: "mock-avs-second", BackupId: "second-backup-id"},
+				}, nil)
+			},
+		},
+		{
+			name: "backup failed",
+			err:  ErrScheduledBackupsFailed,
+			mocker: func(d *mocks.MockDaemon) {
+				d.EXPECT().RunScheduledBackups().Return([]daemon.ScheduledBackupResult{
+					{InstanceId: "mock-avs-default", BackupId: "backup-id"},
+					{InstanceId: "mock-avs-second", Err: assert.AnError},
+				}, nil)
+			},
+		},
+		{
+			name: "daemon error",
+			err:  assert.AnError,
+			mocker: func(d *mocks.MockDaemon) {
+				d.EXPECT().RunScheduledBackups().Return(nil, assert.AnError)
+			},
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			d := mocks.NewMockDaemon(ctrl)
+			tt.mocker(d)
+
+			cmd := BackupRunScheduledCmd(d)
+			cmd.SetArgs([]string{})
+			err := cmd.Execute()
+
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
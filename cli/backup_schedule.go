@@ -0,0 +1,138 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/NethermindEth/eigenlayer/pkg/daemon"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+func BackupScheduleCmd(d daemon.Daemon) *cobra.Command {
+	var (
+		instanceId string
+		schedule   daemon.BackupSchedule
+	)
+	cmd := cobra.Command{
+		Use:   "schedule <instance-id>",
+		Short: "Schedule the backups of an instance",
+		Long:  "Schedule the backups of an instance with a cron expression, like '0 3 * * *' or '@daily', and set the retention policy of its scheduled backups. Other backups of the instance, like manual or pre-update backups, are not affected by the policy. A backup is kept if any of the --keep-* rules keeps it, and all the backups are kept if no rule is set. With --passphrase-file, the scheduled backups are encrypted with the passphrase in the given file, which is read each time a scheduled backup runs, so it must stay readable by the user running them; otherwise the scheduled backups are not encrypted. The scheduled backups are run by 'eigenlayer backup run-scheduled', which should be run periodically, for instance by a systemd timer. To list the schedules, use 'eigenlayer backup schedule ls'",
+		Args:  cobra.ExactArgs(1),
+		PreRun: func(cmd *cobra.Command, args []string) {
+			instanceId = args[0]
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if schedule.PassphraseFile != "" {
+				// The scheduled backups may run from another directory
+				path, err := filepath.Abs(schedule.PassphraseFile)
+				if err != nil {
+					return err
+				}
+				schedule.PassphraseFile = path
+			}
+			if err := d.SetBackupSchedule(instanceId, schedule); err != nil {
+				return err
+			}
+			log.Infof("Backups of instance %s scheduled with %q", instanceId, schedule.Cron)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&schedule.Cron, "cron", "", "cron expression of the backup schedule, in local time.")
+	cmd.Flags().StringVar(&schedule.Compression, "compression", "zstd", "compression of the backup data: zstd, gzip or none.")
+	cmd.Flags().StringVar(&schedule.Consistency, "consistency", "stop", "how the running services are quiesced while their volumes are backed up: stop or pause.")
+	cmd.Flags().StringVar(&schedule.PassphraseFile, "passphrase-file", "", "encrypt the scheduled backups with the passphrase in the given file.")
+	cmd.Flags().IntVar(&schedule.Retention.KeepLast, "keep-last", 0, "number of latest backups to keep.")
+	cmd.Flags().IntVar(&schedule.Retention.KeepDaily, "keep-daily", 0, "number of days to keep the latest backup of.")
+	cmd.Flags().IntVar(&schedule.Retention.KeepWeekly, "keep-weekly", 0, "number of weeks to keep the latest backup of.")
+	cmd.Flags().IntVar(&schedule.Retention.KeepMonthly, "keep-monthly", 0, "number of months to keep the latest backup of.")
+	cmd.MarkFlagRequired("cron")
+
+	cmd.AddCommand(
+		BackupScheduleLsCmd(d),
+		BackupScheduleRmCmd(d),
+	)
+
+	return &cmd
+}
+
+func BackupScheduleLsCmd(d daemon.Daemon) *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "ls",
+		Short: "List backup schedules",
+		Long:  "List the backup schedules of the instances, with the retention policy of their backups and the time of the last and the next scheduled backups.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			schedules, err := d.BackupSchedules()
+			if err != nil {
+				return err
+			}
+			printBackupScheduleTable(schedules, cmd.OutOrStdout())
+			return nil
+		},
+	}
+	return &cmd
+}
+
+func BackupScheduleRmCmd(d daemon.Daemon) *cobra.Command {
+	var instanceId string
+	cmd := cobra.Command{
+		Use:   "rm <instance-id>",
+		Short: "Remove the backup schedule of an instance",
+		Long:  "Remove the backup schedule of an instance. The existing backups of the instance are not removed.",
+		Args:  cobra.ExactArgs(1),
+		PreRun: func(cmd *cobra.Command, args []string) {
+			instanceId = args[0]
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := d.RemoveBackupSchedule(instanceId); err != nil {
+				return err
+			}
+			log.Infof("Backup schedule of instance %s removed", instanceId)
+			return nil
+		},
+	}
+	return &cmd
+}
+
+func printBackupScheduleTable(schedules []daemon.BackupScheduleInfo, out io.Writer) {
+	w := tabwriter.NewWriter(out, 0, 0, 4, ' ', 0)
+	fmt.Fprintln(w, "AVS Instance ID\tCRON\tRETENTION\tLAST RUN\tNEXT RUN\t")
+	for _, s := range schedules {
+		lastRun := "-"
+		if !s.LastRun.IsZero() {
+			lastRun = s.LastRun.Format(time.DateTime)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t\n", s.InstanceId, s.Cron, retentionString(s.Retention), lastRun, s.NextRun.Format(time.DateTime))
+	}
+	w.Flush()
+}
+
+func retentionString(r daemon.BackupRetention) string {
+	if r == (daemon.BackupRetention{}) {
+		return "all"
+	}
+	out := ""
+	for _, rule := range []struct {
+		n    int
+		name string
+	}{
+		{r.KeepLast, "last"},
+		{r.KeepDaily, "daily"},
+		{r.KeepWeekly, "weekly"},
+		{r.KeepMonthly, "monthly"},
+	} {
+		if rule.n == 0 {
+			continue
+		}
+		if out != "" {
+			out += ","
+		}
+		out += fmt.Sprintf("%s=%d", rule.name, rule.n)
+	}
+	return out
+}
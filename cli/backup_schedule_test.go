@@ -0,0 +1,137 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/NethermindEth/eigenlayer/cli/mocks"
+	"github.com/NethermindEth/eigenlayer/pkg/daemon"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackupSchedule(t *testing.T) {
+	tc := []struct {
+		name   string
+		args   []string
+		err    error
+		mocker func(d *mocks.MockDaemon)
+	}{
+		{
+			name: "missing cron",
+			args: []string{"mock-avs-default"},
+			err:  assert.AnError,
+		},
+		{
+			name: "schedule with retention",
+			args: []string{"mock-avs-default", "--cron", "0 3 * * *", "--keep-daily", "7", "--keep-weekly", "4", "--consistency", "pause"},
+			mocker: func(d *mocks.MockDaemon) {
+				d.EXPECT().SetBackupSchedule("mock-avs-default", daemon.BackupSchedule{
+					Cron:        "0 3 * * *",
+					Compression: "zstd",
+					Consistency: "pause",
+					Retention:   daemon.BackupRetention{KeepDaily: 7, KeepWeekly: 4},
+				}).Return(nil)
+			},
+		},
+		{
+			name: "encrypted schedule",
+			args: []string{"mock-avs-default", "--cron", "@daily", "--passphrase-file", "/etc/eigenlayer/passphrase"},
+			mocker: func(d *mocks.MockDaemon) {
+				d.EXPECT().SetBackupSchedule("mock-avs-default", daemon.BackupSchedule{
+					Cron:           "@daily",
+					Compression:    "zstd",
+					Consistency:    "stop",
+					PassphraseFile: "/etc/eigenlayer/passphrase",
+				}).Return(nil)
+			},
+		},
+		{
+			name: "daemon error",
+			args: []string{"mock-avs-default", "--cron", "@daily"},
+			err:  daemon.ErrInstanceNotFound,
+			mocker: func(d *mocks.MockDaemon) {
+				d.EXPECT().SetBackupSchedule("mock-avs-default", daemon.BackupSchedule{
+					Cron:        "@daily",
+					Compression: "zstd",
+					Consistency: "stop",
+				}).Return(daemon.ErrInstanceNotFound)
+			},
+		},
+		{
+			name: "remove schedule",
+			args: []string{"rm", "mock-avs-default"},
+			mocker: func(d *mocks.MockDaemon) {
+				d.EXPECT().RemoveBackupSchedule("mock-avs-default").Return(nil)
+			},
+		},
+		{
+			name: "remove missing schedule",
+			args: []string{"rm", "mock-avs-default"},
+			err:  daemon.ErrBackupScheduleNotFound,
+			mocker: func(d *mocks.MockDaemon) {
+				d.EXPECT().RemoveBackupSchedule("mock-avs-default").Return(daemon.ErrBackupScheduleNotFound)
+			},
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			d := mocks.NewMockDaemon(ctrl)
+			if tt.mocker != nil {
+				tt.mocker(d)
+			}
+
+			cmd := BackupScheduleCmd(d)
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(&bytes.Buffer{})
+			cmd.SetErr(&bytes.Buffer{})
+			err := cmd.Execute()
+
+			if tt.err == assert.AnError {
+				assert.Error(t, err)
+			} else if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestBackupScheduleLs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	d := mocks.NewMockDaemon(ctrl)
+	d.EXPECT().BackupSchedules().Return([]daemon.BackupScheduleInfo{
+		{
+			InstanceId: "mock-avs-default",
+			BackupSchedule: daemon.BackupSchedule{
+				Cron:      "0 3 * * *",
+				Retention: daemon.BackupRetention{KeepLast: 2, KeepMonthly: 6},
+			},
+			LastRun: time.Date(2023, 10, 4, 3, 0, 0, 0, time.UTC),
+			NextRun: time.Date(2023, 10, 5, 3, 0, 0, 0, time.UTC),
+		},
+		{
+			InstanceId: "mock-avs-second",
+			BackupSchedule: daemon.BackupSchedule{
+				Cron: "@weekly",
+			},
+			NextRun: time.Date(2023, 10, 8, 0, 0, 0, 0, time.UTC),
+		},
+	}, nil)
+
+	var out bytes.Buffer
+	cmd := BackupScheduleLsCmd(d)
+	cmd.SetArgs([]string{})
+	cmd.SetOut(&out)
+	assert.NoError(t, cmd.Execute())
+	assert.Equal(t, ""+
+		"AVS Instance ID     CRON         RETENTION           LAST RUN               NEXT RUN               \n"+
+		"mock-avs-default    0 3 * * *    last=2,monthly=6    2023-10-04 03:00:00    2023-10-05 03:00:00    \n"+
+		"mock-avs-second     @weekly      all                 -                      2023-10-08 00:00:00    \n",
+		out.String())
+}
@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/NethermindEth/eigenlayer/cli/mocks"
+	prompterMock "github.com/NethermindEth/eigenlayer/cli/prompter/mocks"
+	"github.com/NethermindEth/eigenlayer/pkg/daemon"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackup(t *testing.T) {
+	passphraseFile := filepath.Join(t.TempDir(), "passphrase")
+	require.NoError(t, os.WriteFile(passphraseFile, []byte("secret\r\n"), 0o600))
+	emptyFile := filepath.Join(t.TempDir(), "empty")
+	require.NoError(t, os.WriteFile(emptyFile, nil, 0o600))
+
+	tc := []struct {
+		name   string
+		args   []string
+		err    error
+		mocker func(d *mocks.MockDaemon, p *prompterMock.MockPrompter)
+	}{
+		{
+			name: "backup",
+			args: []string{"mock-avs-default"},
+			mocker: func(d *mocks.MockDaemon, p *prompterMock.MockPrompter) {
+				d.EXPECT().Backup(gomock.Any(), "mock-avs-default", daemon.BackupOptions{Compression: "zstd", Consistency: "stop"}).Return("backup-id", nil)
+			},
+		},
+		{
+			name: "pause consistency",
+			args: []string{"mock-avs-default", "--consistency", "pause"},
+			mocker: func(d *mocks.MockDaemon, p *prompterMock.MockPrompter) {
+				d.EXPECT().Backup(gomock.Any(), "mock-avs-default", daemon.BackupOptions{Compression: "zstd", Consistency: "pause"}).Return("backup-id", nil)
+			},
+		},
+		{
+			name: "include and exclude volumes",
+			args: []string{"mock-avs-default", "--include", "main-service,option-returner:/data", "--exclude", "main-service:/tmp"},
+			mocker: func(d *mocks.MockDaemon, p *prompterMock.MockPrompter) {
+				d.EXPECT().Backup(gomock.Any(), "mock-avs-default", daemon.BackupOptions{
+					Compression: "zstd",
+					Consistency: "stop",
+					Include:     []string{"main-service", "option-returner:/data"},
+					Exclude:     []string{"main-service:/tmp"},
+				}).Return("backup-id", nil)
+			},
+		},
+		{
+			name: "config only",
+			args: []string{"mock-avs-default", "--config-only"},
+			mocker: func(d *mocks.MockDaemon, p *prompterMock.MockPrompter) {
+				d.EXPECT().Backup(gomock.Any(), "mock-avs-default", daemon.BackupOptions{Compression: "zstd", Consistency: "stop", ConfigOnly: true}).Return("backup-id", nil)
+			},
+		},
+		{
+			name: "backup error",
+			args: []string{"mock-avs-default", "--compression", "gzip"},
+			err:  assert.AnError,
+			mocker: func(d *mocks.MockDaemon, p *prompterMock.MockPrompter) {
+				d.EXPECT().Backup(gomock.Any(), "mock-avs-default", daemon.BackupOptions{Compression: "gzip", Consistency: "stop"}).Return("", assert.AnError)
+			},
+		},
+		{
+			name: "encrypt with passphrase prompt",
+			args: []string{"mock-avs-default", "--encrypt"},
+			mocker: func(d *mocks.MockDaemon, p *prompterMock.MockPrompter) {
+				gomock.InOrder(
+					p.EXPECT().InputHiddenString("Enter the passphrase to encrypt the backup:", gomock.Any(), gomock.Any()).Return("secret", nil),
+					p.EXPECT().InputHiddenString("Confirm the passphrase:", "", gomock.Any()).Return("secret", nil),
+					d.EXPECT().Backup(gomock.Any(), "mock-avs-default", daemon.BackupOptions{Compression: "zstd", Consistency: "stop", Passphrase: "secret"}).Return("backup-id", nil),
+				)
+			},
+		},
+		{
+			name: "passphrase mismatch",
+			args: []string{"mock-avs-default", "--encrypt"},
+			err:  ErrPassphraseMismatch,
+			mocker: func(d *mocks.MockDaemon, p *prompterMock.MockPrompter) {
+				gomock.InOrder(
+					p.EXPECT().InputHiddenString("Enter the passphrase to encrypt the backup:", gomock.Any(), gomock.Any()).Return("secret", nil),
+					p.EXPECT().InputHiddenString("Confirm the passphrase:", "", gomock.Any()).Return("secrte", nil),
+				)
+			},
+		},
+		{
+			name: "encrypt with passphrase file",
+			args: []string{"mock-avs-default", "--passphrase-file", passphraseFile},
+			mocker: func(d *mocks.MockDaemon, p *prompterMock.MockPrompter) {
+				d.EXPECT().Backup(gomock.Any(), "mock-avs-default", daemon.BackupOptions{Compression: "zstd", Consistency: "stop", Passphrase: "secret"}).Return("backup-id", nil)
+			},
+		},
+		{
+			name: "empty passphrase file",
+			args: []string{"mock-avs-default", "--passphrase-file", emptyFile},
+			err:  ErrEmptyPassphrase,
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			d := mocks.NewMockDaemon(ctrl)
+			p := prompterMock.NewMockPrompter(ctrl)
+
+			if tt.mocker != nil {
+				tt.mocker(d, p)
+			}
+
+			cmd := BackupCmd(d, p)
+
+			cmd.SetArgs(tt.args)
+			err := cmd.Execute()
+
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
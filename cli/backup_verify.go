@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"errors"
+
+	"github.com/NethermindEth/eigenlayer/cli/prompter"
+	"github.com/NethermindEth/eigenlayer/pkg/daemon"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+func BackupVerifyCmd(d daemon.Daemon, p prompter.Prompter) *cobra.Command {
+	var (
+		backupId       string
+		options        daemon.BackupVerifyOptions
+		passphraseFile string
+	)
+	cmd := cobra.Command{
+		Use:   "verify <backup-id>",
+		Short: "Verify that a backup is restorable",
+		Long:  "Verify that a backup is restorable without restoring it. The backup data is read and checked against the checksums recorded when the backup was created, the structure of the backup is validated, and the instance state is checked. With --restore, the backup is also restored as a temporary instance, which is removed afterwards with its volumes. Backups of instances using bind mounts outside the instance directory, or external or explicitly named volumes are not restored with --restore, as the temporary instance would share them with the instance of the backup. Fixed container names are removed from the temporary instance, whose containers are named after it. The secrets of the instance must be stored on this host, as they are not included in backups; restore the backup with 'eigenlayer restore --tag <tag>' to enter them. The passphrase of encrypted backups is read from the --passphrase-file file, or asked interactively.",
+		Args:  cobra.ExactArgs(1),
+		PreRun: func(cmd *cobra.Command, args []string) {
+			backupId = args[0]
+		},
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			if passphraseFile != "" {
+				options.Passphrase, err = readPassphraseFile(passphraseFile)
+				if err != nil {
+					return err
+				}
+			}
+			result, err := d.BackupVerify(backupId, options)
+			if errors.Is(err, daemon.ErrBackupEncrypted) && passphraseFile == "" {
+				options.Passphrase, err = p.InputHiddenString("Enter the passphrase to decrypt the backup:", "", validatePassphrase)
+				if err != nil {
+					return err
+				}
+				result, err = d.BackupVerify(backupId, options)
+			}
+			if err != nil {
+				return err
+			}
+			if !result.Checksums {
+				log.Warnf("Backup %s has no checksums, it was created by a previous version", backupId)
+			}
+			if result.Restored {
+				log.Infof("Backup %s verified: %d files, restored successfully", backupId, result.Files)
+			} else {
+				log.Infof("Backup %s verified: %d files", backupId, result.Files)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&options.Restore, "restore", false, "restore the backup as a temporary instance, removed afterwards")
+	cmd.Flags().StringVar(&passphraseFile, "passphrase-file", "", "read the passphrase of encrypted backups from the given file")
+	return &cmd
+}
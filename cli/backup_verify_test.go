@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/NethermindEth/eigenlayer/cli/mocks"
+	prompterMock "github.com/NethermindEth/eigenlayer/cli/prompter/mocks"
+	"github.com/NethermindEth/eigenlayer/pkg/daemon"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackupVerify(t *testing.T) {
+	passphraseFile := filepath.Join(t.TempDir(), "passphrase")
+	require.NoError(t, os.WriteFile(passphraseFile, []byte("secret\n"), 0o600))
+
+	tc := []struct {
+		name   string
+		args   []string
+		err    error
+		mocker func(d *mocks.MockDaemon, p *prompterMock.MockPrompter)
+	}{
+		{
+			name: "no args",
+			args: []string{},
+			err:  errors.New("accepts 1 arg(s), received 0"),
+		},
+		{
+			name: "valid backup",
+			args: []string{"backup-id"},
+			mocker: func(d *mocks.MockDaemon, p *prompterMock.MockPrompter) {
+				d.EXPECT().BackupVerify("backup-id", daemon.BackupVerifyOptions{}).Return(daemon.BackupVerification{Files: 4, Checksums: true}, nil)
+			},
+		},
+		{
+			name: "trial restore",
+			args: []string{"backup-id", "--restore"},
+			mocker: func(d *mocks.MockDaemon, p *prompterMock.MockPrompter) {
+				d.EXPECT().BackupVerify("backup-id", daemon.BackupVerifyOptions{Restore: true}).Return(daemon.BackupVerification{Files: 4, Checksums: true, Restored: true}, nil)
+			},
+		},
+		{
+			name: "corrupted backup",
+			args: []string{"backup-id"},
+			err:  daemon.ErrCorruptedBackup,
+			mocker: func(d *mocks.MockDaemon, p *prompterMock.MockPrompter) {
+				d.EXPECT().BackupVerify("backup-id", daemon.BackupVerifyOptions{}).Return(daemon.BackupVerification{}, daemon.ErrCorruptedBackup)
+			},
+		},
+		{
+			name: "encrypted backup, passphrase file",
+			args: []string{"backup-id", "--passphrase-file", passphraseFile},
+			mocker: func(d *mocks.MockDaemon, p *prompterMock.MockPrompter) {
+				d.EXPECT().BackupVerify("backup-id", daemon.BackupVerifyOptions{Passphrase: "secret"}).Return(daemon.BackupVerification{Files: 4}, nil)
+			},
+		},
+		{
+			name: "encrypted backup, passphrase prompt",
+			args: []string{"backup-id"},
+			mocker: func(d *mocks.MockDaemon, p *prompterMock.MockPrompter) {
+				gomock.InOrder(
+					d.EXPECT().BackupVerify("backup-id", daemon.BackupVerifyOptions{}).Return(daemon.BackupVerification{}, daemon.ErrBackupEncrypted),
+					p.EXPECT().InputHiddenString("Enter the passphrase to decrypt the backup:", "", gomock.Any()).Return("secret", nil),
+					d.EXPECT().BackupVerify("backup-id", daemon.BackupVerifyOptions{Passphrase: "secret"}).Return(daemon.BackupVerification{Files: 4, Checksums: true}, nil),
+				)
+			},
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			d := mocks.NewMockDaemon(ctrl)
+			p := prompterMock.NewMockPrompter(ctrl)
+
+			if tt.mocker != nil {
+				tt.mocker(d, p)
+			}
+
+			cmd := BackupCmd(d, p)
+
+			cmd.SetArgs(append([]string{"verify"}, tt.args...))
+			err := cmd.Execute()
+
+			if tt.err != nil && !errors.Is(err, tt.err) {
+				assert.EqualError(t, err, tt.err.Error())
+			} else if tt.err == nil {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
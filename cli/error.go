@@ -3,8 +3,15 @@ package cli
 import "errors"
 
 var (
-	ErrInvalidURL           = errors.New("invalid URL")
-	ErrOptionWithoutDefault = errors.New("option without default value")
-	ErrInvalidNumberOfArgs  = errors.New("invalid number of arguments")
-	ErrInvalidArgs          = errors.New("invalid arguments")
+	ErrInvalidURL             = errors.New("invalid URL")
+	ErrOptionWithoutDefault   = errors.New("option without default value")
+	ErrInvalidNumberOfArgs    = errors.New("invalid number of arguments")
+	ErrInvalidArgs            = errors.New("invalid arguments")
+	ErrIncompatibleData       = errors.New("new version can not reuse the data of the current instance")
+	ErrInvalidValuesFile      = errors.New("invalid values file")
+	ErrUnknownOption          = errors.New("unknown option")
+	ErrOptionValuesLost       = errors.New("option values of the current instance can not be kept")
+	ErrPassphraseMismatch     = errors.New("passphrases do not match")
+	ErrEmptyPassphrase        = errors.New("empty passphrase")
+	ErrScheduledBackupsFailed = errors.New("scheduled backups failed")
 )
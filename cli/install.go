@@ -12,14 +12,15 @@ import (
 
 func InstallCmd(d daemon.Daemon, p prompter.Prompter) *cobra.Command {
 	var (
-		url      string
-		version  string
-		profile  string
-		tag      string
-		commit   string
-		noPrompt bool
-		help     bool
-		yes      bool
+		url        string
+		version    string
+		profile    string
+		tag        string
+		commit     string
+		valuesPath string
+		noPrompt   bool
+		help       bool
+		yes        bool
 	)
 	cmd := cobra.Command{
 		Use:   "install [flags] <repository_url>",
@@ -29,6 +30,10 @@ Installs the AVS node software by downloading it from a git repository. The
 repository URL is required as the unique argument, which must be an HTTP or 
 HTTPS URL. Use the --version flag if you need to specify a version.
 
+Packages could also be downloaded from a container registry as OCI artifacts,
+using an URL like oci://<registry>/<repository>, or as versioned tarballs listed
+in an index file, using an URL like tar+https://<host>/<path>/index.yml.
+
 To preselect a profile, use the --profile flag and the CLI will not prompt you
 to select a profile, meaning that the correct profile selection is the user's
 responsibility in this case.
@@ -42,6 +47,12 @@ Profile options can be specified using the --option.<option-name> flag. The
 options are dynamic and depend on the profile selected. If the profile is not
 specified, the CLI will prompt you to select a profile. It is responsibility of
 the user to know which options are available for each profile.
+
+Option values could also be read from a YAML values file with the --values flag.
+The values file maps profile names to the values of their options, and could be
+generated with the default values of a package using the pull command with the
+--dump-values flag. Options specified with flags override the values file, and
+options with a value are not prompted.
 `,
 		DisableFlagParsing: true,
 		PreRunE: func(cmd *cobra.Command, args []string) error {
@@ -92,6 +103,21 @@ the user to know which options are available for each profile.
 				log.Infof("Commit %s", pullResult.Commit)
 			}
 
+			// Read values file
+			var values valuesFile
+			if valuesPath != "" {
+				values, err = readValuesFile(valuesPath)
+				if err != nil {
+					return err
+				}
+				if profile == "" && len(values) == 1 {
+					// The values file selects the profile
+					for profileName := range values {
+						profile = profileName
+					}
+				}
+			}
+
 			// Select profile if not specified
 			if !noPrompt && profile == "" {
 				profileNames := make([]string, 0, len(pullResult.Options))
@@ -110,21 +136,9 @@ the user to know which options are available for each profile.
 			}
 
 			// Check profile hardware requirements
-			requirements := pullResult.HardwareRequirements[profile]
-
-			ok, err = d.CheckHardwareRequirements(requirements)
-			if err != nil {
+			if err = checkHardwareRequirements(d, profile, pullResult.HardwareRequirements[profile]); err != nil {
 				return err
 			}
-			if !ok {
-				log.Printf("Hardware requirements: %s", requirements)
-				if requirements.StopIfRequirementsAreNotMet {
-					return fmt.Errorf("profile %s does not meet the hardware requirements", profile)
-				}
-				log.Warnf("Profile %s does not meet the hardware requirements", profile)
-			} else {
-				log.Infof("Profile %s meets the hardware requirements", profile)
-			}
 
 			// Build dynamic flags with the profile options
 			for _, o := range profileOptions {
@@ -137,33 +151,57 @@ the user to know which options are available for each profile.
 				return err
 			}
 
+			// Values from the values file are used as flags, unless the flag
+			// is already set
+			for name, value := range values[profile] {
+				flag := cmd.Flags().Lookup("option." + name)
+				if flag == nil {
+					return fmt.Errorf("%w: %s", ErrUnknownOption, name)
+				}
+				if !flag.Changed {
+					if err = cmd.Flags().Set(flag.Name, string(value)); err != nil {
+						return err
+					}
+				}
+			}
+
 			// Fill options
+			optionValues := make(map[string]string, len(profileOptions))
 			for _, o := range profileOptions {
-				flagValue, err := cmd.Flags().GetString("option." + o.Name())
+				name := o.Name()
+				flagValue, err := cmd.Flags().GetString("option." + name)
 				if err != nil {
 					return err
 				}
-				if noPrompt {
+				provided := cmd.Flags().Changed("option." + name)
+				if !o.Active(optionValues) && !provided {
+					// Inactive options are not asked and keep their default value
+					optionValues[name] = o.Default()
+					continue
+				}
+				if noPrompt || provided {
 					if flagValue == "" {
-						return fmt.Errorf("%w: %s", ErrOptionWithoutDefault, o.Name())
+						return fmt.Errorf("%w: %s", ErrOptionWithoutDefault, name)
 					}
-					if err = o.Set(flagValue); err != nil {
+					if err = setOptionValue(o, flagValue); err != nil {
 						return err
 					}
+					optionValues[name] = flagValue
 				} else {
-					var err error
+					var value string
 					if o.Hidden() {
-						_, err = p.InputHiddenString(o.Name(), o.Help(), func(s string) error {
+						value, err = p.InputHiddenString(name, o.Help(), func(s string) error {
 							return o.Set(s)
 						})
 					} else {
-						_, err = p.InputString(o.Name(), o.Default(), o.Help(), func(s string) error {
-							return o.Set(s)
+						value, err = p.InputString(name, o.Default(), o.Help(), func(s string) error {
+							return setOptionValue(o, s)
 						})
 					}
 					if err != nil {
 						return err
 					}
+					optionValues[name] = value
 				}
 			}
 
@@ -191,9 +229,9 @@ the user to know which options are available for each profile.
 			}
 			log.Info("Installed successfully with instance id: ", instanceId)
 
-			if pullResult.HasPlugin {
+			if image, ok := pullResult.PluginImages[profile]; ok {
 				// TODO: improve this message with the command to run the plugin
-				log.Info("The installed node software has a plugin.")
+				log.Infof("The installed node software has a plugin with image %s.", image)
 			}
 
 			ok = yes
@@ -214,8 +252,39 @@ the user to know which options are available for each profile.
 	cmd.Flags().StringVar(&commit, "commit", "", "commit to install from. If not specified the latest version will be installed.")
 	cmd.Flags().StringVarP(&profile, "profile", "p", "", "profile to use for the new instance name. If not specified a list of available profiles will be shown to select from.")
 	cmd.Flags().StringVarP(&tag, "tag", "t", "default", "tag to use for the new instance name.")
+	cmd.Flags().StringVar(&valuesPath, "values", "", "YAML file with the option values of each profile.")
 	cmd.Flags().BoolVar(&noPrompt, "no-prompt", false, "disable command prompts, and all options should be passed using command flags.")
 	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "skip confirmation prompts.")
 	cmd.MarkFlagsMutuallyExclusive("version", "commit")
 	return &cmd
 }
+
+// checkHardwareRequirements checks the given hardware requirements of the
+// profile, returning an error if they are not met and the profile requires to
+// stop in that case.
+func checkHardwareRequirements(d daemon.Daemon, profile string, requirements daemon.HardwareRequirements) error {
+	ok, err := d.CheckHardwareRequirements(requirements)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		log.Printf("Hardware requirements: %s", requirements)
+		if requirements.StopIfRequirementsAreNotMet {
+			return fmt.Errorf("profile %s does not meet the hardware requirements", profile)
+		}
+		log.Warnf("Profile %s does not meet the hardware requirements", profile)
+	} else {
+		log.Infof("Profile %s meets the hardware requirements", profile)
+	}
+	return nil
+}
+
+// setOptionValue sets the value of the option, unless the value is the
+// template default of the option. In that case the option is left unset, so
+// the daemon renders its default on install.
+func setOptionValue(o daemon.Option, value string) error {
+	if daemon.IsDefaultTemplate(o) && value == o.Default() {
+		return nil
+	}
+	return o.Set(value)
+}
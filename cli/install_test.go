@@ -1,16 +1,22 @@
 package cli
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/golang/mock/gomock"
+	log "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	daemonMock "github.com/NethermindEth/eigenlayer/cli/mocks"
 	prompterMock "github.com/NethermindEth/eigenlayer/cli/prompter/mocks"
 	"github.com/NethermindEth/eigenlayer/internal/common"
+	"github.com/NethermindEth/eigenlayer/internal/profile"
 	"github.com/NethermindEth/eigenlayer/pkg/daemon"
 )
 
@@ -42,7 +48,8 @@ func TestInstall(t *testing.T) {
 			err:  nil,
 			daemonMock: func(d *daemonMock.MockDaemon, p *prompterMock.MockPrompter) {
 				option := daemonMock.NewMockOption(gomock.NewController(t))
-				option.EXPECT().Name().Return("option1").Times(3)
+				option.EXPECT().Active(gomock.Any()).Return(true).AnyTimes()
+				option.EXPECT().Name().Return("option1").Times(2)
 				option.EXPECT().Default().Return("default1").Times(2)
 				option.EXPECT().Help().Return("help1").Times(2)
 				option.EXPECT().Hidden().Return(false)
@@ -92,7 +99,8 @@ func TestInstall(t *testing.T) {
 			err:  nil,
 			daemonMock: func(d *daemonMock.MockDaemon, p *prompterMock.MockPrompter) {
 				option := daemonMock.NewMockOption(gomock.NewController(t))
-				option.EXPECT().Name().Return("option1").Times(3)
+				option.EXPECT().Active(gomock.Any()).Return(true).AnyTimes()
+				option.EXPECT().Name().Return("option1").Times(2)
 				option.EXPECT().Default().Return("default1")
 				option.EXPECT().Help().Return("help1").Times(2)
 				option.EXPECT().Hidden().Return(true)
@@ -141,7 +149,8 @@ func TestInstall(t *testing.T) {
 			err:  assert.AnError,
 			daemonMock: func(d *daemonMock.MockDaemon, p *prompterMock.MockPrompter) {
 				option := daemonMock.NewMockOption(gomock.NewController(t))
-				option.EXPECT().Name().Return("option1").Times(3)
+				option.EXPECT().Active(gomock.Any()).Return(true).AnyTimes()
+				option.EXPECT().Name().Return("option1").Times(2)
 				option.EXPECT().Default().Return("default1").Times(2)
 				option.EXPECT().Help().Return("help1").Times(2)
 				option.EXPECT().Hidden().Return(false).AnyTimes()
@@ -171,7 +180,8 @@ func TestInstall(t *testing.T) {
 			err:  assert.AnError,
 			daemonMock: func(d *daemonMock.MockDaemon, p *prompterMock.MockPrompter) {
 				option := daemonMock.NewMockOption(gomock.NewController(t))
-				option.EXPECT().Name().Return("option1").Times(3)
+				option.EXPECT().Active(gomock.Any()).Return(true).AnyTimes()
+				option.EXPECT().Name().Return("option1").Times(2)
 				option.EXPECT().Default().Return("default1").Times(2)
 				option.EXPECT().Help().Return("help1").Times(2)
 				option.EXPECT().Hidden().Return(false)
@@ -211,7 +221,8 @@ func TestInstall(t *testing.T) {
 			err:  assert.AnError,
 			daemonMock: func(d *daemonMock.MockDaemon, p *prompterMock.MockPrompter) {
 				option := daemonMock.NewMockOption(gomock.NewController(t))
-				option.EXPECT().Name().Return("option1").Times(3)
+				option.EXPECT().Active(gomock.Any()).Return(true).AnyTimes()
+				option.EXPECT().Name().Return("option1").Times(2)
 				option.EXPECT().Default().Return("default1").Times(2)
 				option.EXPECT().Help().Return("help1").Times(2)
 				option.EXPECT().Hidden().Return(false)
@@ -250,7 +261,8 @@ func TestInstall(t *testing.T) {
 			err:  nil,
 			daemonMock: func(d *daemonMock.MockDaemon, p *prompterMock.MockPrompter) {
 				option := daemonMock.NewMockOption(gomock.NewController(t))
-				option.EXPECT().Name().Return("option1").Times(3)
+				option.EXPECT().Active(gomock.Any()).Return(true).AnyTimes()
+				option.EXPECT().Name().Return("option1").Times(2)
 				option.EXPECT().Default().Return("default1").Times(2)
 				option.EXPECT().Help().Return("help1").Times(2)
 				option.EXPECT().Hidden().Return(false)
@@ -289,7 +301,8 @@ func TestInstall(t *testing.T) {
 			err:  assert.AnError,
 			daemonMock: func(d *daemonMock.MockDaemon, p *prompterMock.MockPrompter) {
 				option := daemonMock.NewMockOption(gomock.NewController(t))
-				option.EXPECT().Name().Return("option1").Times(3)
+				option.EXPECT().Active(gomock.Any()).Return(true).AnyTimes()
+				option.EXPECT().Name().Return("option1").Times(2)
 				option.EXPECT().Default().Return("default1").Times(2)
 				option.EXPECT().Help().Return("help1").Times(2)
 				option.EXPECT().Hidden().Return(false)
@@ -328,7 +341,8 @@ func TestInstall(t *testing.T) {
 			err:  errors.New("input string error"),
 			daemonMock: func(d *daemonMock.MockDaemon, p *prompterMock.MockPrompter) {
 				option := daemonMock.NewMockOption(gomock.NewController(t))
-				option.EXPECT().Name().Return("option1").Times(3)
+				option.EXPECT().Active(gomock.Any()).Return(true).AnyTimes()
+				option.EXPECT().Name().Return("option1").Times(2)
 				option.EXPECT().Default().Return("default1").Times(2)
 				option.EXPECT().Help().Return("help1").Times(2)
 				option.EXPECT().Hidden().Return(false)
@@ -403,7 +417,8 @@ func TestInstall(t *testing.T) {
 			err:  errors.New("install error"),
 			daemonMock: func(d *daemonMock.MockDaemon, p *prompterMock.MockPrompter) {
 				option := daemonMock.NewMockOption(gomock.NewController(t))
-				option.EXPECT().Name().Return("option1").Times(3)
+				option.EXPECT().Active(gomock.Any()).Return(true).AnyTimes()
+				option.EXPECT().Name().Return("option1").Times(2)
 				option.EXPECT().Default().Return("default1").Times(2)
 				option.EXPECT().Help().Return("help1").Times(2)
 				option.EXPECT().Hidden().Return(false)
@@ -503,7 +518,8 @@ func TestInstall(t *testing.T) {
 			err:  nil,
 			daemonMock: func(d *daemonMock.MockDaemon, p *prompterMock.MockPrompter) {
 				option := daemonMock.NewMockOption(gomock.NewController(t))
-				option.EXPECT().Name().Return("option1").Times(3)
+				option.EXPECT().Active(gomock.Any()).Return(true).AnyTimes()
+				option.EXPECT().Name().Return("option1").Times(2)
 				option.EXPECT().Default().Return("default1").Times(2)
 				option.EXPECT().Help().Return("help1").Times(2)
 				option.EXPECT().Hidden().Return(false)
@@ -573,3 +589,202 @@ func TestInstall(t *testing.T) {
 		})
 	}
 }
+
+func TestInstall_ValuesFile(t *testing.T) {
+	valuesPath := filepath.Join(t.TempDir(), "values.yml")
+	err := os.WriteFile(valuesPath, []byte("profile1:\n  option1: file-value\n  option2: [a, b]\n"), 0o644)
+	require.NoError(t, err)
+	unknownValuesPath := filepath.Join(t.TempDir(), "values.yml")
+	err = os.WriteFile(unknownValuesPath, []byte("profile1:\n  unknown: value\n"), 0o644)
+	require.NoError(t, err)
+
+	ts := []struct {
+		name   string
+		args   []string
+		values map[string]string
+		err    error
+	}{
+		{
+			name:   "values from file",
+			args:   []string{"--values", valuesPath, "--no-prompt", "--yes", common.MockAvsPkg.Repo()},
+			values: map[string]string{"option1": "file-value", "option2": "a,b"},
+		},
+		{
+			name:   "flags override the values file",
+			args:   []string{"--values", valuesPath, "--option.option1", "flag-value", "--yes", common.MockAvsPkg.Repo()},
+			values: map[string]string{"option1": "flag-value", "option2": "a,b"},
+		},
+		{
+			name: "unknown option",
+			args: []string{"--values", unknownValuesPath, "--no-prompt", common.MockAvsPkg.Repo()},
+			err:  fmt.Errorf("%w: unknown", ErrUnknownOption),
+		},
+	}
+	for _, tc := range ts {
+		t.Run(tc.name, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			d := daemonMock.NewMockDaemon(controller)
+			p := prompterMock.NewMockPrompter(controller)
+
+			var options []daemon.Option
+			for _, name := range []string{"option1", "option2"} {
+				option := daemonMock.NewMockOption(controller)
+				option.EXPECT().Name().Return(name).AnyTimes()
+				option.EXPECT().Default().Return("").AnyTimes()
+				option.EXPECT().Help().Return("help").AnyTimes()
+				option.EXPECT().Active(gomock.Any()).Return(true).AnyTimes()
+				if tc.err == nil {
+					option.EXPECT().Set(tc.values[name]).Return(nil)
+				}
+				options = append(options, option)
+			}
+			d.EXPECT().Pull(common.MockAvsPkg.Repo(), daemon.PullTarget{}, true).Return(daemon.PullResult{
+				Version: common.MockAvsPkg.Version(),
+				Options: map[string][]daemon.Option{"profile1": options},
+			}, nil)
+			d.EXPECT().CheckHardwareRequirements(daemon.HardwareRequirements{}).Return(true, nil)
+			if tc.err == nil {
+				d.EXPECT().InitMonitoring(false, false).Return(nil)
+				d.EXPECT().Install(daemon.InstallOptions{
+					URL:     common.MockAvsPkg.Repo(),
+					Version: common.MockAvsPkg.Version(),
+					Profile: "profile1",
+					Options: options,
+					Tag:     "default",
+				}).Return("mock-avs-pkg-default", nil)
+				d.EXPECT().Run("mock-avs-pkg-default").Return(nil)
+			}
+
+			installCmd := InstallCmd(d, p)
+			installCmd.SetArgs(tc.args)
+			err := installCmd.Execute()
+			if tc.err == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.err.Error())
+			}
+		})
+	}
+}
+
+func TestInstall_Secret(t *testing.T) {
+	const secret = "s3cr3t-api-key"
+
+	ts := []struct {
+		name   string
+		args   []string
+		prompt bool
+		err    error
+	}{
+		{
+			name:   "prompted as hidden",
+			args:   []string{"--profile", "profile1", "--yes", common.MockAvsPkg.Repo()},
+			prompt: true,
+		},
+		{
+			name: "from flag",
+			args: []string{"--profile", "profile1", "--option.api-key", secret, "--no-prompt", "--yes", common.MockAvsPkg.Repo()},
+		},
+		{
+			name: "no prompt without value",
+			args: []string{"--profile", "profile1", "--no-prompt", common.MockAvsPkg.Repo()},
+			err:  fmt.Errorf("%w: api-key", ErrOptionWithoutDefault),
+		},
+	}
+	for _, tc := range ts {
+		t.Run(tc.name, func(t *testing.T) {
+			out := captureLogs(t)
+			controller := gomock.NewController(t)
+			d := daemonMock.NewMockDaemon(controller)
+			p := prompterMock.NewMockPrompter(controller)
+
+			option := daemon.NewOptionSecret(profile.Option{Name: "api-key", Target: "API_KEY", Type: "secret", Help: "API key"})
+			d.EXPECT().Pull(common.MockAvsPkg.Repo(), daemon.PullTarget{}, true).Return(daemon.PullResult{
+				Version: common.MockAvsPkg.Version(),
+				Options: map[string][]daemon.Option{"profile1": {option}},
+			}, nil)
+			d.EXPECT().CheckHardwareRequirements(daemon.HardwareRequirements{}).Return(true, nil)
+			if tc.prompt {
+				p.EXPECT().InputHiddenString("api-key", "API key", gomock.Any()).
+					DoAndReturn(func(_, _ string, validator func(string) error) (string, error) {
+						return secret, validator(secret)
+					})
+			}
+			if tc.err == nil {
+				d.EXPECT().InitMonitoring(false, false).Return(nil)
+				d.EXPECT().Install(daemon.InstallOptions{
+					URL:     common.MockAvsPkg.Repo(),
+					Version: common.MockAvsPkg.Version(),
+					Profile: "profile1",
+					Options: []daemon.Option{option},
+					Tag:     "default",
+				}).DoAndReturn(func(options daemon.InstallOptions) (string, error) {
+					// The secret is passed to the daemon as it was entered
+					v, err := options.Options[0].Value()
+					require.NoError(t, err)
+					assert.Equal(t, secret, v)
+					return "mock-avs-pkg-default", nil
+				})
+				d.EXPECT().Run("mock-avs-pkg-default").Return(nil)
+			}
+
+			installCmd := InstallCmd(d, p)
+			installCmd.SetOut(out)
+			installCmd.SetErr(out)
+			installCmd.SetArgs(tc.args)
+			err := installCmd.Execute()
+			if tc.err == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.err.Error())
+			}
+			assert.NotContains(t, out.String(), secret)
+		})
+	}
+}
+
+// captureLogs returns a buffer with the logs written during the test, with the
+// debug level enabled.
+func captureLogs(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var out bytes.Buffer
+	output, level := log.StandardLogger().Out, log.GetLevel()
+	log.SetOutput(&out)
+	log.SetLevel(log.DebugLevel)
+	t.Cleanup(func() {
+		log.SetOutput(output)
+		log.SetLevel(level)
+	})
+	return &out
+}
+
+func TestSetOptionValue(t *testing.T) {
+	newOption := func(t *testing.T) daemon.Option {
+		o, err := daemon.NewOptionInt(profile.Option{
+			Name:    "workers",
+			Target:  "WORKERS",
+			Type:    "int",
+			Default: "{{ .CPUs }}",
+			Help:    "Number of workers",
+		})
+		require.NoError(t, err)
+		return o
+	}
+
+	t.Run("template default is left unset", func(t *testing.T) {
+		o := newOption(t)
+		require.NoError(t, setOptionValue(o, "{{ .CPUs }}"))
+		assert.False(t, o.IsSet())
+	})
+	t.Run("value is set", func(t *testing.T) {
+		o := newOption(t)
+		require.NoError(t, setOptionValue(o, "4"))
+		v, err := o.Value()
+		require.NoError(t, err)
+		assert.Equal(t, "4", v)
+	})
+	t.Run("invalid value", func(t *testing.T) {
+		o := newOption(t)
+		assert.Error(t, setOptionValue(o, "four"))
+	})
+}
@@ -22,21 +22,37 @@ func LocalInstallCmd(d daemon.Daemon) *cobra.Command {
 		run      bool
 		options  = make(map[string]string)
 		logDebug bool
+		secure   bool
+		sigPath  string
+		keyPaths []string
 	)
 	cmd := cobra.Command{
 		Use:   "local-install [flags] --profile <profile_name> <path>",
-		Short: "Install AVS node software from a local directory",
+		Short: "Install AVS node software from a local directory or tarball",
 		Long: `
-!!! THIS INSTALLATION METHOD IS INSECURE !!!
+!!! WITHOUT --secure THIS INSTALLATION METHOD IS INSECURE !!!
 !!! USE ONLY FOR DEVELOPMENT PURPOSES !!!
 
-Installs the AVS node software from a local directory. Make sure to select
-the correct profile and set its options properly.	
+Installs the AVS node software from a local directory or a package tarball
+(.tar.gz). Make sure to select the correct profile and set its options
+properly. Option values are validated against the option types of the profile.
+
+Use the --secure flag to install a package tarball, for instance built by a CI
+pipeline, with the same checks of the install command: package integrity check,
+hardware requirements check and signature verification. The tarball must be
+signed with an ed25519 key using, for instance:
+
+	openssl pkeyutl -sign -rawin -inkey key.pem -in pkg.tar.gz -out pkg.tar.gz.sig
+
+The signature is passed with the --signature flag, and the trusted public keys
+with the --trusted-key flag. The digest of the tarball is recorded as the
+provenance of the new instance.
 
 To ensure each instance of the node software is uniquely identified, use the
 --tag flag to create an unique id which is in the format of 
 <name>-<tag>. If the tag is not specified, the "default" tag will be 
-used.
+used. The name of packages installed from a tarball is the name in the package
+manifest, and the name of the directory otherwise.
 
 Profile options can be specified using the --option.<option-name> flag.
 Flags are the only way to specify options for local installations, and it is
@@ -44,7 +60,6 @@ the user's responsibility to know which options are available for each
 profile.`,
 		DisableFlagParsing: true,
 		PreRunE: func(cmd *cobra.Command, args []string) error {
-			log.Warn("This command is insecure and should only be used for development purposes")
 			if logDebug {
 				log.SetLevel(log.DebugLevel)
 			}
@@ -86,7 +101,19 @@ profile.`,
 			if err != nil {
 				return err
 			}
-			name = filepath.Base(path)
+			if !isTarball(path) {
+				name = filepath.Base(path)
+			}
+			if !secure {
+				log.Warn("This command is insecure and should only be used for development purposes")
+				return nil
+			}
+			if !isTarball(path) {
+				return fmt.Errorf("%w: secure installations require a package tarball", ErrInvalidArgs)
+			}
+			if sigPath == "" || len(keyPaths) == 0 {
+				return fmt.Errorf("%w: secure installations require --signature and --trusted-key", ErrInvalidArgs)
+			}
 			return nil
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -95,25 +122,40 @@ profile.`,
 				return cmd.Help()
 			}
 
-			// Create temporary tar file
-			tarFile, err := os.CreateTemp(os.TempDir(), "eigenlayer-local-install-*.tar.gz")
-			if err != nil {
-				return err
-			}
-
-			// Build tar file
-			err = utils.CompressToTarGz(path, tarFile)
-			if err != nil {
-				return err
+			installOptions := daemon.LocalInstallOptions{
+				Name:    name,
+				Tag:     tag,
+				Profile: profile,
+				Options: options,
+				Secure:  secure,
 			}
-			if err := tarFile.Close(); err != nil {
-				return err
+			if secure {
+				sigData, err := os.ReadFile(sigPath)
+				if err != nil {
+					return err
+				}
+				installOptions.Signature, err = daemon.ParseSignature(sigData)
+				if err != nil {
+					return err
+				}
+				for _, keyPath := range keyPaths {
+					keyData, err := os.ReadFile(keyPath)
+					if err != nil {
+						return err
+					}
+					key, err := daemon.ParsePublicKey(keyData)
+					if err != nil {
+						return fmt.Errorf("%s: %w", keyPath, err)
+					}
+					installOptions.TrustedKeys = append(installOptions.TrustedKeys, key)
+				}
 			}
 
-			tarFile, err = os.Open(tarFile.Name())
+			tarFile, err := localPackageTarball(path)
 			if err != nil {
 				return err
 			}
+			defer tarFile.Close()
 
 			// // If the monitoring stack is running, it needs to be initialized before the install
 			// // because if the install fails, the install cleanup will fail depending of the state.
@@ -124,12 +166,7 @@ profile.`,
 				return err
 			}
 
-			instanceId, err := d.LocalInstall(tarFile, daemon.LocalInstallOptions{
-				Name:    name,
-				Tag:     tag,
-				Profile: profile,
-				Options: options,
-			})
+			instanceId, err := d.LocalInstall(tarFile, installOptions)
 			if err != nil {
 				return err
 			}
@@ -145,7 +182,37 @@ profile.`,
 	cmd.Flags().BoolVarP(&run, "run", "r", false, "run the new instance after installation")
 	cmd.Flags().StringVarP(&profile, "profile", "p", "", "profile to use for the new instance. If not specified, the installation will fail.")
 	cmd.Flags().StringVarP(&tag, "tag", "t", "default", "tag to use for the new instance.")
+	cmd.Flags().BoolVar(&secure, "secure", false, "verify the package tarball signature, integrity and hardware requirements")
+	cmd.Flags().StringVar(&sigPath, "signature", "", "path to the detached ed25519 signature of the package tarball")
+	cmd.Flags().StringArrayVar(&keyPaths, "trusted-key", nil, "path to an ed25519 public key trusted to sign the package. Could be repeated.")
 
 	cmd.MarkFlagRequired("profile")
 	return &cmd
 }
+
+func isTarball(path string) bool {
+	return strings.HasSuffix(path, ".tar.gz") || strings.HasSuffix(path, ".tgz")
+}
+
+// localPackageTarball opens the package tarball in the given path. If the path
+// is a directory, it is compressed into a temporary tarball.
+func localPackageTarball(path string) (*os.File, error) {
+	if isTarball(path) {
+		return os.Open(path)
+	}
+	// Create temporary tar file
+	tarFile, err := os.CreateTemp(os.TempDir(), "eigenlayer-local-install-*.tar.gz")
+	if err != nil {
+		return nil, err
+	}
+
+	// Build tar file
+	err = utils.CompressToTarGz(path, tarFile)
+	if err != nil {
+		return nil, err
+	}
+	if err := tarFile.Close(); err != nil {
+		return nil, err
+	}
+	return os.Open(tarFile.Name())
+}
@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
@@ -33,7 +34,10 @@ Options of the new version can be specified using the --option.<option-name> fla
 
 To avoid any data loss during the update process, the user can specify the --backup
 flag. In this case, the current instance will be backed up before uninstalling it,
-and if the update process fails, the instance will be restored.`,
+and if the update process fails, the instance will be restored. Without --backup,
+a running instance is restarted if the migrations of the new version fail, but if
+the update fails once the current instance is uninstalled, the instance is left
+uninstalled and the previous version must be installed again to recover it.`,
 		DisableFlagParsing: true,
 		PreRunE: func(cmd *cobra.Command, args []string) error {
 			// Parse static flags
@@ -96,14 +100,24 @@ and if the update process fails, the instance will be restored.`,
 
 			// Log useful information about the update
 			logVersionChange(pullResult.OldVersion, pullResult.NewVersion)
+			logSpecVersionChange(pullResult.OldSpecVersion, pullResult.NewSpecVersion)
 			logCommitChange(pullResult.OldCommit, pullResult.NewCommit)
+			if err = checkHardwareRequirements(d, pullResult.Profile, pullResult.HardwareRequirements); err != nil {
+				return err
+			}
 			printOptionsTable(pullResult.OldOptions, pullResult.MergedOptions)
+			if err = confirmOptionChanges(pullResult, p, cmd.OutOrStdout(), yes, noPrompt); err != nil {
+				return err
+			}
 
 			// Build dynamic flags with the profile options
 			for _, o := range pullResult.MergedOptions {
 				v, err := o.Value()
 				if err != nil {
 					cmd.Flags().String("option."+o.Name(), o.Default(), o.Help())
+				} else if o.Hidden() {
+					// Hidden values are not shown as flag defaults
+					cmd.Flags().String("option."+o.Name(), "", o.Help())
 				} else {
 					cmd.Flags().String("option."+o.Name(), v, o.Help())
 				}
@@ -116,41 +130,66 @@ and if the update process fails, the instance will be restored.`,
 			}
 
 			// Fill necessary options
+			values := make(map[string]string, len(pullResult.MergedOptions))
 			for _, o := range pullResult.MergedOptions {
+				if !o.Active(values) && !o.IsSet() && !cmd.Flags().Changed("option."+o.Name()) {
+					// Inactive options are not asked and keep their default value
+					values[o.Name()] = o.Default()
+					continue
+				}
 				if noPrompt {
 					flagValue, err := cmd.Flags().GetString("option." + o.Name())
 					if err != nil {
 						return err
 					}
 					if flagValue == "" {
+						if o.Hidden() && o.IsSet() {
+							// Keep the current hidden value
+							values[o.Name()], _ = o.Value()
+							continue
+						}
 						return fmt.Errorf("%w: %s", ErrOptionWithoutDefault, o.Name())
 					}
-					if err = o.Set(flagValue); err != nil {
+					if err = setOptionValue(o, flagValue); err != nil {
 						return err
 					}
 				}
-				if !o.IsSet() {
-					_, err := p.InputString(o.Name(), o.Default(), o.Help(), func(s string) error {
-						return o.Set(s)
-					})
+				if !o.IsSet() && !(noPrompt && daemon.IsDefaultTemplate(o)) {
+					var err error
+					if o.Hidden() {
+						_, err = p.InputHiddenString(o.Name(), o.Help(), func(s string) error {
+							return o.Set(s)
+						})
+					} else {
+						_, err = p.InputString(o.Name(), o.Default(), o.Help(), func(s string) error {
+							return setOptionValue(o, s)
+						})
+					}
 					if err != nil {
 						return err
 					}
 				}
+				values[o.Name()], _ = o.Value()
+			}
+
+			// Confirm the update if the data of the current instance can not be
+			// reused by the new version
+			if err = confirmIncompatibleData(pullResult, p, yes, noPrompt); err != nil {
+				return err
 			}
 
 			// Backup instance
 			var backupId string
 			if backup {
-				backupId, err = d.Backup(instanceId)
+				backupId, err = d.Backup(cmd.Context(), instanceId, daemon.BackupOptions{})
 				if err != nil {
 					return err
 				}
 				log.Info("Backup created with id: ", backupId)
 			}
 
-			// Uninstall current instance
-			err = uninstallPackage(d, instanceId)
+			// Run migrations of the new version on the stopped instance
+			err = runMigrations(d, instanceId, pullResult.Migrations, !backup)
 			if err != nil {
 				if backup {
 					return abortWithRestore(d, backupId, err)
@@ -158,11 +197,25 @@ and if the update process fails, the instance will be restored.`,
 				return err
 			}
 
+			// Uninstall current instance, keeping its volumes for the new version
+			// unless it can not reuse them
+			err = uninstallPackage(d, instanceId, !pullResult.IncompatibleData)
+			if err != nil {
+				if backup {
+					return abortWithRestore(d, backupId, err)
+				}
+				return abortWithoutBackup(instanceId, pullResult, false, err)
+			}
+
 			// Build options map
 			options := make(map[string]string)
 			for _, o := range pullResult.MergedOptions {
 				v, err := o.Value()
 				if err != nil {
+					if errors.Is(err, daemon.ErrOptionNotSet) && daemon.IsDefaultTemplate(o) {
+						// The daemon renders the default on install
+						continue
+					}
 					return err
 				}
 				options[o.Name()] = v
@@ -185,7 +238,7 @@ and if the update process fails, the instance will be restored.`,
 				if backup {
 					return abortWithRestore(d, backupId, err)
 				}
-				return err
+				return abortWithoutBackup(instanceId, pullResult, true, err)
 			}
 			if newInstanceId != instanceId {
 				// NOTE: I think this never happens but it could be useful to check
@@ -196,7 +249,7 @@ and if the update process fails, the instance will be restored.`,
 			}
 
 			if pullResult.HasPlugin {
-				log.Info("The installed node software has a plugin.")
+				log.Infof("The installed node software has a plugin with image %s.", pullResult.PluginImage)
 			}
 
 			return runInstance(d, newInstanceId, p, yes, noPrompt)
@@ -29,13 +29,17 @@ func commitPrefix(commit string) string {
 }
 
 func ListCmd(d daemon.Daemon) *cobra.Command {
-	return &cobra.Command{
+	var checks bool
+	cmd := cobra.Command{
 		Use:   "ls",
 		Short: "List all installed AVS nodes and their health status.",
 		Long: `List all installed AVS nodes and their health status. If the AVS node is not running the health check will not be
 performed. An AVS node is considered running if it is installed and has at least one running service. The health check
 is performed by calling the health endpoint of the AVS node, to know more about this endpoint please refer to this
-Eigenlayer AVS Specification link https://eigen.nethermind.io/docs/metrics/metrics-api#get-eigennodehealth.`,
+Eigenlayer AVS Specification link https://eigen.nethermind.io/docs/metrics/metrics-api#get-eigennodehealth.
+If the profile of the AVS node defines health checks, they are run instead and the health is their aggregate: healthy if
+all of them pass, unhealthy if none of them pass, and partially healthy otherwise. Use the --checks flag to show the
+result of each health check.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			instances, err := d.ListInstances()
 			if err != nil {
@@ -56,7 +60,25 @@ Eigenlayer AVS Specification link https://eigen.nethermind.io/docs/metrics/metri
 			}
 			w.Flush()
 
+			if checks {
+				fmt.Fprintln(cmd.OutOrStdout())
+				w = tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 4, ' ', 0)
+				fmt.Fprintln(w, "AVS Instance ID\tHEALTH CHECK\tSERVICE\tSTATUS\tDETAIL\t")
+				for _, instance := range instances {
+					for _, check := range instance.HealthChecks {
+						status := "passing"
+						if !check.Healthy {
+							status = "failing"
+						}
+						fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t\n", instance.ID, check.Name, check.Service, status, check.Detail)
+					}
+				}
+				w.Flush()
+			}
+
 			return nil
 		},
 	}
+	cmd.Flags().BoolVar(&checks, "checks", false, "show the result of each health check of the AVS nodes.")
+	return &cmd
 }
@@ -14,6 +14,7 @@ import (
 func TestList(t *testing.T) {
 	tests := []struct {
 		name   string
+		args   []string
 		mocker func(d *daemonMock.MockDaemon)
 		err    error
 		stdOut []byte
@@ -82,6 +83,34 @@ func TestList(t *testing.T) {
 				"AVS Instance ID    RUNNING    HEALTH    VERSION    COMMIT    COMMENT    \n",
 			),
 		},
+		{
+			name: "success, health checks",
+			args: []string{"--checks"},
+			mocker: func(d *daemonMock.MockDaemon) {
+				d.EXPECT().ListInstances().Return([]daemon.ListInstanceItem{
+					{
+						ID:      "id1",
+						Running: true,
+						Health:  daemon.NodePartiallyHealthy,
+						Comment: "Failed health checks: db (container is exited)",
+						Version: common.MockAvsPkg.Version(),
+						Commit:  common.MockAvsPkg.CommitHash()[:7],
+						HealthChecks: []daemon.HealthCheckResult{
+							{Name: "api", Service: "main-service", Healthy: true},
+							{Name: "db", Service: "db", Detail: "container is exited"},
+						},
+					},
+				}, nil)
+			},
+			stdOut: []byte(
+				"AVS Instance ID    RUNNING    HEALTH               VERSION    COMMIT     COMMENT                                           \n" +
+					"id1                true       partially healthy    " + common.MockAvsPkg.Version() + "     " + common.MockAvsPkg.CommitHash()[:7] + "    Failed health checks: db (container is exited)    \n" +
+					"\n" +
+					"AVS Instance ID    HEALTH CHECK    SERVICE         STATUS     DETAIL                 \n" +
+					"id1                api             main-service    passing                           \n" +
+					"id1                db              db              failing    container is exited    \n",
+			),
+		},
 		{
 			name: "daemon list error",
 			mocker: func(d *daemonMock.MockDaemon) {
@@ -104,6 +133,7 @@ func TestList(t *testing.T) {
 			)
 
 			cmd := ListCmd(d)
+			cmd.SetArgs(tt.args)
 			cmd.SetOut(&stdOut)
 			cmd.SetErr(&errOut)
 			err := cmd.Execute()
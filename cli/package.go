@@ -0,0 +1,18 @@
+package cli
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func PackageCmd() *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "package",
+		Short: "Tools for AVS node software package authors",
+	}
+
+	cmd.AddCommand(
+		PackageInitCmd(),
+		PackageChecksumCmd(),
+	)
+	return &cmd
+}
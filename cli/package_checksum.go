@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"github.com/NethermindEth/eigenlayer/internal/package_handler"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+func PackageChecksumCmd() *cobra.Command {
+	var (
+		path  string
+		check bool
+	)
+	cmd := cobra.Command{
+		Use:   "checksum [flags] <path>",
+		Short: "Generate the checksum.txt file of an AVS node software package",
+		Long: `
+Computes the checksums of all the files inside the pkg directory of the package
+and writes them into the checksum.txt file, using the same hashing used to
+verify the package during installation.
+
+Use the --check flag to verify the current checksum.txt file without modifying it.`,
+		Args: cobra.ExactArgs(1),
+		PreRun: func(cmd *cobra.Command, args []string) {
+			path = args[0]
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pkgHandler := package_handler.NewPackageHandler(path)
+			if check {
+				if err := pkgHandler.Check(); err != nil {
+					return err
+				}
+				log.Info("Package checksums are valid")
+				return nil
+			}
+			if err := pkgHandler.WriteChecksum(); err != nil {
+				return err
+			}
+			log.Info("Package checksums updated")
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&check, "check", false, "verify the checksum.txt file instead of generating it.")
+	return &cmd
+}
@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/NethermindEth/eigenlayer/internal/package_handler"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPackageChecksum(t *testing.T) {
+	pkgPath := t.TempDir()
+	_, err := package_handler.NewPackage(pkgPath, package_handler.NewPackageOptions{
+		Name:     "mock-avs",
+		Profiles: []string{"default"},
+	})
+	require.NoError(t, err)
+
+	runCmd := func(args ...string) error {
+		cmd := PackageChecksumCmd()
+		cmd.SetArgs(args)
+		return cmd.Execute()
+	}
+
+	// Valid checksums
+	assert.NoError(t, runCmd("--check", pkgPath))
+
+	// Edit a package file by hand
+	profilePath := filepath.Join(pkgPath, "pkg", "default", "profile.yml")
+	f, err := os.OpenFile(profilePath, os.O_APPEND|os.O_WRONLY, 0o644)
+	require.NoError(t, err)
+	_, err = f.WriteString("# edited\n")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	assert.ErrorIs(t, runCmd("--check", pkgPath), package_handler.ErrInvalidChecksum)
+
+	// Regenerate checksums
+	require.NoError(t, runCmd(pkgPath))
+	assert.NoError(t, runCmd("--check", pkgPath))
+
+	// Missing argument
+	assert.Error(t, runCmd())
+}
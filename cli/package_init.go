@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"path/filepath"
+
+	"github.com/NethermindEth/eigenlayer/internal/package_handler"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+func PackageInitCmd() *cobra.Command {
+	var (
+		path     string
+		name     string
+		profiles []string
+		force    bool
+	)
+	cmd := cobra.Command{
+		Use:   "init [flags] <path>",
+		Short: "Create a new AVS node software package",
+		Long: `
+Creates a new AVS node software package in the given directory. The generated
+package is minimal but valid: it contains the manifest file, a profile directory
+for each profile with its profile.yml, docker-compose.yml and .env files, and
+the checksum.txt file.
+
+Use the --name flag to set the name of the AVS. If it is not specified, the name
+of the package directory is used. Use the --profile flag to set the profiles
+to create, by default a single "default" profile is created.
+
+After editing the package files, run 'eigenlayer package checksum <path>' to
+regenerate the checksum.txt file.`,
+		Args: cobra.ExactArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			var err error
+			path, err = filepath.Abs(args[0])
+			if err != nil {
+				return err
+			}
+			if name == "" {
+				name = filepath.Base(path)
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, err := package_handler.NewPackage(path, package_handler.NewPackageOptions{
+				Name:     name,
+				Profiles: profiles,
+				Force:    force,
+			})
+			if err != nil {
+				return err
+			}
+			log.Infof("Package %s created at %s", name, path)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&name, "name", "n", "", "name of the AVS. If not specified, the name of the package directory will be used.")
+	cmd.Flags().StringSliceVarP(&profiles, "profile", "p", []string{"default"}, "profiles to create. Can be specified multiple times or as a comma-separated list.")
+	cmd.Flags().BoolVarP(&force, "force", "f", false, "create the package even if the directory is not empty, overwriting existing files.")
+	return &cmd
+}
@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/NethermindEth/eigenlayer/internal/package_handler"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPackageInit(t *testing.T) {
+	tc := []struct {
+		name     string
+		args     func(pkgPath string) []string
+		setup    func(t *testing.T, pkgPath string)
+		err      error
+		avsName  string
+		profiles []string
+	}{
+		{
+			name: "no args",
+			args: func(string) []string { return []string{} },
+			err:  errors.New("accepts 1 arg(s), received 0"),
+		},
+		{
+			name:     "default values",
+			args:     func(pkgPath string) []string { return []string{pkgPath} },
+			avsName:  "mock-avs",
+			profiles: []string{"default"},
+		},
+		{
+			name: "with name and profiles",
+			args: func(pkgPath string) []string {
+				return []string{"--name", "my-avs", "--profile", "mainnet,holesky", pkgPath}
+			},
+			avsName:  "my-avs",
+			profiles: []string{"mainnet", "holesky"},
+		},
+		{
+			name: "non-empty directory",
+			args: func(pkgPath string) []string { return []string{pkgPath} },
+			setup: func(t *testing.T, pkgPath string) {
+				require.NoError(t, os.MkdirAll(pkgPath, 0o755))
+				require.NoError(t, os.WriteFile(filepath.Join(pkgPath, "README.md"), []byte("# mock-avs"), 0o644))
+			},
+			err: package_handler.ErrPackageDirNotEmpty,
+		},
+		{
+			name: "non-empty directory with force",
+			args: func(pkgPath string) []string { return []string{"--force", pkgPath} },
+			setup: func(t *testing.T, pkgPath string) {
+				require.NoError(t, os.MkdirAll(pkgPath, 0o755))
+				require.NoError(t, os.WriteFile(filepath.Join(pkgPath, "README.md"), []byte("# mock-avs"), 0o644))
+			},
+			avsName:  "mock-avs",
+			profiles: []string{"default"},
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			pkgPath := filepath.Join(t.TempDir(), "mock-avs")
+			if tt.setup != nil {
+				tt.setup(t, pkgPath)
+			}
+
+			cmd := PackageInitCmd()
+			cmd.SetArgs(tt.args(pkgPath))
+			err := cmd.Execute()
+
+			if tt.err != nil {
+				assert.ErrorContains(t, err, tt.err.Error())
+				return
+			}
+			require.NoError(t, err)
+
+			pkgHandler := package_handler.NewPackageHandler(pkgPath)
+			assert.NoError(t, pkgHandler.Check())
+			name, err := pkgHandler.Name()
+			require.NoError(t, err)
+			assert.Equal(t, tt.avsName, name)
+			profiles, err := pkgHandler.Profiles()
+			require.NoError(t, err)
+			profileNames := make([]string, 0, len(profiles))
+			for _, p := range profiles {
+				profileNames = append(profileNames, p.Name)
+			}
+			assert.Equal(t, tt.profiles, profileNames)
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/NethermindEth/eigenlayer/pkg/daemon"
+	"golang.org/x/term"
+	"kythe.io/kythe/go/util/datasize"
+)
+
+// progressBarWidth is the number of characters of the bar of a progress bar.
+const progressBarWidth = 30
+
+// progressBar renders the progress of the backup or the restore of the volumes
+// of an instance, with a line for each volume that is updated in place.
+type progressBar struct {
+	w io.Writer
+	// line is true if the line of a volume is being updated.
+	line bool
+}
+
+// newBackupProgressBar returns a progress bar that renders to stderr if it is
+// a terminal, or nil otherwise, so the progress does not clutter redirected
+// output.
+func newBackupProgressBar() *progressBar {
+	if !term.IsTerminal(int(os.Stderr.Fd())) {
+		return nil
+	}
+	return &progressBar{w: os.Stderr}
+}
+
+// Update renders the given progress event. The line of a volume is ended with
+// its last event.
+func (p *progressBar) Update(e daemon.BackupProgress) {
+	fmt.Fprintf(p.w, "\r\033[K%s", formatBackupProgress(e))
+	p.line = true
+	if e.Total >= 0 && e.Processed == e.Total {
+		p.Done()
+	}
+}
+
+// Done ends the line of the volume being updated, if any.
+func (p *progressBar) Done() {
+	if p.line {
+		fmt.Fprintln(p.w)
+		p.line = false
+	}
+}
+
+// formatBackupProgress formats the progress of a volume as a bar with the
+// percentage and the size processed, or only the size processed if the size
+// of the volume is unknown.
+func formatBackupProgress(e daemon.BackupProgress) string {
+	volume := e.Service + ":" + e.Volume
+	processed := datasize.Size(e.Processed).String()
+	if e.Total < 0 {
+		return fmt.Sprintf("%s %s", volume, processed)
+	}
+	percent := 100
+	if e.Total > 0 {
+		percent = int(min(e.Processed, e.Total) * 100 / e.Total)
+	}
+	filled := percent * progressBarWidth / 100
+	bar := strings.Repeat("=", filled)
+	if filled < progressBarWidth {
+		bar += ">" + strings.Repeat(" ", progressBarWidth-filled-1)
+	}
+	return fmt.Sprintf("%s [%s] %3d%% %s / %s", volume, bar, percent, processed, datasize.Size(e.Total).String())
+}
+
+// interruptContext returns a context that is canceled by the first interrupt
+// or termination signal, so the command can stop gracefully. Further signals
+// terminate the process as usual.
+func interruptContext(parent context.Context) (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(parent, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	return ctx, stop
+}
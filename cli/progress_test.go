@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/NethermindEth/eigenlayer/pkg/daemon"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatBackupProgress(t *testing.T) {
+	tests := []struct {
+		name     string
+		progress daemon.BackupProgress
+		want     string
+	}{
+		{
+			name:     "started",
+			progress: daemon.BackupProgress{Service: "main-service", Volume: "/data", Processed: 0, Total: 4096},
+			want:     "main-service:/data [>                             ]   0% 0B / 4KiB",
+		},
+		{
+			name:     "half",
+			progress: daemon.BackupProgress{Service: "main-service", Volume: "/data", Processed: 2048, Total: 4096},
+			want:     "main-service:/data [===============>              ]  50% 2KiB / 4KiB",
+		},
+		{
+			name:     "finished",
+			progress: daemon.BackupProgress{Service: "main-service", Volume: "/data", Processed: 4096, Total: 4096},
+			want:     "main-service:/data [==============================] 100% 4KiB / 4KiB",
+		},
+		{
+			name:     "empty volume",
+			progress: daemon.BackupProgress{Service: "main-service", Volume: "/tmp", Processed: 0, Total: 0},
+			want:     "main-service:/tmp [==============================] 100% 0B / 0B",
+		},
+		{
+			name:     "unknown size",
+			progress: daemon.BackupProgress{Service: "main-service", Volume: "/data", Processed: 1536, Total: -1},
+			want:     "main-service:/data 1.50KiB",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, formatBackupProgress(tt.progress))
+		})
+	}
+}
+
+func TestProgressBar(t *testing.T) {
+	var out bytes.Buffer
+	bar := &progressBar{w: &out}
+
+	bar.Update(daemon.BackupProgress{Service: "main-service", Volume: "/data", Processed: 0, Total: -1})
+	bar.Update(daemon.BackupProgress{Service: "main-service", Volume: "/data", Processed: 512, Total: 512})
+	bar.Update(daemon.BackupProgress{Service: "main-service", Volume: "/tmp", Processed: 1024, Total: -1})
+	bar.Done()
+	bar.Done()
+
+	assert.Equal(t, "\r\033[Kmain-service:/data 0B"+
+		"\r\033[Kmain-service:/data [==============================] 100% 512B / 512B\n"+
+		"\r\033[Kmain-service:/tmp 1KiB\n", out.String())
+}
@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/NethermindEth/eigenlayer/pkg/daemon"
+)
+
+func PullCmd(d daemon.Daemon) *cobra.Command {
+	var (
+		url        string
+		version    string
+		commit     string
+		profile    string
+		dumpValues bool
+		output     string
+	)
+	cmd := cobra.Command{
+		Use:   "pull [flags] <repository_url>",
+		Short: "Pull AVS node software and show its profiles",
+		Long: `
+Pulls the AVS node software package from the given URL, checks it and shows the
+available profiles. Use the --version or --commit flags to pull a specific
+version of the package.
+
+Use the --dump-values flag to write a values file template with the default
+values of the profile options and their help texts, that could be used to
+install the package without prompts using the install command with the --values
+flag. Use the --profile flag to dump the values of a single profile, and the
+--output flag to write the template to a file instead of the standard output.
+Options without a default value and hidden options are commented out in the
+template, uncomment them to set their values.
+`,
+		Args: cobra.ExactArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			url = args[0]
+			return validatePkgURL(url)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pullResult, err := d.Pull(url, daemon.PullTarget{
+				Version: version,
+				Commit:  commit,
+			}, true)
+			if err != nil {
+				return err
+			}
+
+			if !dumpValues {
+				if pullResult.Version != "" {
+					log.Infof("Version %s", pullResult.Version)
+				}
+				if pullResult.Commit != "" {
+					log.Infof("Commit %s", pullResult.Commit)
+				}
+				profiles := make([]string, 0, len(pullResult.Options))
+				for profileName := range pullResult.Options {
+					profiles = append(profiles, profileName)
+				}
+				sort.Strings(profiles)
+				for _, profileName := range profiles {
+					fmt.Fprintln(cmd.OutOrStdout(), profileName)
+				}
+				return nil
+			}
+
+			if output == "" {
+				return writeValuesTemplate(cmd.OutOrStdout(), pullResult, profile)
+			}
+			f, err := os.Create(output)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			if err := writeValuesTemplate(f, pullResult, profile); err != nil {
+				return err
+			}
+			log.Infof("Values template written to %s", output)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&version, "version", "v", "", "version to pull. If not specified the latest version will be pulled.")
+	cmd.Flags().StringVar(&commit, "commit", "", "commit to pull. If not specified the latest version will be pulled.")
+	cmd.Flags().StringVarP(&profile, "profile", "p", "", "profile to dump the values of. If not specified the values of all profiles are dumped.")
+	cmd.Flags().BoolVar(&dumpValues, "dump-values", false, "write a values file template with the default option values.")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "file to write the values template to, instead of the standard output.")
+	cmd.MarkFlagsMutuallyExclusive("version", "commit")
+	return &cmd
+}
@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	daemonMock "github.com/NethermindEth/eigenlayer/cli/mocks"
+	"github.com/NethermindEth/eigenlayer/internal/common"
+	"github.com/NethermindEth/eigenlayer/pkg/daemon"
+)
+
+func TestPull(t *testing.T) {
+	outputPath := filepath.Join(t.TempDir(), "values.yml")
+	template := "# Option values of mock-avs " + common.MockAvsPkg.Version() + "\nprofile1:\n  # help1\n  option1: default1\n"
+
+	ts := []struct {
+		name    string
+		args    []string
+		out     string
+		file    string
+		wantErr bool
+	}{
+		{
+			name: "list profiles",
+			args: []string{common.MockAvsPkg.Repo()},
+			out:  "profile1\nprofile2\n",
+		},
+		{
+			name: "dump values",
+			args: []string{"--dump-values", "--profile", "profile1", common.MockAvsPkg.Repo()},
+			out:  template,
+		},
+		{
+			name: "dump values to file",
+			args: []string{"--dump-values", "--profile", "profile1", "--output", outputPath, common.MockAvsPkg.Repo()},
+			file: template,
+		},
+		{
+			name:    "dump values of unknown profile",
+			args:    []string{"--dump-values", "--profile", "profile3", common.MockAvsPkg.Repo()},
+			wantErr: true,
+		},
+	}
+	for _, tc := range ts {
+		t.Run(tc.name, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			d := daemonMock.NewMockDaemon(controller)
+			option := daemonMock.NewMockOption(controller)
+			option.EXPECT().Name().Return("option1").AnyTimes()
+			option.EXPECT().Help().Return("help1").AnyTimes()
+			option.EXPECT().Default().Return("default1").AnyTimes()
+			option.EXPECT().Hidden().Return(false).AnyTimes()
+			d.EXPECT().Pull(common.MockAvsPkg.Repo(), daemon.PullTarget{}, true).Return(daemon.PullResult{
+				Name:    "mock-avs",
+				Version: common.MockAvsPkg.Version(),
+				Options: map[string][]daemon.Option{
+					"profile1": {option},
+					"profile2": {},
+				},
+			}, nil)
+
+			var out bytes.Buffer
+			pullCmd := PullCmd(d)
+			pullCmd.SetArgs(tc.args)
+			pullCmd.SetOut(&out)
+			err := pullCmd.Execute()
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.out, out.String())
+			if tc.file != "" {
+				data, err := os.ReadFile(outputPath)
+				require.NoError(t, err)
+				assert.Equal(t, tc.file, string(data))
+			}
+		})
+	}
+}
@@ -1,27 +1,73 @@
 package cli
 
 import (
+	"errors"
+
+	"github.com/NethermindEth/eigenlayer/cli/prompter"
 	"github.com/NethermindEth/eigenlayer/pkg/daemon"
 	"github.com/spf13/cobra"
 )
 
-func RestoreCmd(d daemon.Daemon) *cobra.Command {
+func RestoreCmd(d daemon.Daemon, p prompter.Prompter) *cobra.Command {
 	var (
-		backupId string
-		run      bool
+		backupId       string
+		options        daemon.RestoreOptions
+		passphraseFile string
 	)
 	cmd := cobra.Command{
 		Use:   "restore [flags] <backup-id>",
 		Short: "Restore an instance from a backup",
+		Long:  "Restore an instance from a backup. If the backup is not stored locally, the --from flag sets the backup store to pull it from, like an s3:// or sftp:// URL. See 'eigenlayer backup push --help' for the supported stores. The passphrase of encrypted backups is read from the --passphrase-file file, or asked interactively. The volumes to restore are selected with --include and --exclude, and --config-only restores the instance configuration without any volume. Volumes not restored, including the volumes not present in partial backups, are kept from the current instance. With --tag, the backup is restored as a new instance with the given tag, without changing the instance of the backup, for example to test the backup. Instances using bind mounts outside the instance directory, or external or explicitly named volumes can not be restored with --tag, as the new instance would share them with the instance of the backup. Fixed container names are removed from the new instance, whose containers are named after it. The new instance gets the secrets of the instance of the backup if it is installed, and the options of the instance keep the values of the backup. Secrets are not included in backups, so the secrets that are not stored on this host, like when the backup is imported from another host, are asked before restoring any volume. The progress of each volume is shown while it is restored, and the restore can be canceled with Ctrl+C, which may leave the instance partially restored. A new instance restored with --tag is removed if the restore fails or is canceled.",
 		Args:  cobra.ExactArgs(1),
 		PreRun: func(cmd *cobra.Command, args []string) {
 			backupId = args[0]
 		},
-		RunE: func(cmd *cobra.Command, args []string) error {
-			return d.Restore(backupId, run)
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			if passphraseFile != "" {
+				options.Passphrase, err = readPassphraseFile(passphraseFile)
+				if err != nil {
+					return err
+				}
+			}
+			if bar := newBackupProgressBar(); bar != nil {
+				options.Progress = bar.Update
+				defer bar.Done()
+			}
+			ctx, stop := interruptContext(cmd.Context())
+			defer stop()
+			err = d.Restore(ctx, backupId, options)
+			if errors.Is(err, daemon.ErrBackupEncrypted) && passphraseFile == "" {
+				options.Passphrase, err = p.InputHiddenString("Enter the passphrase to decrypt the backup:", "", validatePassphrase)
+				if err != nil {
+					return err
+				}
+				err = d.Restore(ctx, backupId, options)
+			}
+			// Secrets are not included in backups, the ones that are not
+			// stored on this host are asked
+			var missingErr daemon.MissingSecretsError
+			if errors.As(err, &missingErr) {
+				options.Secrets = make(map[string]string, len(missingErr.Options))
+				for _, o := range missingErr.Options {
+					options.Secrets[o.Name()], err = p.InputHiddenString(o.Name(), o.Help(), o.Set)
+					if err != nil {
+						return err
+					}
+				}
+				err = d.Restore(ctx, backupId, options)
+			}
+			return err
 		},
 	}
 
-	cmd.Flags().BoolVarP(&run, "run", "r", false, "Run the instance after restoring it")
+	cmd.Flags().BoolVarP(&options.Run, "run", "r", false, "Run the instance after restoring it")
+	cmd.Flags().StringVar(&options.From, "from", "", "backup store to pull the backup from, if it is not stored locally")
+	cmd.Flags().StringVar(&passphraseFile, "passphrase-file", "", "read the passphrase of encrypted backups from the given file")
+	cmd.Flags().StringSliceVar(&options.Include, "include", nil, "volumes to restore, as <service> or <service>:<volume>. All the volumes of the backup are restored by default")
+	cmd.Flags().StringSliceVar(&options.Exclude, "exclude", nil, "volumes not to restore, as <service> or <service>:<volume>")
+	cmd.Flags().BoolVar(&options.ConfigOnly, "config-only", false, "restore the instance configuration without any volume")
+	cmd.Flags().StringVar(&options.Tag, "tag", "", "restore the backup as a new instance with the given tag")
+	cmd.MarkFlagsMutuallyExclusive("config-only", "include")
+	cmd.MarkFlagsMutuallyExclusive("config-only", "exclude")
 	return &cmd
 }
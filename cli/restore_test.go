@@ -2,19 +2,27 @@ package cli
 
 import (
 	"errors"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/NethermindEth/eigenlayer/cli/mocks"
+	prompterMock "github.com/NethermindEth/eigenlayer/cli/prompter/mocks"
+	"github.com/NethermindEth/eigenlayer/pkg/daemon"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestRestore(t *testing.T) {
+	passphraseFile := filepath.Join(t.TempDir(), "passphrase")
+	require.NoError(t, os.WriteFile(passphraseFile, []byte("secret\n"), 0o600))
+
 	tc := []struct {
 		name   string
 		args   []string
 		err    error
-		mocker func(d *mocks.MockDaemon)
+		mocker func(d *mocks.MockDaemon, p *prompterMock.MockPrompter)
 	}{
 		{
 			name: "no args",
@@ -25,24 +33,102 @@ func TestRestore(t *testing.T) {
 			name: "daemon restore error",
 			args: []string{"backup-id"},
 			err:  assert.AnError,
-			mocker: func(d *mocks.MockDaemon) {
-				d.EXPECT().Restore("backup-id", false).Return(assert.AnError)
+			mocker: func(d *mocks.MockDaemon, p *prompterMock.MockPrompter) {
+				d.EXPECT().Restore(gomock.Any(), "backup-id", daemon.RestoreOptions{}).Return(assert.AnError)
 			},
 		},
 		{
 			name: "daemon restore success",
 			args: []string{"backup-id"},
-			mocker: func(d *mocks.MockDaemon) {
-				d.EXPECT().Restore("backup-id", false).Return(nil)
+			mocker: func(d *mocks.MockDaemon, p *prompterMock.MockPrompter) {
+				d.EXPECT().Restore(gomock.Any(), "backup-id", daemon.RestoreOptions{}).Return(nil)
 			},
 		},
 		{
 			name: "restore with run flag",
 			args: []string{"backup-id", "--run"},
-			mocker: func(d *mocks.MockDaemon) {
-				d.EXPECT().Restore("backup-id", true).Return(nil)
+			mocker: func(d *mocks.MockDaemon, p *prompterMock.MockPrompter) {
+				d.EXPECT().Restore(gomock.Any(), "backup-id", daemon.RestoreOptions{Run: true}).Return(nil)
+			},
+		},
+		{
+			name: "restore from backup store",
+			args: []string{"backup-id", "--from", "s3://bucket/backups"},
+			mocker: func(d *mocks.MockDaemon, p *prompterMock.MockPrompter) {
+				d.EXPECT().Restore(gomock.Any(), "backup-id", daemon.RestoreOptions{From: "s3://bucket/backups"}).Return(nil)
 			},
 		},
+		{
+			name: "restore selected volumes",
+			args: []string{"backup-id", "--include", "main-service", "--exclude", "main-service:/tmp"},
+			mocker: func(d *mocks.MockDaemon, p *prompterMock.MockPrompter) {
+				d.EXPECT().Restore(gomock.Any(), "backup-id", daemon.RestoreOptions{Include: []string{"main-service"}, Exclude: []string{"main-service:/tmp"}}).Return(nil)
+			},
+		},
+		{
+			name: "restore with new tag",
+			args: []string{"backup-id", "--tag", "test", "--run"},
+			mocker: func(d *mocks.MockDaemon, p *prompterMock.MockPrompter) {
+				d.EXPECT().Restore(gomock.Any(), "backup-id", daemon.RestoreOptions{Tag: "test", Run: true}).Return(nil)
+			},
+		},
+		{
+			name: "restore config only",
+			args: []string{"backup-id", "--config-only"},
+			mocker: func(d *mocks.MockDaemon, p *prompterMock.MockPrompter) {
+				d.EXPECT().Restore(gomock.Any(), "backup-id", daemon.RestoreOptions{ConfigOnly: true}).Return(nil)
+			},
+		},
+		{
+			name: "config only with exclude",
+			args: []string{"backup-id", "--config-only", "--exclude", "main-service"},
+			err:  errors.New("if any flags in the group [config-only exclude] are set none of the others can be; [config-only exclude] were all set"),
+		},
+		{
+			name: "encrypted backup, passphrase file",
+			args: []string{"backup-id", "--passphrase-file", passphraseFile},
+			mocker: func(d *mocks.MockDaemon, p *prompterMock.MockPrompter) {
+				d.EXPECT().Restore(gomock.Any(), "backup-id", daemon.RestoreOptions{Passphrase: "secret"}).Return(nil)
+			},
+		},
+		{
+			name: "encrypted backup, wrong passphrase file",
+			args: []string{"backup-id", "--passphrase-file", passphraseFile},
+			err:  daemon.ErrInvalidBackupPassphrase,
+			mocker: func(d *mocks.MockDaemon, p *prompterMock.MockPrompter) {
+				d.EXPECT().Restore(gomock.Any(), "backup-id", daemon.RestoreOptions{Passphrase: "secret"}).Return(daemon.ErrInvalidBackupPassphrase)
+			},
+		},
+		{
+			name: "encrypted backup, passphrase prompt",
+			args: []string{"backup-id", "--from", "s3://bucket/backups"},
+			mocker: func(d *mocks.MockDaemon, p *prompterMock.MockPrompter) {
+				gomock.InOrder(
+					d.EXPECT().Restore(gomock.Any(), "backup-id", daemon.RestoreOptions{From: "s3://bucket/backups"}).Return(daemon.ErrBackupEncrypted),
+					p.EXPECT().InputHiddenString("Enter the passphrase to decrypt the backup:", "", gomock.Any()).Return("secret", nil),
+					d.EXPECT().Restore(gomock.Any(), "backup-id", daemon.RestoreOptions{From: "s3://bucket/backups", Passphrase: "secret"}).Return(nil),
+				)
+			},
+		},
+		{
+			name: "missing secrets prompt",
+			args: []string{"backup-id", "--tag", "test"},
+			mocker: func(d *mocks.MockDaemon, p *prompterMock.MockPrompter) {
+				option := mocks.NewMockOption(gomock.NewController(t))
+				option.EXPECT().Name().Return("api-key").AnyTimes()
+				option.EXPECT().Help().Return("API key")
+				gomock.InOrder(
+					d.EXPECT().Restore(gomock.Any(), "backup-id", daemon.RestoreOptions{Tag: "test"}).Return(daemon.MissingSecretsError{Options: []daemon.Option{option}}),
+					p.EXPECT().InputHiddenString("api-key", "API key", gomock.Any()).Return("secret", nil),
+					d.EXPECT().Restore(gomock.Any(), "backup-id", daemon.RestoreOptions{Tag: "test", Secrets: map[string]string{"api-key": "secret"}}).Return(nil),
+				)
+			},
+		},
+		{
+			name: "missing passphrase file",
+			args: []string{"backup-id", "--passphrase-file", filepath.Join(t.TempDir(), "missing")},
+			err:  os.ErrNotExist,
+		},
 	}
 	for _, tt := range tc {
 		t.Run(tt.name, func(t *testing.T) {
@@ -50,19 +136,20 @@ func TestRestore(t *testing.T) {
 			defer ctrl.Finish()
 
 			d := mocks.NewMockDaemon(ctrl)
+			p := prompterMock.NewMockPrompter(ctrl)
 
 			if tt.mocker != nil {
-				tt.mocker(d)
+				tt.mocker(d, p)
 			}
 
-			cmd := RestoreCmd(d)
+			cmd := RestoreCmd(d, p)
 
 			cmd.SetArgs(tt.args)
 			err := cmd.Execute()
 
-			if tt.err != nil {
+			if tt.err != nil && !errors.Is(err, tt.err) {
 				assert.EqualError(t, err, tt.err.Error())
-			} else {
+			} else if tt.err == nil {
 				assert.NoError(t, err)
 			}
 		})
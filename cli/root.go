@@ -15,6 +15,7 @@ func RootCmd(d daemon.Daemon, p prompter.Prompter) *cobra.Command {
 	cmd.AddCommand(
 		// Commenting these now since we are going native installation
 		// InstallCmd(d, p),
+		// PullCmd(d),
 		// LocalInstallCmd(d),
 		// StopCmd(d),
 		// UninstallCmd(d),
@@ -26,9 +27,10 @@ func RootCmd(d daemon.Daemon, p prompter.Prompter) *cobra.Command {
 		// CleanMonitoringCmd(d),
 		// UpdateCmd(d, p),
 		// LocalUpdateCmd(d, p),
-		// BackupCmd(d),
-		// RestoreCmd(d),
+		// BackupCmd(d, p),
+		// RestoreCmd(d, p),
 		OperatorCmd(p),
+		PackageCmd(),
 	)
 	cmd.CompletionOptions.DisableDefaultCmd = true
 	return &cmd
@@ -20,7 +20,7 @@ func UninstallCmd(d daemon.Daemon) *cobra.Command {
 			if err := d.InitMonitoring(false, false); err != nil {
 				return err
 			}
-			return d.Uninstall(instanceId)
+			return d.Uninstall(instanceId, daemon.UninstallOptions{})
 		},
 	}
 	return &cmd
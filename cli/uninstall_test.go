@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	daemonMock "github.com/NethermindEth/eigenlayer/cli/mocks"
+	"github.com/NethermindEth/eigenlayer/pkg/daemon"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 )
@@ -33,7 +34,7 @@ func TestUninstall(t *testing.T) {
 			mocker: func(d *daemonMock.MockDaemon) {
 				gomock.InOrder(
 					d.EXPECT().InitMonitoring(false, false).Return(nil),
-					d.EXPECT().Uninstall("instance1").Return(nil),
+					d.EXPECT().Uninstall("instance1", daemon.UninstallOptions{}).Return(nil),
 				)
 			},
 		},
@@ -52,7 +53,7 @@ func TestUninstall(t *testing.T) {
 			mocker: func(d *daemonMock.MockDaemon) {
 				gomock.InOrder(
 					d.EXPECT().InitMonitoring(false, false).Return(nil),
-					d.EXPECT().Uninstall("instance1").Return(errors.New("uninstall error")),
+					d.EXPECT().Uninstall("instance1", daemon.UninstallOptions{}).Return(errors.New("uninstall error")),
 				)
 			},
 		},
@@ -2,8 +2,10 @@ package cli
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"regexp"
 	"text/tabwriter"
 
@@ -46,7 +48,17 @@ Options of the new version can be specified using the --option.<option-name> fla
 To avoid any data loss during the update process, the user can specify the --backup
 flag. In this case, the current instance will be backed up before uninstalling it,
 and if the update process fails, the instance will be restored. Also, the backup
-could be restored manually using the 'eigenlayer restore' command.`,
+could be restored manually using the 'eigenlayer restore' command. Without --backup,
+a running instance is restarted if the migrations of the new version fail, but if
+the update fails once the current instance is uninstalled, the instance is left
+uninstalled and the previous version must be installed again to recover it.
+
+The new version could declare the versions it supports updating from, in which
+case the update will fail if the current version is not one of them. If the new
+version declares migrations, they are run with the volumes of the stopped instance
+before installing the new version. The volumes of the current instance are kept
+and reused by the new version, unless it can not reuse the data of the current
+one, in which case a confirmation is required and the volumes are removed.`,
 		Example: `
 - Updating to the latest version:
 	
@@ -126,14 +138,24 @@ could be restored manually using the 'eigenlayer restore' command.`,
 
 			// Log useful information about the update
 			logVersionChange(pullResult.OldVersion, pullResult.NewVersion)
+			logSpecVersionChange(pullResult.OldSpecVersion, pullResult.NewSpecVersion)
 			logCommitChange(pullResult.OldCommit, pullResult.NewCommit)
+			if err = checkHardwareRequirements(d, pullResult.Profile, pullResult.HardwareRequirements); err != nil {
+				return err
+			}
 			printOptionsTable(pullResult.OldOptions, pullResult.MergedOptions)
+			if err = confirmOptionChanges(pullResult, p, cmd.OutOrStdout(), yes, noPrompt); err != nil {
+				return err
+			}
 
 			// Build dynamic flags with the profile options
 			for _, o := range pullResult.MergedOptions {
 				v, err := o.Value()
 				if err != nil {
 					cmd.Flags().String("option."+o.Name(), o.Default(), o.Help())
+				} else if o.Hidden() {
+					// Hidden values are not shown as flag defaults
+					cmd.Flags().String("option."+o.Name(), "", o.Help())
 				} else {
 					cmd.Flags().String("option."+o.Name(), v, o.Help())
 				}
@@ -146,41 +168,66 @@ could be restored manually using the 'eigenlayer restore' command.`,
 			}
 
 			// Fill necessary options
+			values := make(map[string]string, len(pullResult.MergedOptions))
 			for _, o := range pullResult.MergedOptions {
+				if !o.Active(values) && !o.IsSet() && !cmd.Flags().Changed("option."+o.Name()) {
+					// Inactive options are not asked and keep their default value
+					values[o.Name()] = o.Default()
+					continue
+				}
 				if noPrompt {
 					flagValue, err := cmd.Flags().GetString("option." + o.Name())
 					if err != nil {
 						return err
 					}
 					if flagValue == "" {
+						if o.Hidden() && o.IsSet() {
+							// Keep the current hidden value
+							values[o.Name()], _ = o.Value()
+							continue
+						}
 						return fmt.Errorf("%w: %s", ErrOptionWithoutDefault, o.Name())
 					}
-					if err = o.Set(flagValue); err != nil {
+					if err = setOptionValue(o, flagValue); err != nil {
 						return err
 					}
 				}
-				if !o.IsSet() {
-					_, err := p.InputString(o.Name(), o.Default(), o.Help(), func(s string) error {
-						return o.Set(s)
-					})
+				if !o.IsSet() && !(noPrompt && daemon.IsDefaultTemplate(o)) {
+					var err error
+					if o.Hidden() {
+						_, err = p.InputHiddenString(o.Name(), o.Help(), func(s string) error {
+							return o.Set(s)
+						})
+					} else {
+						_, err = p.InputString(o.Name(), o.Default(), o.Help(), func(s string) error {
+							return setOptionValue(o, s)
+						})
+					}
 					if err != nil {
 						return err
 					}
 				}
+				values[o.Name()], _ = o.Value()
+			}
+
+			// Confirm the update if the data of the current instance can not be
+			// reused by the new version
+			if err = confirmIncompatibleData(pullResult, p, yes, noPrompt); err != nil {
+				return err
 			}
 
 			// Backup instance
 			var backupId string
 			if backup {
-				backupId, err = d.Backup(instanceId)
+				backupId, err = d.Backup(cmd.Context(), instanceId, daemon.BackupOptions{})
 				if err != nil {
 					return err
 				}
 				log.Info("Backup created with id: ", backupId)
 			}
 
-			// Uninstall current instance
-			err = uninstallPackage(d, instanceId)
+			// Run migrations of the new version on the stopped instance
+			err = runMigrations(d, instanceId, pullResult.Migrations, !backup)
 			if err != nil {
 				if backup {
 					return abortWithRestore(d, backupId, err)
@@ -188,6 +235,16 @@ could be restored manually using the 'eigenlayer restore' command.`,
 				return err
 			}
 
+			// Uninstall current instance, keeping its volumes for the new version
+			// unless it can not reuse them
+			err = uninstallPackage(d, instanceId, !pullResult.IncompatibleData)
+			if err != nil {
+				if backup {
+					return abortWithRestore(d, backupId, err)
+				}
+				return abortWithoutBackup(instanceId, pullResult, false, err)
+			}
+
 			// Install new instance's version
 			newInstanceId, err := install(d, daemon.InstallOptions{
 				Name:    pullResult.Name,
@@ -202,7 +259,7 @@ could be restored manually using the 'eigenlayer restore' command.`,
 				if backup {
 					return abortWithRestore(d, backupId, err)
 				}
-				return err
+				return abortWithoutBackup(instanceId, pullResult, true, err)
 			}
 			if newInstanceId != instanceId {
 				// NOTE: I think this never happens but it could be useful to check
@@ -213,7 +270,7 @@ could be restored manually using the 'eigenlayer restore' command.`,
 			}
 
 			if pullResult.HasPlugin {
-				log.Info("The installed node software has a plugin.")
+				log.Infof("The installed node software has a plugin with image %s.", pullResult.PluginImage)
 			}
 
 			return runInstance(d, newInstanceId, p, yes, noPrompt)
@@ -229,7 +286,138 @@ could be restored manually using the 'eigenlayer restore' command.`,
 func abortWithRestore(d daemon.Daemon, backupId string, updateErr error) error {
 	log.Errorf("Update process failed with error: %s", updateErr.Error())
 	log.Infof("Restoring instance from backup %s...", backupId)
-	return d.Restore(backupId, false)
+	return d.Restore(context.Background(), backupId, daemon.RestoreOptions{})
+}
+
+// abortWithoutBackup tells the user the state the instance was left in when an
+// update without backup fails while uninstalling the current instance or
+// installing the new version, as the current instance can not be restarted.
+func abortWithoutBackup(instanceId string, pullResult daemon.PullUpdateResult, uninstalled bool, updateErr error) error {
+	log.Errorf("Update process failed with error: %s", updateErr.Error())
+	if !uninstalled {
+		log.Warnf("Instance %s could not be uninstalled and may be left stopped. Check its state with 'eigenlayer ls', and run it again with 'eigenlayer run %s'", instanceId, instanceId)
+		return updateErr
+	}
+	volumes := "its volumes were kept"
+	if pullResult.IncompatibleData {
+		volumes = "its volumes were removed"
+	}
+	log.Warnf("Instance %s was uninstalled and %s, so it is not running. Install version %s again to recover it", instanceId, volumes, pullResult.OldVersion)
+	return updateErr
+}
+
+func confirmIncompatibleData(pullResult daemon.PullUpdateResult, p prompter.Prompter, yes, noPrompt bool) error {
+	if !pullResult.IncompatibleData {
+		return nil
+	}
+	log.Warnf("Version %s can not reuse the data of the current version %s", pullResult.NewVersion, pullResult.OldVersion)
+	if yes {
+		return nil
+	}
+	if noPrompt {
+		return fmt.Errorf("%w: use --yes to update anyway", ErrIncompatibleData)
+	}
+	ok, err := p.Confirm("The new version can not reuse the data of the current instance. Continue with the update?")
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrIncompatibleData
+	}
+	return nil
+}
+
+// confirmOptionChanges shows how the option values of the current instance are
+// carried over to the new version, and asks for confirmation if some of them can
+// not be kept.
+func confirmOptionChanges(pullResult daemon.PullUpdateResult, p prompter.Prompter, out io.Writer, yes, noPrompt bool) error {
+	if len(pullResult.OptionChanges) == 0 {
+		return nil
+	}
+	printOptionChanges(pullResult.OptionChanges, out)
+	lost := false
+	for _, c := range pullResult.OptionChanges {
+		if c.Kind == daemon.OptionReset || c.Kind == daemon.OptionRemoved {
+			lost = true
+		}
+	}
+	if !lost || yes || noPrompt {
+		return nil
+	}
+	ok, err := p.Confirm("Some option values of the current instance can not be kept. Continue with the update?")
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrOptionValuesLost
+	}
+	return nil
+}
+
+func printOptionChanges(changes []daemon.OptionChange, out io.Writer) {
+	w := tabwriter.NewWriter(out, 0, 0, 4, ' ', 0)
+	fmt.Fprintln(w, "OPTION\tCHANGE\tOLD VALUE\tNEW VALUE\tNOTES\t")
+	for _, c := range changes {
+		notes := c.Reason
+		if c.Kind == daemon.OptionRenamed {
+			notes = "renamed from " + c.OldName
+		}
+		newValue := c.NewValue
+		if newValue == "" && c.Kind != daemon.OptionRemoved {
+			newValue = "<to be set>"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t\n", c.Name, c.Kind, c.OldValue, newValue, notes)
+	}
+	w.Flush()
+}
+
+// runMigrations stops the instance and runs the given migrations on its
+// volumes. The instance is stopped even if it was just backed up, as backups
+// leave the services in their previous state. If restart is true and the
+// migrations fail, the instance is run again if it was running before.
+func runMigrations(d daemon.Daemon, instanceID string, migrations []daemon.Migration, restart bool) error {
+	if len(migrations) == 0 {
+		return nil
+	}
+	var running bool
+	if restart {
+		var err error
+		if running, err = instanceRunning(d, instanceID); err != nil {
+			return err
+		}
+	}
+	log.Infof("Stopping instance %s to run migrations...", instanceID)
+	if err := d.Stop(instanceID); err != nil {
+		return err
+	}
+	log.Infof("Running %d migrations...", len(migrations))
+	err := d.RunMigrations(instanceID, migrations)
+	if err != nil {
+		if running {
+			log.Errorf("Update process failed with error: %s", err.Error())
+			log.Infof("Restarting instance %s...", instanceID)
+			if runErr := d.Run(instanceID); runErr != nil {
+				log.Errorf("Instance %s was left stopped, it could not be restarted: %s", instanceID, runErr.Error())
+			}
+		}
+		return err
+	}
+	log.Info("Migrations run successfully")
+	return nil
+}
+
+// instanceRunning returns true if the instance with the given ID is running.
+func instanceRunning(d daemon.Daemon, instanceID string) (bool, error) {
+	instances, err := d.ListInstances()
+	if err != nil {
+		return false, err
+	}
+	for _, instance := range instances {
+		if instance.ID == instanceID {
+			return instance.Running, nil
+		}
+	}
+	return false, nil
 }
 
 func pullUpdate(d daemon.Daemon, instanceID, version, commit string) (daemon.PullUpdateResult, error) {
@@ -241,9 +429,12 @@ func pullUpdate(d daemon.Daemon, instanceID, version, commit string) (daemon.Pul
 	return pullResult, err
 }
 
-func uninstallPackage(d daemon.Daemon, instanceID string) error {
+func uninstallPackage(d daemon.Daemon, instanceID string, keepVolumes bool) error {
 	log.Info("Uninstalling current package...")
-	err := d.Uninstall(instanceID)
+	if !keepVolumes {
+		log.Info("The volumes of the current instance are removed")
+	}
+	err := d.Uninstall(instanceID, daemon.UninstallOptions{KeepVolumes: keepVolumes})
 	if err == nil {
 		log.Info("Package uninstalled successfully")
 	}
@@ -283,6 +474,12 @@ func logVersionChange(oldVersion, newVersion string) {
 	}
 }
 
+func logSpecVersionChange(oldSpecVersion, newSpecVersion string) {
+	if newSpecVersion != "" && newSpecVersion != oldSpecVersion {
+		log.Infof("AVS Node Specification version changed: %s -> %s", oldSpecVersion, newSpecVersion)
+	}
+}
+
 func logCommitChange(oldCommit, newCommit string) {
 	if newCommit != "" {
 		log.Infof("Package commit changed from %s -> %s", oldCommit, newCommit)
@@ -299,11 +496,24 @@ func (i tableOptionItem) String() string {
 	return fmt.Sprintf("%s\t%s\t%s\t", i.name, i.old, i.new)
 }
 
+// optionDisplayValue returns the value of the option to show to the user,
+// redacting hidden values like secrets.
+func optionDisplayValue(o daemon.Option) (string, error) {
+	v, err := o.Value()
+	if err != nil {
+		return "", err
+	}
+	if o.Hidden() {
+		return "<redacted>", nil
+	}
+	return v, nil
+}
+
 func printOptionsTable(old, merged []daemon.Option) error {
 	rows := make(map[string]*tableOptionItem)
 	for _, o := range old {
 		if o.IsSet() {
-			v, err := o.Value()
+			v, err := optionDisplayValue(o)
 			if err != nil {
 				return err
 			}
@@ -320,7 +530,7 @@ func printOptionsTable(old, merged []daemon.Option) error {
 	}
 	for _, o := range merged {
 		if o.IsSet() {
-			v, err := o.Value()
+			v, err := optionDisplayValue(o)
 			if err != nil {
 				return err
 			}
@@ -1,16 +1,20 @@
 package cli
 
 import (
+	"bytes"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
 	daemonMock "github.com/NethermindEth/eigenlayer/cli/mocks"
 	prompterMock "github.com/NethermindEth/eigenlayer/cli/prompter/mocks"
 	"github.com/NethermindEth/eigenlayer/internal/common"
+	"github.com/NethermindEth/eigenlayer/internal/profile"
 	"github.com/NethermindEth/eigenlayer/pkg/daemon"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestUpdate(t *testing.T) {
@@ -25,16 +29,16 @@ func TestUpdate(t *testing.T) {
 			name: "update to latest version",
 			args: []string{instanceId},
 			mocker: func(ctrl *gomock.Controller, d *daemonMock.MockDaemon, p *prompterMock.MockPrompter) {
-				oldOption := daemonMock.NewMockOption(ctrl)
-				newOption := daemonMock.NewMockOption(ctrl)
-				mergedOption := daemonMock.NewMockOption(ctrl)
+				oldOption := newMockOption(ctrl)
+				newOption := newMockOption(ctrl)
+				mergedOption := newMockOption(ctrl)
 
 				oldOption.EXPECT().IsSet().Return(true)
 				oldOption.EXPECT().Value().Return("old-value", nil)
 				oldOption.EXPECT().Name().Return("old-option").Times(3)
 				mergedOption.EXPECT().IsSet().Return(true).Times(2)
-				mergedOption.EXPECT().Value().Return("old-value", nil).Times(2)
-				mergedOption.EXPECT().Name().Return("old-option").Times(2)
+				mergedOption.EXPECT().Value().Return("old-value", nil).Times(3)
+				mergedOption.EXPECT().Name().Return("old-option").Times(3)
 				mergedOption.EXPECT().Help().Return("option help")
 
 				gomock.InOrder(
@@ -58,7 +62,13 @@ func TestUpdate(t *testing.T) {
 							StopIfRequirementsAreNotMet: true,
 						},
 					}, nil),
-					d.EXPECT().Uninstall(instanceId).Return(nil),
+					d.EXPECT().CheckHardwareRequirements(daemon.HardwareRequirements{
+						MinCPUCores:                 2,
+						MinRAM:                      2048,
+						MinFreeSpace:                5120,
+						StopIfRequirementsAreNotMet: true,
+					}).Return(true, nil),
+					d.EXPECT().Uninstall(instanceId, daemon.UninstallOptions{KeepVolumes: true}).Return(nil),
 					d.EXPECT().Install(daemon.InstallOptions{
 						Name:    "mock-avs",
 						Tag:     "default",
@@ -77,16 +87,16 @@ func TestUpdate(t *testing.T) {
 			name: "update to fixed version",
 			args: []string{instanceId, common.MockAvsPkg.Version()},
 			mocker: func(ctrl *gomock.Controller, d *daemonMock.MockDaemon, p *prompterMock.MockPrompter) {
-				oldOption := daemonMock.NewMockOption(ctrl)
-				newOption := daemonMock.NewMockOption(ctrl)
-				mergedOption := daemonMock.NewMockOption(ctrl)
+				oldOption := newMockOption(ctrl)
+				newOption := newMockOption(ctrl)
+				mergedOption := newMockOption(ctrl)
 
 				oldOption.EXPECT().IsSet().Return(true)
 				oldOption.EXPECT().Value().Return("old-value", nil)
 				oldOption.EXPECT().Name().Return("old-option").Times(3)
 				mergedOption.EXPECT().IsSet().Return(true).Times(2)
-				mergedOption.EXPECT().Value().Return("old-value", nil).Times(2)
-				mergedOption.EXPECT().Name().Return("old-option").Times(2)
+				mergedOption.EXPECT().Value().Return("old-value", nil).Times(3)
+				mergedOption.EXPECT().Name().Return("old-option").Times(3)
 				mergedOption.EXPECT().Help().Return("option help")
 
 				gomock.InOrder(
@@ -112,7 +122,13 @@ func TestUpdate(t *testing.T) {
 							StopIfRequirementsAreNotMet: true,
 						},
 					}, nil),
-					d.EXPECT().Uninstall(instanceId).Return(nil),
+					d.EXPECT().CheckHardwareRequirements(daemon.HardwareRequirements{
+						MinCPUCores:                 2,
+						MinRAM:                      2048,
+						MinFreeSpace:                5120,
+						StopIfRequirementsAreNotMet: true,
+					}).Return(true, nil),
+					d.EXPECT().Uninstall(instanceId, daemon.UninstallOptions{KeepVolumes: true}).Return(nil),
 					d.EXPECT().Install(daemon.InstallOptions{
 						Name:    "mock-avs",
 						Tag:     "default",
@@ -131,16 +147,16 @@ func TestUpdate(t *testing.T) {
 			name: "update to fixed commit",
 			args: []string{instanceId, common.MockAvsPkg.CommitHash()},
 			mocker: func(ctrl *gomock.Controller, d *daemonMock.MockDaemon, p *prompterMock.MockPrompter) {
-				oldOption := daemonMock.NewMockOption(ctrl)
-				newOption := daemonMock.NewMockOption(ctrl)
-				mergedOption := daemonMock.NewMockOption(ctrl)
+				oldOption := newMockOption(ctrl)
+				newOption := newMockOption(ctrl)
+				mergedOption := newMockOption(ctrl)
 
 				oldOption.EXPECT().IsSet().Return(true)
 				oldOption.EXPECT().Value().Return("old-value", nil)
 				oldOption.EXPECT().Name().Return("old-option").Times(3)
 				mergedOption.EXPECT().IsSet().Return(true).Times(2)
-				mergedOption.EXPECT().Value().Return("old-value", nil).Times(2)
-				mergedOption.EXPECT().Name().Return("old-option").Times(2)
+				mergedOption.EXPECT().Value().Return("old-value", nil).Times(3)
+				mergedOption.EXPECT().Name().Return("old-option").Times(3)
 				mergedOption.EXPECT().Help().Return("option help")
 
 				gomock.InOrder(
@@ -166,7 +182,13 @@ func TestUpdate(t *testing.T) {
 							StopIfRequirementsAreNotMet: true,
 						},
 					}, nil),
-					d.EXPECT().Uninstall(instanceId).Return(nil),
+					d.EXPECT().CheckHardwareRequirements(daemon.HardwareRequirements{
+						MinCPUCores:                 2,
+						MinRAM:                      2048,
+						MinFreeSpace:                5120,
+						StopIfRequirementsAreNotMet: true,
+					}).Return(true, nil),
+					d.EXPECT().Uninstall(instanceId, daemon.UninstallOptions{KeepVolumes: true}).Return(nil),
 					d.EXPECT().Install(daemon.InstallOptions{
 						Name:    "mock-avs",
 						Tag:     "default",
@@ -186,16 +208,16 @@ func TestUpdate(t *testing.T) {
 			args: []string{instanceId, "--backup"},
 			err:  nil,
 			mocker: func(ctrl *gomock.Controller, d *daemonMock.MockDaemon, p *prompterMock.MockPrompter) {
-				oldOption := daemonMock.NewMockOption(ctrl)
-				newOption := daemonMock.NewMockOption(ctrl)
-				mergedOption := daemonMock.NewMockOption(ctrl)
+				oldOption := newMockOption(ctrl)
+				newOption := newMockOption(ctrl)
+				mergedOption := newMockOption(ctrl)
 
 				oldOption.EXPECT().IsSet().Return(true)
 				oldOption.EXPECT().Value().Return("old-value", nil)
 				oldOption.EXPECT().Name().Return("old-option").Times(3)
 				mergedOption.EXPECT().IsSet().Return(true).Times(2)
-				mergedOption.EXPECT().Value().Return("old-value", nil).Times(2)
-				mergedOption.EXPECT().Name().Return("old-option").Times(2)
+				mergedOption.EXPECT().Value().Return("old-value", nil).Times(3)
+				mergedOption.EXPECT().Name().Return("old-option").Times(3)
 				mergedOption.EXPECT().Help().Return("option help")
 
 				gomock.InOrder(
@@ -219,8 +241,14 @@ func TestUpdate(t *testing.T) {
 							StopIfRequirementsAreNotMet: true,
 						},
 					}, nil),
-					d.EXPECT().Backup(instanceId).Return(fmt.Sprintf("%s-%d", instanceId, time.Now().Unix()), nil),
-					d.EXPECT().Uninstall(instanceId).Return(nil),
+					d.EXPECT().CheckHardwareRequirements(daemon.HardwareRequirements{
+						MinCPUCores:                 2,
+						MinRAM:                      2048,
+						MinFreeSpace:                5120,
+						StopIfRequirementsAreNotMet: true,
+					}).Return(true, nil),
+					d.EXPECT().Backup(gomock.Any(), instanceId, daemon.BackupOptions{}).Return(fmt.Sprintf("%s-%d", instanceId, time.Now().Unix()), nil),
+					d.EXPECT().Uninstall(instanceId, daemon.UninstallOptions{KeepVolumes: true}).Return(nil),
 					d.EXPECT().Install(daemon.InstallOptions{
 						Name:    "mock-avs",
 						Tag:     "default",
@@ -240,16 +268,16 @@ func TestUpdate(t *testing.T) {
 			args: []string{instanceId, "--backup"},
 			err:  assert.AnError,
 			mocker: func(ctrl *gomock.Controller, d *daemonMock.MockDaemon, p *prompterMock.MockPrompter) {
-				oldOption := daemonMock.NewMockOption(ctrl)
-				newOption := daemonMock.NewMockOption(ctrl)
-				mergedOption := daemonMock.NewMockOption(ctrl)
+				oldOption := newMockOption(ctrl)
+				newOption := newMockOption(ctrl)
+				mergedOption := newMockOption(ctrl)
 
 				oldOption.EXPECT().IsSet().Return(true)
 				oldOption.EXPECT().Value().Return("old-value", nil)
 				oldOption.EXPECT().Name().Return("old-option").Times(3)
 				mergedOption.EXPECT().IsSet().Return(true).Times(2)
-				mergedOption.EXPECT().Value().Return("old-value", nil).Times(2)
-				mergedOption.EXPECT().Name().Return("old-option").Times(2)
+				mergedOption.EXPECT().Value().Return("old-value", nil).Times(3)
+				mergedOption.EXPECT().Name().Return("old-option").Times(3)
 				mergedOption.EXPECT().Help().Return("option help")
 
 				gomock.InOrder(
@@ -273,7 +301,13 @@ func TestUpdate(t *testing.T) {
 							StopIfRequirementsAreNotMet: true,
 						},
 					}, nil),
-					d.EXPECT().Backup(instanceId).Return("", assert.AnError),
+					d.EXPECT().CheckHardwareRequirements(daemon.HardwareRequirements{
+						MinCPUCores:                 2,
+						MinRAM:                      2048,
+						MinFreeSpace:                5120,
+						StopIfRequirementsAreNotMet: true,
+					}).Return(true, nil),
+					d.EXPECT().Backup(gomock.Any(), instanceId, daemon.BackupOptions{}).Return("", assert.AnError),
 				)
 			},
 		},
@@ -282,16 +316,16 @@ func TestUpdate(t *testing.T) {
 			args: []string{instanceId, "--backup"},
 			err:  nil,
 			mocker: func(ctrl *gomock.Controller, d *daemonMock.MockDaemon, p *prompterMock.MockPrompter) {
-				oldOption := daemonMock.NewMockOption(ctrl)
-				newOption := daemonMock.NewMockOption(ctrl)
-				mergedOption := daemonMock.NewMockOption(ctrl)
+				oldOption := newMockOption(ctrl)
+				newOption := newMockOption(ctrl)
+				mergedOption := newMockOption(ctrl)
 
 				oldOption.EXPECT().IsSet().Return(true)
 				oldOption.EXPECT().Value().Return("old-value", nil)
 				oldOption.EXPECT().Name().Return("old-option").Times(3)
 				mergedOption.EXPECT().IsSet().Return(true).Times(2)
-				mergedOption.EXPECT().Value().Return("old-value", nil).Times(2)
-				mergedOption.EXPECT().Name().Return("old-option").Times(2)
+				mergedOption.EXPECT().Value().Return("old-value", nil).Times(3)
+				mergedOption.EXPECT().Name().Return("old-option").Times(3)
 				mergedOption.EXPECT().Help().Return("option help")
 
 				gomock.InOrder(
@@ -315,9 +349,15 @@ func TestUpdate(t *testing.T) {
 							StopIfRequirementsAreNotMet: true,
 						},
 					}, nil),
-					d.EXPECT().Backup(instanceId).Return(fmt.Sprintf("%s-%d", instanceId, time.Now().Unix()), nil),
-					d.EXPECT().Uninstall(instanceId).Return(assert.AnError),
-					d.EXPECT().Restore(gomock.Any(), false).Return(nil),
+					d.EXPECT().CheckHardwareRequirements(daemon.HardwareRequirements{
+						MinCPUCores:                 2,
+						MinRAM:                      2048,
+						MinFreeSpace:                5120,
+						StopIfRequirementsAreNotMet: true,
+					}).Return(true, nil),
+					d.EXPECT().Backup(gomock.Any(), instanceId, daemon.BackupOptions{}).Return(fmt.Sprintf("%s-%d", instanceId, time.Now().Unix()), nil),
+					d.EXPECT().Uninstall(instanceId, daemon.UninstallOptions{KeepVolumes: true}).Return(assert.AnError),
+					d.EXPECT().Restore(gomock.Any(), gomock.Any(), daemon.RestoreOptions{}).Return(nil),
 				)
 			},
 		},
@@ -326,16 +366,16 @@ func TestUpdate(t *testing.T) {
 			args: []string{instanceId, "--backup"},
 			err:  assert.AnError,
 			mocker: func(ctrl *gomock.Controller, d *daemonMock.MockDaemon, p *prompterMock.MockPrompter) {
-				oldOption := daemonMock.NewMockOption(ctrl)
-				newOption := daemonMock.NewMockOption(ctrl)
-				mergedOption := daemonMock.NewMockOption(ctrl)
+				oldOption := newMockOption(ctrl)
+				newOption := newMockOption(ctrl)
+				mergedOption := newMockOption(ctrl)
 
 				oldOption.EXPECT().IsSet().Return(true)
 				oldOption.EXPECT().Value().Return("old-value", nil)
 				oldOption.EXPECT().Name().Return("old-option").Times(3)
 				mergedOption.EXPECT().IsSet().Return(true).Times(2)
-				mergedOption.EXPECT().Value().Return("old-value", nil).Times(2)
-				mergedOption.EXPECT().Name().Return("old-option").Times(2)
+				mergedOption.EXPECT().Value().Return("old-value", nil).Times(3)
+				mergedOption.EXPECT().Name().Return("old-option").Times(3)
 				mergedOption.EXPECT().Help().Return("option help")
 
 				gomock.InOrder(
@@ -359,9 +399,15 @@ func TestUpdate(t *testing.T) {
 							StopIfRequirementsAreNotMet: true,
 						},
 					}, nil),
-					d.EXPECT().Backup(instanceId).Return(fmt.Sprintf("%s-%d", instanceId, time.Now().Unix()), nil),
-					d.EXPECT().Uninstall(instanceId).Return(assert.AnError),
-					d.EXPECT().Restore(gomock.Any(), false).Return(assert.AnError),
+					d.EXPECT().CheckHardwareRequirements(daemon.HardwareRequirements{
+						MinCPUCores:                 2,
+						MinRAM:                      2048,
+						MinFreeSpace:                5120,
+						StopIfRequirementsAreNotMet: true,
+					}).Return(true, nil),
+					d.EXPECT().Backup(gomock.Any(), instanceId, daemon.BackupOptions{}).Return(fmt.Sprintf("%s-%d", instanceId, time.Now().Unix()), nil),
+					d.EXPECT().Uninstall(instanceId, daemon.UninstallOptions{KeepVolumes: true}).Return(assert.AnError),
+					d.EXPECT().Restore(gomock.Any(), gomock.Any(), daemon.RestoreOptions{}).Return(assert.AnError),
 				)
 			},
 		},
@@ -370,16 +416,16 @@ func TestUpdate(t *testing.T) {
 			args: []string{instanceId, "--backup"},
 			err:  nil,
 			mocker: func(ctrl *gomock.Controller, d *daemonMock.MockDaemon, p *prompterMock.MockPrompter) {
-				oldOption := daemonMock.NewMockOption(ctrl)
-				newOption := daemonMock.NewMockOption(ctrl)
-				mergedOption := daemonMock.NewMockOption(ctrl)
+				oldOption := newMockOption(ctrl)
+				newOption := newMockOption(ctrl)
+				mergedOption := newMockOption(ctrl)
 
 				oldOption.EXPECT().IsSet().Return(true)
 				oldOption.EXPECT().Value().Return("old-value", nil)
 				oldOption.EXPECT().Name().Return("old-option").Times(3)
 				mergedOption.EXPECT().IsSet().Return(true).Times(2)
-				mergedOption.EXPECT().Value().Return("old-value", nil).Times(2)
-				mergedOption.EXPECT().Name().Return("old-option").Times(2)
+				mergedOption.EXPECT().Value().Return("old-value", nil).Times(3)
+				mergedOption.EXPECT().Name().Return("old-option").Times(3)
 				mergedOption.EXPECT().Help().Return("option help")
 
 				gomock.InOrder(
@@ -403,8 +449,14 @@ func TestUpdate(t *testing.T) {
 							StopIfRequirementsAreNotMet: true,
 						},
 					}, nil),
-					d.EXPECT().Backup(instanceId).Return(fmt.Sprintf("%s-%d", instanceId, time.Now().Unix()), nil),
-					d.EXPECT().Uninstall(instanceId).Return(nil),
+					d.EXPECT().CheckHardwareRequirements(daemon.HardwareRequirements{
+						MinCPUCores:                 2,
+						MinRAM:                      2048,
+						MinFreeSpace:                5120,
+						StopIfRequirementsAreNotMet: true,
+					}).Return(true, nil),
+					d.EXPECT().Backup(gomock.Any(), instanceId, daemon.BackupOptions{}).Return(fmt.Sprintf("%s-%d", instanceId, time.Now().Unix()), nil),
+					d.EXPECT().Uninstall(instanceId, daemon.UninstallOptions{KeepVolumes: true}).Return(nil),
 					d.EXPECT().Install(daemon.InstallOptions{
 						Name:    "mock-avs",
 						Tag:     "default",
@@ -414,7 +466,7 @@ func TestUpdate(t *testing.T) {
 						Commit:  common.MockAvsPkg.CommitHash(),
 						Options: []daemon.Option{mergedOption},
 					}).Return("", assert.AnError),
-					d.EXPECT().Restore(gomock.Any(), false).Return(nil),
+					d.EXPECT().Restore(gomock.Any(), gomock.Any(), daemon.RestoreOptions{}).Return(nil),
 				)
 			},
 		},
@@ -423,16 +475,16 @@ func TestUpdate(t *testing.T) {
 			args: []string{instanceId, "--backup"},
 			err:  assert.AnError,
 			mocker: func(ctrl *gomock.Controller, d *daemonMock.MockDaemon, p *prompterMock.MockPrompter) {
-				oldOption := daemonMock.NewMockOption(ctrl)
-				newOption := daemonMock.NewMockOption(ctrl)
-				mergedOption := daemonMock.NewMockOption(ctrl)
+				oldOption := newMockOption(ctrl)
+				newOption := newMockOption(ctrl)
+				mergedOption := newMockOption(ctrl)
 
 				oldOption.EXPECT().IsSet().Return(true)
 				oldOption.EXPECT().Value().Return("old-value", nil)
 				oldOption.EXPECT().Name().Return("old-option").Times(3)
 				mergedOption.EXPECT().IsSet().Return(true).Times(2)
-				mergedOption.EXPECT().Value().Return("old-value", nil).Times(2)
-				mergedOption.EXPECT().Name().Return("old-option").Times(2)
+				mergedOption.EXPECT().Value().Return("old-value", nil).Times(3)
+				mergedOption.EXPECT().Name().Return("old-option").Times(3)
 				mergedOption.EXPECT().Help().Return("option help")
 
 				gomock.InOrder(
@@ -456,8 +508,14 @@ func TestUpdate(t *testing.T) {
 							StopIfRequirementsAreNotMet: true,
 						},
 					}, nil),
-					d.EXPECT().Backup(instanceId).Return(fmt.Sprintf("%s-%d", instanceId, time.Now().Unix()), nil),
-					d.EXPECT().Uninstall(instanceId).Return(nil),
+					d.EXPECT().CheckHardwareRequirements(daemon.HardwareRequirements{
+						MinCPUCores:                 2,
+						MinRAM:                      2048,
+						MinFreeSpace:                5120,
+						StopIfRequirementsAreNotMet: true,
+					}).Return(true, nil),
+					d.EXPECT().Backup(gomock.Any(), instanceId, daemon.BackupOptions{}).Return(fmt.Sprintf("%s-%d", instanceId, time.Now().Unix()), nil),
+					d.EXPECT().Uninstall(instanceId, daemon.UninstallOptions{KeepVolumes: true}).Return(nil),
 					d.EXPECT().Install(daemon.InstallOptions{
 						Name:    "mock-avs",
 						Tag:     "default",
@@ -467,10 +525,235 @@ func TestUpdate(t *testing.T) {
 						Commit:  common.MockAvsPkg.CommitHash(),
 						Options: []daemon.Option{mergedOption},
 					}).Return("", assert.AnError),
-					d.EXPECT().Restore(gomock.Any(), false).Return(assert.AnError),
+					d.EXPECT().Restore(gomock.Any(), gomock.Any(), daemon.RestoreOptions{}).Return(assert.AnError),
 				)
 			},
 		},
+		{
+			name: "update with migrations",
+			args: []string{instanceId, "--yes"},
+			mocker: func(ctrl *gomock.Controller, d *daemonMock.MockDaemon, p *prompterMock.MockPrompter) {
+				migrations := []daemon.Migration{{Image: "mock-migration:latest", Args: []string{"--data", "/data"}}}
+				gomock.InOrder(
+					d.EXPECT().PullUpdate(instanceId, daemon.PullTarget{}).Return(daemon.PullUpdateResult{
+						Name:       "mock-avs",
+						Tag:        "default",
+						Url:        common.MockAvsPkg.Repo(),
+						Profile:    "option-returner",
+						OldVersion: "v5.4.0",
+						NewVersion: common.MockAvsPkg.Version(),
+						OldCommit:  "b64c50c15e53ae7afebbdbe210b834d1ee471043",
+						NewCommit:  common.MockAvsPkg.CommitHash(),
+						Migrations: migrations,
+					}, nil),
+					d.EXPECT().CheckHardwareRequirements(daemon.HardwareRequirements{}).Return(true, nil),
+					d.EXPECT().ListInstances().Return([]daemon.ListInstanceItem{{ID: instanceId, Running: true}}, nil),
+					d.EXPECT().Stop(instanceId).Return(nil),
+					d.EXPECT().RunMigrations(instanceId, migrations).Return(nil),
+					d.EXPECT().Uninstall(instanceId, daemon.UninstallOptions{KeepVolumes: true}).Return(nil),
+					d.EXPECT().Install(daemon.InstallOptions{
+						Name:    "mock-avs",
+						Tag:     "default",
+						URL:     common.MockAvsPkg.Repo(),
+						Profile: "option-returner",
+						Version: common.MockAvsPkg.Version(),
+						Commit:  common.MockAvsPkg.CommitHash(),
+					}).Return(instanceId, nil),
+					d.EXPECT().Run(instanceId),
+				)
+			},
+		},
+		{
+			name: "update with failing migrations and backup",
+			args: []string{instanceId, "--backup"},
+			mocker: func(ctrl *gomock.Controller, d *daemonMock.MockDaemon, p *prompterMock.MockPrompter) {
+				migrations := []daemon.Migration{{Image: "mock-migration:latest"}}
+				gomock.InOrder(
+					d.EXPECT().PullUpdate(instanceId, daemon.PullTarget{}).Return(daemon.PullUpdateResult{
+						Name:       "mock-avs",
+						Tag:        "default",
+						Url:        common.MockAvsPkg.Repo(),
+						Profile:    "option-returner",
+						OldVersion: "v5.4.0",
+						NewVersion: common.MockAvsPkg.Version(),
+						Migrations: migrations,
+					}, nil),
+					d.EXPECT().CheckHardwareRequirements(daemon.HardwareRequirements{}).Return(true, nil),
+					d.EXPECT().Backup(gomock.Any(), instanceId, daemon.BackupOptions{}).Return(fmt.Sprintf("%s-%d", instanceId, time.Now().Unix()), nil),
+					// The instance is stopped even if the backup left it running
+					d.EXPECT().Stop(instanceId).Return(nil),
+					d.EXPECT().RunMigrations(instanceId, migrations).Return(assert.AnError),
+					d.EXPECT().Restore(gomock.Any(), gomock.Any(), daemon.RestoreOptions{}).Return(nil),
+				)
+			},
+		},
+		{
+			name: "update with failing migrations, running instance restarted",
+			args: []string{instanceId, "--yes"},
+			err:  assert.AnError,
+			mocker: func(ctrl *gomock.Controller, d *daemonMock.MockDaemon, p *prompterMock.MockPrompter) {
+				migrations := []daemon.Migration{{Image: "mock-migration:latest"}}
+				gomock.InOrder(
+					d.EXPECT().PullUpdate(instanceId, daemon.PullTarget{}).Return(daemon.PullUpdateResult{
+						Name:       "mock-avs",
+						Tag:        "default",
+						OldVersion: "v5.4.0",
+						NewVersion: common.MockAvsPkg.Version(),
+						Migrations: migrations,
+					}, nil),
+					d.EXPECT().CheckHardwareRequirements(daemon.HardwareRequirements{}).Return(true, nil),
+					d.EXPECT().ListInstances().Return([]daemon.ListInstanceItem{
+						{ID: "mock-avs-second", Running: false},
+						{ID: instanceId, Running: true},
+					}, nil),
+					d.EXPECT().Stop(instanceId).Return(nil),
+					d.EXPECT().RunMigrations(instanceId, migrations).Return(assert.AnError),
+					d.EXPECT().Run(instanceId).Return(nil),
+				)
+			},
+		},
+		{
+			name: "update with failing migrations, stopped instance left stopped",
+			args: []string{instanceId, "--yes"},
+			err:  assert.AnError,
+			mocker: func(ctrl *gomock.Controller, d *daemonMock.MockDaemon, p *prompterMock.MockPrompter) {
+				migrations := []daemon.Migration{{Image: "mock-migration:latest"}}
+				gomock.InOrder(
+					d.EXPECT().PullUpdate(instanceId, daemon.PullTarget{}).Return(daemon.PullUpdateResult{
+						Name:       "mock-avs",
+						Tag:        "default",
+						OldVersion: "v5.4.0",
+						NewVersion: common.MockAvsPkg.Version(),
+						Migrations: migrations,
+					}, nil),
+					d.EXPECT().CheckHardwareRequirements(daemon.HardwareRequirements{}).Return(true, nil),
+					d.EXPECT().ListInstances().Return([]daemon.ListInstanceItem{{ID: instanceId, Running: false}}, nil),
+					d.EXPECT().Stop(instanceId).Return(nil),
+					d.EXPECT().RunMigrations(instanceId, migrations).Return(assert.AnError),
+				)
+			},
+		},
+		{
+			name: "update without backup, fails to install new instance",
+			args: []string{instanceId, "--yes"},
+			err:  assert.AnError,
+			mocker: func(ctrl *gomock.Controller, d *daemonMock.MockDaemon, p *prompterMock.MockPrompter) {
+				gomock.InOrder(
+					d.EXPECT().PullUpdate(instanceId, daemon.PullTarget{}).Return(daemon.PullUpdateResult{
+						Name:       "mock-avs",
+						Tag:        "default",
+						Url:        common.MockAvsPkg.Repo(),
+						Profile:    "option-returner",
+						OldVersion: "v5.4.0",
+						NewVersion: common.MockAvsPkg.Version(),
+					}, nil),
+					d.EXPECT().CheckHardwareRequirements(daemon.HardwareRequirements{}).Return(true, nil),
+					d.EXPECT().Uninstall(instanceId, daemon.UninstallOptions{KeepVolumes: true}).Return(nil),
+					d.EXPECT().Install(daemon.InstallOptions{
+						Name:    "mock-avs",
+						Tag:     "default",
+						URL:     common.MockAvsPkg.Repo(),
+						Profile: "option-returner",
+						Version: common.MockAvsPkg.Version(),
+					}).Return("", assert.AnError),
+				)
+			},
+		},
+		{
+			name: "update with incompatible data, no prompt",
+			args: []string{instanceId, "--no-prompt"},
+			mocker: func(ctrl *gomock.Controller, d *daemonMock.MockDaemon, p *prompterMock.MockPrompter) {
+				d.EXPECT().PullUpdate(instanceId, daemon.PullTarget{}).Return(daemon.PullUpdateResult{
+					Name:             "mock-avs",
+					Tag:              "default",
+					OldVersion:       "v5.4.0",
+					NewVersion:       common.MockAvsPkg.Version(),
+					IncompatibleData: true,
+				}, nil)
+				d.EXPECT().CheckHardwareRequirements(daemon.HardwareRequirements{}).Return(true, nil)
+			},
+			err: fmt.Errorf("%w: use --yes to update anyway", ErrIncompatibleData),
+		},
+		{
+			name: "update with incompatible data, not confirmed",
+			args: []string{instanceId},
+			mocker: func(ctrl *gomock.Controller, d *daemonMock.MockDaemon, p *prompterMock.MockPrompter) {
+				gomock.InOrder(
+					d.EXPECT().PullUpdate(instanceId, daemon.PullTarget{}).Return(daemon.PullUpdateResult{
+						Name:             "mock-avs",
+						Tag:              "default",
+						OldVersion:       "v5.4.0",
+						NewVersion:       common.MockAvsPkg.Version(),
+						IncompatibleData: true,
+					}, nil),
+					d.EXPECT().CheckHardwareRequirements(daemon.HardwareRequirements{}).Return(true, nil),
+					p.EXPECT().Confirm(gomock.Any()).Return(false, nil),
+				)
+			},
+			err: ErrIncompatibleData,
+		},
+		{
+			name: "update with incompatible data, confirmed, volumes removed",
+			args: []string{instanceId},
+			mocker: func(ctrl *gomock.Controller, d *daemonMock.MockDaemon, p *prompterMock.MockPrompter) {
+				gomock.InOrder(
+					d.EXPECT().PullUpdate(instanceId, daemon.PullTarget{}).Return(daemon.PullUpdateResult{
+						Name:             "mock-avs",
+						Tag:              "default",
+						Url:              common.MockAvsPkg.Repo(),
+						Profile:          "option-returner",
+						OldVersion:       "v5.4.0",
+						NewVersion:       common.MockAvsPkg.Version(),
+						NewCommit:        common.MockAvsPkg.CommitHash(),
+						IncompatibleData: true,
+					}, nil),
+					d.EXPECT().CheckHardwareRequirements(daemon.HardwareRequirements{}).Return(true, nil),
+					p.EXPECT().Confirm(gomock.Any()).Return(true, nil),
+					d.EXPECT().Uninstall(instanceId, daemon.UninstallOptions{KeepVolumes: false}).Return(nil),
+					d.EXPECT().Install(daemon.InstallOptions{
+						Name:    "mock-avs",
+						Tag:     "default",
+						URL:     common.MockAvsPkg.Repo(),
+						Profile: "option-returner",
+						Version: common.MockAvsPkg.Version(),
+						Commit:  common.MockAvsPkg.CommitHash(),
+					}).Return(instanceId, nil),
+					p.EXPECT().Confirm("Run the new instance now?").Return(false, nil),
+				)
+			},
+		},
+		{
+			name: "update with hardware requirements not met",
+			args: []string{instanceId},
+			mocker: func(ctrl *gomock.Controller, d *daemonMock.MockDaemon, p *prompterMock.MockPrompter) {
+				requirements := daemon.HardwareRequirements{
+					MinCPUCores:                 64,
+					MinRAM:                      2048,
+					MinFreeSpace:                5120,
+					StopIfRequirementsAreNotMet: true,
+				}
+				gomock.InOrder(
+					d.EXPECT().PullUpdate(instanceId, daemon.PullTarget{}).Return(daemon.PullUpdateResult{
+						Name:                 "mock-avs",
+						Tag:                  "default",
+						Profile:              "option-returner",
+						OldVersion:           "v5.4.0",
+						NewVersion:           common.MockAvsPkg.Version(),
+						HardwareRequirements: requirements,
+					}, nil),
+					d.EXPECT().CheckHardwareRequirements(requirements).Return(false, nil),
+				)
+			},
+			err: fmt.Errorf("profile %s does not meet the hardware requirements", "option-returner"),
+		},
+		{
+			name: "update with incompatible upgrade",
+			args: []string{instanceId},
+			mocker: func(ctrl *gomock.Controller, d *daemonMock.MockDaemon, p *prompterMock.MockPrompter) {
+				d.EXPECT().PullUpdate(instanceId, daemon.PullTarget{}).Return(daemon.PullUpdateResult{}, daemon.ErrIncompatibleUpgrade)
+			},
+			err: daemon.ErrIncompatibleUpgrade,
+		},
 		{
 			name: "invalid arguments, instance id is required",
 			args: []string{},
@@ -509,3 +792,210 @@ func TestUpdate(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdate_Secret(t *testing.T) {
+	const (
+		instanceId = "mock-avs-default"
+		oldSecret  = "old-s3cr3t-api-key"
+		newSecret  = "new-s3cr3t-api-key"
+	)
+
+	tc := []struct {
+		name   string
+		args   []string
+		set    bool
+		prompt bool
+		run    bool
+		want   string
+	}{
+		{
+			name: "current value kept",
+			args: []string{"--no-prompt", instanceId},
+			set:  true,
+			want: oldSecret,
+		},
+		{
+			name: "value from flag",
+			args: []string{"--no-prompt", "--option.api-key", newSecret, instanceId},
+			set:  true,
+			want: newSecret,
+		},
+		{
+			name:   "unset value prompted as hidden",
+			args:   []string{"--yes", instanceId},
+			prompt: true,
+			run:    true,
+			want:   newSecret,
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			out := captureLogs(t)
+			ctrl := gomock.NewController(t)
+			d := daemonMock.NewMockDaemon(ctrl)
+			p := prompterMock.NewMockPrompter(ctrl)
+
+			newOption := func() daemon.Option {
+				return daemon.NewOptionSecret(profile.Option{Name: "api-key", Target: "API_KEY", Type: "secret", Help: "API key"})
+			}
+			oldOption, mergedOption := newOption(), newOption()
+			if tt.set {
+				require.NoError(t, oldOption.Set(oldSecret))
+				require.NoError(t, mergedOption.Set(oldSecret))
+			}
+			d.EXPECT().PullUpdate(instanceId, daemon.PullTarget{}).Return(daemon.PullUpdateResult{
+				Name:          "mock-avs",
+				Tag:           "default",
+				Url:           common.MockAvsPkg.Repo(),
+				Profile:       "option-returner",
+				OldVersion:    "v5.4.0",
+				NewVersion:    common.MockAvsPkg.Version(),
+				OldOptions:    []daemon.Option{oldOption},
+				NewOptions:    []daemon.Option{newOption()},
+				MergedOptions: []daemon.Option{mergedOption},
+			}, nil)
+			d.EXPECT().CheckHardwareRequirements(daemon.HardwareRequirements{}).Return(true, nil)
+			if tt.prompt {
+				p.EXPECT().InputHiddenString("api-key", "API key", gomock.Any()).
+					DoAndReturn(func(_, _ string, validator func(string) error) (string, error) {
+						return newSecret, validator(newSecret)
+					})
+			}
+			d.EXPECT().Uninstall(instanceId, daemon.UninstallOptions{KeepVolumes: true}).Return(nil)
+			d.EXPECT().Install(gomock.Any()).DoAndReturn(func(options daemon.InstallOptions) (string, error) {
+				// The secret is passed to the daemon as it was entered
+				require.Len(t, options.Options, 1)
+				v, err := options.Options[0].Value()
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, v)
+				return instanceId, nil
+			})
+			if tt.run {
+				d.EXPECT().Run(instanceId).Return(nil)
+			}
+
+			updateCmd := UpdateCmd(d, p)
+			updateCmd.SetOut(out)
+			updateCmd.SetErr(out)
+			updateCmd.SetArgs(tt.args)
+			require.NoError(t, updateCmd.Execute())
+
+			assert.NotContains(t, out.String(), oldSecret)
+			assert.NotContains(t, out.String(), newSecret)
+			if tt.set {
+				assert.Contains(t, out.String(), "<redacted>")
+			}
+		})
+	}
+}
+
+// newMockOption returns a mock option with a visible value.
+func newMockOption(ctrl *gomock.Controller) *daemonMock.MockOption {
+	o := daemonMock.NewMockOption(ctrl)
+	o.EXPECT().Hidden().Return(false).AnyTimes()
+	o.EXPECT().Active(gomock.Any()).Return(true).AnyTimes()
+	return o
+}
+
+func TestOptionDisplayValue(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	visible := newMockOption(ctrl)
+	visible.EXPECT().Value().Return("value", nil)
+	secret := daemonMock.NewMockOption(ctrl)
+	secret.EXPECT().Value().Return("secret-api-key", nil)
+	secret.EXPECT().Hidden().Return(true)
+
+	v, err := optionDisplayValue(visible)
+	require.NoError(t, err)
+	assert.Equal(t, "value", v)
+	v, err = optionDisplayValue(secret)
+	require.NoError(t, err)
+	assert.Equal(t, "<redacted>", v)
+}
+
+func TestConfirmOptionChanges(t *testing.T) {
+	kept := daemon.OptionChange{Name: "port", Kind: daemon.OptionKept, OldValue: "8080", NewValue: "8080"}
+	renamed := daemon.OptionChange{Name: "rpc-url", OldName: "eth-rpc", Kind: daemon.OptionRenamed, OldValue: "https://rpc", NewValue: "https://rpc"}
+	reset := daemon.OptionChange{Name: "log-level", Kind: daemon.OptionReset, OldValue: "verbose", Reason: "invalid value"}
+
+	tc := []struct {
+		name     string
+		changes  []daemon.OptionChange
+		yes      bool
+		noPrompt bool
+		confirm  *bool
+		err      error
+	}{
+		{
+			name:    "no changes",
+			changes: nil,
+		},
+		{
+			name:    "values kept",
+			changes: []daemon.OptionChange{kept, renamed},
+		},
+		{
+			name:    "values lost, confirmed",
+			changes: []daemon.OptionChange{kept, reset},
+			confirm: boolP(true),
+		},
+		{
+			name:    "values lost, not confirmed",
+			changes: []daemon.OptionChange{kept, reset},
+			confirm: boolP(false),
+			err:     ErrOptionValuesLost,
+		},
+		{
+			name:    "values lost, --yes",
+			changes: []daemon.OptionChange{reset},
+			yes:     true,
+		},
+		{
+			name:     "values lost, --no-prompt",
+			changes:  []daemon.OptionChange{reset},
+			noPrompt: true,
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			p := prompterMock.NewMockPrompter(ctrl)
+			if tt.confirm != nil {
+				p.EXPECT().Confirm("Some option values of the current instance can not be kept. Continue with the update?").Return(*tt.confirm, nil)
+			}
+			var out bytes.Buffer
+			err := confirmOptionChanges(daemon.PullUpdateResult{OptionChanges: tt.changes}, p, &out, tt.yes, tt.noPrompt)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+			} else {
+				assert.NoError(t, err)
+			}
+			for _, c := range tt.changes {
+				assert.Contains(t, out.String(), c.Name)
+			}
+		})
+	}
+}
+
+func TestPrintOptionChanges(t *testing.T) {
+	var out bytes.Buffer
+	printOptionChanges([]daemon.OptionChange{
+		{Name: "rpc-url", OldName: "eth-rpc", Kind: daemon.OptionRenamed, OldValue: "https://rpc", NewValue: "https://rpc"},
+		{Name: "log-level", Kind: daemon.OptionReset, OldValue: "verbose", Reason: "invalid value"},
+		{Name: "legacy", Kind: daemon.OptionRemoved, OldValue: "value"},
+	}, &out)
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	for i := range lines {
+		lines[i] = strings.TrimRight(lines[i], " ")
+	}
+	assert.Equal(t, []string{
+		"OPTION       CHANGE     OLD VALUE      NEW VALUE      NOTES",
+		"rpc-url      renamed    https://rpc    https://rpc    renamed from eth-rpc",
+		"log-level    reset      verbose        <to be set>    invalid value",
+		"legacy       removed    value",
+	}, lines)
+}
+
+func boolP(b bool) *bool {
+	return &b
+}
@@ -10,8 +10,10 @@ func validatePkgURL(urlStr string) error {
 	if err != nil {
 		return fmt.Errorf("%w: %s", ErrInvalidURL, err.Error())
 	}
-	if parsedURL.Scheme != "https" && parsedURL.Scheme != "http" {
-		return fmt.Errorf("%w: %s", ErrInvalidURL, "URL must be HTTP or HTTPS")
+	switch parsedURL.Scheme {
+	case "https", "http", "oci", "tar+https", "tar+http":
+	default:
+		return fmt.Errorf("%w: %s", ErrInvalidURL, "URL must be HTTP, HTTPS, OCI or tar+HTTP(S)")
 	}
 	return nil
 }
@@ -32,7 +32,17 @@ func TestValidateURL(t *testing.T) {
 		{
 			name: "non HTTP or HTTPS URL",
 			url:  "ftp://github.com/NethermidEth/mock-avs-pkg.git",
-			err:  fmt.Errorf("%w: URL must be HTTP or HTTPS", ErrInvalidURL),
+			err:  fmt.Errorf("%w: URL must be HTTP, HTTPS, OCI or tar+HTTP(S)", ErrInvalidURL),
+		},
+		{
+			name: "OCI URL",
+			url:  "oci://ghcr.io/nethermindeth/mock-avs-pkg",
+			err:  nil,
+		},
+		{
+			name: "tarball index URL",
+			url:  "tar+https://example.com/mock-avs-pkg/index.yml",
+			err:  nil,
 		},
 		{
 			name: "URL with IP instead of domain",
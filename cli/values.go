@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/NethermindEth/eigenlayer/pkg/daemon"
+)
+
+// valuesFile is the content of an options values file, mapping profile names to
+// the values of their options by option name.
+type valuesFile map[string]map[string]optionValue
+
+// optionValue is the value of an option in a values file. Scalars are used as
+// they are, and sequences are joined with commas to fill list options.
+type optionValue string
+
+func (v *optionValue) UnmarshalYAML(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		*v = optionValue(node.Value)
+	case yaml.SequenceNode:
+		var items []string
+		if err := node.Decode(&items); err != nil {
+			return err
+		}
+		*v = optionValue(strings.Join(items, ","))
+	default:
+		return fmt.Errorf("%w: line %d: option values must be scalars or lists", ErrInvalidValuesFile, node.Line)
+	}
+	return nil
+}
+
+// readValuesFile reads the options values file in the given path.
+func readValuesFile(path string) (valuesFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var values valuesFile
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidValuesFile, err)
+	}
+	return values, nil
+}
+
+// writeValuesTemplate writes a values file with the default values of the given
+// profiles options, and their help texts as comments. Options without a default
+// value and hidden options are written commented out, so the file can be used
+// as it is, and they are only set if they are uncommented. If profile is not
+// empty, only the options of that profile are written.
+func writeValuesTemplate(w io.Writer, pullResult daemon.PullResult, profile string) error {
+	profiles := make([]string, 0, len(pullResult.Options))
+	for profileName := range pullResult.Options {
+		if profile == "" || profile == profileName {
+			profiles = append(profiles, profileName)
+		}
+	}
+	if len(profiles) == 0 {
+		return fmt.Errorf("profile %s not found", profile)
+	}
+	sort.Strings(profiles)
+
+	root := &yaml.Node{Kind: yaml.MappingNode}
+	root.HeadComment = "Option values of " + pullResult.Name
+	if pullResult.Version != "" {
+		root.HeadComment += " " + pullResult.Version
+	} else if pullResult.Commit != "" {
+		root.HeadComment += " " + pullResult.Commit
+	}
+	for _, profileName := range profiles {
+		options := &yaml.Node{Kind: yaml.MappingNode}
+		// Comments of the commented out options, written before the next
+		// option or after the last one
+		var commented []string
+		for _, o := range pullResult.Options[profileName] {
+			help := o.Help()
+			if o.Hidden() {
+				help += " (hidden)"
+			}
+			if o.Hidden() || o.Default() == "" {
+				// An empty value would be used instead of asking for the
+				// option or using its hidden default value
+				commented = append(commented, help, o.Name()+`: ""`)
+				continue
+			}
+			key := &yaml.Node{Kind: yaml.ScalarNode, Value: o.Name(), HeadComment: strings.Join(append(commented, help), "\n")}
+			value := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: o.Default()}
+			options.Content = append(options.Content, key, value)
+			commented = nil
+		}
+		if len(commented) > 0 {
+			if len(options.Content) == 0 {
+				// A profile with all its options commented out has no values
+				options = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null"}
+			}
+			last := options
+			if len(options.Content) > 0 {
+				last = options.Content[len(options.Content)-1]
+			}
+			last.FootComment = strings.Join(commented, "\n")
+		}
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: profileName}, options)
+	}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(root); err != nil {
+		return err
+	}
+	return enc.Close()
+}
@@ -0,0 +1,120 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	daemonMock "github.com/NethermindEth/eigenlayer/cli/mocks"
+	"github.com/NethermindEth/eigenlayer/pkg/daemon"
+)
+
+func TestReadValuesFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    valuesFile
+		wantErr error
+	}{
+		{
+			name:    "scalars and lists",
+			content: "default:\n  port: 8080\n  enabled: true\n  rpcs:\n    - https://rpc-1\n    - https://rpc-2\n",
+			want: valuesFile{
+				"default": {
+					"port":    "8080",
+					"enabled": "true",
+					"rpcs":    "https://rpc-1,https://rpc-2",
+				},
+			},
+		},
+		{
+			name:    "nested values",
+			content: "default:\n  port:\n    value: 8080\n",
+			wantErr: ErrInvalidValuesFile,
+		},
+		{
+			name:    "invalid yaml",
+			content: "default: [",
+			wantErr: ErrInvalidValuesFile,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "values.yml")
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), 0o644))
+			got, err := readValuesFile(path)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestWriteValuesTemplate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	port := daemonMock.NewMockOption(ctrl)
+	port.EXPECT().Name().Return("port").AnyTimes()
+	port.EXPECT().Help().Return("Node port").AnyTimes()
+	port.EXPECT().Default().Return("8080").AnyTimes()
+	port.EXPECT().Hidden().Return(false).AnyTimes()
+	apiKey := daemonMock.NewMockOption(ctrl)
+	apiKey.EXPECT().Name().Return("api-key").AnyTimes()
+	apiKey.EXPECT().Help().Return("API key").AnyTimes()
+	apiKey.EXPECT().Default().Return("").AnyTimes()
+	apiKey.EXPECT().Hidden().Return(true).AnyTimes()
+	endpoint := daemonMock.NewMockOption(ctrl)
+	endpoint.EXPECT().Name().Return("endpoint").AnyTimes()
+	endpoint.EXPECT().Help().Return("RPC endpoint").AnyTimes()
+	endpoint.EXPECT().Default().Return("").AnyTimes()
+	endpoint.EXPECT().Hidden().Return(false).AnyTimes()
+	pullResult := daemon.PullResult{
+		Name:    "mock-avs",
+		Version: "v1.0.0",
+		Options: map[string][]daemon.Option{
+			"default": {endpoint, port, apiKey},
+			"other":   {port},
+			"secrets": {apiKey},
+		},
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, writeValuesTemplate(&out, pullResult, "default"))
+	assert.Equal(t, `# Option values of mock-avs v1.0.0
+default:
+  # RPC endpoint
+  # endpoint: ""
+  # Node port
+  port: "8080"
+  # API key (hidden)
+  # api-key: ""
+`, out.String())
+
+	// The template can be read back as a values file
+	path := filepath.Join(t.TempDir(), "values.yml")
+	require.NoError(t, os.WriteFile(path, out.Bytes(), 0o644))
+	values, err := readValuesFile(path)
+	require.NoError(t, err)
+	// The options without default value are not set
+	assert.Equal(t, valuesFile{"default": {"port": "8080"}}, values)
+
+	out.Reset()
+	require.NoError(t, writeValuesTemplate(&out, pullResult, ""))
+	assert.Contains(t, out.String(), "other:\n")
+
+	out.Reset()
+	require.NoError(t, writeValuesTemplate(&out, pullResult, "secrets"))
+	require.NoError(t, os.WriteFile(path, out.Bytes(), 0o644))
+	values, err = readValuesFile(path)
+	require.NoError(t, err)
+	assert.Empty(t, values["secrets"])
+
+	assert.EqualError(t, writeValuesTemplate(&out, pullResult, "missing"), "profile missing not found")
+}
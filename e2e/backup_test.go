@@ -77,8 +77,8 @@ func TestBackupList(t *testing.T) {
 		func(t *testing.T) {
 			t.Log(string(out))
 			assert.NoError(t, backupErr, "backup ls command should succeed")
-			assert.Regexp(t, regexp.MustCompile(`ID\s+AVS Instance ID\s+VERSION\s+COMMIT\s+TIMESTAMP\s+SIZE\s+URL\s+`+
-				`[a-f0-9]+\s+mock-avs-default\s+`+common.MockAvsPkg.Version()+`\s+`+common.MockAvsPkg.CommitHash()+`\s+.*\s+10KiB\s+https://github.com/NethermindEth/mock-avs-pkg\s+`),
+			assert.Regexp(t, regexp.MustCompile(`ID\s+AVS Instance ID\s+VERSION\s+COMMIT\s+TIMESTAMP\s+SIZE\s+STORED\s+URL\s+`+
+				`[a-f0-9]+\s+mock-avs-default\s+`+common.MockAvsPkg.Version()+`\s+`+common.MockAvsPkg.CommitHash()+`\s+.*\s+10KiB\s+\S+\s+https://github.com/NethermindEth/mock-avs-pkg\s+`),
 				string(out))
 		},
 	)
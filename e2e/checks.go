@@ -402,10 +402,11 @@ func checkStateJson(t *testing.T, instanceId string, s json.RawMessage) {
 	assert.Equal(t, s, loadStateJSON(t, instanceId), "state.json should be equal")
 }
 
-// TODO add doc
+// checkBackupExist checks that the manifest of the backup with the given id
+// exists in the data dir.
 func checkBackupExist(t *testing.T, backupId string) {
 	dataDir, err := dataDirPath()
 	require.NoError(t, err)
-	backupPath := filepath.Join(dataDir, "backup", backupId+".tar")
-	assert.FileExists(t, backupPath)
+	manifestPath := filepath.Join(dataDir, "backup", backupId+".json")
+	assert.FileExists(t, manifestPath)
 }
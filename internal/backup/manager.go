@@ -1,25 +1,24 @@
 package backup
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
-	"os"
+	"io"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
-	"github.com/NethermindEth/docker-volumes-snapshotter/pkg/backuptar"
 	"github.com/NethermindEth/eigenlayer/internal/compose"
 	"github.com/NethermindEth/eigenlayer/internal/data"
 	"github.com/NethermindEth/eigenlayer/internal/docker"
+	"github.com/NethermindEth/eigenlayer/internal/profile"
 	"github.com/compose-spec/compose-go/types"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/afero"
-)
-
-const (
-	SnapshotterVersion       = "v0.2.0"
-	SnapshotterRepo          = "github.com/NethermindEth/docker-volumes-snapshotter"
-	SnapshotterRemoteContext = SnapshotterRepo + ".git#" + SnapshotterVersion
-	SnapshotterImage         = "eigenlayer-snapshotter:" + SnapshotterVersion
+	"gopkg.in/yaml.v3"
 )
 
 type BackupInfo struct {
@@ -28,6 +27,57 @@ type BackupInfo struct {
 	SizeBytes uint64
 }
 
+// BackupOptions are the options of a new backup.
+type BackupOptions struct {
+	// Compression is the compression of the backup data, one of
+	// data.CompressionZstd (default), data.CompressionGzip and
+	// data.CompressionNone.
+	Compression string
+	// Passphrase encrypts the backup data if it is not empty. The backup
+	// information, like the instance and the timestamp, is not encrypted.
+	Passphrase string
+	// Consistency is how the running services of the instance are quiesced
+	// while their volumes are backed up, one of data.BackupConsistencyStop
+	// (default) and data.BackupConsistencyPause.
+	Consistency string
+	// Volumes selects the volumes to back up. All the volumes are backed up
+	// if it is empty.
+	Volumes data.VolumeFilter
+	// ConfigOnly backs up the instance data without any volume. The services
+	// of the instance are not quiesced.
+	ConfigOnly bool
+	// Scheduled marks the backup as created by the backup schedule of the
+	// instance.
+	Scheduled bool
+	// Progress receives the progress of the backup of each volume, if it is
+	// not nil.
+	Progress ProgressFunc
+}
+
+// RestoreOptions are the options to restore a backup.
+type RestoreOptions struct {
+	// Passphrase decrypts the data of encrypted backups.
+	Passphrase string
+	// Volumes selects the volumes to restore among the volumes of the backup.
+	// All the volumes of the backup are restored if it is empty.
+	Volumes data.VolumeFilter
+	// ConfigOnly restores the instance data without any volume.
+	ConfigOnly bool
+	// Secrets are the values of the active secret options of the instance
+	// that are not stored, by option name, like when the backup is restored
+	// on another host.
+	Secrets map[string]string
+	// Tag restores the backup as a new instance with the given tag, instead
+	// of the instance of the backup. The fixed container names of the
+	// instance are removed, and it must not use bind mounts outside its
+	// directory, or external or explicitly named volumes, which would be
+	// shared with the instance of the backup.
+	Tag string
+	// Progress receives the progress of the restore of each volume, if it is
+	// not nil.
+	Progress ProgressFunc
+}
+
 type BackupManager struct {
 	dataDir    *data.DataDir
 	dockerMgr  *docker.DockerManager
@@ -44,266 +94,536 @@ func NewBackupManager(fs afero.Fs, dataDir *data.DataDir, dockerMgr *docker.Dock
 	}
 }
 
-// BackupInstance creates a backup of the instance with the given ID.
-func (b *BackupManager) BackupInstance(instanceId string) (string, error) {
+// BackupInstance creates a backup of the instance with the given ID. The
+// backup is stored compressed, deduplicating the data already stored by
+// previous backups of the instance. The backup is canceled when the context is
+// done. The backup tar is stored in chunks as it is written, and if the backup
+// fails or is canceled, the backup is not stored.
+func (b *BackupManager) BackupInstance(ctx context.Context, instanceId string, options BackupOptions) (string, error) {
 	if !b.dataDir.HasInstance(instanceId) {
 		return "", fmt.Errorf("%w: instance %s", data.ErrInstanceNotFound, instanceId)
 	}
+	if err := data.ValidateCompression(options.Compression); err != nil {
+		return "", err
+	}
+	if err := data.ValidateBackupConsistency(options.Consistency); err != nil {
+		return "", err
+	}
+	if err := options.Volumes.Validate(); err != nil {
+		return "", err
+	}
+	consistency := options.Consistency
+	if consistency == "" {
+		consistency = data.BackupConsistencyStop
+	}
 	instance, err := b.dataDir.Instance(instanceId)
 	if err != nil {
 		return "", err
 	}
 	log.Info("Backing up instance ", instanceId)
-	if err := b.buildSnapshotterImage(); err != nil {
-		return "", err
-	}
 	instanceProject, err := instance.ComposeProject()
 	if err != nil {
 		return "", err
 	}
-
-	backup := &data.Backup{
-		InstanceId: instanceId,
-		Timestamp:  time.Now(),
-		Version:    instance.Version,
-		Commit:     instance.Commit,
-		Url:        instance.URL,
-	}
-
-	err = b.dataDir.InitBackup(backup)
-	if err != nil {
+	volumes := instanceVolumes(instanceProject)
+	if err := options.Volumes.CheckIncluded(volumes); err != nil {
 		return "", err
 	}
+	volumes = selectVolumes(volumes, func(v data.BackupVolume) bool {
+		return !options.ConfigOnly && options.Volumes.Match(v)
+	})
 
-	// Add volumes of each service
-	for _, service := range instanceProject.Services {
-		err := b.backupInstanceServiceVolumes(service, backup)
-		if err != nil {
-			return "", err
+	backup := &data.Backup{
+		InstanceId:  instanceId,
+		Timestamp:   time.Now(),
+		Version:     instance.Version,
+		Commit:      instance.Commit,
+		Url:         instance.URL,
+		Consistency: consistency,
+		Scheduled:   options.Scheduled,
+	}
+	if options.ConfigOnly || !options.Volumes.IsZero() {
+		backup.Selection = &data.BackupSelection{
+			Filter:     options.Volumes,
+			ConfigOnly: options.ConfigOnly,
+			Volumes:    volumes,
 		}
 	}
 
-	// Add instance data
-	err = b.backupInstanceData(instanceId, backup)
+	err = b.dataDir.InitBackup(backup)
 	if err != nil {
 		return "", err
 	}
 
-	// Add timestamp
-	err = b.addTimestamp(backup)
+	// Store the backup chunks as the backup tar is written
+	if options.Passphrase != "" {
+		log.Info("Compressing, encrypting and storing backup data...")
+	} else {
+		log.Info("Compressing and storing backup data...")
+	}
+	pr, pw := io.Pipe()
+	packed := make(chan error, 1)
+	go func() {
+		err := b.dataDir.PackBackup(ctx, backup, pr, data.PackOptions{
+			Compression: options.Compression,
+			Passphrase:  options.Passphrase,
+		})
+		// Stop the backup if the chunks can't be stored
+		pr.CloseWithError(err)
+		packed <- err
+	}()
+	err = b.writeBackupTar(ctx, pw, instance, instanceProject, volumes, backup, options.Progress)
+	pw.CloseWithError(err)
+	if packErr := <-packed; err == nil {
+		err = packErr
+	}
 	if err != nil {
 		return "", err
 	}
+	log.Infof("Backup size: %d bytes, stored: %d bytes", backup.Size, backup.StoredSize)
 
 	return backup.Id(), nil
 }
 
-func (b *BackupManager) RestoreInstance(backupId string) error {
+// RestoreInstance restores the backup with the given ID, and returns the ID of
+// the restored instance. Encrypted backups are decrypted with the passphrase of
+// the options. The restore is canceled when the context is done. If the restore
+// fails or is canceled after restoring the instance data, the ID of the
+// instance is returned with the error. Backups restored with a new tag are
+// refused with a data.ErrSharedResources error if the instance uses resources
+// that are not namespaced to it, before any volume or container is touched.
+func (b *BackupManager) RestoreInstance(ctx context.Context, backupId string, options RestoreOptions) (string, error) {
 	backup, err := b.dataDir.Backup(backupId)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	log.Infof("Restoring backup INSTANCE_ID: %s, VERSION: %s, COMMIT: %s", backup.InstanceId, backup.Version, backup.Commit)
 
-	backupPath := b.dataDir.BackupPath(backup.Id())
-	if err != nil {
-		return err
+	// The backup tar is read from the backup chunks each time it is needed,
+	// instead of writing it to disk
+	read := func(fn func(io.Reader) error) error {
+		r, err := b.dataDir.OpenBackup(ctx, backup.Id(), options.Passphrase)
+		if err != nil {
+			return err
+		}
+		defer r.Close()
+		return fn(r)
 	}
 
 	// Restore instance data
-	err = b.restoreInstanceData(backup.InstanceId, backupPath)
+	instanceId := backup.InstanceId
+	if options.Tag != "" {
+		err = read(func(r io.Reader) error {
+			instanceId, err = b.dataDir.ExtractInstanceFromTar(r, options.Tag)
+			return err
+		})
+		if err == nil {
+			log.Infof("Restoring backup as instance %s", instanceId)
+		}
+	} else {
+		err = read(func(r io.Reader) error {
+			return b.dataDir.ReplaceInstanceDirFromTar(backup.InstanceId, r, "data")
+		})
+	}
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	if err := b.buildSnapshotterImage(); err != nil {
-		return err
+	instance, err := b.dataDir.Instance(instanceId)
+	if err != nil {
+		return instanceId, err
+	}
+	if options.Tag != "" {
+		// Fixed container names would clash with the containers of the
+		// instance of the backup, so compose generates them instead.
+		if err := b.removeContainerNames(instance.ComposePath()); err != nil {
+			return instanceId, err
+		}
+	}
+	instanceProject, err := instance.ComposeProject()
+	if err != nil {
+		return instanceId, err
+	}
+	if options.Tag != "" {
+		// The new instance must not use the volumes of other instances, like
+		// the instance of the backup, as they would be cleared, overwritten or
+		// removed with the new instance.
+		if err := b.checkSharedResources(instanceId, instanceProject); err != nil {
+			if rmErr := b.dataDir.RemoveInstance(instanceId); rmErr != nil {
+				log.Errorf("Error removing instance %s: %v", instanceId, rmErr)
+			}
+			return "", err
+		}
 	}
 
-	instance, err := b.dataDir.Instance(backup.InstanceId)
+	// Secrets are not included in backups, so the instance gets the secrets of
+	// the instance of the backup, if it is installed, and the ones of the
+	// options, before any volume or container is touched.
+	secrets, err := b.restoreSecrets(instance, backup.InstanceId, options.Secrets)
 	if err != nil {
-		return err
+		return instanceId, err
+	}
+	if err := b.dataDir.Secrets().Set(instanceId, secrets); err != nil {
+		return instanceId, err
+	}
+
+	// Select the volumes to restore
+	volumes := selectVolumes(instanceVolumes(instanceProject), func(v data.BackupVolume) bool {
+		if options.ConfigOnly || !options.Volumes.Match(v) {
+			return false
+		}
+		if !backup.HasVolume(v.Service, v.Target) {
+			log.Warnf("Volume %s of service \"%s\" is not in the backup, skipping it", v.Target, v.Service)
+			return false
+		}
+		return true
+	})
+	if err := options.Volumes.CheckIncluded(volumes); err != nil {
+		log.Warn(err)
+	}
+	// Clear the selected volumes before creating the compose project, which
+	// creates again the removed named volumes
+	if err := b.clearVolumes(instanceProject, volumes); err != nil {
+		return instanceId, err
 	}
 
-	// Create compose project
+	// Create compose project with the secrets of the instance
 	err = b.composeMgr.Create(compose.DockerComposeCreateOptions{
 		Path: instance.ComposePath(),
+		Env:  secrets,
 	})
 	if err != nil {
-		return err
+		return instanceId, err
 	}
 
-	instanceProject, err := instance.ComposeProject()
-	if err != nil {
-		return err
+	// Restore selected volumes of each service
+	if err := b.restoreVolumes(ctx, instanceProject, volumes, read, options.Progress); err != nil {
+		return instanceId, err
 	}
 
-	// Restore volumes of each service
-	for _, service := range instanceProject.Services {
-		err := b.restoreInstanceServiceVolumes(service, backupPath)
+	return instanceId, nil
+}
+
+// restoreSecrets returns the secrets of the restored instance: the secrets of
+// the instance of the backup, if it is installed, and the given secrets, by
+// option name, for its other active secret options. A data.MissingSecretsError
+// is returned if an active secret option of the instance has no value.
+func (b *BackupManager) restoreSecrets(instance *data.Instance, backupInstanceId string, provided map[string]string) (map[string]string, error) {
+	secrets, err := b.dataDir.Secrets().Get(backupInstanceId)
+	if err != nil {
+		return nil, err
+	}
+	ok, err := afero.Exists(b.fs, filepath.Join(filepath.Dir(instance.ComposePath()), "profile.yml"))
+	if err != nil || !ok {
+		// Instances without profile file have no options
+		return secrets, err
+	}
+	p, err := instance.ProfileFile()
+	if err != nil {
+		return nil, err
+	}
+	env, err := instance.Env()
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]string, len(p.Options))
+	var missing []profile.Option
+	for _, o := range p.Options {
+		c, err := o.Condition()
 		if err != nil {
-			return err
+			return nil, err
 		}
+		if o.Type != "secret" {
+			v, ok := env[o.Target]
+			if !ok {
+				v = o.Default
+			}
+			values[o.Name] = v
+			continue
+		}
+		v, ok := secrets[o.Target]
+		if !ok && c.Eval(values) {
+			if v, ok = provided[o.Name]; ok {
+				if secrets == nil {
+					secrets = make(map[string]string)
+				}
+				secrets[o.Target] = v
+			} else {
+				missing = append(missing, o)
+			}
+		}
+		values[o.Name] = v
 	}
-
-	return nil
+	if len(missing) > 0 {
+		return nil, data.MissingSecretsError{Options: missing}
+	}
+	return secrets, nil
 }
 
-func (b *BackupManager) backupInstanceData(instanceId string, backup *data.Backup) error {
-	log.Info("Backing up instance data...")
-	backupPath := b.dataDir.BackupPath(backup.Id())
+// instanceVolumes returns the volumes of the services of the given project.
+func instanceVolumes(project *types.Project) []data.BackupVolume {
+	var volumes []data.BackupVolume
+	for _, service := range project.Services {
+		for _, v := range service.Volumes {
+			volumes = append(volumes, data.BackupVolume{
+				Service: service.Name,
+				Source:  v.Source,
+				Target:  v.Target,
+			})
+		}
+	}
+	return volumes
+}
 
-	instancePath, err := b.dataDir.InstancePath(instanceId)
+// removeContainerNames removes the container_name field of the services of the
+// compose file at the given path, so compose generates the container names
+// from the project name. The rest of the file is kept as is, including its
+// comments.
+func (b *BackupManager) removeContainerNames(composePath string) error {
+	raw, err := afero.ReadFile(b.fs, composePath)
 	if err != nil {
 		return err
 	}
-	backupWriter, err := backuptar.NewBackupWriter(backupPath)
-	if err != nil {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(raw, &doc); err != nil {
 		return err
 	}
-	defer backupWriter.Close()
-	return backupWriter.AddDir(instancePath, filepath.Join("data"))
-}
-
-func (b *BackupManager) backupInstanceServiceVolumes(service types.ServiceConfig, backup *data.Backup) (err error) {
-	if len(service.Volumes) == 0 {
+	removed := false
+	if services := mappingValue(&doc, "services"); services != nil && services.Kind == yaml.MappingNode {
+		for i := 1; i < len(services.Content); i += 2 {
+			service := services.Content[i]
+			if service.Kind != yaml.MappingNode {
+				continue
+			}
+			for j := 0; j < len(service.Content); j += 2 {
+				if service.Content[j].Value == "container_name" {
+					service.Content = append(service.Content[:j], service.Content[j+2:]...)
+					removed = true
+					break
+				}
+			}
+		}
+	}
+	if !removed {
 		return nil
 	}
-	log.Infof("Backing up %d volumes from service \"%s\"...", len(service.Volumes), service.Name)
-	backupPath := b.dataDir.BackupPath(backup.Id())
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return err
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+	return afero.WriteFile(b.fs, composePath, buf.Bytes(), 0o644)
+}
 
-	volumes := make([]string, 0, len(service.Volumes))
-	for _, v := range service.Volumes {
-		volumes = append(volumes, v.Target)
+// mappingValue returns the value of the given key of the mapping node, or of
+// the mapping of the document node, or nil if there is none.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
 	}
-	config := backupConfig{
-		Prefix:  snapshotterConfigPrefix(service.Name),
-		Volumes: volumes,
+	if node.Kind != yaml.MappingNode {
+		return nil
 	}
-	f, err := afero.TempFile(b.fs, os.TempDir(), "eigenlayer-snapshotter-config-*.yml")
-	if err != nil {
-		return err
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
 	}
-	defer f.Close()
-	err = config.Save(f)
+	return nil
+}
+
+// checkSharedResources returns an ErrSharedResources error if the given
+// project of an instance uses resources that are not namespaced to the
+// instance, and may be used by other instances: bind mounts outside the
+// instance directory, and external or explicitly named volumes.
+func (b *BackupManager) checkSharedResources(instanceId string, project *types.Project) error {
+	instancePath, err := b.dataDir.InstancePath(instanceId)
 	if err != nil {
 		return err
 	}
-	err = b.dockerMgr.Run(SnapshotterImage, docker.RunOptions{
-		Args:       []string{"backup"},
-		AutoRemove: true,
-		Mounts: []docker.Mount{
-			{
-				Type:   docker.VolumeTypeBind,
-				Source: f.Name(),
-				Target: "/snapshotter.yml",
-			},
-			{
-				Type:   docker.VolumeTypeBind,
-				Source: backupPath,
-				Target: "/backup.tar",
-			},
-		},
-		VolumesFrom: []string{service.ContainerName},
-	})
-	if err != nil {
-		return fmt.Errorf("snapshotter failed with error: %w", err)
+	var shared []string
+	for _, service := range project.Services {
+		for _, v := range service.Volumes {
+			if v.Source == "" {
+				continue
+			}
+			switch v.Type {
+			case types.VolumeTypeBind:
+				rel, err := filepath.Rel(instancePath, v.Source)
+				if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+					shared = append(shared, fmt.Sprintf("bind mount %s of service \"%s\"", v.Source, service.Name))
+				}
+			case types.VolumeTypeVolume:
+				vc := project.Volumes[v.Source]
+				if vc.External.External {
+					shared = append(shared, fmt.Sprintf("external volume %s of service \"%s\"", volumeName(project, v.Source), service.Name))
+				} else if vc.Name != "" && vc.Name != project.Name+"_"+v.Source {
+					shared = append(shared, fmt.Sprintf("volume %s of service \"%s\"", vc.Name, service.Name))
+				}
+			}
+		}
+	}
+	if len(shared) > 0 {
+		return fmt.Errorf("%w: %s", data.ErrSharedResources, strings.Join(shared, ", "))
 	}
 	return nil
 }
 
-func (b *BackupManager) addTimestamp(backup *data.Backup) error {
-	log.Infof("Adding timestamp %s...", backup.Timestamp.Format(time.DateTime))
-	backupPath := b.dataDir.BackupPath(backup.Id())
+// selectVolumes returns the given volumes for which selected returns true.
+func selectVolumes(volumes []data.BackupVolume, selected func(data.BackupVolume) bool) []data.BackupVolume {
+	var result []data.BackupVolume
+	for _, v := range volumes {
+		if selected(v) {
+			result = append(result, v)
+		}
+	}
+	return result
+}
 
-	timestampTmp, err := afero.TempFile(b.fs, afero.GetTempDir(b.fs, ""), "backup-timestamp-*")
-	if err != nil {
-		return err
+// serviceTargets returns the mount targets of the given volumes of a service.
+func serviceTargets(volumes []data.BackupVolume, service string) []string {
+	var targets []string
+	for _, v := range volumes {
+		if v.Service == service {
+			targets = append(targets, v.Target)
+		}
 	}
-	defer timestampTmp.Close()
-	defer b.fs.Remove(timestampTmp.Name())
+	return targets
+}
+
+// writeBackupTar writes the backup tar of the instance to w: the given volumes
+// of its services, the instance data, the timestamp of the backup and the
+// checksums of the backup files, checked by backup verify.
+func (b *BackupManager) writeBackupTar(ctx context.Context, w io.Writer, instance *data.Instance, project *types.Project, volumes []data.BackupVolume, backup *data.Backup, progress ProgressFunc) error {
+	tw := b.dataDir.NewBackupTarWriter(w)
 
-	_, err = timestampTmp.WriteString(fmt.Sprintf("%d", backup.Timestamp.Unix()))
+	// Add selected volumes of each service
+	err := b.backupInstanceVolumes(ctx, instance.ComposePath(), project, volumes, backup, tw, progress)
 	if err != nil {
 		return err
 	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 
-	backupWriter, err := backuptar.NewBackupWriter(backupPath)
+	// Add instance data
+	log.Info("Backing up instance data...")
+	instancePath, err := b.dataDir.InstancePath(backup.InstanceId)
 	if err != nil {
 		return err
 	}
-	defer backupWriter.Close()
-
-	return backupWriter.AddFile(timestampTmp.Name(), "timestamp")
-}
+	if err := tw.AddDir(instancePath, "data"); err != nil {
+		return err
+	}
 
-func (b *BackupManager) restoreInstanceData(instanceId string, backupPath string) error {
-	return b.dataDir.ReplaceInstanceDirFromTar(instanceId, backupPath, "data")
+	// Add timestamp
+	log.Infof("Adding timestamp %s...", backup.Timestamp.Format(time.DateTime))
+	if err := tw.AddFile("timestamp", []byte(strconv.FormatInt(backup.Timestamp.Unix(), 10))); err != nil {
+		return err
+	}
+	return tw.Close()
 }
 
-func (b *BackupManager) restoreInstanceServiceVolumes(service types.ServiceConfig, backupPath string) error {
-	if len(service.Volumes) == 0 {
+// backupInstanceVolumes backs up the given volumes of the services of the
+// instance. The running services are quiesced during the backup according to
+// the backup consistency mode, and they are always returned to their running
+// state afterwards, even if the backup fails or is canceled. Services are not
+// quiesced if there are no volumes to back up.
+func (b *BackupManager) backupInstanceVolumes(ctx context.Context, composePath string, project *types.Project, volumes []data.BackupVolume, backup *data.Backup, tw tarWriter, progress ProgressFunc) (err error) {
+	if len(volumes) == 0 {
 		return nil
 	}
-	log.Infof("Restoring %d volumes from service \"%s\"...", len(service.Volumes), service.Name)
-
-	volumes := make([]string, 0, len(service.Volumes))
-	for _, v := range service.Volumes {
-		volumes = append(volumes, v.Target)
-	}
-	config := backupConfig{
-		Prefix:  filepath.Join("volumes", service.Name),
-		Volumes: volumes,
-	}
-	f, err := afero.TempFile(b.fs, os.TempDir(), "eigenlayer-snapshotter-config-*.yml")
+	resume, err := b.quiesceServices(composePath, backup.Consistency)
 	if err != nil {
 		return err
 	}
-	defer f.Close()
-	err = config.Save(f)
-	if err != nil {
-		return err
-	}
-	err = b.dockerMgr.Run(SnapshotterImage, docker.RunOptions{
-		Args:       []string{"restore"},
-		AutoRemove: true,
-		Mounts: []docker.Mount{
-			{
-				Type:   docker.VolumeTypeBind,
-				Source: f.Name(),
-				Target: "/snapshotter.yml",
-			},
-			{
-				Type:   docker.VolumeTypeBind,
-				Source: backupPath,
-				Target: "/backup.tar",
-			},
-		},
-		VolumesFrom: []string{service.ContainerName},
-	})
-	if err != nil {
-		return fmt.Errorf("snapshotter failed with error: %w", err)
+	defer func() {
+		err = errors.Join(err, resume())
+	}()
+	for _, service := range project.Services {
+		targets := serviceTargets(volumes, service.Name)
+		if len(targets) == 0 {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		var totals []int64
+		if progress != nil {
+			totals = b.estimateVolumeSizes(project, service, targets)
+		}
+		err := b.backupInstanceServiceVolumes(ctx, project.Name, service, targets, tw, newServiceProgress(progress, service.Name, targets, totals))
+		if err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
-func (b *BackupManager) buildSnapshotterImage() error {
-	ok, err := b.dockerMgr.ImageExist(SnapshotterImage)
+// quiesceServices stops or pauses the running services of the compose project
+// at the given path, depending on the backup consistency mode, and returns a
+// function that starts or unpauses them again. Services that are not running
+// are not changed.
+func (b *BackupManager) quiesceServices(composePath, consistency string) (resume func() error, err error) {
+	psServices, err := b.composeMgr.PS(compose.DockerComposePsOptions{
+		Path:          composePath,
+		Format:        "json",
+		FilterRunning: true,
+	})
 	if err != nil {
-		return err
+		return nil, err
+	}
+	services := make([]string, 0, len(psServices))
+	for _, s := range psServices {
+		services = append(services, s.Service)
+	}
+	if len(services) == 0 {
+		return func() error { return nil }, nil
 	}
-	if !ok {
-		log.Infof("Building snapshotter image \"%s\" from \"%s\" ...", SnapshotterImage, SnapshotterRemoteContext)
-		log.Infof("To learn more about the snapshotter, visit https://%s/tree/%s", SnapshotterRepo, SnapshotterVersion)
-		err = b.dockerMgr.BuildImageFromURI(SnapshotterRemoteContext, SnapshotterImage, nil)
+
+	switch consistency {
+	case data.BackupConsistencyPause:
+		log.Infof("Pausing services %s...", strings.Join(services, ", "))
+		err = b.composeMgr.Pause(compose.DockerComposePauseOptions{Path: composePath, Services: services})
+		if err != nil {
+			// Some services may be paused already
+			return nil, errors.Join(err, b.composeMgr.Unpause(compose.DockerComposeUnpauseOptions{Path: composePath, Services: services}))
+		}
+		return func() error {
+			log.Infof("Unpausing services %s...", strings.Join(services, ", "))
+			return b.composeMgr.Unpause(compose.DockerComposeUnpauseOptions{Path: composePath, Services: services})
+		}, nil
+	case data.BackupConsistencyStop:
+		log.Infof("Stopping services %s...", strings.Join(services, ", "))
+		err = b.composeMgr.Stop(compose.DockerComposeStopOptions{Path: composePath, Services: services})
 		if err != nil {
-			return fmt.Errorf("%w: %s", data.ErrCreatingBackup, err.Error())
+			// Some services may be stopped already
+			return nil, errors.Join(err, b.composeMgr.Start(compose.DockerComposeStartOptions{Path: composePath, Services: services}))
 		}
+		return func() error {
+			log.Infof("Starting services %s...", strings.Join(services, ", "))
+			return b.composeMgr.Start(compose.DockerComposeStartOptions{Path: composePath, Services: services})
+		}, nil
 	}
-	return nil
+	return nil, fmt.Errorf("%w: %s", data.ErrInvalidBackupConsistency, consistency)
 }
 
-func snapshotterConfigPrefix(service string) string {
-	return filepath.Join("volumes", service)
+// backupInstanceServiceVolumes backs up the given volumes of the service of
+// the given compose project to the backup tar, copying them from the container
+// of the service through the Docker API.
+func (b *BackupManager) backupInstanceServiceVolumes(ctx context.Context, project string, service types.ServiceConfig, volumes []string, tw tarWriter, progress *serviceProgress) error {
+	if len(volumes) == 0 {
+		return nil
+	}
+	log.Infof("Backing up %d volumes from service \"%s\"...", len(volumes), service.Name)
+	container, err := b.dockerMgr.ServiceContainerID(project, service.Name)
+	if err != nil {
+		return err
+	}
+	return b.exportServiceVolumes(ctx, container, service, volumes, tw, progress)
 }
@@ -0,0 +1,346 @@
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/NethermindEth/eigenlayer/internal/commands"
+	"github.com/NethermindEth/eigenlayer/internal/compose"
+	"github.com/NethermindEth/eigenlayer/internal/compose/mocks"
+	"github.com/NethermindEth/eigenlayer/internal/data"
+	"github.com/NethermindEth/eigenlayer/internal/docker"
+	dockerMocks "github.com/NethermindEth/eigenlayer/internal/docker/mocks"
+	"github.com/NethermindEth/eigenlayer/internal/locker"
+	dockerTypes "github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/golang/mock/gomock"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuiesceServices(t *testing.T) {
+	const (
+		composePath = "/instance/docker-compose.yml"
+		psCmd       = "docker compose -f " + composePath + " ps --filter status=running --format json"
+		psOut       = `[{"ID":"1","Service":"main-service","State":"running"},{"ID":"2","Service":"option-returner","State":"running"}]`
+	)
+	tests := []struct {
+		name        string
+		consistency string
+		mocker      func(r *mocks.MockCMDRunner)
+		wantErr     bool
+		resumeErr   bool
+	}{
+		{
+			name:        "stop running services",
+			consistency: data.BackupConsistencyStop,
+			mocker: func(r *mocks.MockCMDRunner) {
+				gomock.InOrder(
+					r.EXPECT().RunCMD(commands.Command{Cmd: psCmd, GetOutput: true}).Return(psOut, 0, nil),
+					r.EXPECT().RunCMD(commands.Command{Cmd: "docker compose -f " + composePath + " stop main-service option-returner", GetOutput: true}).Return("", 0, nil),
+					r.EXPECT().RunCMD(commands.Command{Cmd: "docker compose -f " + composePath + " start main-service option-returner", GetOutput: true}).Return("", 0, nil),
+				)
+			},
+		},
+		{
+			name:        "pause running services",
+			consistency: data.BackupConsistencyPause,
+			mocker: func(r *mocks.MockCMDRunner) {
+				gomock.InOrder(
+					r.EXPECT().RunCMD(commands.Command{Cmd: psCmd, GetOutput: true}).Return(psOut, 0, nil),
+					r.EXPECT().RunCMD(commands.Command{Cmd: "docker compose -f " + composePath + " pause main-service option-returner", GetOutput: true}).Return("", 0, nil),
+					r.EXPECT().RunCMD(commands.Command{Cmd: "docker compose -f " + composePath + " unpause main-service option-returner", GetOutput: true}).Return("", 0, nil),
+				)
+			},
+		},
+		{
+			name:        "instance not running",
+			consistency: data.BackupConsistencyStop,
+			mocker: func(r *mocks.MockCMDRunner) {
+				r.EXPECT().RunCMD(commands.Command{Cmd: psCmd, GetOutput: true}).Return("[]", 0, nil)
+			},
+		},
+		{
+			name:        "stop error starts the services again",
+			consistency: data.BackupConsistencyStop,
+			mocker: func(r *mocks.MockCMDRunner) {
+				gomock.InOrder(
+					r.EXPECT().RunCMD(commands.Command{Cmd: psCmd, GetOutput: true}).Return(psOut, 0, nil),
+					r.EXPECT().RunCMD(commands.Command{Cmd: "docker compose -f " + composePath + " stop main-service option-returner", GetOutput: true}).Return("", 1, assert.AnError),
+					r.EXPECT().RunCMD(commands.Command{Cmd: "docker compose -f " + composePath + " start main-service option-returner", GetOutput: true}).Return("", 0, nil),
+				)
+			},
+			wantErr: true,
+		},
+		{
+			name:        "unpause error",
+			consistency: data.BackupConsistencyPause,
+			mocker: func(r *mocks.MockCMDRunner) {
+				gomock.InOrder(
+					r.EXPECT().RunCMD(commands.Command{Cmd: psCmd, GetOutput: true}).Return(psOut, 0, nil),
+					r.EXPECT().RunCMD(commands.Command{Cmd: "docker compose -f " + composePath + " pause main-service option-returner", GetOutput: true}).Return("", 0, nil),
+					r.EXPECT().RunCMD(commands.Command{Cmd: "docker compose -f " + composePath + " unpause main-service option-returner", GetOutput: true}).Return("", 1, assert.AnError),
+				)
+			},
+			resumeErr: true,
+		},
+		{
+			name:        "invalid consistency",
+			consistency: "snapshot",
+			mocker: func(r *mocks.MockCMDRunner) {
+				r.EXPECT().RunCMD(commands.Command{Cmd: psCmd, GetOutput: true}).Return(psOut, 0, nil)
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			runner := mocks.NewMockCMDRunner(ctrl)
+			tt.mocker(runner)
+			b := NewBackupManager(afero.NewMemMapFs(), nil, nil, compose.NewComposeManager(runner))
+
+			resume, err := b.quiesceServices(composePath, tt.consistency)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			err = resume()
+			if tt.resumeErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+// newTestInstance creates the mock-avs-default instance in a new data dir on
+// the OS file system, as the backups are written with the os package.
+func newTestInstance(t *testing.T) (afero.Fs, *data.DataDir) {
+	t.Helper()
+	fs := afero.NewOsFs()
+	dataDirPath := t.TempDir()
+	instancePath := filepath.Join(dataDirPath, "nodes", "mock-avs-default")
+	require.NoError(t, fs.MkdirAll(instancePath, 0o755))
+	require.NoError(t, afero.WriteFile(fs, filepath.Join(instancePath, "state.json"), []byte(`{"name": "mock-avs", "tag": "default", "url": "https://github.com/NethermindEth/mock-avs", "version": "v5.5.0", "profile": "option-returner"}`), 0o644))
+	require.NoError(t, afero.WriteFile(fs, filepath.Join(instancePath, ".env"), nil, 0o644))
+	require.NoError(t, afero.WriteFile(fs, filepath.Join(instancePath, "docker-compose.yml"), []byte("services:\n  main-service:\n    image: mock-avs\n"), 0o644))
+	dataDir, err := data.NewDataDir(dataDirPath, fs, locker.NewFLock())
+	require.NoError(t, err)
+	return fs, dataDir
+}
+
+func TestBackupInstance_Canceled(t *testing.T) {
+	fs, dataDir := newTestInstance(t)
+
+	ctrl := gomock.NewController(t)
+	dockerClient := dockerMocks.NewMockAPIClient(ctrl)
+	b := NewBackupManager(fs, dataDir, docker.NewDockerManager(dockerClient), nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := b.BackupInstance(ctx, "mock-avs-default", BackupOptions{ConfigOnly: true})
+	assert.ErrorIs(t, err, context.Canceled)
+	// The partial backup is removed
+	backups, err := dataDir.BackupList()
+	require.NoError(t, err)
+	assert.Empty(t, backups)
+}
+
+func TestRestoreInstance_Secrets(t *testing.T) {
+	fs, dataDir := newTestInstance(t)
+	secrets := map[string]string{"API_KEY": "secret"}
+	require.NoError(t, dataDir.Secrets().Set("mock-avs-default", secrets))
+
+	ctrl := gomock.NewController(t)
+	runner := mocks.NewMockCMDRunner(ctrl)
+	composePath := filepath.Join(dataDir.Path(), "nodes", "mock-avs-default", "docker-compose.yml")
+	// The secrets are passed to compose, as they are not in the backup
+	runner.EXPECT().RunCMD(commands.Command{Cmd: "docker compose -f " + composePath + " create", GetOutput: true, Env: secrets}).Return("", 0, nil)
+	b := NewBackupManager(fs, dataDir, docker.NewDockerManager(dockerMocks.NewMockAPIClient(ctrl)), compose.NewComposeManager(runner))
+
+	backupId, err := b.BackupInstance(context.Background(), "mock-avs-default", BackupOptions{ConfigOnly: true})
+	require.NoError(t, err)
+	instanceId, err := b.RestoreInstance(context.Background(), backupId, RestoreOptions{ConfigOnly: true})
+	require.NoError(t, err)
+	assert.Equal(t, "mock-avs-default", instanceId)
+}
+
+func TestRestoreInstance_Tagged(t *testing.T) {
+	tests := []struct {
+		name    string
+		compose string
+		wantErr []string
+	}{
+		{
+			name:    "namespaced resources",
+			compose: "services:\n  main-service:\n    image: mock-avs\n    container_name: mock-avs-main\n    volumes:\n      - ./data:/data\n      - cache:/cache\nvolumes:\n  cache:\n",
+		},
+		{
+			name: "shared resources",
+			compose: "services:\n  main-service:\n    image: mock-avs\n    volumes:\n      - ${SHARED_DIR}:/shared\n      - data:/data\n      - external:/external\n" +
+				"volumes:\n  data:\n    name: mock-avs-data\n  external:\n    external: true\n",
+			wantErr: []string{
+				"bind mount SHARED_DIR",
+				"volume mock-avs-data",
+				"external volume external",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs, dataDir := newTestInstance(t)
+			sharedDir := t.TempDir()
+			require.NoError(t, afero.WriteFile(fs, filepath.Join(sharedDir, "key"), []byte("key"), 0o644))
+			instancePath := filepath.Join(dataDir.Path(), "nodes", "mock-avs-default")
+			require.NoError(t, afero.WriteFile(fs, filepath.Join(instancePath, ".env"), []byte("SHARED_DIR="+sharedDir+"\n"), 0o644))
+			require.NoError(t, afero.WriteFile(fs, filepath.Join(instancePath, "docker-compose.yml"), []byte(tt.compose), 0o644))
+			require.NoError(t, fs.MkdirAll(filepath.Join(instancePath, "data"), 0o755))
+			require.NoError(t, afero.WriteFile(fs, filepath.Join(instancePath, "data", "file"), []byte("data"), 0o644))
+
+			ctrl := gomock.NewController(t)
+			runner := mocks.NewMockCMDRunner(ctrl)
+			if tt.wantErr == nil {
+				composePath := filepath.Join(dataDir.Path(), "nodes", "mock-avs-restored", "docker-compose.yml")
+				runner.EXPECT().RunCMD(commands.Command{Cmd: "docker compose -f " + composePath + " create", GetOutput: true}).Return("", 0, nil)
+			}
+			// No Docker calls are expected, the volumes of the original
+			// instance are not touched
+			b := NewBackupManager(fs, dataDir, docker.NewDockerManager(dockerMocks.NewMockAPIClient(ctrl)), compose.NewComposeManager(runner))
+
+			backupId, err := b.BackupInstance(context.Background(), "mock-avs-default", BackupOptions{ConfigOnly: true})
+			require.NoError(t, err)
+			instanceId, err := b.RestoreInstance(context.Background(), backupId, RestoreOptions{Tag: "restored"})
+			if tt.wantErr == nil {
+				require.NoError(t, err)
+				assert.Equal(t, "mock-avs-restored", instanceId)
+				// Compose generates the container names of the new instance
+				composeFile, err := afero.ReadFile(fs, filepath.Join(dataDir.Path(), "nodes", "mock-avs-restored", "docker-compose.yml"))
+				require.NoError(t, err)
+				assert.Equal(t, "services:\n  main-service:\n    image: mock-avs\n    volumes:\n      - ./data:/data\n      - cache:/cache\nvolumes:\n  cache:\n", string(composeFile))
+			} else {
+				assert.ErrorIs(t, err, data.ErrSharedResources)
+				for _, want := range tt.wantErr {
+					assert.ErrorContains(t, err, strings.ReplaceAll(want, "SHARED_DIR", sharedDir))
+				}
+				assert.Empty(t, instanceId)
+				assert.False(t, dataDir.HasInstance("mock-avs-restored"))
+			}
+
+			// The original instance survives the restore
+			assert.True(t, dataDir.HasInstance("mock-avs-default"))
+			content, err := afero.ReadFile(fs, filepath.Join(instancePath, "data", "file"))
+			require.NoError(t, err)
+			assert.Equal(t, "data", string(content))
+			content, err = afero.ReadFile(fs, filepath.Join(sharedDir, "key"))
+			require.NoError(t, err)
+			assert.Equal(t, "key", string(content))
+		})
+	}
+}
+
+func TestRestoreInstance_TaggedVolumes(t *testing.T) {
+	fs, dataDir := newTestInstance(t)
+	instancePath := filepath.Join(dataDir.Path(), "nodes", "mock-avs-default")
+	composeFile := "services:\n  main-service:\n    image: mock-avs\n    container_name: mock-avs-main\n    volumes:\n      - cache:/cache\nvolumes:\n  cache:\n"
+	require.NoError(t, afero.WriteFile(fs, filepath.Join(instancePath, "docker-compose.yml"), []byte(composeFile), 0o644))
+	cacheTar := writeTar(t, []tarEntry{
+		{name: "cache/", typeflag: tar.TypeDir},
+		{name: "cache/a", typeflag: tar.TypeReg, content: "hello"},
+	})
+	containers := func(project string) dockerTypes.ContainerListOptions {
+		return dockerTypes.ContainerListOptions{
+			All: true,
+			Filters: filters.NewArgs(
+				filters.Arg("label", "com.docker.compose.project="+project),
+				filters.Arg("label", "com.docker.compose.service=main-service"),
+			),
+		}
+	}
+
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	runner := mocks.NewMockCMDRunner(ctrl)
+	dockerClient := dockerMocks.NewMockAPIClient(ctrl)
+	composePath := filepath.Join(instancePath, "docker-compose.yml")
+	restoredComposePath := filepath.Join(dataDir.Path(), "nodes", "mock-avs-restored", "docker-compose.yml")
+	var restored []tarEntry
+	gomock.InOrder(
+		// Back up the volume from the container of the instance
+		runner.EXPECT().RunCMD(commands.Command{Cmd: "docker compose -f " + composePath + " ps --filter status=running --format json", GetOutput: true}).Return("[]", 0, nil),
+		dockerClient.EXPECT().ContainerList(gomock.Any(), containers("mock-avs-default")).Return([]dockerTypes.Container{{ID: "default-id"}}, nil),
+		dockerClient.EXPECT().CopyFromContainer(gomock.Any(), "default-id", "/cache").
+			Return(io.NopCloser(bytes.NewReader(cacheTar)), dockerTypes.ContainerPathStat{Name: "cache", Mode: os.ModeDir | 0o755}, nil),
+		// Restore the volume to the container of the new instance
+		dockerClient.EXPECT().VolumeRemove(gomock.Any(), "mock-avs-restored_cache", false).Return(nil),
+		runner.EXPECT().RunCMD(commands.Command{Cmd: "docker compose -f " + restoredComposePath + " create", GetOutput: true}).Return("", 0, nil),
+		dockerClient.EXPECT().ContainerList(gomock.Any(), containers("mock-avs-restored")).Return([]dockerTypes.Container{{ID: "restored-id"}}, nil),
+		dockerClient.EXPECT().CopyToContainer(gomock.Any(), "restored-id", "/", gomock.Any(), dockerTypes.CopyToContainerOptions{}).
+			DoAndReturn(func(_ context.Context, _, _ string, content io.Reader, _ dockerTypes.CopyToContainerOptions) error {
+				restored = readTar(t, content)
+				return nil
+			}),
+	)
+	b := NewBackupManager(fs, dataDir, docker.NewDockerManager(dockerClient), compose.NewComposeManager(runner))
+
+	backupId, err := b.BackupInstance(ctx, "mock-avs-default", BackupOptions{})
+	require.NoError(t, err)
+	instanceId, err := b.RestoreInstance(ctx, backupId, RestoreOptions{Tag: "restored"})
+	require.NoError(t, err)
+	assert.Equal(t, "mock-avs-restored", instanceId)
+	assert.Equal(t, []tarEntry{
+		{name: "cache", typeflag: tar.TypeDir},
+		{name: "cache/a", typeflag: tar.TypeReg, content: "hello"},
+	}, restored)
+}
+
+func TestRestoreInstance_MissingSecrets(t *testing.T) {
+	fs, dataDir := newTestInstance(t)
+	instancePath := filepath.Join(dataDir.Path(), "nodes", "mock-avs-default")
+	profileFile := "options:\n" +
+		"  - name: node-key\n    target: NODE_KEY\n    type: secret\n    help: Node key\n" +
+		"  - name: external-signer\n    target: EXTERNAL_SIGNER\n    type: bool\n    default: \"false\"\n    help: External signer\n" +
+		"  - name: api-key\n    target: API_KEY\n    type: secret\n    help: API key\n" +
+		"  - name: signer-key\n    target: SIGNER_KEY\n    type: secret\n    help: Signer key\n    depends_on: [external-signer]\n" +
+		"monitoring:\n  targets:\n    - service: main-service\n      port: 9090\n      path: /metrics\n"
+	require.NoError(t, afero.WriteFile(fs, filepath.Join(instancePath, "profile.yml"), []byte(profileFile), 0o644))
+	require.NoError(t, afero.WriteFile(fs, filepath.Join(instancePath, ".env"), []byte("EXTERNAL_SIGNER=false\n"), 0o644))
+	require.NoError(t, dataDir.Secrets().Set("mock-avs-default", map[string]string{"NODE_KEY": "node"}))
+	// The API key is not stored, like when it was added by an update and the
+	// backup is restored on another host
+	restoredComposePath := filepath.Join(dataDir.Path(), "nodes", "mock-avs-restored", "docker-compose.yml")
+	wantSecrets := map[string]string{"NODE_KEY": "node", "API_KEY": "key"}
+
+	ctrl := gomock.NewController(t)
+	runner := mocks.NewMockCMDRunner(ctrl)
+	runner.EXPECT().RunCMD(commands.Command{Cmd: "docker compose -f " + restoredComposePath + " create", GetOutput: true, Env: wantSecrets}).Return("", 0, nil)
+	b := NewBackupManager(fs, dataDir, docker.NewDockerManager(dockerMocks.NewMockAPIClient(ctrl)), compose.NewComposeManager(runner))
+	backupId, err := b.BackupInstance(context.Background(), "mock-avs-default", BackupOptions{ConfigOnly: true})
+	require.NoError(t, err)
+
+	// The missing secrets are reported before the compose project is created
+	instanceId, err := b.RestoreInstance(context.Background(), backupId, RestoreOptions{Tag: "restored"})
+	var missingErr data.MissingSecretsError
+	require.ErrorAs(t, err, &missingErr)
+	require.Len(t, missingErr.Options, 1)
+	assert.Equal(t, "api-key", missingErr.Options[0].Name)
+	assert.Equal(t, "mock-avs-restored", instanceId)
+	require.NoError(t, dataDir.RemoveInstance(instanceId))
+
+	// The new instance gets the secrets of the instance of the backup and the
+	// missing ones
+	instanceId, err = b.RestoreInstance(context.Background(), backupId, RestoreOptions{Tag: "restored", Secrets: map[string]string{"api-key": "key"}})
+	require.NoError(t, err)
+	secrets, err := dataDir.Secrets().Get(instanceId)
+	require.NoError(t, err)
+	assert.Equal(t, wantSecrets, secrets)
+}
@@ -0,0 +1,161 @@
+package backup
+
+import (
+	"io"
+	"os"
+	"time"
+
+	"github.com/compose-spec/compose-go/types"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+// progressInterval is the minimum time between the progress events of a
+// volume.
+const progressInterval = time.Second
+
+// Progress is a progress event of the backup or the restore of a volume.
+type Progress struct {
+	// Service is the service of the volume.
+	Service string
+	// Volume is the mount target of the volume in the service.
+	Volume string
+	// Processed is the number of bytes of the volume processed so far.
+	Processed int64
+	// Total is the size of the volume in bytes, or -1 if it is unknown. The
+	// size of the volumes being backed up is estimated before the backup. The
+	// last event of each volume, and only that one, has Processed equal to
+	// Total.
+	Total int64
+}
+
+// ProgressFunc receives the progress events of a backup or a restore. The
+// volumes are reported one after the other, in the order they are processed.
+type ProgressFunc func(Progress)
+
+// serviceProgress reports the progress of the volumes of a service, which are
+// processed one after the other.
+type serviceProgress struct {
+	fn       ProgressFunc
+	service  string
+	volumes  []string
+	totals   []int64
+	interval time.Duration
+	// current is the index of the volume being processed, and processed the
+	// bytes processed of it.
+	current   int
+	processed int64
+	last      time.Time
+}
+
+// newServiceProgress returns a serviceProgress of the given volumes of a
+// service, with their total size in bytes or -1 if it is unknown. A nil totals
+// slice means that the size of all the volumes is unknown.
+func newServiceProgress(fn ProgressFunc, service string, volumes []string, totals []int64) *serviceProgress {
+	if fn == nil {
+		fn = func(Progress) {}
+	}
+	if totals == nil {
+		totals = make([]int64, len(volumes))
+		for i := range totals {
+			totals[i] = -1
+		}
+	}
+	return &serviceProgress{fn: fn, service: service, volumes: volumes, totals: totals, interval: progressInterval}
+}
+
+// start starts the progress of the volume with the given index.
+func (p *serviceProgress) start(volume int) {
+	p.current, p.processed = volume, 0
+	p.report()
+}
+
+// add adds the given number of bytes to the volume being processed. The
+// progress is reported at most once every progress interval.
+func (p *serviceProgress) add(n int64) {
+	p.processed += n
+	if time.Since(p.last) >= p.interval {
+		p.report()
+	}
+}
+
+// done reports the volume being processed as finished.
+func (p *serviceProgress) done() {
+	p.last = time.Now()
+	p.fn(Progress{Service: p.service, Volume: p.volumes[p.current], Processed: p.processed, Total: p.processed})
+}
+
+func (p *serviceProgress) report() {
+	p.last = time.Now()
+	total := p.totals[p.current]
+	if total >= 0 && p.processed >= total {
+		// The size estimated before the backup is too low, and only the last
+		// event of a volume has Processed equal to Total
+		total = -1
+	}
+	p.fn(Progress{Service: p.service, Volume: p.volumes[p.current], Processed: p.processed, Total: total})
+}
+
+// progressReader is a reader that adds the bytes read to the volume being
+// processed by a serviceProgress.
+type progressReader struct {
+	r        io.Reader
+	progress *serviceProgress
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.progress.add(int64(n))
+	return n, err
+}
+
+// estimateVolumeSizes returns the size in bytes of the given volumes of a
+// service, by mount target, or -1 for the volumes whose size is not known. The
+// size of bind mounts is the size of their files, and the size of named
+// volumes is read from Docker.
+func (b *BackupManager) estimateVolumeSizes(project *types.Project, service types.ServiceConfig, volumes []string) []int64 {
+	var dockerSizes map[string]int64
+	sizes := make([]int64, len(volumes))
+	for i, target := range volumes {
+		sizes[i] = -1
+		v, ok := serviceVolume(service, target)
+		if !ok || v.Source == "" {
+			continue
+		}
+		switch v.Type {
+		case types.VolumeTypeBind:
+			sizes[i] = b.dirSize(v.Source)
+		case types.VolumeTypeVolume:
+			if dockerSizes == nil {
+				var err error
+				if dockerSizes, err = b.dockerMgr.VolumeSizes(); err != nil {
+					log.Debugf("Failed to get the size of the volumes: %v", err)
+					dockerSizes = make(map[string]int64)
+				}
+			}
+			if size, ok := dockerSizes[volumeName(project, v.Source)]; ok {
+				sizes[i] = size
+			}
+		}
+	}
+	return sizes
+}
+
+// dirSize returns the size of the files in the given path, or -1 if it can't
+// be read.
+func (b *BackupManager) dirSize(path string) int64 {
+	var size int64
+	err := afero.Walk(b.fs, path, func(_ string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() {
+			size += info.Size()
+		}
+		return nil
+	})
+	if err != nil {
+		return -1
+	}
+	return size
+}
@@ -0,0 +1,92 @@
+package backup
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/NethermindEth/eigenlayer/internal/docker"
+	"github.com/NethermindEth/eigenlayer/internal/docker/mocks"
+	"github.com/compose-spec/compose-go/types"
+	dockerTypes "github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/volume"
+	"github.com/golang/mock/gomock"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServiceProgress(t *testing.T) {
+	var events []Progress
+	p := newServiceProgress(func(e Progress) { events = append(events, e) }, "main-service", []string{"/data", "/tmp"}, []int64{100, -1})
+	p.interval = 0
+
+	p.start(0)
+	p.add(40)
+	// The estimated size is too low
+	p.add(80)
+	p.add(30)
+	p.done()
+	p.start(1)
+	p.add(10)
+	p.add(20)
+	p.done()
+
+	assert.Equal(t, []Progress{
+		{Service: "main-service", Volume: "/data", Processed: 0, Total: 100},
+		{Service: "main-service", Volume: "/data", Processed: 40, Total: 100},
+		{Service: "main-service", Volume: "/data", Processed: 120, Total: -1},
+		{Service: "main-service", Volume: "/data", Processed: 150, Total: -1},
+		{Service: "main-service", Volume: "/data", Processed: 150, Total: 150},
+		{Service: "main-service", Volume: "/tmp", Processed: 0, Total: -1},
+		{Service: "main-service", Volume: "/tmp", Processed: 10, Total: -1},
+		{Service: "main-service", Volume: "/tmp", Processed: 30, Total: -1},
+		{Service: "main-service", Volume: "/tmp", Processed: 30, Total: 30},
+	}, events)
+}
+
+func TestServiceProgress_Interval(t *testing.T) {
+	var events []Progress
+	p := newServiceProgress(func(e Progress) { events = append(events, e) }, "main-service", []string{"/data"}, nil)
+	p.interval = time.Hour
+
+	p.start(0)
+	_, err := io.Copy(io.Discard, &progressReader{r: bytes.NewReader(make([]byte, 2048)), progress: p})
+	require.NoError(t, err)
+	p.done()
+
+	// Only the first and the last events are reported
+	assert.Equal(t, []Progress{
+		{Service: "main-service", Volume: "/data", Processed: 0, Total: -1},
+		{Service: "main-service", Volume: "/data", Processed: 2048, Total: 2048},
+	}, events)
+}
+
+func TestEstimateVolumeSizes(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/mock-avs/config/a.yml", make([]byte, 100), 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/mock-avs/config/sub/b.yml", make([]byte, 20), 0o644))
+
+	ctrl := gomock.NewController(t)
+	dockerClient := mocks.NewMockAPIClient(ctrl)
+	dockerClient.EXPECT().DiskUsage(gomock.Any(), gomock.Any()).Return(dockerTypes.DiskUsage{
+		Volumes: []*volume.Volume{{Name: "mock-avs_data", UsageData: &volume.UsageData{Size: 2048}}},
+	}, nil)
+
+	b := NewBackupManager(fs, nil, docker.NewDockerManager(dockerClient), nil)
+	project := &types.Project{
+		Volumes: types.Volumes{"data": types.VolumeConfig{Name: "mock-avs_data"}},
+	}
+	service := types.ServiceConfig{
+		Name: "main-service",
+		Volumes: []types.ServiceVolumeConfig{
+			{Type: types.VolumeTypeVolume, Source: "data", Target: "/data"},
+			{Type: types.VolumeTypeBind, Source: "/mock-avs/config", Target: "/config"},
+			{Type: types.VolumeTypeBind, Source: "/not-found", Target: "/not-found"},
+			{Type: types.VolumeTypeVolume, Target: "/anonymous"},
+		},
+	}
+	sizes := b.estimateVolumeSizes(project, service, []string{"/data", "/config", "/not-found", "/anonymous"})
+	assert.Equal(t, []int64{2048, 120, -1, -1}, sizes)
+}
@@ -0,0 +1,107 @@
+package backup
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/NethermindEth/eigenlayer/internal/data"
+	"github.com/NethermindEth/eigenlayer/internal/storage"
+	log "github.com/sirupsen/logrus"
+)
+
+// PushBackup copies the local backup with the given id to the backup store at
+// the given location. See storage.Open for the supported locations.
+func (b *BackupManager) PushBackup(backupId, location string) error {
+	store, err := storage.Open(location)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+	log.Infof("Pushing backup %s to %s...", backupId, location)
+	return copyBackup(b.dataDir.BackupStore(), store, backupId)
+}
+
+// PullBackup copies the backup with the given id from the backup store at the
+// given location to the local backups. See storage.Open for the supported
+// locations.
+func (b *BackupManager) PullBackup(backupId, location string) error {
+	store, err := storage.Open(location)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+	log.Infof("Pulling backup %s from %s...", backupId, location)
+	return copyBackup(store, b.dataDir.BackupStore(), backupId)
+}
+
+// copyBackup copies the backup with the given id from the src store to the dst
+// store. Only the chunks missing in dst are copied, and the manifest is copied
+// last, so an interrupted copy never leaves a backup with missing chunks. The
+// chunks are copied as they are, and their hashes are checked on restore.
+func copyBackup(src, dst storage.BackupStore, backupId string) error {
+	manifestKey := data.BackupManifestKey(backupId)
+	ok, err := src.Exists(manifestKey)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		// Backup stored as a single tar file
+		tarKey := data.BackupTarKey(backupId)
+		ok, err := src.Exists(tarKey)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("%w: %s", data.ErrBackupNotFound, backupId)
+		}
+		return copyFile(src, dst, tarKey)
+	}
+
+	manifest, err := readFile(src, manifestKey)
+	if err != nil {
+		return err
+	}
+	backup, err := data.ParseBackupManifest(manifest)
+	if err != nil {
+		return err
+	}
+	if backup.Id() != backupId {
+		return fmt.Errorf("%w: backup id %s does not match %s", data.ErrInvalidBackupManifest, backup.Id(), backupId)
+	}
+	var copied int
+	for _, chunk := range backup.Chunks {
+		key := backup.ChunkKey(chunk)
+		ok, err := dst.Exists(key)
+		if err != nil {
+			return err
+		}
+		if ok {
+			continue
+		}
+		if err := copyFile(src, dst, key); err != nil {
+			return err
+		}
+		copied++
+	}
+	log.Infof("Copied %d of %d backup chunks", copied, len(backup.Chunks))
+	return dst.Put(manifestKey, bytes.NewReader(manifest))
+}
+
+func copyFile(src, dst storage.BackupStore, key string) error {
+	r, err := src.Get(key)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	return dst.Put(key, r)
+}
+
+func readFile(s storage.BackupStore, key string) ([]byte, error) {
+	r, err := s.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
@@ -0,0 +1,127 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"math/rand"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/NethermindEth/eigenlayer/internal/data"
+	"github.com/NethermindEth/eigenlayer/internal/storage"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestBackup creates a chunked backup in the given data dir, with random
+// data as the content of its tar file, and returns the backup and its content.
+func newTestBackup(t *testing.T, dataDir *data.DataDir, timestamp int64, seed int64) (*data.Backup, []byte) {
+	t.Helper()
+	b := &data.Backup{
+		InstanceId: "mock-avs-default",
+		Timestamp:  time.Unix(timestamp, 0),
+		Version:    "v5.5.0",
+	}
+	require.NoError(t, dataDir.InitBackup(b))
+	content := make([]byte, 4*1024*1024)
+	rand.New(rand.NewSource(seed)).Read(content)
+	require.NoError(t, dataDir.PackBackup(context.Background(), b, bytes.NewReader(content), data.PackOptions{Compression: data.CompressionZstd}))
+	return b, content
+}
+
+func newTestDataDir(t *testing.T) *data.DataDir {
+	t.Helper()
+	dataDir, err := data.NewDataDir(t.TempDir(), afero.NewOsFs(), nil)
+	require.NoError(t, err)
+	return dataDir
+}
+
+func TestPushPullBackup(t *testing.T) {
+	local := newTestDataDir(t)
+	backup, content := newTestBackup(t, local, 1696420902, 1)
+	storeDir := filepath.Join(t.TempDir(), "store")
+
+	// Push to the store
+	err := NewBackupManager(afero.NewOsFs(), local, nil, nil).PushBackup(backup.Id(), storeDir)
+	require.NoError(t, err)
+	store := storage.NewLocalStore(afero.NewOsFs(), storeDir)
+	keys, err := store.List("")
+	require.NoError(t, err)
+	assert.Len(t, keys, len(backup.Chunks)+1)
+	assert.Contains(t, keys, data.BackupManifestKey(backup.Id()))
+
+	// Pull to another data dir
+	other := newTestDataDir(t)
+	err = NewBackupManager(afero.NewOsFs(), other, nil, nil).PullBackup(backup.Id(), storeDir)
+	require.NoError(t, err)
+	pulled, err := other.Backup(backup.Id())
+	require.NoError(t, err)
+	assert.Equal(t, backup, pulled)
+	r, err := other.OpenBackup(context.Background(), backup.Id(), "")
+	require.NoError(t, err)
+	defer r.Close()
+	got, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, content, got)
+}
+
+func TestCopyBackup(t *testing.T) {
+	t.Run("only missing chunks are copied", func(t *testing.T) {
+		local := newTestDataDir(t)
+		first, _ := newTestBackup(t, local, 1696420902, 1)
+		second, _ := newTestBackup(t, local, 1696507302, 1)
+		dst := storage.NewLocalStore(afero.NewOsFs(), t.TempDir())
+		require.NoError(t, copyBackup(local.BackupStore(), dst, first.Id()))
+		keys, err := dst.List("")
+		require.NoError(t, err)
+
+		// Remove a chunk of the source, the copy does not need it
+		require.NoError(t, local.BackupStore().Delete(first.ChunkKey(first.Chunks[0])))
+		require.NoError(t, copyBackup(local.BackupStore(), dst, second.Id()))
+		secondKeys, err := dst.List("")
+		require.NoError(t, err)
+		assert.Len(t, secondKeys, len(keys)+1)
+	})
+	t.Run("single tar backup", func(t *testing.T) {
+		src := storage.NewLocalStore(afero.NewOsFs(), t.TempDir())
+		dst := storage.NewLocalStore(afero.NewOsFs(), t.TempDir())
+		require.NoError(t, src.Put(data.BackupTarKey("backup-id"), bytes.NewReader([]byte("tar"))))
+		require.NoError(t, copyBackup(src, dst, "backup-id"))
+		ok, err := dst.Exists(data.BackupTarKey("backup-id"))
+		require.NoError(t, err)
+		assert.True(t, ok)
+	})
+	t.Run("backup not found", func(t *testing.T) {
+		src := storage.NewLocalStore(afero.NewOsFs(), t.TempDir())
+		dst := storage.NewLocalStore(afero.NewOsFs(), t.TempDir())
+		err := copyBackup(src, dst, "backup-id")
+		assert.ErrorIs(t, err, data.ErrBackupNotFound)
+	})
+	t.Run("missing chunk", func(t *testing.T) {
+		local := newTestDataDir(t)
+		backup, _ := newTestBackup(t, local, 1696420902, 1)
+		require.NoError(t, local.BackupStore().Delete(backup.ChunkKey(backup.Chunks[0])))
+		dst := storage.NewLocalStore(afero.NewOsFs(), t.TempDir())
+		err := copyBackup(local.BackupStore(), dst, backup.Id())
+		assert.ErrorIs(t, err, storage.ErrFileNotFound)
+		// The manifest is not copied
+		ok, err := dst.Exists(data.BackupManifestKey(backup.Id()))
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+	t.Run("manifest of another backup", func(t *testing.T) {
+		local := newTestDataDir(t)
+		backup, _ := newTestBackup(t, local, 1696420902, 1)
+		src := local.BackupStore()
+		r, err := src.Get(data.BackupManifestKey(backup.Id()))
+		require.NoError(t, err)
+		require.NoError(t, src.Put(data.BackupManifestKey("backup-id"), r))
+		r.Close()
+		dst := storage.NewLocalStore(afero.NewOsFs(), t.TempDir())
+		err = copyBackup(src, dst, "backup-id")
+		assert.ErrorIs(t, err, data.ErrInvalidBackupManifest)
+	})
+}
@@ -1,6 +0,0 @@
-package testdata
-
-import "embed"
-
-//go:embed all:*
-var TestData embed.FS
@@ -0,0 +1,526 @@
+package backup
+
+import (
+	"archive/tar"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/NethermindEth/eigenlayer/internal/data"
+	"github.com/compose-spec/compose-go/types"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// volumesDataFile is the file with the volumes of a service in the backup
+	// tar, in the directory of the volumes of the service.
+	volumesDataFile = "volumes-data.yml"
+
+	volumeTypeDir  = "dir"
+	volumeTypeFile = "file"
+)
+
+// volumeData is a volume of a service in the backup tar. The content of the
+// volume is stored in the directory of the volumes of the service, under its
+// id.
+type volumeData struct {
+	Id     string `yaml:"id"`
+	Type   string `yaml:"type"`
+	Target string `yaml:"target"`
+}
+
+// volumeId returns the id of the volume with the given mount target in the
+// backup tar.
+func volumeId(target string) string {
+	hash := sha256.Sum256([]byte(target))
+	return hex.EncodeToString(hash[:])
+}
+
+// volumesPrefix returns the directory of the volumes of the given service in
+// the backup tar.
+func volumesPrefix(service string) string {
+	return path.Join("volumes", service)
+}
+
+// serviceVolume returns the volume of the service with the given mount target.
+func serviceVolume(service types.ServiceConfig, target string) (types.ServiceVolumeConfig, bool) {
+	for _, v := range service.Volumes {
+		if v.Target == target {
+			return v, true
+		}
+	}
+	return types.ServiceVolumeConfig{}, false
+}
+
+// volumeName returns the name of the Docker volume of the named volume of the
+// project with the given source.
+func volumeName(project *types.Project, source string) string {
+	if vc, ok := project.Volumes[source]; ok && vc.Name != "" {
+		return vc.Name
+	}
+	return source
+}
+
+// tarWriter writes the entries of a tar, like tar.Writer.
+type tarWriter interface {
+	io.Writer
+	WriteHeader(h *tar.Header) error
+}
+
+// exportServiceVolumes writes the given volumes of the service to the backup
+// tar, copying them from the given container of the service through the Docker
+// API, and the volumes data of the service.
+func (b *BackupManager) exportServiceVolumes(ctx context.Context, container string, service types.ServiceConfig, volumes []string, tw tarWriter, progress *serviceProgress) error {
+	prefix := volumesPrefix(service.Name)
+	volumesData := make([]volumeData, 0, len(volumes))
+	for i, target := range volumes {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		v := volumeData{Id: volumeId(target), Type: volumeTypeFile, Target: target}
+		progress.start(i)
+		content, stat, err := b.dockerMgr.CopyFromContainer(ctx, container, target)
+		if err != nil {
+			return err
+		}
+		if stat.Mode.IsDir() {
+			v.Type = volumeTypeDir
+		}
+		err = copyTarEntries(tw, tar.NewReader(content), progress, func(name string) (string, bool) {
+			rel, ok := cutTarPath(name, path.Base(target))
+			return path.Join(prefix, v.Id, rel), ok
+		})
+		content.Close()
+		if err != nil {
+			return fmt.Errorf("error backing up volume %s of service \"%s\": %w", target, service.Name, err)
+		}
+		progress.done()
+		volumesData = append(volumesData, v)
+	}
+
+	raw, err := yaml.Marshal(volumesData)
+	if err != nil {
+		return err
+	}
+	err = tw.WriteHeader(&tar.Header{
+		Name:     path.Join(prefix, volumesDataFile),
+		Typeflag: tar.TypeReg,
+		Mode:     0o644,
+		Size:     int64(len(raw)),
+		ModTime:  time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = tw.Write(raw)
+	return err
+}
+
+// volumeRestore is a volume being restored from the backup tar.
+type volumeRestore struct {
+	data      volumeData
+	service   types.ServiceConfig
+	container string
+	// progress is the progress of the volumes of the service, and index the
+	// index of the volume in it.
+	progress *serviceProgress
+	index    int
+	restored bool
+}
+
+// restoreVolumes restores the given volumes of the services of the project from
+// the backup tar, copying them to the containers of the services through the
+// Docker API. File volumes bound to a host file are written to the host file,
+// as they can't be replaced through the container. The backup tar is read with
+// read twice: to find the volumes in the backup, and to restore all of them.
+func (b *BackupManager) restoreVolumes(ctx context.Context, project *types.Project, volumes []data.BackupVolume, read func(func(io.Reader) error) error, progressFn ProgressFunc) error {
+	if len(volumes) == 0 {
+		return nil
+	}
+	var (
+		volumesData map[string]volumeData
+		sizes       map[string]int64
+	)
+	err := read(func(r io.Reader) (err error) {
+		volumesData, sizes, err = readVolumesData(r)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	restores := make(map[string]*volumeRestore)
+	for _, service := range project.Services {
+		targets := serviceTargets(volumes, service.Name)
+		if len(targets) == 0 {
+			continue
+		}
+		var (
+			dirs   []string
+			totals []int64
+		)
+		targets = slices.DeleteFunc(targets, func(target string) bool {
+			dir := path.Join(volumesPrefix(service.Name), volumeId(target))
+			if _, ok := volumesData[dir]; !ok {
+				log.Warnf("Volume %s of service \"%s\" is not in the backup, skipping it", target, service.Name)
+				return true
+			}
+			dirs, totals = append(dirs, dir), append(totals, sizes[dir])
+			return false
+		})
+		if len(targets) == 0 {
+			continue
+		}
+		log.Infof("Restoring %d volumes from service \"%s\"...", len(targets), service.Name)
+		container, err := b.dockerMgr.ServiceContainerID(project.Name, service.Name)
+		if err != nil {
+			return err
+		}
+		progress := newServiceProgress(progressFn, service.Name, targets, totals)
+		for i, dir := range dirs {
+			restores[dir] = &volumeRestore{
+				data:      volumesData[dir],
+				service:   service,
+				container: container,
+				progress:  progress,
+				index:     i,
+			}
+		}
+	}
+	if len(restores) == 0 {
+		return nil
+	}
+
+	err = read(func(r io.Reader) error {
+		return b.importVolumes(ctx, tar.NewReader(r), restores)
+	})
+	if err != nil {
+		return err
+	}
+	for _, v := range restores {
+		if !v.restored {
+			return fmt.Errorf("volume %s of service \"%s\" not found in the backup", v.data.Target, v.service.Name)
+		}
+	}
+	return nil
+}
+
+// importVolumes restores the given volumes, by directory in the backup tar,
+// from the entries of the tar. The entries of each volume are consecutive in
+// the tar, so the volumes are restored one after the other.
+func (b *BackupManager) importVolumes(ctx context.Context, tr *tar.Reader, restores map[string]*volumeRestore) (err error) {
+	var (
+		current *volumeRestore
+		sink    volumeSink
+	)
+	// finish finishes the restore of the current volume, if any
+	finish := func(err error) error {
+		if sink == nil {
+			return err
+		}
+		err = sink.close(err)
+		if err != nil {
+			err = fmt.Errorf("error restoring volume %s of service \"%s\": %w", current.data.Target, current.service.Name, err)
+		} else {
+			current.progress.done()
+			current.restored = true
+		}
+		current, sink = nil, nil
+		return err
+	}
+	defer func() {
+		err = finish(err)
+	}()
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		v := restores[volumeDir(h.Name)]
+		if v != current {
+			if err := finish(nil); err != nil {
+				return err
+			}
+		}
+		if v == nil {
+			continue
+		}
+		if current == nil {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			v.progress.start(v.index)
+			if sink, err = b.newVolumeSink(ctx, v); err != nil {
+				return err
+			}
+			current = v
+		}
+		if err := sink.add(h, tr); err != nil {
+			return err
+		}
+	}
+}
+
+// volumeDir returns the directory of the volume of the backup tar entry with
+// the given name, volumes/<service>/<id>, or an empty string if the entry is
+// not in the directory of a volume.
+func volumeDir(name string) string {
+	parts := strings.SplitN(strings.TrimSuffix(name, "/"), "/", 4)
+	if len(parts) < 3 || parts[0] != "volumes" {
+		return ""
+	}
+	return path.Join(parts[:3]...)
+}
+
+// volumeSink restores the entries of the backup tar of a volume.
+type volumeSink interface {
+	// add restores the entry with the given header and content.
+	add(h *tar.Header, r io.Reader) error
+	// close finishes the restore, which is aborted if err is not nil, and
+	// returns the error of the restore.
+	close(err error) error
+}
+
+// newVolumeSink returns the sink of the entries of the given volume. File
+// volumes bound to a host file are written to the host file, and the rest of
+// the volumes are copied to the container.
+func (b *BackupManager) newVolumeSink(ctx context.Context, v *volumeRestore) (volumeSink, error) {
+	src := path.Join(volumesPrefix(v.service.Name), v.data.Id)
+	switch v.data.Type {
+	case volumeTypeDir:
+		return b.newContainerSink(ctx, v.container, path.Dir(v.data.Target), v.progress, func(name string) (string, bool) {
+			rel, ok := cutTarPath(name, src)
+			return path.Join(path.Base(v.data.Target), rel), ok
+		}), nil
+	case volumeTypeFile:
+		sv, ok := serviceVolume(v.service, v.data.Target)
+		if ok && sv.Type == types.VolumeTypeBind {
+			return &hostFileSink{fs: b.fs, src: src, dst: sv.Source, progress: v.progress}, nil
+		}
+		return b.newContainerSink(ctx, v.container, path.Dir(v.data.Target), v.progress, func(name string) (string, bool) {
+			return path.Base(v.data.Target), name == src
+		}), nil
+	}
+	return nil, fmt.Errorf("unknown type %s", v.data.Type)
+}
+
+// containerSink copies the entries selected by rename to the given directory
+// of a container, with the name returned by rename.
+type containerSink struct {
+	tw       *tar.Writer
+	pw       *io.PipeWriter
+	progress *serviceProgress
+	rename   func(string) (string, bool)
+	// copied receives the error of the copy to the container.
+	copied chan error
+}
+
+func (b *BackupManager) newContainerSink(ctx context.Context, container, dstPath string, progress *serviceProgress, rename func(string) (string, bool)) *containerSink {
+	pr, pw := io.Pipe()
+	s := &containerSink{tw: tar.NewWriter(pw), pw: pw, progress: progress, rename: rename, copied: make(chan error, 1)}
+	go func() {
+		err := b.dockerMgr.CopyToContainer(ctx, container, dstPath, pr)
+		// Stop the copy of the entries if the container did not read all of them
+		pr.CloseWithError(err)
+		s.copied <- err
+	}()
+	return s
+}
+
+func (s *containerSink) add(h *tar.Header, r io.Reader) error {
+	return copyTarEntry(s.tw, h, r, s.progress, s.rename)
+}
+
+func (s *containerSink) close(err error) error {
+	if err == nil {
+		err = s.tw.Close()
+	}
+	s.pw.CloseWithError(err)
+	if copyErr := <-s.copied; copyErr != nil {
+		return copyErr
+	}
+	return err
+}
+
+// hostFileSink writes the entry of a file volume to the host file bound to the
+// volume.
+type hostFileSink struct {
+	fs       afero.Fs
+	src, dst string
+	progress *serviceProgress
+	written  bool
+}
+
+func (s *hostFileSink) add(h *tar.Header, r io.Reader) error {
+	if h.Name != s.src {
+		return nil
+	}
+	dst, err := s.fs.OpenFile(s.dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, h.FileInfo().Mode().Perm())
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(dst, &progressReader{r: r, progress: s.progress})
+	s.written = true
+	return errors.Join(err, dst.Close())
+}
+
+func (s *hostFileSink) close(err error) error {
+	if err == nil && !s.written {
+		return fmt.Errorf("file %s not found in the backup", s.src)
+	}
+	return err
+}
+
+// copyTarEntries copies the entries of the tar reader selected by rename to the
+// tar writer, with the name returned by rename. The content of the regular
+// files is added to the progress of the volume being processed.
+func copyTarEntries(tw tarWriter, tr *tar.Reader, progress *serviceProgress, rename func(string) (string, bool)) error {
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := copyTarEntry(tw, h, tr, progress, rename); err != nil {
+			return err
+		}
+	}
+}
+
+// copyTarEntry copies the entry with the given header and content to the tar
+// writer, with the name returned by rename, if it is selected by rename.
+func copyTarEntry(tw tarWriter, h *tar.Header, r io.Reader, progress *serviceProgress, rename func(string) (string, bool)) error {
+	name, ok := rename(h.Name)
+	if !ok {
+		return nil
+	}
+	h.Name = name
+	if h.Typeflag == tar.TypeLink {
+		if h.Linkname, ok = rename(h.Linkname); !ok {
+			return fmt.Errorf("hard link %s to %s out of the volume", h.Name, h.Linkname)
+		}
+	}
+	// The format of the source may not encode the new name
+	h.Format = tar.FormatUnknown
+	if err := tw.WriteHeader(h); err != nil {
+		return err
+	}
+	if h.Typeflag == tar.TypeReg {
+		if _, err := io.Copy(tw, &progressReader{r: r, progress: progress}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cutTarPath returns the path of the tar entry with the given name relative to
+// dir, and whether the entry is dir or is in dir. Directory entries may have a
+// trailing slash.
+func cutTarPath(name, dir string) (string, bool) {
+	name = strings.TrimSuffix(name, "/")
+	if name == dir {
+		return "", true
+	}
+	rel, ok := strings.CutPrefix(name, dir+"/")
+	return rel, ok
+}
+
+// readVolumesData returns the volumes of the services in the backup tar read
+// from r, by directory in the tar, volumes/<service>/<id>, and the size of the
+// files of each volume, by directory.
+func readVolumesData(r io.Reader) (map[string]volumeData, map[string]int64, error) {
+	volumesData := make(map[string]volumeData)
+	sizes := make(map[string]int64)
+	tr := tar.NewReader(r)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return volumesData, sizes, nil
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		rel, ok := cutTarPath(h.Name, "volumes")
+		if !ok || rel == "" {
+			continue
+		}
+		if service, file, _ := strings.Cut(rel, "/"); file == volumesDataFile {
+			raw, err := io.ReadAll(tr)
+			if err != nil {
+				return nil, nil, err
+			}
+			var serviceVolumes []volumeData
+			if err := yaml.Unmarshal(raw, &serviceVolumes); err != nil {
+				return nil, nil, fmt.Errorf("invalid %s: %w", h.Name, err)
+			}
+			for _, v := range serviceVolumes {
+				volumesData[path.Join(volumesPrefix(service), v.Id)] = v
+			}
+			continue
+		}
+		if h.Typeflag == tar.TypeReg {
+			sizes[volumeDir(h.Name)] += h.Size
+		}
+	}
+}
+
+// clearVolumes removes the content of the given volumes of the project, before
+// they are restored. Named volumes are removed, to be created again with the
+// project, and the content of bind mounted directories is removed. External
+// volumes are not changed.
+func (b *BackupManager) clearVolumes(project *types.Project, volumes []data.BackupVolume) error {
+	for _, bv := range volumes {
+		service, err := project.GetService(bv.Service)
+		if err != nil {
+			return err
+		}
+		v, ok := serviceVolume(service, bv.Target)
+		if !ok || v.Source == "" {
+			continue
+		}
+		switch v.Type {
+		case types.VolumeTypeVolume:
+			if project.Volumes[v.Source].External.External {
+				log.Warnf("Volume %s of service \"%s\" is external, restoring it over its current content", bv.Target, bv.Service)
+				continue
+			}
+			if err := b.dockerMgr.VolumeRemove(volumeName(project, v.Source)); err != nil {
+				return err
+			}
+		case types.VolumeTypeBind:
+			info, err := b.fs.Stat(v.Source)
+			if errors.Is(err, os.ErrNotExist) || (err == nil && !info.IsDir()) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			entries, err := afero.ReadDir(b.fs, v.Source)
+			if err != nil {
+				return err
+			}
+			for _, e := range entries {
+				if err := b.fs.RemoveAll(filepath.Join(v.Source, e.Name())); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,268 @@
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+
+	"github.com/NethermindEth/eigenlayer/internal/data"
+	"github.com/NethermindEth/eigenlayer/internal/docker"
+	"github.com/NethermindEth/eigenlayer/internal/docker/mocks"
+	"github.com/compose-spec/compose-go/types"
+	dockerTypes "github.com/docker/docker/api/types"
+	"github.com/golang/mock/gomock"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type tarEntry struct {
+	name     string
+	typeflag byte
+	content  string
+	linkname string
+}
+
+func writeTar(t *testing.T, entries []tarEntry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, e := range entries {
+		h := &tar.Header{Name: e.name, Typeflag: e.typeflag, Linkname: e.linkname, Mode: 0o644, Size: int64(len(e.content))}
+		if e.typeflag == tar.TypeDir {
+			h.Mode = 0o755
+		}
+		require.NoError(t, tw.WriteHeader(h))
+		_, err := tw.Write([]byte(e.content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	return buf.Bytes()
+}
+
+func readTar(t *testing.T, r io.Reader) []tarEntry {
+	t.Helper()
+	var entries []tarEntry
+	tr := tar.NewReader(r)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return entries
+		}
+		require.NoError(t, err)
+		content, err := io.ReadAll(tr)
+		require.NoError(t, err)
+		entries = append(entries, tarEntry{name: h.Name, typeflag: h.Typeflag, content: string(content), linkname: h.Linkname})
+	}
+}
+
+// readBytes returns a function to read the backup tar with the given content,
+// like the one passed to restoreVolumes.
+func readBytes(content []byte) func(func(io.Reader) error) error {
+	return func(fn func(io.Reader) error) error {
+		return fn(bytes.NewReader(content))
+	}
+}
+
+func TestExportRestoreServiceVolumes(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yml")
+	require.NoError(t, os.WriteFile(configPath, []byte("old"), 0o644))
+
+	service := types.ServiceConfig{
+		Name: "main-service",
+		Volumes: []types.ServiceVolumeConfig{
+			{Type: types.VolumeTypeVolume, Source: "data", Target: "/data"},
+			{Type: types.VolumeTypeBind, Source: configPath, Target: "/etc/config.yml"},
+		},
+	}
+	volumes := []string{"/data", "/etc/config.yml"}
+	dataTar := writeTar(t, []tarEntry{
+		{name: "data/", typeflag: tar.TypeDir},
+		{name: "data/a", typeflag: tar.TypeReg, content: "hello"},
+		{name: "data/sub/", typeflag: tar.TypeDir},
+		{name: "data/sub/b", typeflag: tar.TypeReg, content: "world!"},
+		{name: "data/c", typeflag: tar.TypeLink, linkname: "data/a"},
+	})
+	configTar := writeTar(t, []tarEntry{
+		{name: "config.yml", typeflag: tar.TypeReg, content: "new"},
+	})
+
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	dockerClient := mocks.NewMockAPIClient(ctrl)
+	gomock.InOrder(
+		dockerClient.EXPECT().CopyFromContainer(ctx, "mock-avs-main-service", "/data").
+			Return(io.NopCloser(bytes.NewReader(dataTar)), dockerTypes.ContainerPathStat{Name: "data", Mode: os.ModeDir | 0o755}, nil),
+		dockerClient.EXPECT().CopyFromContainer(ctx, "mock-avs-main-service", "/etc/config.yml").
+			Return(io.NopCloser(bytes.NewReader(configTar)), dockerTypes.ContainerPathStat{Name: "config.yml", Mode: 0o644}, nil),
+	)
+	dockerClient.EXPECT().ContainerList(gomock.Any(), gomock.Any()).Return([]dockerTypes.Container{{ID: "mock-avs-main-service"}}, nil)
+	var restored []tarEntry
+	dockerClient.EXPECT().CopyToContainer(ctx, "mock-avs-main-service", "/", gomock.Any(), dockerTypes.CopyToContainerOptions{}).
+		DoAndReturn(func(_ context.Context, _, _ string, content io.Reader, _ dockerTypes.CopyToContainerOptions) error {
+			restored = readTar(t, content)
+			return nil
+		})
+	b := NewBackupManager(afero.NewOsFs(), nil, docker.NewDockerManager(dockerClient), nil)
+
+	// Back up the volumes
+	var events []Progress
+	progress := newServiceProgress(func(e Progress) { events = append(events, e) }, service.Name, volumes, nil)
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	require.NoError(t, b.exportServiceVolumes(ctx, "mock-avs-main-service", service, volumes, tw, progress))
+	require.NoError(t, tw.Close())
+	backupTar := buf.Bytes()
+
+	dataId, configId := path.Join("volumes/main-service", volumeId("/data")), path.Join("volumes/main-service", volumeId("/etc/config.yml"))
+	assert.Equal(t, []tarEntry{
+		{name: dataId, typeflag: tar.TypeDir},
+		{name: dataId + "/a", typeflag: tar.TypeReg, content: "hello"},
+		{name: dataId + "/sub", typeflag: tar.TypeDir},
+		{name: dataId + "/sub/b", typeflag: tar.TypeReg, content: "world!"},
+		{name: dataId + "/c", typeflag: tar.TypeLink, linkname: dataId + "/a"},
+		{name: configId, typeflag: tar.TypeReg, content: "new"},
+		{name: "volumes/main-service/volumes-data.yml", typeflag: tar.TypeReg, content: "- id: " + volumeId("/data") + "\n  type: dir\n  target: /data\n" +
+			"- id: " + volumeId("/etc/config.yml") + "\n  type: file\n  target: /etc/config.yml\n"},
+	}, readTar(t, bytes.NewReader(backupTar)))
+	assert.Equal(t, []Progress{
+		{Service: "main-service", Volume: "/data", Processed: 0, Total: -1},
+		{Service: "main-service", Volume: "/data", Processed: 11, Total: 11},
+		{Service: "main-service", Volume: "/etc/config.yml", Processed: 0, Total: -1},
+		{Service: "main-service", Volume: "/etc/config.yml", Processed: 3, Total: 3},
+	}, events)
+
+	// Restore the volumes
+	events = nil
+	project := &types.Project{Name: "mock-avs", Services: types.Services{service}}
+	backupVolumes := []data.BackupVolume{
+		{Service: service.Name, Source: "data", Target: "/data"},
+		{Service: service.Name, Source: configPath, Target: "/etc/config.yml"},
+	}
+	require.NoError(t, b.restoreVolumes(ctx, project, backupVolumes, readBytes(backupTar), func(e Progress) { events = append(events, e) }))
+
+	assert.Equal(t, []tarEntry{
+		{name: "data", typeflag: tar.TypeDir},
+		{name: "data/a", typeflag: tar.TypeReg, content: "hello"},
+		{name: "data/sub", typeflag: tar.TypeDir},
+		{name: "data/sub/b", typeflag: tar.TypeReg, content: "world!"},
+		{name: "data/c", typeflag: tar.TypeLink, linkname: "data/a"},
+	}, restored)
+	config, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, "new", string(config))
+	assert.Equal(t, []Progress{
+		{Service: "main-service", Volume: "/data", Processed: 0, Total: 11},
+		{Service: "main-service", Volume: "/data", Processed: 11, Total: 11},
+		{Service: "main-service", Volume: "/etc/config.yml", Processed: 0, Total: 3},
+		{Service: "main-service", Volume: "/etc/config.yml", Processed: 3, Total: 3},
+	}, events)
+}
+
+func TestRestoreVolumes_NotInBackup(t *testing.T) {
+	backupTar := writeTar(t, nil)
+
+	// No Docker calls are expected
+	b := NewBackupManager(afero.NewOsFs(), nil, docker.NewDockerManager(mocks.NewMockAPIClient(gomock.NewController(t))), nil)
+	project := &types.Project{Name: "mock-avs", Services: types.Services{{Name: "main-service"}}}
+	volumes := []data.BackupVolume{{Service: "main-service", Target: "/data"}}
+	assert.NoError(t, b.restoreVolumes(context.Background(), project, volumes, readBytes(backupTar), nil))
+}
+
+func TestRestoreVolumes_CopyError(t *testing.T) {
+	dataId := path.Join("volumes/main-service", volumeId("/data"))
+	backupTar := writeTar(t, []tarEntry{
+		{name: dataId, typeflag: tar.TypeDir},
+		{name: dataId + "/a", typeflag: tar.TypeReg, content: "hello"},
+		{name: "volumes/main-service/volumes-data.yml", typeflag: tar.TypeReg, content: "- id: " + volumeId("/data") + "\n  type: dir\n  target: /data\n"},
+	})
+
+	ctrl := gomock.NewController(t)
+	dockerClient := mocks.NewMockAPIClient(ctrl)
+	dockerClient.EXPECT().ContainerList(gomock.Any(), gomock.Any()).Return([]dockerTypes.Container{{ID: "mock-avs-main-service"}}, nil)
+	copyErr := errors.New("copy error")
+	dockerClient.EXPECT().CopyToContainer(gomock.Any(), "mock-avs-main-service", "/", gomock.Any(), dockerTypes.CopyToContainerOptions{}).
+		Return(copyErr)
+	b := NewBackupManager(afero.NewOsFs(), nil, docker.NewDockerManager(dockerClient), nil)
+
+	project := &types.Project{Name: "mock-avs", Services: types.Services{{Name: "main-service"}}}
+	volumes := []data.BackupVolume{{Service: "main-service", Target: "/data"}}
+	err := b.restoreVolumes(context.Background(), project, volumes, readBytes(backupTar), nil)
+	assert.ErrorIs(t, err, copyErr)
+}
+
+func TestClearVolumes(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/mock-avs/config/a.yml", []byte("a"), 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/mock-avs/config/sub/b.yml", []byte("b"), 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/mock-avs/keys/key", []byte("key"), 0o644))
+
+	ctrl := gomock.NewController(t)
+	dockerClient := mocks.NewMockAPIClient(ctrl)
+	dockerClient.EXPECT().VolumeRemove(gomock.Any(), "mock-avs_data", false).Return(nil)
+	b := NewBackupManager(fs, nil, docker.NewDockerManager(dockerClient), nil)
+
+	project := &types.Project{
+		Services: types.Services{{
+			Name: "main-service",
+			Volumes: []types.ServiceVolumeConfig{
+				{Type: types.VolumeTypeVolume, Source: "data", Target: "/data"},
+				{Type: types.VolumeTypeVolume, Source: "shared", Target: "/shared"},
+				{Type: types.VolumeTypeBind, Source: "/mock-avs/config", Target: "/config"},
+				{Type: types.VolumeTypeBind, Source: "/mock-avs/keys", Target: "/keys"},
+				{Type: types.VolumeTypeBind, Source: "/not-found", Target: "/not-found"},
+			},
+		}},
+		Volumes: types.Volumes{
+			"data":   types.VolumeConfig{Name: "mock-avs_data"},
+			"shared": types.VolumeConfig{Name: "shared", External: types.External{External: true}},
+		},
+	}
+	err := b.clearVolumes(project, []data.BackupVolume{
+		{Service: "main-service", Source: "data", Target: "/data"},
+		{Service: "main-service", Source: "shared", Target: "/shared"},
+		{Service: "main-service", Source: "/mock-avs/config", Target: "/config"},
+		{Service: "main-service", Source: "/not-found", Target: "/not-found"},
+	})
+	require.NoError(t, err)
+
+	entries, err := afero.ReadDir(fs, "/mock-avs/config")
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+	// Volumes not selected are kept
+	exists, err := afero.Exists(fs, "/mock-avs/keys/key")
+	require.NoError(t, err)
+	assert.True(t, exists)
+}
+
+func TestCutTarPath(t *testing.T) {
+	tests := []struct {
+		name    string
+		dir     string
+		wantRel string
+		wantOk  bool
+	}{
+		{name: "data", dir: "data", wantRel: "", wantOk: true},
+		{name: "data/", dir: "data", wantRel: "", wantOk: true},
+		{name: "data/sub/", dir: "data", wantRel: "sub", wantOk: true},
+		{name: "data/sub/a", dir: "data", wantRel: "sub/a", wantOk: true},
+		{name: "database/a", dir: "data", wantOk: false},
+		{name: "other", dir: "data", wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rel, ok := cutTarPath(tt.name, tt.dir)
+			assert.Equal(t, tt.wantOk, ok)
+			if ok {
+				assert.Equal(t, tt.wantRel, rel)
+			}
+		})
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	log "github.com/sirupsen/logrus"
+	"golang.org/x/exp/maps"
 )
 
 // Command represents a command to be executed.
@@ -16,6 +17,9 @@ type Command struct {
 	Cmd string
 	// GetOutput indicates whether the output of the command should be returned.
 	GetOutput bool
+	// Env is a set of environment variables added to the environment of the
+	// command. Its values are not logged, so it could be used to pass secrets.
+	Env map[string]string
 }
 
 // CMDRunner is a command runner that can run commands with or without sudo.
@@ -37,21 +41,33 @@ func NewCMDRunnerWithSudo() CMDRunner {
 func (cr *CMDRunner) RunCMD(cmd Command) (out string, exitCode int, err error) {
 	if cr.runWithSudo {
 		log.Debug(`Running command with sudo.`)
-		cmd.Cmd = fmt.Sprintf("sudo %s", cmd.Cmd)
+		if len(cmd.Env) > 0 {
+			// sudo resets the environment unless the variables are preserved
+			cmd.Cmd = fmt.Sprintf("sudo --preserve-env=%s %s", strings.Join(maps.Keys(cmd.Env), ","), cmd.Cmd)
+		} else {
+			cmd.Cmd = fmt.Sprintf("sudo %s", cmd.Cmd)
+		}
 	} else {
 		log.Debug(`Running command without sudo.`)
 	}
-	return runCmd(cmd.Cmd, cmd.GetOutput)
+	return runCmd(cmd.Cmd, cmd.GetOutput, cmd.Env)
 }
 
 // TODO: Refactor to be able to opt for show output to stdout/stderr, and by default show output to stdout/stderr and return output
 // runCmd executes a command and returns the output, exit code, and any error that occurred during execution. If getOutput is true, the output of the command is returned.
-func runCmd(cmd string, getOutput bool) (out string, exitCode int, err error) {
+// The env variables are added to the environment of the command.
+func runCmd(cmd string, getOutput bool, env map[string]string) (out string, exitCode int, err error) {
 	r := strings.ReplaceAll(cmd, "\n", "")
 	spl := strings.Split(r, " ")
 	c, args := spl[0], spl[1:]
 
 	exc := exec.Command(c, args...)
+	if len(env) > 0 {
+		exc.Env = os.Environ()
+		for k, v := range env {
+			exc.Env = append(exc.Env, k+"="+v)
+		}
+	}
 
 	var combinedOut bytes.Buffer
 	if getOutput {
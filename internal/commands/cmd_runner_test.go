@@ -46,6 +46,21 @@ func TestRunCmd(t *testing.T) {
 	}
 }
 
+func TestRunCmd_Env(t *testing.T) {
+	runner := NewCMDRunner()
+	got, _, err := runner.RunCMD(Command{
+		Cmd:       "printenv EGN_TEST_SECRET",
+		GetOutput: true,
+		Env:       map[string]string{"EGN_TEST_SECRET": "secret value"},
+	})
+	if err != nil {
+		t.Fatalf("RunCMD failed: %v", err)
+	}
+	if got != "secret value\n" {
+		t.Errorf("expected secret value but got %s", got)
+	}
+}
+
 func ExampleCMDRunner_RunCMD() {
 	cmdRunner := NewCMDRunner()
 	out, exitCode, err := cmdRunner.RunCMD(Command{Cmd: "echo hello", GetOutput: true})
@@ -1,5 +1,11 @@
 package common
 
 const (
+	// SpecVersion is the latest version of the AVS Node Specification supported
+	// by egn, used for packages that do not declare the versions they target.
 	SpecVersion = "v0.1.0"
 )
+
+// SupportedSpecVersions is the list of AVS Node Specification versions supported
+// by egn.
+var SupportedSpecVersions = []string{SpecVersion}
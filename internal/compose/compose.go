@@ -42,7 +42,7 @@ func (cm *ComposeManager) Up(opts DockerComposeUpOptions) error {
 		upCmd += " " + strings.Join(opts.Services, " ")
 	}
 
-	if out, exitCode, err := cm.cmdRunner.RunCMD(commands.Command{Cmd: upCmd, GetOutput: true}); err != nil || exitCode != 0 {
+	if out, exitCode, err := cm.cmdRunner.RunCMD(commands.Command{Cmd: upCmd, GetOutput: true, Env: opts.Env}); err != nil || exitCode != 0 {
 		return fmt.Errorf("%w: %s. Output: %s", DockerComposeCmdError{cmd: "up"}, err, out)
 	}
 	return nil
@@ -71,7 +71,7 @@ func (cm *ComposeManager) Create(opts DockerComposeCreateOptions) error {
 		createCmd += " " + strings.Join(opts.Services, " ")
 	}
 
-	if out, exitCode, err := cm.cmdRunner.RunCMD(commands.Command{Cmd: createCmd, GetOutput: true}); err != nil || exitCode != 0 {
+	if out, exitCode, err := cm.cmdRunner.RunCMD(commands.Command{Cmd: createCmd, GetOutput: true, Env: opts.Env}); err != nil || exitCode != 0 {
 		return fmt.Errorf("%w: %s. Output: %s", DockerComposeCmdError{cmd: "create"}, err, out)
 	}
 	return nil
@@ -176,6 +176,9 @@ func (cm *ComposeManager) Logs(opts DockerComposeLogsOptions) error {
 // Stop runs the Docker Compose 'stop' command for the specified options.
 func (cm *ComposeManager) Stop(opts DockerComposeStopOptions) error {
 	stopCmd := fmt.Sprintf("docker compose -f %s stop", opts.Path)
+	if len(opts.Services) > 0 {
+		stopCmd += " " + strings.Join(opts.Services, " ")
+	}
 
 	if out, exitCode, err := cm.cmdRunner.RunCMD(commands.Command{Cmd: stopCmd, GetOutput: true}); err != nil || exitCode != 0 {
 		return fmt.Errorf("%w: %s. Output: %s", DockerComposeCmdError{cmd: "stop"}, err, out)
@@ -183,6 +186,45 @@ func (cm *ComposeManager) Stop(opts DockerComposeStopOptions) error {
 	return nil
 }
 
+// Start runs the Docker Compose 'start' command for the specified options.
+func (cm *ComposeManager) Start(opts DockerComposeStartOptions) error {
+	startCmd := fmt.Sprintf("docker compose -f %s start", opts.Path)
+	if len(opts.Services) > 0 {
+		startCmd += " " + strings.Join(opts.Services, " ")
+	}
+
+	if out, exitCode, err := cm.cmdRunner.RunCMD(commands.Command{Cmd: startCmd, GetOutput: true}); err != nil || exitCode != 0 {
+		return fmt.Errorf("%w: %s. Output: %s", DockerComposeCmdError{cmd: "start"}, err, out)
+	}
+	return nil
+}
+
+// Pause runs the Docker Compose 'pause' command for the specified options.
+func (cm *ComposeManager) Pause(opts DockerComposePauseOptions) error {
+	pauseCmd := fmt.Sprintf("docker compose -f %s pause", opts.Path)
+	if len(opts.Services) > 0 {
+		pauseCmd += " " + strings.Join(opts.Services, " ")
+	}
+
+	if out, exitCode, err := cm.cmdRunner.RunCMD(commands.Command{Cmd: pauseCmd, GetOutput: true}); err != nil || exitCode != 0 {
+		return fmt.Errorf("%w: %s. Output: %s", DockerComposeCmdError{cmd: "pause"}, err, out)
+	}
+	return nil
+}
+
+// Unpause runs the Docker Compose 'unpause' command for the specified options.
+func (cm *ComposeManager) Unpause(opts DockerComposeUnpauseOptions) error {
+	unpauseCmd := fmt.Sprintf("docker compose -f %s unpause", opts.Path)
+	if len(opts.Services) > 0 {
+		unpauseCmd += " " + strings.Join(opts.Services, " ")
+	}
+
+	if out, exitCode, err := cm.cmdRunner.RunCMD(commands.Command{Cmd: unpauseCmd, GetOutput: true}); err != nil || exitCode != 0 {
+		return fmt.Errorf("%w: %s. Output: %s", DockerComposeCmdError{cmd: "unpause"}, err, out)
+	}
+	return nil
+}
+
 // Down runs the Docker Compose 'down' command for the specified options.
 func (cm *ComposeManager) Down(opts DockerComposeDownOptions) error {
 	downCmd := fmt.Sprintf("docker compose -f %s down", opts.Path)
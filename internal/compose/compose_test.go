@@ -46,6 +46,15 @@ func TestUp(t *testing.T) {
 			runCMDError: nil,
 			wantError:   nil,
 		},
+		{
+			name: "it passes the environment variables to the command",
+			opts: DockerComposeUpOptions{
+				Path: "/path/to/docker-compose.yml",
+				Env:  map[string]string{"API_KEY": "secret"},
+			},
+			runCMDError: nil,
+			wantError:   nil,
+		},
 	}
 
 	for _, tt := range tests {
@@ -65,9 +74,9 @@ func TestUp(t *testing.T) {
 			}
 
 			if tt.runCMDError != nil {
-				mockRunner.EXPECT().RunCMD(commands.Command{Cmd: expectedCmd, GetOutput: true}).Return("", 1, tt.runCMDError)
+				mockRunner.EXPECT().RunCMD(commands.Command{Cmd: expectedCmd, GetOutput: true, Env: tt.opts.Env}).Return("", 1, tt.runCMDError)
 			} else {
-				mockRunner.EXPECT().RunCMD(commands.Command{Cmd: expectedCmd, GetOutput: true}).Return("", 0, nil)
+				mockRunner.EXPECT().RunCMD(commands.Command{Cmd: expectedCmd, GetOutput: true, Env: tt.opts.Env}).Return("", 0, nil)
 			}
 
 			err := manager.Up(tt.opts)
@@ -642,6 +651,15 @@ func TestStop(t *testing.T) {
 			runCMDError: errors.New("command failed"),
 			wantError:   DockerComposeCmdError{cmd: "stop"},
 		},
+		{
+			name: "it stops the specified services",
+			opts: DockerComposeStopOptions{
+				Path:     "/path/to/docker-compose.yml",
+				Services: []string{"service1", "service2"},
+			},
+			runCMDError: nil,
+			wantError:   nil,
+		},
 	}
 
 	for _, tt := range tests {
@@ -654,6 +672,9 @@ func TestStop(t *testing.T) {
 			manager := NewComposeManager(mockRunner)
 
 			expectedCmd := "docker compose -f " + tt.opts.Path + " stop"
+			if len(tt.opts.Services) > 0 {
+				expectedCmd += " " + strings.Join(tt.opts.Services, " ")
+			}
 
 			if tt.runCMDError != nil {
 				mockRunner.EXPECT().RunCMD(commands.Command{Cmd: expectedCmd, GetOutput: true}).Return("", 1, tt.runCMDError)
@@ -673,6 +694,207 @@ func TestStop(t *testing.T) {
 	}
 }
 
+func TestStart(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        DockerComposeStartOptions
+		runCMDError error
+		wantError   error
+	}{
+		{
+			name: "it runs the correct command",
+			opts: DockerComposeStartOptions{
+				Path:     "/path/to/docker-compose.yml",
+				Services: []string{"service1", "service2"},
+			},
+			runCMDError: nil,
+			wantError:   nil,
+		},
+		{
+			name: "it runs the correct command when no services are specified",
+			opts: DockerComposeStartOptions{
+				Path: "/path/to/docker-compose.yml",
+			},
+			runCMDError: nil,
+			wantError:   nil,
+		},
+		{
+			name: "it returns an error if RunCMD fails",
+			opts: DockerComposeStartOptions{
+				Path:     "/path/to/docker-compose.yml",
+				Services: []string{"service1"},
+			},
+			runCMDError: errors.New("command failed"),
+			wantError:   DockerComposeCmdError{cmd: "start"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRunner := mocks.NewMockCMDRunner(ctrl)
+
+			manager := NewComposeManager(mockRunner)
+
+			expectedCmd := "docker compose -f " + tt.opts.Path + " start"
+			if len(tt.opts.Services) > 0 {
+				expectedCmd += " " + strings.Join(tt.opts.Services, " ")
+			}
+
+			if tt.runCMDError != nil {
+				mockRunner.EXPECT().RunCMD(commands.Command{Cmd: expectedCmd, GetOutput: true}).Return("", 1, tt.runCMDError)
+			} else {
+				mockRunner.EXPECT().RunCMD(commands.Command{Cmd: expectedCmd, GetOutput: true}).Return("", 0, nil)
+			}
+
+			err := manager.Start(tt.opts)
+
+			if tt.wantError != nil {
+				assert.Error(t, err)
+				assert.ErrorIs(t, err, tt.wantError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestPause(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        DockerComposePauseOptions
+		runCMDError error
+		wantError   error
+	}{
+		{
+			name: "it runs the correct command",
+			opts: DockerComposePauseOptions{
+				Path:     "/path/to/docker-compose.yml",
+				Services: []string{"service1", "service2"},
+			},
+			runCMDError: nil,
+			wantError:   nil,
+		},
+		{
+			name: "it runs the correct command when no services are specified",
+			opts: DockerComposePauseOptions{
+				Path: "/path/to/docker-compose.yml",
+			},
+			runCMDError: nil,
+			wantError:   nil,
+		},
+		{
+			name: "it returns an error if RunCMD fails",
+			opts: DockerComposePauseOptions{
+				Path:     "/path/to/docker-compose.yml",
+				Services: []string{"service1"},
+			},
+			runCMDError: errors.New("command failed"),
+			wantError:   DockerComposeCmdError{cmd: "pause"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRunner := mocks.NewMockCMDRunner(ctrl)
+
+			manager := NewComposeManager(mockRunner)
+
+			expectedCmd := "docker compose -f " + tt.opts.Path + " pause"
+			if len(tt.opts.Services) > 0 {
+				expectedCmd += " " + strings.Join(tt.opts.Services, " ")
+			}
+
+			if tt.runCMDError != nil {
+				mockRunner.EXPECT().RunCMD(commands.Command{Cmd: expectedCmd, GetOutput: true}).Return("", 1, tt.runCMDError)
+			} else {
+				mockRunner.EXPECT().RunCMD(commands.Command{Cmd: expectedCmd, GetOutput: true}).Return("", 0, nil)
+			}
+
+			err := manager.Pause(tt.opts)
+
+			if tt.wantError != nil {
+				assert.Error(t, err)
+				assert.ErrorIs(t, err, tt.wantError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestUnpause(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        DockerComposeUnpauseOptions
+		runCMDError error
+		wantError   error
+	}{
+		{
+			name: "it runs the correct command",
+			opts: DockerComposeUnpauseOptions{
+				Path:     "/path/to/docker-compose.yml",
+				Services: []string{"service1", "service2"},
+			},
+			runCMDError: nil,
+			wantError:   nil,
+		},
+		{
+			name: "it runs the correct command when no services are specified",
+			opts: DockerComposeUnpauseOptions{
+				Path: "/path/to/docker-compose.yml",
+			},
+			runCMDError: nil,
+			wantError:   nil,
+		},
+		{
+			name: "it returns an error if RunCMD fails",
+			opts: DockerComposeUnpauseOptions{
+				Path:     "/path/to/docker-compose.yml",
+				Services: []string{"service1"},
+			},
+			runCMDError: errors.New("command failed"),
+			wantError:   DockerComposeCmdError{cmd: "unpause"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRunner := mocks.NewMockCMDRunner(ctrl)
+
+			manager := NewComposeManager(mockRunner)
+
+			expectedCmd := "docker compose -f " + tt.opts.Path + " unpause"
+			if len(tt.opts.Services) > 0 {
+				expectedCmd += " " + strings.Join(tt.opts.Services, " ")
+			}
+
+			if tt.runCMDError != nil {
+				mockRunner.EXPECT().RunCMD(commands.Command{Cmd: expectedCmd, GetOutput: true}).Return("", 1, tt.runCMDError)
+			} else {
+				mockRunner.EXPECT().RunCMD(commands.Command{Cmd: expectedCmd, GetOutput: true}).Return("", 0, nil)
+			}
+
+			err := manager.Unpause(tt.opts)
+
+			if tt.wantError != nil {
+				assert.Error(t, err)
+				assert.ErrorIs(t, err, tt.wantError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
 func TestDown(t *testing.T) {
 	tests := []struct {
 		name        string
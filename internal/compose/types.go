@@ -6,6 +6,10 @@ type DockerComposeUpOptions struct {
 	Path string
 	// Services lists the names of the services to be started.
 	Services []string
+	// Env specifies environment variables used for the interpolation of the
+	// docker-compose.yaml file, in addition to the .env file. It is used to inject
+	// secrets that are not stored in the .env file.
+	Env map[string]string
 }
 
 // DockerComposePullOptions defines the options for the 'docker compose pull' command.
@@ -24,6 +28,10 @@ type DockerComposeCreateOptions struct {
 	Services []string
 	// Build specifies whether to build images before starting containers.
 	Build bool
+	// Env specifies environment variables used for the interpolation of the
+	// docker-compose.yaml file, in addition to the .env file. It is used to inject
+	// secrets that are not stored in the .env file.
+	Env map[string]string
 }
 
 // DockerComposeBuildOptions defines the options for the 'docker compose build' command.
@@ -81,6 +89,33 @@ type DockerComposeLogsOptions struct {
 type DockerComposeStopOptions struct {
 	// Path specifies the location of the docker-compose.yaml file.
 	Path string
+	// Services lists the names of the services to be stopped. All the services
+	// are stopped if it is empty.
+	Services []string
+}
+
+// DockerComposeStartOptions defines the options for the 'docker compose start' command.
+type DockerComposeStartOptions struct {
+	// Path specifies the location of the docker-compose.yaml file.
+	Path string
+	// Services lists the names of the services to be started.
+	Services []string
+}
+
+// DockerComposePauseOptions defines the options for the 'docker compose pause' command.
+type DockerComposePauseOptions struct {
+	// Path specifies the location of the docker-compose.yaml file.
+	Path string
+	// Services lists the names of the services to be paused.
+	Services []string
+}
+
+// DockerComposeUnpauseOptions defines the options for the 'docker compose unpause' command.
+type DockerComposeUnpauseOptions struct {
+	// Path specifies the location of the docker-compose.yaml file.
+	Path string
+	// Services lists the names of the services to be unpaused.
+	Services []string
 }
 
 // DockerComposeDownOptions defines the options for the 'docker compose down' command.
@@ -18,13 +18,62 @@ import (
 
 var backupFileNameRegex = regexp.MustCompile(`^(?P<instance_id>.*)-(?P<timestamp>[0-9]+)\.tar$`)
 
+// Backup consistency modes, how the running services of an instance are
+// quiesced while their volumes are backed up.
+const (
+	// BackupConsistencyStop stops the running services during the backup of
+	// the volumes, and starts them again afterwards.
+	BackupConsistencyStop = "stop"
+	// BackupConsistencyPause pauses the running services during the backup of
+	// the volumes, and unpauses them afterwards. The services keep their
+	// state, so the downtime is shorter than with BackupConsistencyStop.
+	BackupConsistencyPause = "pause"
+)
+
+// ValidateBackupConsistency returns an error if the given backup consistency
+// mode is not supported. An empty mode is valid, and it means stop.
+func ValidateBackupConsistency(consistency string) error {
+	switch consistency {
+	case "", BackupConsistencyStop, BackupConsistencyPause:
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrInvalidBackupConsistency, consistency)
+}
+
 type Backup struct {
 	id         string
-	InstanceId string
-	Timestamp  time.Time
-	Version    string
-	Commit     string
-	Url        string
+	InstanceId string    `json:"instance_id"`
+	Timestamp  time.Time `json:"timestamp"`
+	Version    string    `json:"version"`
+	Commit     string    `json:"commit"`
+	Url        string    `json:"url"`
+	// Consistency is the consistency mode of the backup, how the running
+	// services of the instance were quiesced during the backup. It is empty
+	// for backups created before consistency modes were introduced, which
+	// were created with the instance stopped.
+	Consistency string `json:"consistency,omitempty"`
+	// Selection is the content of partial backups, which include only some of
+	// the volumes of the instance, and nil for backups of all the volumes.
+	Selection *BackupSelection `json:"selection,omitempty"`
+	// Scheduled is true for the backups created by the backup schedule of the
+	// instance, which are the only ones removed by its retention policy.
+	Scheduled bool `json:"scheduled,omitempty"`
+	// Compression is the compression of the backup chunks. It is empty for
+	// backups stored as a single tar file.
+	Compression string `json:"compression,omitempty"`
+	// Size is the logical size in bytes of the backup, the size of its tar
+	// file.
+	Size int64 `json:"size"`
+	// StoredSize is the size in bytes of the data stored by the backup, that
+	// is, the compressed size of the chunks not stored by previous backups of
+	// the instance.
+	StoredSize int64 `json:"stored_size"`
+	// Chunks are the chunks of the tar file of the backup, in order.
+	Chunks []BackupChunk `json:"chunks,omitempty"`
+	// Encryption are the key parameters of encrypted backups, nil if the
+	// backup is not encrypted. Only the chunks are encrypted, the rest of the
+	// backup information is readable without the passphrase.
+	Encryption *BackupEncryption `json:"encryption,omitempty"`
 }
 
 func (b *Backup) Id() string {
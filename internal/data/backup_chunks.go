@@ -0,0 +1,434 @@
+package data
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/NethermindEth/eigenlayer/internal/locker"
+	"github.com/klauspost/compress/zstd"
+	"github.com/spf13/afero"
+)
+
+const (
+	CompressionZstd = "zstd"
+	CompressionGzip = "gzip"
+	CompressionNone = "none"
+)
+
+// chunksDir is the directory of the backups directory where the backup chunks
+// are stored, in a subdirectory for each instance.
+const chunksDir = "chunks"
+
+// BackupChunk is a chunk of the tar file of a backup, identified by the
+// SHA-256 hash of its uncompressed data.
+type BackupChunk struct {
+	Hash string `json:"hash"`
+	Size int64  `json:"size"`
+}
+
+// ValidateCompression returns an error if the given backup compression is not
+// supported. An empty compression is valid, and it means zstd.
+func ValidateCompression(compression string) error {
+	switch compression {
+	case "", CompressionZstd, CompressionGzip, CompressionNone:
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrInvalidCompression, compression)
+}
+
+// chunkCodec compresses and decompresses backup chunks.
+type chunkCodec struct {
+	compression string
+	zstdEncoder *zstd.Encoder
+	zstdDecoder *zstd.Decoder
+}
+
+func newChunkCodec(compression string) (*chunkCodec, error) {
+	c := &chunkCodec{compression: compression}
+	switch compression {
+	case CompressionZstd:
+		var err error
+		if c.zstdEncoder, err = zstd.NewWriter(nil); err != nil {
+			return nil, err
+		}
+		if c.zstdDecoder, err = zstd.NewReader(nil); err != nil {
+			return nil, err
+		}
+	case CompressionGzip, CompressionNone:
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrInvalidCompression, compression)
+	}
+	return c, nil
+}
+
+func (c *chunkCodec) encode(data []byte) ([]byte, error) {
+	switch c.compression {
+	case CompressionZstd:
+		return c.zstdEncoder.EncodeAll(data, nil), nil
+	case CompressionGzip:
+		var out bytes.Buffer
+		w := gzip.NewWriter(&out)
+		if _, err := w.Write(data); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		return out.Bytes(), nil
+	default:
+		return data, nil
+	}
+}
+
+func (c *chunkCodec) decode(data []byte) ([]byte, error) {
+	switch c.compression {
+	case CompressionZstd:
+		return c.zstdDecoder.DecodeAll(data, nil)
+	case CompressionGzip:
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		return io.ReadAll(r)
+	default:
+		return data, nil
+	}
+}
+
+func (c *chunkCodec) Close() {
+	if c.zstdEncoder != nil {
+		c.zstdEncoder.Close()
+	}
+	if c.zstdDecoder != nil {
+		c.zstdDecoder.Close()
+	}
+}
+
+// chunkExt returns the file extension of the chunks with the given compression.
+func chunkExt(compression string) string {
+	switch compression {
+	case CompressionZstd:
+		return ".zst"
+	case CompressionGzip:
+		return ".gz"
+	default:
+		return ""
+	}
+}
+
+// PackOptions are the options to store a backup with PackBackup.
+type PackOptions struct {
+	// Compression is the compression of the backup chunks. If empty, zstd is
+	// used.
+	Compression string
+	// Passphrase encrypts the backup chunks if it is not empty.
+	Passphrase string
+}
+
+// PackBackup stores the tar stream of the given backup, initialized with
+// InitBackup, read from r, in the chunk store of its instance. The stream is
+// split in content-defined chunks as it is read, so the tar is never written to
+// disk as a whole, and only the chunks that are not stored yet by previous
+// backups of the instance are compressed, encrypted if a passphrase is given,
+// and stored. The backup manifest, with the list of chunks, is written once the
+// whole stream is stored. The chunk store of the instance is locked until the
+// manifest is written, so RemoveUnusedBackupChunks doesn't remove the chunks
+// reused by the backup. If reading r fails or the context is done, the backup
+// is not stored and the error is returned.
+func (d *DataDir) PackBackup(ctx context.Context, b *Backup, r io.Reader, options PackOptions) error {
+	compression := options.Compression
+	if compression == "" {
+		compression = CompressionZstd
+	}
+	codec, err := newChunkCodec(compression)
+	if err != nil {
+		return err
+	}
+	defer codec.Close()
+
+	var key *backupKey
+	b.Encryption = nil
+	if options.Passphrase != "" {
+		if b.Encryption, key, err = d.backupEncryption(b.InstanceId, options.Passphrase); err != nil {
+			return err
+		}
+	}
+
+	unlock, err := d.lockChunks(b.InstanceId)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	b.Compression = compression
+	b.Size, b.StoredSize, b.Chunks = 0, 0, nil
+	c := newChunker(r)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		chunk, err := c.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		hash := chunkHash(key, chunk)
+		b.Chunks = append(b.Chunks, BackupChunk{Hash: hash, Size: int64(len(chunk))})
+		b.Size += int64(len(chunk))
+
+		chunkPath := d.chunkPath(b, hash)
+		ok, err := afero.Exists(d.fs, chunkPath)
+		if err != nil {
+			return err
+		}
+		if ok {
+			// Already stored by a previous backup. The chunk is touched, so it
+			// is not removed as unused while this backup is in progress.
+			now := time.Now()
+			if err := d.fs.Chtimes(chunkPath, now, now); err != nil {
+				return err
+			}
+			continue
+		}
+		data, err := codec.encode(chunk)
+		if err != nil {
+			return err
+		}
+		if key != nil {
+			if data, err = key.seal(hash, data); err != nil {
+				return err
+			}
+		}
+		if err := d.writeFileAtomic(chunkPath, data); err != nil {
+			return err
+		}
+		b.StoredSize += int64(len(data))
+	}
+
+	manifest, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	return d.writeFileAtomic(d.backupManifestPath(b.Id()), manifest)
+}
+
+// OpenBackup returns a reader of the tar stream of the backup with the given
+// id. Chunked backups are read from the chunk store one chunk at a time as the
+// stream is read, decrypting them with the given passphrase if the backup is
+// encrypted and verifying the hash of each chunk, so the tar is never written
+// to disk as a whole. Reading a chunk that is missing or corrupted fails with
+// an ErrInvalidBackupChunk error, and reading after the context is done fails
+// with the context error.
+func (d *DataDir) OpenBackup(ctx context.Context, backupId, passphrase string) (io.ReadCloser, error) {
+	b, err := d.Backup(backupId)
+	if err != nil {
+		return nil, err
+	}
+	if b.Compression == "" {
+		// Backup stored as a single tar file
+		return d.fs.Open(d.BackupPath(backupId))
+	}
+	key, err := b.key(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	codec, err := newChunkCodec(b.Compression)
+	if err != nil {
+		return nil, err
+	}
+	return &backupReader{ctx: ctx, d: d, b: b, key: key, codec: codec}, nil
+}
+
+// backupReader reads the tar stream of a chunked backup, reading its chunks
+// one after the other.
+type backupReader struct {
+	ctx   context.Context
+	d     *DataDir
+	b     *Backup
+	key   *backupKey
+	codec *chunkCodec
+	// next is the index of the next chunk to read, and buf the unread data of
+	// the last chunk read.
+	next int
+	buf  []byte
+}
+
+func (r *backupReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if r.next == len(r.b.Chunks) {
+			return 0, io.EOF
+		}
+		if err := r.ctx.Err(); err != nil {
+			return 0, err
+		}
+		data, err := r.d.readChunk(r.codec, r.key, r.b, r.b.Chunks[r.next])
+		if err != nil {
+			return 0, err
+		}
+		r.buf = data
+		r.next++
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+func (r *backupReader) Close() error {
+	r.codec.Close()
+	return nil
+}
+
+// readChunk reads, decrypts and decompresses the given chunk of a backup,
+// checking its hash. The key is nil for backups that are not encrypted.
+func (d *DataDir) readChunk(codec *chunkCodec, key *backupKey, b *Backup, chunk BackupChunk) ([]byte, error) {
+	data, err := afero.ReadFile(d.fs, d.chunkPath(b, chunk.Hash))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrInvalidBackupChunk, chunk.Hash, err)
+	}
+	if key != nil {
+		if data, err = key.open(chunk.Hash, data); err != nil {
+			return nil, err
+		}
+	}
+	data, err = codec.decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrInvalidBackupChunk, chunk.Hash, err)
+	}
+	if chunkHash(key, data) != chunk.Hash || int64(len(data)) != chunk.Size {
+		return nil, fmt.Errorf("%w: %s: hash mismatch", ErrInvalidBackupChunk, chunk.Hash)
+	}
+	return data, nil
+}
+
+// chunkHash returns the id of the chunk with the given data: the SHA-256 hash
+// of the data, or its HMAC with the backup key for encrypted backups.
+func chunkHash(key *backupKey, data []byte) string {
+	if key != nil {
+		return key.chunkId(data)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// writeFileAtomic writes the given data to a temporary file and renames it to
+// the given path, so the file is never left half written.
+func (d *DataDir) writeFileAtomic(path string, data []byte) error {
+	if err := d.fs.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmpPath := path + ".tmp"
+	if err := afero.WriteFile(d.fs, tmpPath, data, 0o644); err != nil {
+		return err
+	}
+	return d.fs.Rename(tmpPath, path)
+}
+
+// lockChunks locks the chunk store of the given instance, and returns the
+// function to unlock it. Backups are written to the OS file system, so a file
+// lock is used if the data dir has no locker.
+func (d *DataDir) lockChunks(instanceId string) (func() error, error) {
+	lockPath := filepath.Join(d.backupsDir(), chunksDir, instanceId+".lock")
+	if err := d.fs.MkdirAll(filepath.Dir(lockPath), 0o755); err != nil {
+		return nil, err
+	}
+	return d.lockFile(lockPath)
+}
+
+// lockFile locks the given lock file, returning the function unlocking it. A
+// file lock is used if the data dir has no locker.
+func (d *DataDir) lockFile(lockPath string) (func() error, error) {
+	l := d.locker
+	if l == nil {
+		l = locker.NewFLock()
+	}
+	l = l.New(lockPath)
+	if err := l.Lock(); err != nil {
+		return nil, err
+	}
+	return l.Unlock, nil
+}
+
+func (d *DataDir) chunkPath(b *Backup, hash string) string {
+	return filepath.Join(d.backupsDir(), filepath.FromSlash(b.ChunkKey(BackupChunk{Hash: hash})))
+}
+
+func (d *DataDir) backupManifestPath(backupId string) string {
+	return filepath.Join(d.backupsDir(), BackupManifestKey(backupId))
+}
+
+// BackupManifestKey returns the key of the manifest of the backup with the
+// given id in a backup store.
+func BackupManifestKey(backupId string) string {
+	return backupId + ".json"
+}
+
+// BackupTarKey returns the key of the tar file of the backup with the given id
+// in a backup store, for backups stored as a single tar file.
+func BackupTarKey(backupId string) string {
+	return backupId + ".tar"
+}
+
+// ChunkKey returns the key of the given chunk of the backup in a backup store.
+// Encrypted chunks have the .enc extension.
+func (b *Backup) ChunkKey(chunk BackupChunk) string {
+	name := chunk.Hash + chunkExt(b.Compression)
+	if b.Encryption != nil {
+		name += ".enc"
+	}
+	return path.Join(chunksDir, b.InstanceId, chunk.Hash[:2], name)
+}
+
+// BackupFromManifest loads a backup information from a backup manifest file.
+func BackupFromManifest(fs afero.Fs, src string) (*Backup, error) {
+	data, err := afero.ReadFile(fs, src)
+	if err != nil {
+		return nil, err
+	}
+	b, err := ParseBackupManifest(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", err, src)
+	}
+	return b, nil
+}
+
+// ParseBackupManifest parses the content of a backup manifest, checking the
+// compression and the chunk hashes, so manifests from backup stores can be
+// trusted to build chunk keys.
+func ParseBackupManifest(data []byte) (*Backup, error) {
+	var b Backup
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidBackupManifest, err)
+	}
+	if b.InstanceId == "" || strings.ContainsAny(b.InstanceId, `/\`) {
+		return nil, fmt.Errorf("%w: invalid instance id %q", ErrInvalidBackupManifest, b.InstanceId)
+	}
+	if b.Compression == "" || ValidateCompression(b.Compression) != nil {
+		return nil, fmt.Errorf("%w: invalid compression %q", ErrInvalidBackupManifest, b.Compression)
+	}
+	if b.Encryption != nil {
+		if err := b.Encryption.validate(); err != nil {
+			return nil, err
+		}
+	}
+	for _, chunk := range b.Chunks {
+		if hash, err := hex.DecodeString(chunk.Hash); err != nil || len(hash) != sha256.Size {
+			return nil, fmt.Errorf("%w: invalid chunk hash %q", ErrInvalidBackupManifest, chunk.Hash)
+		}
+	}
+	// Same location as the timestamps of backups stored as a single tar file
+	b.Timestamp = b.Timestamp.Local()
+	return &b, nil
+}
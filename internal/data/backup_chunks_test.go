@@ -0,0 +1,344 @@
+package data
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// initTestBackup initializes a backup of the given instance, and returns the
+// content of its tar, including the state.json, the timestamp and the given
+// volume data.
+func initTestBackup(t *testing.T, dataDir *DataDir, b *Backup, volume []byte) []byte {
+	t.Helper()
+	require.NoError(t, dataDir.InitBackup(b))
+	var buf bytes.Buffer
+	tarWriter := tar.NewWriter(&buf)
+	tarAddStateJson(t, tarWriter, []byte(`{"name": "mock-avs", "tag": "default", "version": "`+b.Version+`"}`))
+	tarAddTimestamp(t, tarWriter, b.Timestamp)
+	require.NoError(t, tarWriter.WriteHeader(&tar.Header{
+		Name:    "volumes/main/data",
+		Size:    int64(len(volume)),
+		Mode:    0o644,
+		ModTime: time.Unix(0, 0),
+	}))
+	_, err := tarWriter.Write(volume)
+	require.NoError(t, err)
+	require.NoError(t, tarWriter.Close())
+	return buf.Bytes()
+}
+
+// readTestBackup returns the content of the tar of the backup with the given
+// id.
+func readTestBackup(t *testing.T, dataDir *DataDir, backupId, passphrase string) []byte {
+	t.Helper()
+	r, err := dataDir.OpenBackup(context.Background(), backupId, passphrase)
+	require.NoError(t, err)
+	defer r.Close()
+	content, err := io.ReadAll(r)
+	require.NoError(t, err)
+	return content
+}
+
+func TestDataDir_PackBackup(t *testing.T) {
+	volume := make([]byte, 8*1024*1024)
+	rand.New(rand.NewSource(1)).Read(volume)
+
+	tests := []struct {
+		name        string
+		compression string
+		passphrase  string
+		wantErr     error
+	}{
+		{
+			name:        "default compression",
+			compression: "",
+		},
+		{
+			name:        "zstd",
+			compression: CompressionZstd,
+		},
+		{
+			name:        "gzip",
+			compression: CompressionGzip,
+		},
+		{
+			name:        "no compression",
+			compression: CompressionNone,
+		},
+		{
+			name:        "encrypted",
+			compression: CompressionZstd,
+			passphrase:  "correct horse battery staple",
+		},
+		{
+			name:        "encrypted without compression",
+			compression: CompressionNone,
+			passphrase:  "correct horse battery staple",
+		},
+		{
+			name:        "invalid compression",
+			compression: "lz4",
+			wantErr:     ErrInvalidCompression,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewOsFs()
+			dataDir, err := NewDataDir(t.TempDir(), fs, nil)
+			require.NoError(t, err)
+
+			first := Backup{
+				InstanceId: "mock-avs-default",
+				Timestamp:  time.Unix(1696420902, 0),
+				Version:    "v5.5.0",
+			}
+			firstTar := initTestBackup(t, dataDir, &first, volume)
+			err = dataDir.PackBackup(context.Background(), &first, bytes.NewReader(firstTar), PackOptions{Compression: tt.compression, Passphrase: tt.passphrase})
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.NoFileExists(t, dataDir.BackupPath(first.Id()))
+			assert.Equal(t, int64(len(firstTar)), first.Size)
+			assert.Positive(t, first.StoredSize)
+
+			// The second backup only stores the data that changed
+			second := Backup{
+				InstanceId: "mock-avs-default",
+				Timestamp:  time.Unix(1696507302, 0),
+				Version:    "v5.5.1",
+			}
+			secondTar := initTestBackup(t, dataDir, &second, volume)
+			require.NoError(t, dataDir.PackBackup(context.Background(), &second, bytes.NewReader(secondTar), PackOptions{Compression: tt.compression, Passphrase: tt.passphrase}))
+			assert.Equal(t, int64(len(secondTar)), second.Size)
+			assert.Less(t, second.StoredSize, first.StoredSize/2)
+
+			backups, err := dataDir.BackupList()
+			require.NoError(t, err)
+			require.Len(t, backups, 2)
+			for _, b := range backups {
+				b.Id()
+				if b.Id() == first.Id() {
+					assert.Equal(t, first, b)
+				} else {
+					assert.Equal(t, second, b)
+				}
+			}
+
+			// Read the tar of both backups
+			for _, b := range []struct {
+				backup  Backup
+				content []byte
+			}{{first, firstTar}, {second, secondTar}} {
+				assert.Equal(t, b.content, readTestBackup(t, dataDir, b.backup.Id(), tt.passphrase))
+			}
+		})
+	}
+}
+
+func TestDataDir_OpenBackup_Corrupted(t *testing.T) {
+	fs := afero.NewOsFs()
+	dataDir, err := NewDataDir(t.TempDir(), fs, nil)
+	require.NoError(t, err)
+
+	b := Backup{
+		InstanceId: "mock-avs-default",
+		Timestamp:  time.Unix(1696420902, 0),
+		Version:    "v5.5.0",
+	}
+	content := initTestBackup(t, dataDir, &b, []byte("volume data"))
+	require.NoError(t, dataDir.PackBackup(context.Background(), &b, bytes.NewReader(content), PackOptions{Compression: CompressionNone}))
+	require.Len(t, b.Chunks, 1)
+
+	chunkPath := dataDir.chunkPath(&b, b.Chunks[0].Hash)
+	require.NoError(t, afero.WriteFile(fs, chunkPath, []byte("corrupted"), 0o644))
+	r, err := dataDir.OpenBackup(context.Background(), b.Id(), "")
+	require.NoError(t, err)
+	defer r.Close()
+	_, err = io.ReadAll(r)
+	assert.ErrorIs(t, err, ErrInvalidBackupChunk)
+	assert.FileExists(t, filepath.Join(dataDir.Path(), backupDir, b.Id()+".json"))
+}
+
+func TestDataDir_PackBackup_Canceled(t *testing.T) {
+	fs := afero.NewOsFs()
+	dataDir, err := NewDataDir(t.TempDir(), fs, nil)
+	require.NoError(t, err)
+
+	b := Backup{
+		InstanceId: "mock-avs-default",
+		Timestamp:  time.Unix(1696420902, 0),
+		Version:    "v5.5.0",
+	}
+	content := initTestBackup(t, dataDir, &b, []byte("volume data"))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = dataDir.PackBackup(ctx, &b, bytes.NewReader(content), PackOptions{})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.NoFileExists(t, filepath.Join(dataDir.Path(), backupDir, b.Id()+".json"))
+
+	require.NoError(t, dataDir.PackBackup(context.Background(), &b, bytes.NewReader(content), PackOptions{}))
+	r, err := dataDir.OpenBackup(ctx, b.Id(), "")
+	require.NoError(t, err)
+	defer r.Close()
+	_, err = io.ReadAll(r)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestDataDir_PackBackup_ReadError(t *testing.T) {
+	fs := afero.NewOsFs()
+	dataDir, err := NewDataDir(t.TempDir(), fs, nil)
+	require.NoError(t, err)
+
+	b := Backup{
+		InstanceId: "mock-avs-default",
+		Timestamp:  time.Unix(1696420902, 0),
+		Version:    "v5.5.0",
+	}
+	content := initTestBackup(t, dataDir, &b, []byte("volume data"))
+	readErr := errors.New("read error")
+	r := io.MultiReader(bytes.NewReader(content), iotest.ErrReader(readErr))
+	err = dataDir.PackBackup(context.Background(), &b, r, PackOptions{})
+	assert.ErrorIs(t, err, readErr)
+	ok, err := dataDir.HasBackup(b.Id())
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestDataDir_OpenBackup_Encrypted(t *testing.T) {
+	fs := afero.NewOsFs()
+	dataDir, err := NewDataDir(t.TempDir(), fs, nil)
+	require.NoError(t, err)
+
+	volume := []byte("keystore password")
+	first := Backup{
+		InstanceId: "mock-avs-default",
+		Timestamp:  time.Unix(1696420902, 0),
+		Version:    "v5.5.0",
+	}
+	content := initTestBackup(t, dataDir, &first, volume)
+	require.NoError(t, dataDir.PackBackup(context.Background(), &first, bytes.NewReader(content), PackOptions{Compression: CompressionNone, Passphrase: "secret"}))
+	require.NotNil(t, first.Encryption)
+
+	// The chunks are not stored in plaintext
+	chunk, err := afero.ReadFile(fs, dataDir.chunkPath(&first, first.Chunks[0].Hash))
+	require.NoError(t, err)
+	assert.NotContains(t, string(chunk), string(volume))
+	assert.True(t, strings.HasSuffix(first.ChunkKey(first.Chunks[0]), ".enc"))
+
+	// The backup information is readable without the passphrase
+	b, err := dataDir.Backup(first.Id())
+	require.NoError(t, err)
+	assert.Equal(t, first.InstanceId, b.InstanceId)
+
+	_, err = dataDir.OpenBackup(context.Background(), first.Id(), "")
+	assert.ErrorIs(t, err, ErrBackupEncrypted)
+	_, err = dataDir.OpenBackup(context.Background(), first.Id(), "wrong")
+	assert.ErrorIs(t, err, ErrInvalidPassphrase)
+	assert.ErrorIs(t, b.CheckPassphrase("wrong"), ErrInvalidPassphrase)
+	assert.NoError(t, b.CheckPassphrase("secret"))
+
+	// Backups with the same passphrase reuse the key, with another passphrase
+	// a new key is generated
+	second := Backup{
+		InstanceId: "mock-avs-default",
+		Timestamp:  time.Unix(1696507302, 0),
+		Version:    "v5.5.0",
+	}
+	content = initTestBackup(t, dataDir, &second, volume)
+	require.NoError(t, dataDir.PackBackup(context.Background(), &second, bytes.NewReader(content), PackOptions{Compression: CompressionNone, Passphrase: "secret"}))
+	assert.Equal(t, first.Encryption, second.Encryption)
+	third := Backup{
+		InstanceId: "mock-avs-default",
+		Timestamp:  time.Unix(1696593702, 0),
+		Version:    "v5.5.0",
+	}
+	content = initTestBackup(t, dataDir, &third, volume)
+	require.NoError(t, dataDir.PackBackup(context.Background(), &third, bytes.NewReader(content), PackOptions{Compression: CompressionNone, Passphrase: "other"}))
+	assert.NotEqual(t, first.Encryption.Salt, third.Encryption.Salt)
+	assert.Equal(t, content, readTestBackup(t, dataDir, third.Id(), "other"))
+}
+
+func TestDataDir_OpenBackup_SingleTar(t *testing.T) {
+	fs := afero.NewOsFs()
+	dataDir, err := NewDataDir(t.TempDir(), fs, nil)
+	require.NoError(t, err)
+
+	b := Backup{
+		InstanceId: "mock-avs-default",
+		Timestamp:  time.Unix(1696420902, 0),
+		Version:    "v5.5.0",
+	}
+	content := initTestBackup(t, dataDir, &b, []byte("volume data"))
+	require.NoError(t, afero.WriteFile(fs, dataDir.BackupPath(b.Id()), content, 0o644))
+	assert.Equal(t, content, readTestBackup(t, dataDir, b.Id(), ""))
+}
+
+func TestParseBackupManifest(t *testing.T) {
+	hash := "a1b2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f90"
+	tests := []struct {
+		name     string
+		manifest string
+		want     *Backup
+		wantErr  error
+	}{
+		{
+			name:     "valid manifest",
+			manifest: `{"instance_id": "mock-avs-default", "timestamp": "2023-10-04T12:01:42Z", "version": "v5.5.0", "compression": "zstd", "size": 10, "stored_size": 5, "chunks": [{"hash": "` + hash + `", "size": 10}]}`,
+			want: &Backup{
+				InstanceId:  "mock-avs-default",
+				Timestamp:   time.Unix(1696420902, 0),
+				Version:     "v5.5.0",
+				Compression: CompressionZstd,
+				Size:        10,
+				StoredSize:  5,
+				Chunks:      []BackupChunk{{Hash: hash, Size: 10}},
+			},
+		},
+		{
+			name:     "invalid json",
+			manifest: `{"instance_id": `,
+			wantErr:  ErrInvalidBackupManifest,
+		},
+		{
+			name:     "missing compression",
+			manifest: `{"instance_id": "mock-avs-default", "timestamp": "2023-10-04T12:01:42Z"}`,
+			wantErr:  ErrInvalidBackupManifest,
+		},
+		{
+			name:     "instance id with path separator",
+			manifest: `{"instance_id": "../mock-avs-default", "timestamp": "2023-10-04T12:01:42Z", "compression": "zstd"}`,
+			wantErr:  ErrInvalidBackupManifest,
+		},
+		{
+			name:     "invalid chunk hash",
+			manifest: `{"instance_id": "mock-avs-default", "timestamp": "2023-10-04T12:01:42Z", "compression": "zstd", "chunks": [{"hash": "../../a", "size": 10}]}`,
+			wantErr:  ErrInvalidBackupManifest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseBackupManifest([]byte(tt.manifest))
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package data
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// BackupKDFScrypt is the key derivation function of encrypted backups.
+	BackupKDFScrypt = "scrypt"
+
+	scryptN        = 1 << 15
+	scryptR        = 8
+	scryptP        = 1
+	backupSaltSize = 16
+	// backupKeyCheckMessage is authenticated with the key of an encrypted
+	// backup, to detect wrong passphrases before decrypting the chunks.
+	backupKeyCheckMessage = "eigenlayer backup key check"
+)
+
+// BackupEncryption are the parameters of the key of an encrypted backup. The
+// key is derived from a passphrase with scrypt, and it is split in an AES-256
+// key to encrypt the chunks with AES-GCM and an HMAC-SHA256 key to identify
+// them. Identifying the chunks with an HMAC instead of a plain hash keeps the
+// chunk ids from revealing the backup content, while the chunks are still
+// deduplicated across the backups of an instance with the same passphrase.
+type BackupEncryption struct {
+	KDF  string `json:"kdf"`
+	Salt []byte `json:"salt"`
+	N    int    `json:"n"`
+	R    int    `json:"r"`
+	P    int    `json:"p"`
+	// KeyCheck is the HMAC of a fixed message with the backup key.
+	KeyCheck string `json:"key_check"`
+}
+
+// backupKey is the key of an encrypted backup.
+type backupKey struct {
+	aead   cipher.AEAD
+	macKey []byte
+}
+
+// newBackupEncryption returns new encryption parameters, with a random salt,
+// and the key derived from the given passphrase.
+func newBackupEncryption(passphrase string) (*BackupEncryption, *backupKey, error) {
+	e := &BackupEncryption{
+		KDF:  BackupKDFScrypt,
+		Salt: make([]byte, backupSaltSize),
+		N:    scryptN,
+		R:    scryptR,
+		P:    scryptP,
+	}
+	if _, err := rand.Read(e.Salt); err != nil {
+		return nil, nil, err
+	}
+	key, err := e.deriveKey(passphrase)
+	if err != nil {
+		return nil, nil, err
+	}
+	e.KeyCheck = key.chunkId([]byte(backupKeyCheckMessage))
+	return e, key, nil
+}
+
+// validate returns an error if the encryption parameters are not supported,
+// including scrypt costs too high to derive the key in a reasonable time.
+func (e *BackupEncryption) validate() error {
+	if e.KDF != BackupKDFScrypt {
+		return fmt.Errorf("%w: unsupported key derivation function %q", ErrInvalidBackupManifest, e.KDF)
+	}
+	if len(e.Salt) < backupSaltSize {
+		return fmt.Errorf("%w: invalid salt", ErrInvalidBackupManifest)
+	}
+	if e.N < 2 || e.N > 1<<20 || e.N&(e.N-1) != 0 || e.R < 1 || e.P < 1 || e.R*e.P > 16 {
+		return fmt.Errorf("%w: invalid scrypt parameters", ErrInvalidBackupManifest)
+	}
+	if _, err := hex.DecodeString(e.KeyCheck); err != nil || len(e.KeyCheck) != 2*sha256.Size {
+		return fmt.Errorf("%w: invalid key check", ErrInvalidBackupManifest)
+	}
+	return nil
+}
+
+// key derives the backup key from the given passphrase. If the passphrase is
+// wrong, an ErrInvalidPassphrase error is returned.
+func (e *BackupEncryption) key(passphrase string) (*backupKey, error) {
+	if passphrase == "" {
+		return nil, ErrBackupEncrypted
+	}
+	if err := e.validate(); err != nil {
+		return nil, err
+	}
+	key, err := e.deriveKey(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if !hmac.Equal([]byte(key.chunkId([]byte(backupKeyCheckMessage))), []byte(e.KeyCheck)) {
+		return nil, ErrInvalidPassphrase
+	}
+	return key, nil
+}
+
+func (e *BackupEncryption) deriveKey(passphrase string) (*backupKey, error) {
+	derived, err := scrypt.Key([]byte(passphrase), e.Salt, e.N, e.R, e.P, 64)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(derived[:32])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &backupKey{aead: aead, macKey: derived[32:]}, nil
+}
+
+// chunkId returns the id of the chunk with the given data, the hex-encoded
+// HMAC-SHA256 of the data.
+func (k *backupKey) chunkId(data []byte) string {
+	mac := hmac.New(sha256.New, k.macKey)
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// seal encrypts the given chunk data, authenticating the chunk id. The result
+// is the random nonce followed by the ciphertext.
+func (k *backupKey) seal(id string, data []byte) ([]byte, error) {
+	nonce := make([]byte, k.aead.NonceSize(), k.aead.NonceSize()+len(data)+k.aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return k.aead.Seal(nonce, nonce, data, []byte(id)), nil
+}
+
+// open decrypts the given chunk data sealed with seal.
+func (k *backupKey) open(id string, data []byte) ([]byte, error) {
+	if len(data) < k.aead.NonceSize() {
+		return nil, fmt.Errorf("%w: %s: too short", ErrInvalidBackupChunk, id)
+	}
+	nonce, ciphertext := data[:k.aead.NonceSize()], data[k.aead.NonceSize():]
+	plaintext, err := k.aead.Open(nil, nonce, ciphertext, []byte(id))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrInvalidBackupChunk, id, err)
+	}
+	return plaintext, nil
+}
+
+// CheckPassphrase returns an error if the backup is encrypted and the given
+// passphrase is wrong (ErrInvalidPassphrase) or empty (ErrBackupEncrypted).
+// Backups that are not encrypted accept any passphrase.
+func (b *Backup) CheckPassphrase(passphrase string) error {
+	_, err := b.key(passphrase)
+	return err
+}
+
+// key returns the key of the backup, or nil if it is not encrypted.
+func (b *Backup) key(passphrase string) (*backupKey, error) {
+	if b.Encryption == nil {
+		return nil, nil
+	}
+	return b.Encryption.key(passphrase)
+}
+
+// backupEncryption returns the encryption parameters and the key of a new
+// backup of the given instance. The parameters of the latest encrypted backup
+// of the instance with the same passphrase are reused, so the new backup only
+// stores the chunks that changed. Otherwise, new parameters are generated.
+func (d *DataDir) backupEncryption(instanceId, passphrase string) (*BackupEncryption, *backupKey, error) {
+	backups, err := d.chunkedBackups()
+	if err != nil {
+		return nil, nil, err
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].Timestamp.After(backups[j].Timestamp)
+	})
+	tried := make(map[string]bool)
+	for _, b := range backups {
+		if b.InstanceId != instanceId || b.Encryption == nil || tried[string(b.Encryption.Salt)] {
+			continue
+		}
+		tried[string(b.Encryption.Salt)] = true
+		key, err := b.Encryption.key(passphrase)
+		if err == nil {
+			return b.Encryption, key, nil
+		}
+		if !errors.Is(err, ErrInvalidPassphrase) {
+			return nil, nil, err
+		}
+	}
+	return newBackupEncryption(passphrase)
+}
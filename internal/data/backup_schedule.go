@@ -0,0 +1,343 @@
+package data
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"github.com/spf13/afero"
+)
+
+const backupSchedulesFileName = "backup_schedules.json"
+
+// BackupSchedule is the schedule of the automatic backups of an instance, and
+// the retention policy of its backups.
+type BackupSchedule struct {
+	// Cron is a standard cron expression with five fields, like "0 3 * * *",
+	// or a descriptor like "@daily", evaluated in local time.
+	Cron string `json:"cron"`
+	// Compression is the compression of the scheduled backups.
+	Compression string `json:"compression,omitempty"`
+	// Consistency is the consistency mode of the scheduled backups.
+	Consistency string `json:"consistency,omitempty"`
+	// PassphraseFile is the path of the file with the passphrase encrypting
+	// the scheduled backups, empty if they are not encrypted. The file is read
+	// each time a scheduled backup runs.
+	PassphraseFile string `json:"passphrase_file,omitempty"`
+	// Retention is the retention policy of the backups of the instance.
+	Retention BackupRetention `json:"retention"`
+	// CreatedAt is the time the schedule was set. The first scheduled backup
+	// is the first time matching the cron expression after it.
+	CreatedAt time.Time `json:"created_at"`
+	// LastRun is the time of the last scheduled backup, zero if no scheduled
+	// backup has run yet.
+	LastRun time.Time `json:"last_run"`
+}
+
+// Validate returns an error if the schedule is not valid.
+func (s *BackupSchedule) Validate() error {
+	if _, err := parseCron(s.Cron); err != nil {
+		return err
+	}
+	if err := ValidateCompression(s.Compression); err != nil {
+		return err
+	}
+	if err := ValidateBackupConsistency(s.Consistency); err != nil {
+		return err
+	}
+	return s.Retention.Validate()
+}
+
+// Next returns the time of the next scheduled backup, the first time matching
+// the cron expression after the last scheduled backup.
+func (s *BackupSchedule) Next() (time.Time, error) {
+	schedule, err := parseCron(s.Cron)
+	if err != nil {
+		return time.Time{}, err
+	}
+	last := s.LastRun
+	if last.IsZero() {
+		last = s.CreatedAt
+	}
+	return schedule.Next(last), nil
+}
+
+// Due returns true if a scheduled backup should have run at the given time.
+// Backups missed while nothing checked the schedule result in a single due
+// backup.
+func (s *BackupSchedule) Due(now time.Time) (bool, error) {
+	next, err := s.Next()
+	if err != nil {
+		return false, err
+	}
+	return !next.After(now), nil
+}
+
+func parseCron(expr string) (cron.Schedule, error) {
+	schedule, err := cron.ParseStandard(expr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q: %s", ErrInvalidBackupSchedule, expr, err)
+	}
+	return schedule, nil
+}
+
+// BackupRetention is the retention policy of the backups of an instance. A
+// backup is kept if any of the rules keeps it, and the policy keeps all the
+// backups if all the rules are zero.
+type BackupRetention struct {
+	// KeepLast is the number of latest backups to keep.
+	KeepLast int `json:"keep_last,omitempty"`
+	// KeepDaily is the number of days to keep the latest backup of.
+	KeepDaily int `json:"keep_daily,omitempty"`
+	// KeepWeekly is the number of ISO weeks to keep the latest backup of.
+	KeepWeekly int `json:"keep_weekly,omitempty"`
+	// KeepMonthly is the number of months to keep the latest backup of.
+	KeepMonthly int `json:"keep_monthly,omitempty"`
+}
+
+// Validate returns an error if the retention policy is not valid.
+func (r BackupRetention) Validate() error {
+	if r.KeepLast < 0 || r.KeepDaily < 0 || r.KeepWeekly < 0 || r.KeepMonthly < 0 {
+		return fmt.Errorf("%w: negative number of backups to keep", ErrInvalidBackupSchedule)
+	}
+	return nil
+}
+
+// IsZero returns true if the policy has no rules, so it keeps all backups.
+func (r BackupRetention) IsZero() bool {
+	return r == BackupRetention{}
+}
+
+// Apply splits the given backups in the backups kept and the backups removed
+// by the retention policy, both sorted from the latest to the oldest. The
+// daily, weekly and monthly rules keep the latest backup of each of the last
+// periods with backups, in local time. Only scheduled backups are subject to
+// the rules, the backups created otherwise, like manual, partial or pre-update
+// backups, are always kept.
+func (r BackupRetention) Apply(backups []Backup) (keep, remove []Backup) {
+	sorted := make([]Backup, len(backups))
+	copy(sorted, backups)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Timestamp.After(sorted[j].Timestamp)
+	})
+	if r.IsZero() {
+		return sorted, nil
+	}
+
+	rules := []struct {
+		n      int
+		period func(time.Time) string
+		last   string
+	}{
+		{n: r.KeepDaily, period: func(t time.Time) string { return t.Format(time.DateOnly) }},
+		{n: r.KeepWeekly, period: func(t time.Time) string {
+			year, week := t.ISOWeek()
+			return fmt.Sprintf("%d-W%02d", year, week)
+		}},
+		{n: r.KeepMonthly, period: func(t time.Time) string { return t.Format("2006-01") }},
+	}
+	var scheduled int
+	for _, b := range sorted {
+		if !b.Scheduled {
+			keep = append(keep, b)
+			continue
+		}
+		kept := scheduled < r.KeepLast
+		scheduled++
+		for j := range rules {
+			rule := &rules[j]
+			if rule.n == 0 {
+				continue
+			}
+			period := rule.period(b.Timestamp.Local())
+			if period == rule.last {
+				// Not the latest backup of the period
+				continue
+			}
+			rule.last = period
+			rule.n--
+			kept = true
+		}
+		if kept {
+			keep = append(keep, b)
+		} else {
+			remove = append(remove, b)
+		}
+	}
+	return keep, remove
+}
+
+// BackupSchedules returns the backup schedules, by instance id.
+func (d *DataDir) BackupSchedules() (map[string]*BackupSchedule, error) {
+	data, err := afero.ReadFile(d.fs, d.backupSchedulesPath())
+	if os.IsNotExist(err) {
+		return make(map[string]*BackupSchedule), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	schedules := make(map[string]*BackupSchedule)
+	if err := json.Unmarshal(data, &schedules); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidBackupSchedule, err)
+	}
+	return schedules, nil
+}
+
+// SetBackupSchedule sets the backup schedule of the given instance, replacing
+// its previous schedule if any.
+func (d *DataDir) SetBackupSchedule(instanceId string, schedule *BackupSchedule) error {
+	if err := schedule.Validate(); err != nil {
+		return err
+	}
+	unlock, err := d.lockBackupSchedules()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	schedules, err := d.BackupSchedules()
+	if err != nil {
+		return err
+	}
+	schedules[instanceId] = schedule
+	return d.saveBackupSchedules(schedules)
+}
+
+// RemoveBackupSchedule removes the backup schedule of the given instance. If
+// the instance has no schedule, an ErrBackupScheduleNotFound error is
+// returned.
+func (d *DataDir) RemoveBackupSchedule(instanceId string) error {
+	unlock, err := d.lockBackupSchedules()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	schedules, err := d.BackupSchedules()
+	if err != nil {
+		return err
+	}
+	if _, ok := schedules[instanceId]; !ok {
+		return fmt.Errorf("%w: %s", ErrBackupScheduleNotFound, instanceId)
+	}
+	delete(schedules, instanceId)
+	return d.saveBackupSchedules(schedules)
+}
+
+// StartScheduledBackup records a run of the backup schedule of the given
+// instance at the given time if a scheduled backup is due, and returns the
+// schedule and true. The schedules are read again with the schedules file
+// locked, and only the last run of the schedule is changed, so concurrent
+// changes of the schedules are kept and a due backup is started only once. If
+// the instance has no schedule anymore or no backup is due, false is returned.
+func (d *DataDir) StartScheduledBackup(instanceId string, now time.Time) (*BackupSchedule, bool, error) {
+	unlock, err := d.lockBackupSchedules()
+	if err != nil {
+		return nil, false, err
+	}
+	defer unlock()
+
+	schedules, err := d.BackupSchedules()
+	if err != nil {
+		return nil, false, err
+	}
+	schedule, ok := schedules[instanceId]
+	if !ok {
+		return nil, false, nil
+	}
+	due, err := schedule.Due(now)
+	if err != nil || !due {
+		return nil, false, err
+	}
+	schedule.LastRun = now
+	if err := d.saveBackupSchedules(schedules); err != nil {
+		return nil, false, err
+	}
+	return schedule, true, nil
+}
+
+// lockBackupSchedules locks the backup schedules file, returning the function
+// unlocking it.
+func (d *DataDir) lockBackupSchedules() (func() error, error) {
+	return d.lockFile(d.backupSchedulesPath() + ".lock")
+}
+
+func (d *DataDir) saveBackupSchedules(schedules map[string]*BackupSchedule) error {
+	data, err := json.MarshalIndent(schedules, "", "  ")
+	if err != nil {
+		return err
+	}
+	return d.writeFileAtomic(d.backupSchedulesPath(), data)
+}
+
+func (d *DataDir) backupSchedulesPath() string {
+	return filepath.Join(d.path, backupSchedulesFileName)
+}
+
+// RemoveBackup removes the backup with the given id. The chunks of the backup
+// are not removed, see RemoveUnusedBackupChunks.
+func (d *DataDir) RemoveBackup(backupId string) error {
+	ok, err := d.HasBackup(backupId)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrBackupNotFound, backupId)
+	}
+	for _, path := range []string{d.backupManifestPath(backupId), d.BackupPath(backupId)} {
+		if err := d.fs.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// RemoveUnusedBackupChunks removes the chunks of the given instance that are
+// not used by any backup, and returns the number of chunks removed and their
+// size. The chunk store of the instance is locked while the chunks are
+// removed, so the chunks of a backup being stored by PackBackup are kept.
+func (d *DataDir) RemoveUnusedBackupChunks(instanceId string) (removed int, size int64, err error) {
+	unlock, err := d.lockChunks(instanceId)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer unlock()
+
+	backups, err := d.chunkedBackups()
+	if err != nil {
+		return 0, 0, err
+	}
+	used := make(map[string]bool)
+	for i := range backups {
+		b := &backups[i]
+		if b.InstanceId != instanceId {
+			continue
+		}
+		for _, chunk := range b.Chunks {
+			used[filepath.Join(d.backupsDir(), filepath.FromSlash(b.ChunkKey(chunk)))] = true
+		}
+	}
+
+	instanceChunksDir := filepath.Join(d.backupsDir(), chunksDir, instanceId)
+	ok, err := afero.DirExists(d.fs, instanceChunksDir)
+	if err != nil || !ok {
+		return 0, 0, err
+	}
+	err = afero.Walk(d.fs, instanceChunksDir, func(path string, info fs.FileInfo, err error) error {
+		if err != nil || info.IsDir() || used[path] {
+			return err
+		}
+		if err := d.fs.Remove(path); err != nil {
+			return err
+		}
+		removed++
+		size += info.Size()
+		return nil
+	})
+	return removed, size, err
+}
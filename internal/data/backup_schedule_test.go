@@ -0,0 +1,335 @@
+package data
+
+import (
+	"bytes"
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackupSchedule_Due(t *testing.T) {
+	created := time.Date(2023, 10, 1, 12, 0, 0, 0, time.Local)
+	tests := []struct {
+		name     string
+		schedule BackupSchedule
+		now      time.Time
+		want     bool
+		wantErr  error
+	}{
+		{
+			name:     "first run not due",
+			schedule: BackupSchedule{Cron: "0 3 * * *", CreatedAt: created},
+			now:      time.Date(2023, 10, 2, 2, 59, 0, 0, time.Local),
+			want:     false,
+		},
+		{
+			name:     "first run due",
+			schedule: BackupSchedule{Cron: "0 3 * * *", CreatedAt: created},
+			now:      time.Date(2023, 10, 2, 3, 0, 0, 0, time.Local),
+			want:     true,
+		},
+		{
+			name:     "after last run",
+			schedule: BackupSchedule{Cron: "@daily", CreatedAt: created, LastRun: time.Date(2023, 10, 5, 0, 0, 0, 0, time.Local)},
+			now:      time.Date(2023, 10, 5, 23, 0, 0, 0, time.Local),
+			want:     false,
+		},
+		{
+			name:     "missed runs",
+			schedule: BackupSchedule{Cron: "@hourly", CreatedAt: created, LastRun: time.Date(2023, 10, 5, 0, 0, 0, 0, time.Local)},
+			now:      time.Date(2023, 10, 9, 0, 0, 0, 0, time.Local),
+			want:     true,
+		},
+		{
+			name:     "invalid cron",
+			schedule: BackupSchedule{Cron: "0 3 * *", CreatedAt: created},
+			wantErr:  ErrInvalidBackupSchedule,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			due, err := tt.schedule.Due(tt.now)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, due)
+		})
+	}
+}
+
+func TestBackupSchedule_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule BackupSchedule
+		wantErr  error
+	}{
+		{
+			name:     "valid",
+			schedule: BackupSchedule{Cron: "*/15 * * * *", Compression: CompressionGzip, Consistency: BackupConsistencyPause, Retention: BackupRetention{KeepDaily: 7}},
+		},
+		{
+			name:     "invalid cron",
+			schedule: BackupSchedule{Cron: "every day"},
+			wantErr:  ErrInvalidBackupSchedule,
+		},
+		{
+			name:     "invalid compression",
+			schedule: BackupSchedule{Cron: "@daily", Compression: "lz4"},
+			wantErr:  ErrInvalidCompression,
+		},
+		{
+			name:     "invalid consistency",
+			schedule: BackupSchedule{Cron: "@daily", Consistency: "snapshot"},
+			wantErr:  ErrInvalidBackupConsistency,
+		},
+		{
+			name:     "negative retention",
+			schedule: BackupSchedule{Cron: "@daily", Retention: BackupRetention{KeepLast: -1}},
+			wantErr:  ErrInvalidBackupSchedule,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.schedule.Validate()
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestBackupRetention_Apply(t *testing.T) {
+	// Daily backups at 03:00 from 2023-08-15 to 2023-10-15, plus an extra
+	// backup at 15:00 on 2023-10-15
+	var backups []Backup
+	for d := time.Date(2023, 8, 15, 3, 0, 0, 0, time.Local); !d.After(time.Date(2023, 10, 15, 3, 0, 0, 0, time.Local)); d = d.AddDate(0, 0, 1) {
+		backups = append(backups, Backup{InstanceId: "mock-avs-default", Timestamp: d, Scheduled: true})
+	}
+	backups = append(backups, Backup{InstanceId: "mock-avs-default", Timestamp: time.Date(2023, 10, 15, 15, 0, 0, 0, time.Local), Scheduled: true})
+	rand.New(rand.NewSource(1)).Shuffle(len(backups), func(i, j int) {
+		backups[i], backups[j] = backups[j], backups[i]
+	})
+	date := func(month time.Month, day, hour int) time.Time {
+		return time.Date(2023, month, day, hour, 0, 0, 0, time.Local)
+	}
+
+	tests := []struct {
+		name      string
+		retention BackupRetention
+		want      []time.Time
+	}{
+		{
+			name:      "keep last",
+			retention: BackupRetention{KeepLast: 3},
+			want:      []time.Time{date(10, 15, 15), date(10, 15, 3), date(10, 14, 3)},
+		},
+		{
+			name:      "keep daily",
+			retention: BackupRetention{KeepDaily: 3},
+			want:      []time.Time{date(10, 15, 15), date(10, 14, 3), date(10, 13, 3)},
+		},
+		{
+			name:      "keep weekly",
+			retention: BackupRetention{KeepWeekly: 3},
+			// 2023-10-15 and 2023-10-08 are Sundays, the last days of their
+			// ISO weeks
+			want: []time.Time{date(10, 15, 15), date(10, 8, 3), date(10, 1, 3)},
+		},
+		{
+			name:      "keep monthly",
+			retention: BackupRetention{KeepMonthly: 6},
+			want:      []time.Time{date(10, 15, 15), date(9, 30, 3), date(8, 31, 3)},
+		},
+		{
+			name:      "grandfather-father-son",
+			retention: BackupRetention{KeepLast: 1, KeepDaily: 2, KeepWeekly: 2, KeepMonthly: 2},
+			want:      []time.Time{date(10, 15, 15), date(10, 14, 3), date(10, 8, 3), date(9, 30, 3)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keep, remove := tt.retention.Apply(backups)
+			assert.Len(t, keep, len(tt.want))
+			assert.Len(t, remove, len(backups)-len(tt.want))
+			for i := range keep {
+				if i < len(tt.want) {
+					assert.True(t, tt.want[i].Equal(keep[i].Timestamp), "kept %s, want %s", keep[i].Timestamp, tt.want[i])
+				}
+			}
+			for i := 1; i < len(remove); i++ {
+				assert.True(t, remove[i-1].Timestamp.After(remove[i].Timestamp), "removed backups are sorted")
+			}
+		})
+	}
+
+	t.Run("no rules keep all backups", func(t *testing.T) {
+		keep, remove := BackupRetention{}.Apply(backups)
+		assert.Len(t, keep, len(backups))
+		assert.Empty(t, remove)
+	})
+
+	t.Run("backups not scheduled are kept", func(t *testing.T) {
+		manual := Backup{InstanceId: "mock-avs-default", Timestamp: date(9, 1, 12)}
+		partial := Backup{InstanceId: "mock-avs-default", Timestamp: date(10, 15, 18), Selection: &BackupSelection{ConfigOnly: true}}
+		keep, remove := BackupRetention{KeepLast: 1}.Apply(append([]Backup{manual, partial}, backups...))
+		require.Len(t, keep, 3)
+		assert.Equal(t, partial.Timestamp, keep[0].Timestamp)
+		assert.Equal(t, date(10, 15, 15), keep[1].Timestamp)
+		assert.Equal(t, manual.Timestamp, keep[2].Timestamp)
+		assert.Len(t, remove, len(backups)-1)
+	})
+}
+
+func TestDataDir_BackupSchedules(t *testing.T) {
+	dataDir, err := NewDataDir(t.TempDir(), afero.NewOsFs(), nil)
+	require.NoError(t, err)
+
+	schedules, err := dataDir.BackupSchedules()
+	require.NoError(t, err)
+	assert.Empty(t, schedules)
+
+	schedule := &BackupSchedule{
+		Cron:      "0 3 * * *",
+		Retention: BackupRetention{KeepDaily: 7, KeepWeekly: 4},
+		CreatedAt: time.Unix(1696420902, 0).Local(),
+	}
+	require.NoError(t, dataDir.SetBackupSchedule("mock-avs-default", schedule))
+	require.NoError(t, dataDir.SetBackupSchedule("mock-avs-second", &BackupSchedule{Cron: "@weekly"}))
+	err = dataDir.SetBackupSchedule("mock-avs-third", &BackupSchedule{Cron: "invalid"})
+	assert.ErrorIs(t, err, ErrInvalidBackupSchedule)
+
+	schedules, err = dataDir.BackupSchedules()
+	require.NoError(t, err)
+	assert.Len(t, schedules, 2)
+	assert.Equal(t, schedule.Retention, schedules["mock-avs-default"].Retention)
+	assert.True(t, schedule.CreatedAt.Equal(schedules["mock-avs-default"].CreatedAt))
+
+	require.NoError(t, dataDir.RemoveBackupSchedule("mock-avs-second"))
+	err = dataDir.RemoveBackupSchedule("mock-avs-second")
+	assert.ErrorIs(t, err, ErrBackupScheduleNotFound)
+	schedules, err = dataDir.BackupSchedules()
+	require.NoError(t, err)
+	assert.Len(t, schedules, 1)
+	assert.Contains(t, schedules, "mock-avs-default")
+}
+
+func TestDataDir_StartScheduledBackup(t *testing.T) {
+	dataDir, err := NewDataDir(t.TempDir(), afero.NewOsFs(), nil)
+	require.NoError(t, err)
+	createdAt := time.Now().Add(-2 * time.Hour)
+	require.NoError(t, dataDir.SetBackupSchedule("mock-avs-default", &BackupSchedule{Cron: "@hourly", CreatedAt: createdAt}))
+	require.NoError(t, dataDir.SetBackupSchedule("mock-avs-second", &BackupSchedule{Cron: "@yearly", CreatedAt: time.Now()}))
+
+	// The schedule is changed after the schedules were read by the caller
+	schedules, err := dataDir.BackupSchedules()
+	require.NoError(t, err)
+	require.NoError(t, dataDir.SetBackupSchedule("mock-avs-default", &BackupSchedule{
+		Cron:           "@hourly",
+		PassphraseFile: "/secret",
+		Retention:      BackupRetention{KeepLast: 3},
+		CreatedAt:      createdAt,
+	}))
+	due, err := schedules["mock-avs-default"].Due(time.Now())
+	require.NoError(t, err)
+	require.True(t, due)
+
+	now := time.Now()
+	schedule, ok, err := dataDir.StartScheduledBackup("mock-avs-default", now)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, "/secret", schedule.PassphraseFile)
+	assert.Equal(t, BackupRetention{KeepLast: 3}, schedule.Retention)
+
+	schedules, err = dataDir.BackupSchedules()
+	require.NoError(t, err)
+	assert.True(t, now.Equal(schedules["mock-avs-default"].LastRun))
+	assert.Equal(t, "/secret", schedules["mock-avs-default"].PassphraseFile)
+	assert.Equal(t, BackupRetention{KeepLast: 3}, schedules["mock-avs-default"].Retention)
+
+	// Already started
+	_, ok, err = dataDir.StartScheduledBackup("mock-avs-default", now)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	// Not due
+	_, ok, err = dataDir.StartScheduledBackup("mock-avs-second", now)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	// Removed schedule
+	_, ok, err = dataDir.StartScheduledBackup("mock-avs-third", now)
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestDataDir_RemoveBackup(t *testing.T) {
+	fs := afero.NewOsFs()
+	dataDir, err := NewDataDir(t.TempDir(), fs, nil)
+	require.NoError(t, err)
+
+	volume := make([]byte, 4*1024*1024)
+	rand.New(rand.NewSource(1)).Read(volume)
+	first := Backup{InstanceId: "mock-avs-default", Timestamp: time.Unix(1696420902, 0), Version: "v5.5.0"}
+	content := initTestBackup(t, dataDir, &first, volume)
+	require.NoError(t, dataDir.PackBackup(context.Background(), &first, bytes.NewReader(content), PackOptions{}))
+	// The second backup shares most chunks with the first one
+	volume[0]++
+	second := Backup{InstanceId: "mock-avs-default", Timestamp: time.Unix(1696507302, 0), Version: "v5.5.0"}
+	content = initTestBackup(t, dataDir, &second, volume)
+	require.NoError(t, dataDir.PackBackup(context.Background(), &second, bytes.NewReader(content), PackOptions{}))
+
+	require.NoError(t, dataDir.RemoveBackup(first.Id()))
+	err = dataDir.RemoveBackup(first.Id())
+	assert.ErrorIs(t, err, ErrBackupNotFound)
+	backups, err := dataDir.BackupList()
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+	assert.Equal(t, second.Id(), backups[0].Id())
+
+	// Chunks not used by the second backup are removed
+	used := make(map[string]bool)
+	for _, chunk := range second.Chunks {
+		used[chunk.Hash] = true
+	}
+	var unused int
+	for _, chunk := range first.Chunks {
+		if !used[chunk.Hash] {
+			unused++
+		}
+	}
+	require.NotZero(t, unused)
+	removed, size, err := dataDir.RemoveUnusedBackupChunks("mock-avs-default")
+	require.NoError(t, err)
+	assert.Equal(t, unused, removed)
+	assert.Positive(t, size)
+
+	assert.Equal(t, content, readTestBackup(t, dataDir, second.Id(), ""))
+}
+
+func TestDataDir_RemoveUnusedBackupChunks_Locked(t *testing.T) {
+	dataDir, err := NewDataDir(t.TempDir(), afero.NewOsFs(), nil)
+	require.NoError(t, err)
+
+	// The chunk store is locked by a backup being stored
+	unlock, err := dataDir.lockChunks("mock-avs-default")
+	require.NoError(t, err)
+	done := make(chan error)
+	go func() {
+		_, _, err := dataDir.RemoveUnusedBackupChunks("mock-avs-default")
+		done <- err
+	}()
+	select {
+	case <-done:
+		t.Fatal("unused chunks removed while the chunk store is locked")
+	case <-time.After(100 * time.Millisecond):
+	}
+	require.NoError(t, unlock())
+	assert.NoError(t, <-done)
+}
@@ -0,0 +1,113 @@
+package data
+
+import (
+	"fmt"
+	"strings"
+)
+
+// VolumeFilter selects the volumes of the services of an instance. The
+// entries of Include and Exclude have the format <service> or
+// <service>:<volume>, where <volume> is the volume name or its mount target in
+// the service container, like "main-service:/data".
+type VolumeFilter struct {
+	// Include are the volumes to select. All the volumes are selected if it is
+	// empty.
+	Include []string `json:"include,omitempty"`
+	// Exclude are the volumes not to select, even if they are included.
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// IsZero returns true if the filter selects all the volumes.
+func (f VolumeFilter) IsZero() bool {
+	return len(f.Include) == 0 && len(f.Exclude) == 0
+}
+
+// Validate returns an error if an entry of the filter is not valid.
+func (f VolumeFilter) Validate() error {
+	for _, entry := range append(append([]string{}, f.Include...), f.Exclude...) {
+		service, volume, hasVolume := strings.Cut(entry, ":")
+		if service == "" || (hasVolume && volume == "") {
+			return fmt.Errorf("%w: %q, the format is <service> or <service>:<volume>", ErrInvalidVolumeFilter, entry)
+		}
+	}
+	return nil
+}
+
+// Match returns true if the filter selects the given volume.
+func (f VolumeFilter) Match(v BackupVolume) bool {
+	for _, entry := range f.Exclude {
+		if v.match(entry) {
+			return false
+		}
+	}
+	if len(f.Include) == 0 {
+		return true
+	}
+	for _, entry := range f.Include {
+		if v.match(entry) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckIncluded returns an error if an entry of Include does not match any of
+// the given volumes, usually a typo in a service or volume name.
+func (f VolumeFilter) CheckIncluded(volumes []BackupVolume) error {
+	for _, entry := range f.Include {
+		found := false
+		for _, v := range volumes {
+			if v.match(entry) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%w: %q does not match any volume", ErrInvalidVolumeFilter, entry)
+		}
+	}
+	return nil
+}
+
+// BackupVolume is a volume of a service of an instance.
+type BackupVolume struct {
+	Service string `json:"service"`
+	// Source is the volume name, or the host path of bind mounts.
+	Source string `json:"source,omitempty"`
+	// Target is the mount path of the volume in the service container.
+	Target string `json:"target"`
+}
+
+func (v BackupVolume) match(entry string) bool {
+	service, volume, hasVolume := strings.Cut(entry, ":")
+	if service != v.Service {
+		return false
+	}
+	return !hasVolume || volume == v.Source || volume == v.Target
+}
+
+// BackupSelection is the content of a partial backup.
+type BackupSelection struct {
+	// Filter is the filter of the volumes of the backup.
+	Filter VolumeFilter `json:"filter"`
+	// ConfigOnly is true if the backup only includes the instance data, and
+	// no volumes.
+	ConfigOnly bool `json:"config_only,omitempty"`
+	// Volumes are the volumes included in the backup.
+	Volumes []BackupVolume `json:"volumes"`
+}
+
+// HasVolume returns true if the backup includes the volume of the given
+// service mounted at the given target. Backups that are not partial include
+// all the volumes.
+func (b *Backup) HasVolume(service, target string) bool {
+	if b.Selection == nil {
+		return true
+	}
+	for _, v := range b.Selection.Volumes {
+		if v.Service == service && v.Target == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,126 @@
+package data
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVolumeFilter_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  VolumeFilter
+		wantErr bool
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name:   "services and volumes",
+			filter: VolumeFilter{Include: []string{"main-service", "main-service:/data"}, Exclude: []string{"main-service:mock-avs-data"}},
+		},
+		{
+			name:    "empty service",
+			filter:  VolumeFilter{Include: []string{":/data"}},
+			wantErr: true,
+		},
+		{
+			name:    "empty volume",
+			filter:  VolumeFilter{Exclude: []string{"main-service:"}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.filter.Validate()
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidVolumeFilter)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestVolumeFilter_Match(t *testing.T) {
+	data := BackupVolume{Service: "main-service", Source: "mock-avs-data", Target: "/data"}
+	tmp := BackupVolume{Service: "main-service", Source: "/tmp/mock-avs", Target: "/tmp"}
+	other := BackupVolume{Service: "option-returner", Source: "option-returner-data", Target: "/data"}
+	volumes := []BackupVolume{data, tmp, other}
+
+	tests := []struct {
+		name   string
+		filter VolumeFilter
+		want   []BackupVolume
+	}{
+		{
+			name: "empty filter",
+			want: volumes,
+		},
+		{
+			name:   "include service",
+			filter: VolumeFilter{Include: []string{"main-service"}},
+			want:   []BackupVolume{data, tmp},
+		},
+		{
+			name:   "include volume by target",
+			filter: VolumeFilter{Include: []string{"main-service:/data"}},
+			want:   []BackupVolume{data},
+		},
+		{
+			name:   "include volume by name",
+			filter: VolumeFilter{Include: []string{"option-returner:option-returner-data"}},
+			want:   []BackupVolume{other},
+		},
+		{
+			name:   "exclude volume",
+			filter: VolumeFilter{Exclude: []string{"main-service:/tmp/mock-avs"}},
+			want:   []BackupVolume{data, other},
+		},
+		{
+			name:   "exclude wins over include",
+			filter: VolumeFilter{Include: []string{"main-service"}, Exclude: []string{"main-service:/data"}},
+			want:   []BackupVolume{tmp},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []BackupVolume
+			for _, v := range volumes {
+				if tt.filter.Match(v) {
+					got = append(got, v)
+				}
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestVolumeFilter_CheckIncluded(t *testing.T) {
+	volumes := []BackupVolume{{Service: "main-service", Source: "mock-avs-data", Target: "/data"}}
+
+	assert.NoError(t, VolumeFilter{Include: []string{"main-service", "main-service:mock-avs-data"}}.CheckIncluded(volumes))
+	// Excluded volumes are not checked
+	assert.NoError(t, VolumeFilter{Exclude: []string{"option-returner"}}.CheckIncluded(volumes))
+	err := VolumeFilter{Include: []string{"main-service:/tmp"}}.CheckIncluded(volumes)
+	assert.ErrorIs(t, err, ErrInvalidVolumeFilter)
+}
+
+func TestBackup_HasVolume(t *testing.T) {
+	full := Backup{InstanceId: "mock-avs-default"}
+	assert.True(t, full.HasVolume("main-service", "/data"))
+
+	partial := Backup{
+		InstanceId: "mock-avs-default",
+		Selection: &BackupSelection{
+			Filter:  VolumeFilter{Include: []string{"main-service:/data"}},
+			Volumes: []BackupVolume{{Service: "main-service", Source: "mock-avs-data", Target: "/data"}},
+		},
+	}
+	assert.True(t, partial.HasVolume("main-service", "/data"))
+	assert.False(t, partial.HasVolume("main-service", "/tmp"))
+	assert.False(t, partial.HasVolume("option-returner", "/data"))
+
+	configOnly := Backup{InstanceId: "mock-avs-default", Selection: &BackupSelection{ConfigOnly: true}}
+	assert.False(t, configOnly.HasVolume("main-service", "/data"))
+}
@@ -22,6 +22,13 @@ func TestBackupId(t *testing.T) {
 	assert.Equal(t, b.Id(), "33de69fe9225b95c8fb909cb418e5102970c8d73")
 }
 
+func TestValidateBackupConsistency(t *testing.T) {
+	for _, consistency := range []string{"", BackupConsistencyStop, BackupConsistencyPause} {
+		assert.NoError(t, ValidateBackupConsistency(consistency), consistency)
+	}
+	assert.ErrorIs(t, ValidateBackupConsistency("snapshot"), ErrInvalidBackupConsistency)
+}
+
 func TestParseBackupName(t *testing.T) {
 	tc := []struct {
 		name       string
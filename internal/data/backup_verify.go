@@ -0,0 +1,327 @@
+package data
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+const (
+	// backupChecksumsFile is the file of the backup tar with the SHA-256
+	// checksums of the other files of the tar, by file name.
+	backupChecksumsFile = "checksums.json"
+	// backupVolumesDataFile is the file with the volumes of each service in
+	// the backup tar.
+	backupVolumesDataFile = "volumes-data.yml"
+)
+
+// BackupVerification is the result of the verification of a backup.
+type BackupVerification struct {
+	// Files is the number of files in the backup.
+	Files int
+	// Checksums is true if the checksums of the files were verified. Backups
+	// created before checksums were recorded do not have them.
+	Checksums bool
+}
+
+// BackupTarWriter writes the tar stream of a backup, recording the SHA-256
+// checksums of its files. The checksums are added to the tar by Close, to be
+// checked by VerifyBackup.
+type BackupTarWriter struct {
+	fs        afero.Fs
+	tw        *tar.Writer
+	checksums map[string]string
+	// name is the name of the regular file being written, and hash its
+	// running checksum.
+	name string
+	hash hash.Hash
+}
+
+// NewBackupTarWriter returns a BackupTarWriter that writes the tar stream of a
+// backup to w. Directories are added from the file system of the data dir.
+func (d *DataDir) NewBackupTarWriter(w io.Writer) *BackupTarWriter {
+	return &BackupTarWriter{
+		fs:        d.fs,
+		tw:        tar.NewWriter(w),
+		checksums: make(map[string]string),
+	}
+}
+
+// WriteHeader writes the given header and prepares to write the content of
+// the entry with Write, like tar.Writer.WriteHeader.
+func (w *BackupTarWriter) WriteHeader(h *tar.Header) error {
+	w.sumFile()
+	if err := w.tw.WriteHeader(h); err != nil {
+		return err
+	}
+	if h.Typeflag == tar.TypeReg {
+		w.name, w.hash = h.Name, sha256.New()
+	}
+	return nil
+}
+
+// Write writes the content of the current entry of the tar.
+func (w *BackupTarWriter) Write(p []byte) (int, error) {
+	n, err := w.tw.Write(p)
+	if w.hash != nil {
+		w.hash.Write(p[:n])
+	}
+	return n, err
+}
+
+// AddDir adds the directory at src, with its content, to the tar as name.
+// Only directories and regular files are supported.
+func (w *BackupTarWriter) AddDir(src, name string) error {
+	return afero.Walk(w.fs, src, func(filePath string, info fs.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, filePath)
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && !info.Mode().IsRegular() {
+			return fmt.Errorf("unsupported file type of %s", filePath)
+		}
+		h, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		h.Name = path.Join(name, filepath.ToSlash(rel))
+		if err := w.WriteHeader(h); err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		f, err := w.fs.Open(filePath)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(w, f)
+		return err
+	})
+}
+
+// AddFile adds a regular file with the given name and content to the tar.
+func (w *BackupTarWriter) AddFile(name string, content []byte) error {
+	err := w.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     0o644,
+		Size:     int64(len(content)),
+		ModTime:  time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(content)
+	return err
+}
+
+// Close adds the checksums of the files to the tar and writes the end of the
+// tar. The underlying writer is not closed.
+func (w *BackupTarWriter) Close() error {
+	w.sumFile()
+	data, err := json.Marshal(w.checksums)
+	if err != nil {
+		return err
+	}
+	if err := w.AddFile(backupChecksumsFile, data); err != nil {
+		return err
+	}
+	w.sumFile()
+	return w.tw.Close()
+}
+
+// sumFile records the checksum of the regular file being written, if any.
+func (w *BackupTarWriter) sumFile() {
+	if w.hash != nil {
+		w.checksums[w.name] = hex.EncodeToString(w.hash.Sum(nil))
+		w.name, w.hash = "", nil
+	}
+}
+
+// VerifyBackup checks that the backup with the given id is restorable. Chunked
+// backups are read checking the hash of each chunk, decrypting them with
+// the given passphrase if the backup is encrypted. Then the structure of the
+// backup tar and the checksums of its files, if the backup has them, are
+// checked, and the state of the instance is validated. If the backup is
+// corrupted, an ErrCorruptedBackup or ErrInvalidBackupChunk error is returned.
+func (d *DataDir) VerifyBackup(backupId, passphrase string) (*BackupVerification, error) {
+	b, err := d.Backup(backupId)
+	if err != nil {
+		return nil, err
+	}
+	r, err := d.OpenBackup(context.Background(), backupId, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	var (
+		sums          = make(map[string]string)
+		checksums     map[string]string
+		stateData     []byte
+		timestampData []byte
+		services      = make(map[string]bool)
+	)
+	err = walkBackupTar(r, func(h *tar.Header, r io.Reader) error {
+		service, err := checkBackupTarName(h.Name)
+		if err != nil {
+			return err
+		}
+		if service != "" && !services[service] {
+			// Not found the volumes data of the service yet
+			services[service] = false
+		}
+		if h.Typeflag != tar.TypeReg {
+			return nil
+		}
+		switch h.Name {
+		case backupChecksumsFile:
+			data, err := io.ReadAll(r)
+			if err != nil {
+				return err
+			}
+			if err := json.Unmarshal(data, &checksums); err != nil {
+				return fmt.Errorf("%w: invalid %s: %s", ErrCorruptedBackup, backupChecksumsFile, err)
+			}
+			return nil
+		case "data/state.json":
+			if stateData, err = io.ReadAll(r); err != nil {
+				return err
+			}
+			sums[h.Name], _ = sha256Sum(bytes.NewReader(stateData))
+			return nil
+		case "timestamp":
+			if timestampData, err = io.ReadAll(r); err != nil {
+				return err
+			}
+			sums[h.Name], _ = sha256Sum(bytes.NewReader(timestampData))
+			return nil
+		}
+		if service != "" && h.Name == path.Join("volumes", service, backupVolumesDataFile) {
+			services[service] = true
+		}
+		sums[h.Name], err = sha256Sum(r)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Check the structure
+	for service, ok := range services {
+		if !ok {
+			return nil, fmt.Errorf("%w: volumes of service %s without %s", ErrCorruptedBackup, service, backupVolumesDataFile)
+		}
+	}
+	if timestampData == nil {
+		return nil, fmt.Errorf("%w: timestamp not found", ErrCorruptedBackup)
+	}
+	timestamp, err := strconv.ParseInt(string(timestampData), 10, 64)
+	if err != nil || timestamp != b.Timestamp.Unix() {
+		return nil, fmt.Errorf("%w: timestamp %q does not match the backup", ErrCorruptedBackup, timestampData)
+	}
+	if stateData == nil {
+		return nil, fmt.Errorf("%w: data/state.json not found", ErrCorruptedBackup)
+	}
+	var instance Instance
+	if err := json.Unmarshal(stateData, &instance); err != nil {
+		return nil, fmt.Errorf("%w: invalid state.json: %s", ErrCorruptedBackup, err)
+	}
+	if err := instance.validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCorruptedBackup, err)
+	}
+	if instance.ID() != b.InstanceId {
+		return nil, fmt.Errorf("%w: state.json of instance %s instead of %s", ErrCorruptedBackup, instance.ID(), b.InstanceId)
+	}
+
+	// Check the checksums
+	if checksums != nil {
+		for name, sum := range sums {
+			if checksums[name] == "" {
+				return nil, fmt.Errorf("%w: %s has no checksum", ErrCorruptedBackup, name)
+			}
+			if checksums[name] != sum {
+				return nil, fmt.Errorf("%w: %s: checksum mismatch", ErrCorruptedBackup, name)
+			}
+		}
+		for name := range checksums {
+			if _, ok := sums[name]; !ok {
+				return nil, fmt.Errorf("%w: %s not found", ErrCorruptedBackup, name)
+			}
+		}
+	}
+	return &BackupVerification{Files: len(sums), Checksums: checksums != nil}, nil
+}
+
+// checkBackupTarName returns an error if the given name of a backup tar entry
+// is not a name written by backups: the timestamp and the checksums files, the
+// instance data in data, and the volumes of each service in
+// volumes/<service>. The service is returned for volume entries.
+func checkBackupTarName(name string) (service string, err error) {
+	clean := path.Clean(name)
+	if path.IsAbs(name) || clean != strings.TrimSuffix(name, "/") || clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", fmt.Errorf("%w: invalid file name %q", ErrCorruptedBackup, name)
+	}
+	first, rest, _ := strings.Cut(clean, "/")
+	switch first {
+	case "timestamp", backupChecksumsFile:
+		if rest == "" {
+			return "", nil
+		}
+	case "data":
+		return "", nil
+	case "volumes":
+		if rest == "" {
+			return "", nil
+		}
+		service, _, _ = strings.Cut(rest, "/")
+		return service, nil
+	}
+	return "", fmt.Errorf("%w: unexpected file %q", ErrCorruptedBackup, name)
+}
+
+// walkBackupTar calls fn for each entry of the tar stream read from r, with a
+// reader of the entry content.
+func walkBackupTar(r io.Reader, fn func(h *tar.Header, r io.Reader) error) error {
+	tr := tar.NewReader(r)
+	for {
+		h, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrCorruptedBackup, err)
+		}
+		if err := fn(h, tr); err != nil {
+			return err
+		}
+	}
+}
+
+func sha256Sum(r io.Reader) (string, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,240 @@
+package data
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testTarFile struct {
+	name    string
+	content string
+}
+
+// initTestBackupTar initializes a backup stored as a single tar file with the
+// given files, and returns its id.
+func initTestBackupTar(t *testing.T, dataDir *DataDir, files []testTarFile) string {
+	t.Helper()
+	var buf bytes.Buffer
+	tarWriter := tar.NewWriter(&buf)
+	writeTestTarFiles(t, tarWriter, files)
+	require.NoError(t, tarWriter.Close())
+	return writeTestBackupTar(t, dataDir, buf.Bytes())
+}
+
+// writeTestBackupTar initializes a backup stored as a single tar file with the
+// given content, and returns its id.
+func writeTestBackupTar(t *testing.T, dataDir *DataDir, content []byte) string {
+	t.Helper()
+	b := &Backup{InstanceId: "mock-avs-default", Timestamp: time.Unix(1696367916, 0), Version: "v5.5.0"}
+	require.NoError(t, dataDir.InitBackup(b))
+	require.NoError(t, os.WriteFile(dataDir.BackupPath(b.Id()), content, 0o644))
+	return b.Id()
+}
+
+func writeTestTarFiles(t *testing.T, tw tarWriter, files []testTarFile) {
+	t.Helper()
+	for _, f := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: f.name, Size: int64(len(f.content)), Mode: 0o644, Typeflag: tar.TypeReg}))
+		_, err := tw.Write([]byte(f.content))
+		require.NoError(t, err)
+	}
+}
+
+// tarWriter writes the entries of a tar, like tar.Writer and BackupTarWriter.
+type tarWriter interface {
+	io.Writer
+	WriteHeader(h *tar.Header) error
+}
+
+// packTestBackupTar stores the backup with the given id, stored as a single
+// tar file, in chunks.
+func packTestBackupTar(t *testing.T, dataDir *DataDir, backupId string, options PackOptions) {
+	t.Helper()
+	b, err := dataDir.Backup(backupId)
+	require.NoError(t, err)
+	tarFile, err := os.Open(dataDir.BackupPath(backupId))
+	require.NoError(t, err)
+	defer tarFile.Close()
+	require.NoError(t, dataDir.PackBackup(context.Background(), b, tarFile, options))
+	require.NoError(t, os.Remove(dataDir.BackupPath(backupId)))
+}
+
+func TestDataDir_VerifyBackup(t *testing.T) {
+	state := testTarFile{"data/state.json", `{"name": "mock-avs", "url": "https://github.com/NethermindEth/mock-avs-pkg", "version": "v5.5.0", "profile": "option-returner", "tag": "default"}`}
+	timestamp := testTarFile{"timestamp", strconv.FormatInt(1696367916, 10)}
+	volumes := []testTarFile{
+		{"volumes/main-service/volumes-data.yml", "- id: data\n"},
+		{"volumes/main-service/data/file", "volume data"},
+	}
+	files := append([]testTarFile{state, timestamp}, volumes...)
+
+	tests := []struct {
+		name          string
+		files         []testTarFile
+		checksums     bool
+		appended      []testTarFile
+		pack          bool
+		wantFiles     int
+		wantChecksums bool
+		wantErr       error
+	}{
+		{
+			name:          "valid backup",
+			files:         files,
+			checksums:     true,
+			wantFiles:     4,
+			wantChecksums: true,
+		},
+		{
+			name:          "valid chunked backup",
+			files:         files,
+			checksums:     true,
+			pack:          true,
+			wantFiles:     4,
+			wantChecksums: true,
+		},
+		{
+			name:      "backup without checksums",
+			files:     files,
+			wantFiles: 4,
+		},
+		{
+			name: "checksum mismatch",
+			files: append(append([]testTarFile{}, files...), testTarFile{backupChecksumsFile, func() string {
+				checksums := map[string]string{}
+				for _, f := range files {
+					checksums[f.name] = "0000"
+				}
+				data, _ := json.Marshal(checksums)
+				return string(data)
+			}()}),
+			wantErr: ErrCorruptedBackup,
+		},
+		{
+			name:      "file without checksum",
+			files:     files,
+			checksums: true,
+			appended:  []testTarFile{{"data/extra", "extra"}},
+			wantErr:   ErrCorruptedBackup,
+		},
+		{
+			name:    "unexpected file",
+			files:   append([]testTarFile{state, timestamp}, testTarFile{"etc/passwd", "root"}),
+			wantErr: ErrCorruptedBackup,
+		},
+		{
+			name:    "file outside the tar",
+			files:   append([]testTarFile{state, timestamp}, testTarFile{"data/../../passwd", "root"}),
+			wantErr: ErrCorruptedBackup,
+		},
+		{
+			name:    "volumes without volumes data",
+			files:   []testTarFile{state, timestamp, volumes[1]},
+			wantErr: ErrCorruptedBackup,
+		},
+		{
+			name:    "invalid state",
+			files:   []testTarFile{{"data/state.json", `{"name": "mock-avs", "version": "v5.5.0", "tag": "default"}`}, timestamp},
+			wantErr: ErrInvalidInstance,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewOsFs()
+			dataDir, err := NewDataDir(t.TempDir(), fs, nil)
+			require.NoError(t, err)
+			var backupId string
+			if tt.checksums {
+				var buf bytes.Buffer
+				tw := dataDir.NewBackupTarWriter(&buf)
+				writeTestTarFiles(t, tw, tt.files)
+				require.NoError(t, tw.Close())
+				content := buf.Bytes()
+				if len(tt.appended) > 0 {
+					// Append files after the checksums, replacing the end of the tar
+					buf.Truncate(buf.Len() - 1024)
+					tarWriter := tar.NewWriter(&buf)
+					writeTestTarFiles(t, tarWriter, tt.appended)
+					require.NoError(t, tarWriter.Close())
+					content = buf.Bytes()
+				}
+				backupId = writeTestBackupTar(t, dataDir, content)
+			} else {
+				backupId = initTestBackupTar(t, dataDir, tt.files)
+			}
+			if tt.pack {
+				packTestBackupTar(t, dataDir, backupId, PackOptions{Passphrase: "secret"})
+			}
+
+			got, err := dataDir.VerifyBackup(backupId, "secret")
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantFiles, got.Files)
+			assert.Equal(t, tt.wantChecksums, got.Checksums)
+		})
+	}
+
+	t.Run("wrong passphrase", func(t *testing.T) {
+		dataDir, err := NewDataDir(t.TempDir(), afero.NewOsFs(), nil)
+		require.NoError(t, err)
+		backupId := initTestBackupTar(t, dataDir, files)
+		packTestBackupTar(t, dataDir, backupId, PackOptions{Passphrase: "secret"})
+		_, err = dataDir.VerifyBackup(backupId, "wrong")
+		assert.ErrorIs(t, err, ErrInvalidPassphrase)
+	})
+}
+
+func TestBackupTarWriter(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	dataDir, err := NewDataDir("/data", fs, nil)
+	require.NoError(t, err)
+	require.NoError(t, afero.WriteFile(fs, "/instance/state.json", []byte(`{"name": "mock-avs"}`), 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/instance/src/.env", []byte("VAR=1"), 0o600))
+
+	var buf bytes.Buffer
+	tw := dataDir.NewBackupTarWriter(&buf)
+	require.NoError(t, tw.AddDir("/instance", "data"))
+	require.NoError(t, tw.AddFile("timestamp", []byte("1696367916")))
+	require.NoError(t, tw.Close())
+
+	contents := make(map[string]string)
+	modes := make(map[string]int64)
+	err = walkBackupTar(&buf, func(h *tar.Header, r io.Reader) error {
+		content, err := io.ReadAll(r)
+		contents[h.Name], modes[h.Name] = string(content), h.Mode&0o777
+		return err
+	})
+	require.NoError(t, err)
+	var checksums map[string]string
+	require.NoError(t, json.Unmarshal([]byte(contents[backupChecksumsFile]), &checksums))
+	delete(contents, backupChecksumsFile)
+	assert.Equal(t, map[string]string{
+		"data":            "",
+		"data/src":        "",
+		"data/src/.env":   "VAR=1",
+		"data/state.json": `{"name": "mock-avs"}`,
+		"timestamp":       "1696367916",
+	}, contents)
+	assert.Equal(t, int64(0o600), modes["data/src/.env"])
+	for _, name := range []string{"data/src/.env", "data/state.json", "timestamp"} {
+		sum, err := sha256Sum(strings.NewReader(contents[name]))
+		require.NoError(t, err)
+		assert.Equal(t, sum, checksums[name], name)
+	}
+	assert.Len(t, checksums, 3)
+}
@@ -0,0 +1,103 @@
+package data
+
+import (
+	"io"
+)
+
+const (
+	// chunkMinSize is the minimum size of a backup chunk, except the last one.
+	chunkMinSize = 512 * 1024
+	// chunkMaxSize is the maximum size of a backup chunk.
+	chunkMaxSize = 8 * 1024 * 1024
+	// chunkMask sets the average size of the backup chunks to about 2 MiB
+	// over the minimum size. The high bits of the hash are used, because they
+	// depend on more bytes of the window than the low bits.
+	chunkMask uint64 = (1<<21 - 1) << (64 - 21)
+)
+
+// gearTable is the table of random values of the gear rolling hash used to
+// find chunk boundaries. It is generated from a fixed seed, so the same data
+// is always split in the same chunks.
+var gearTable = func() (table [256]uint64) {
+	// splitmix64
+	seed := uint64(0x6567656e6c61796e)
+	for i := range table {
+		seed += 0x9e3779b97f4a7c15
+		z := seed
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		table[i] = z ^ (z >> 31)
+	}
+	return table
+}()
+
+// chunker splits a stream in content-defined chunks, using a gear rolling hash
+// to find the chunk boundaries. Inserting or removing data only changes the
+// chunks around the change, so the rest of chunks are deduplicated across
+// backups of the same data.
+type chunker struct {
+	r   io.Reader
+	buf []byte
+	// start and end are the bounds of the buffered data not returned yet.
+	start, end int
+	eof        bool
+}
+
+func newChunker(r io.Reader) *chunker {
+	return &chunker{
+		r:   r,
+		buf: make([]byte, 2*chunkMaxSize),
+	}
+}
+
+// Next returns the next chunk of the stream, or io.EOF if there are no more
+// chunks. The returned slice is only valid until the next call to Next.
+func (c *chunker) Next() ([]byte, error) {
+	if err := c.fill(); err != nil {
+		return nil, err
+	}
+	if c.start == c.end {
+		return nil, io.EOF
+	}
+	data := c.buf[c.start:c.end]
+	size := cut(data)
+	c.start += size
+	return data[:size], nil
+}
+
+// fill reads from the stream until there is a full chunk of buffered data or
+// the stream ends.
+func (c *chunker) fill() error {
+	if c.end-c.start >= chunkMaxSize || c.eof {
+		return nil
+	}
+	// Move the buffered data to the beginning of the buffer
+	n := copy(c.buf, c.buf[c.start:c.end])
+	c.start, c.end = 0, n
+	for c.end < len(c.buf) && !c.eof {
+		n, err := c.r.Read(c.buf[c.end:])
+		c.end += n
+		if err == io.EOF {
+			c.eof = true
+		} else if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cut returns the size of the first chunk of the given data.
+func cut(data []byte) int {
+	if len(data) <= chunkMinSize {
+		return len(data)
+	}
+	n := min(len(data), chunkMaxSize)
+	var hash uint64
+	for i := chunkMinSize; i < n; i++ {
+		hash = (hash << 1) + gearTable[data[i]]
+		if hash&chunkMask == 0 {
+			return i + 1
+		}
+	}
+	return n
+}
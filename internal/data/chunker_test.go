@@ -0,0 +1,87 @@
+package data
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func chunks(t *testing.T, data []byte) [][]byte {
+	t.Helper()
+	var out [][]byte
+	c := newChunker(bytes.NewReader(data))
+	for {
+		chunk, err := c.Next()
+		if errors.Is(err, io.EOF) {
+			return out
+		}
+		require.NoError(t, err)
+		out = append(out, bytes.Clone(chunk))
+	}
+}
+
+func TestChunker(t *testing.T) {
+	data := make([]byte, 32*1024*1024)
+	rand.New(rand.NewSource(1)).Read(data)
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{
+			name: "empty",
+			data: []byte{},
+		},
+		{
+			name: "smaller than minimum chunk size",
+			data: data[:chunkMinSize/2],
+		},
+		{
+			name: "random data",
+			data: data,
+		},
+		{
+			name: "zeros",
+			data: make([]byte, 3*chunkMaxSize+1),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := chunks(t, tt.data)
+			assert.Equal(t, tt.data, bytes.Join(got, nil), "chunks should add up to the data")
+			for i, chunk := range got {
+				assert.LessOrEqual(t, len(chunk), chunkMaxSize)
+				if i < len(got)-1 {
+					assert.GreaterOrEqual(t, len(chunk), chunkMinSize)
+				}
+			}
+		})
+	}
+}
+
+func TestChunker_Insertion(t *testing.T) {
+	data := make([]byte, 32*1024*1024)
+	rand.New(rand.NewSource(1)).Read(data)
+	// Insert some bytes in the middle of the data
+	modified := bytes.Join([][]byte{data[:len(data)/2], []byte("inserted"), data[len(data)/2:]}, nil)
+
+	original := make(map[string]bool)
+	for _, chunk := range chunks(t, data) {
+		original[string(chunk)] = true
+	}
+	got := chunks(t, modified)
+	var changed int
+	for _, chunk := range got {
+		if !original[string(chunk)] {
+			changed++
+		}
+	}
+	// Only the chunks around the insertion change
+	assert.LessOrEqual(t, changed, 2)
+	assert.Greater(t, len(got), 4)
+}
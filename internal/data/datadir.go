@@ -1,14 +1,19 @@
 package data
 
 import (
+	"archive/tar"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 
-	"github.com/NethermindEth/docker-volumes-snapshotter/pkg/backuptar"
 	"github.com/NethermindEth/eigenlayer/internal/locker"
 	"github.com/NethermindEth/eigenlayer/internal/package_handler"
+	"github.com/NethermindEth/eigenlayer/internal/storage"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/afero"
 )
@@ -24,9 +29,10 @@ const monitoringStackDirName = "monitoring"
 
 // DataDir is the directory where all the data is stored.
 type DataDir struct {
-	path   string
-	fs     afero.Fs
-	locker locker.Locker
+	path    string
+	fs      afero.Fs
+	locker  locker.Locker
+	secrets SecretStore
 }
 
 // NewDataDir creates a new DataDir instance with the given path as root.
@@ -35,7 +41,12 @@ func NewDataDir(path string, fs afero.Fs, locker locker.Locker) (*DataDir, error
 	if err != nil {
 		return nil, err
 	}
-	return &DataDir{path: absPath, fs: fs, locker: locker}, nil
+	return &DataDir{
+		path:    absPath,
+		fs:      fs,
+		locker:  locker,
+		secrets: NewFileSecretStore(fs, filepath.Join(absPath, secretsDir)),
+	}, nil
 }
 
 // Path returns the path of the data dir.
@@ -43,6 +54,19 @@ func (d *DataDir) Path() string {
 	return d.path
 }
 
+// Secrets returns the store of the instance secrets. By default, secrets are
+// encrypted in the secrets directory of the data dir, outside the instance
+// directories, so they are never included in backups.
+func (d *DataDir) Secrets() SecretStore {
+	return d.secrets
+}
+
+// SetSecretStore replaces the store of the instance secrets, for instance to
+// use an external secret backend.
+func (d *DataDir) SetSecretStore(store SecretStore) {
+	d.secrets = store
+}
+
 // NewDataDirDefault creates a new DataDir instance with the default path as root.
 // Default path is $XDG_DATA_HOME/.eigen or $HOME/.local/share/.eigen if $XDG_DATA_HOME is not set
 // as defined in the XDG Base Directory Specification
@@ -114,19 +138,136 @@ func (d *DataDir) InstancePath(instanceId string) (string, error) {
 	return instancePath, nil
 }
 
-func (d *DataDir) ReplaceInstanceDirFromTar(instanceId, tarPath, srcPath string) error {
-	// Clear instance dir
-	instancePath := filepath.Join(d.path, nodesDirName, instanceId)
-	err := d.fs.RemoveAll(instancePath)
+// ReplaceInstanceDirFromTar replaces the directory of the instance with the
+// given id with the srcPath directory of the backup tar stream read from r.
+// The directory is extracted to a temporary directory first, so the instance
+// directory is kept if the extraction fails.
+func (d *DataDir) ReplaceInstanceDirFromTar(instanceId string, r io.Reader, srcPath string) error {
+	extractPath, err := d.extractTempDir(r, srcPath)
 	if err != nil {
 		return err
 	}
-	// Create instance dir
-	err = d.fs.MkdirAll(instancePath, 0o755)
-	if err != nil {
+	defer d.fs.RemoveAll(extractPath)
+	// Replace instance dir
+	instancePath := filepath.Join(d.path, nodesDirName, instanceId)
+	if err := d.fs.RemoveAll(instancePath); err != nil {
 		return err
 	}
-	return backuptar.ExtractDir(tarPath, srcPath, instancePath)
+	if err := d.fs.MkdirAll(filepath.Dir(instancePath), 0o755); err != nil {
+		return err
+	}
+	return d.fs.Rename(extractPath, instancePath)
+}
+
+// ExtractInstanceFromTar extracts the instance data of the backup tar stream
+// read from r as a new instance with the given tag, and returns the id of the
+// new instance. The tag is replaced in the state of the instance, and the rest
+// of the instance data is kept as is. If an instance with the new id already
+// exists, an ErrInstanceAlreadyExists error is returned.
+func (d *DataDir) ExtractInstanceFromTar(r io.Reader, tag string) (string, error) {
+	extractPath, err := d.extractTempDir(r, "data")
+	if err != nil {
+		return "", err
+	}
+	defer d.fs.RemoveAll(extractPath)
+	stateData, err := afero.ReadFile(d.fs, filepath.Join(extractPath, "state.json"))
+	if err != nil {
+		return "", err
+	}
+	var instance Instance
+	if err := json.Unmarshal(stateData, &instance); err != nil {
+		return "", fmt.Errorf("%w: %s", ErrInvalidInstance, err)
+	}
+	instanceId := InstanceId(instance.Name, tag)
+	if d.HasInstance(instanceId) {
+		return "", fmt.Errorf("%w: %s", ErrInstanceAlreadyExists, instanceId)
+	}
+	instancePath := filepath.Join(d.path, nodesDirName, instanceId)
+	if err := d.fs.MkdirAll(filepath.Dir(instancePath), 0o755); err != nil {
+		return "", err
+	}
+	if err := d.fs.Rename(extractPath, instancePath); err != nil {
+		return "", err
+	}
+	// Replace only the tag, so fields unknown to this version are kept
+	statePath := filepath.Join(instancePath, "state.json")
+	var state map[string]json.RawMessage
+	if err := json.Unmarshal(stateData, &state); err != nil {
+		return "", fmt.Errorf("%w: %s", ErrInvalidInstance, err)
+	}
+	state["tag"], err = json.Marshal(tag)
+	if err != nil {
+		return "", err
+	}
+	stateData, err = json.Marshal(state)
+	if err != nil {
+		return "", err
+	}
+	return instanceId, afero.WriteFile(d.fs, statePath, stateData, 0o644)
+}
+
+// extractTempDir extracts the srcPath directory of the tar stream read from r
+// to a new temporary directory of the data dir, and returns its path. Only
+// directories and regular files are supported.
+func (d *DataDir) extractTempDir(r io.Reader, srcPath string) (string, error) {
+	tempRoot := filepath.Join(d.path, tempDir)
+	if err := d.fs.MkdirAll(tempRoot, 0o755); err != nil {
+		return "", err
+	}
+	extractPath, err := afero.TempDir(d.fs, tempRoot, "extract-")
+	if err != nil {
+		return "", err
+	}
+	if err := d.fs.Chmod(extractPath, 0o755); err != nil {
+		return "", err
+	}
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return extractPath, nil
+		}
+		if err != nil {
+			d.fs.RemoveAll(extractPath)
+			return "", err
+		}
+		relPath, ok := strings.CutPrefix(header.Name, srcPath+"/")
+		if !ok || relPath == "" {
+			continue
+		}
+		if err := extractTarEntry(d.fs, tr, header, extractPath, relPath); err != nil {
+			d.fs.RemoveAll(extractPath)
+			return "", err
+		}
+	}
+}
+
+// extractTarEntry extracts the current entry of tr, with the given header, as
+// relPath in the dst directory.
+func extractTarEntry(fs afero.Fs, tr *tar.Reader, header *tar.Header, dst, relPath string) error {
+	targetPath := filepath.Join(dst, filepath.FromSlash(relPath))
+	if !strings.HasPrefix(targetPath, dst+string(filepath.Separator)) {
+		return fmt.Errorf("%w: invalid file name %q", ErrCorruptedBackup, header.Name)
+	}
+	switch header.Typeflag {
+	case tar.TypeDir:
+		return fs.MkdirAll(targetPath, 0o755)
+	case tar.TypeReg:
+		if err := fs.MkdirAll(filepath.Dir(targetPath), 0o755); err != nil {
+			return err
+		}
+		f, err := fs.OpenFile(targetPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, header.FileInfo().Mode().Perm())
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(f, tr); err != nil {
+			f.Close()
+			return fmt.Errorf("failed to copy file %s: %w", targetPath, err)
+		}
+		return f.Close()
+	default:
+		return fmt.Errorf("unexpected typeflag %d for %s", header.Typeflag, header.Name)
+	}
 }
 
 // RemoveInstance removes the instance with the given id.
@@ -188,7 +329,7 @@ func (d *DataDir) TempPath(id string) (string, error) {
 
 // BackupList returns the list of paths to all the backups.
 func (d *DataDir) BackupList() ([]Backup, error) {
-	err := d.initBackupDir()
+	backups, err := d.chunkedBackups()
 	if err != nil {
 		return nil, err
 	}
@@ -197,10 +338,44 @@ func (d *DataDir) BackupList() ([]Backup, error) {
 		return nil, err
 	}
 
+	manifests := make(map[string]bool, len(backups))
+	for i := range backups {
+		manifests[backups[i].Id()] = true
+	}
+	for _, backupFile := range backupFiles {
+		if backupFile.IsDir() || filepath.Ext(backupFile.Name()) != ".tar" {
+			continue
+		}
+		if manifests[strings.TrimSuffix(backupFile.Name(), ".tar")] {
+			// Tar file of a chunked backup being packed or unpacked
+			continue
+		}
+		b, err := BackupFromTar(d.fs, filepath.Join(d.backupsDir(), backupFile.Name()))
+		if err != nil {
+			return nil, err
+		}
+		b.Size = backupFile.Size()
+		b.StoredSize = backupFile.Size()
+		backups = append(backups, *b)
+	}
+	return backups, nil
+}
+
+// chunkedBackups returns the list of the backups stored in chunks, loaded from
+// their manifests.
+func (d *DataDir) chunkedBackups() ([]Backup, error) {
+	err := d.initBackupDir()
+	if err != nil {
+		return nil, err
+	}
+	backupFiles, err := afero.ReadDir(d.fs, d.backupsDir())
+	if err != nil {
+		return nil, err
+	}
 	var backups []Backup
 	for _, backupFile := range backupFiles {
-		if !backupFile.IsDir() && filepath.Ext(backupFile.Name()) == ".tar" {
-			b, err := BackupFromTar(d.fs, filepath.Join(d.backupsDir(), backupFile.Name()))
+		if !backupFile.IsDir() && filepath.Ext(backupFile.Name()) == ".json" {
+			b, err := BackupFromManifest(d.fs, filepath.Join(d.backupsDir(), backupFile.Name()))
 			if err != nil {
 				return nil, err
 			}
@@ -210,15 +385,6 @@ func (d *DataDir) BackupList() ([]Backup, error) {
 	return backups, nil
 }
 
-// BackupSize returns the size in bytes of the backup with the given id.
-func (d *DataDir) BackupSize(backupId string) (int64, error) {
-	backupStat, err := d.fs.Stat(d.BackupPath(backupId))
-	if err != nil {
-		return -1, err
-	}
-	return backupStat.Size(), nil
-}
-
 // Backup returns the backup with the given id. If the backup does not exist,
 // an ErrBackupNotFound error is returned.
 func (d *DataDir) Backup(backupId string) (*Backup, error) {
@@ -236,14 +402,13 @@ func (d *DataDir) Backup(backupId string) (*Backup, error) {
 
 // HasBackup returns true if the backup with the given id exists.
 func (d *DataDir) HasBackup(backupId string) (bool, error) {
-	_, err := d.fs.Stat(d.BackupPath(backupId))
-	if err != nil {
-		if os.IsNotExist(err) {
-			return false, nil
+	for _, path := range []string{d.backupManifestPath(backupId), d.BackupPath(backupId)} {
+		ok, err := afero.Exists(d.fs, path)
+		if err != nil || ok {
+			return ok, err
 		}
-		return false, err
 	}
-	return true, nil
+	return false, nil
 }
 
 // BackupPath returns the path to the backup with the given id.
@@ -251,8 +416,9 @@ func (d *DataDir) BackupPath(backupId string) string {
 	return filepath.Join(d.path, backupDir, backupId+".tar")
 }
 
-// InitBackup initialized a new backup. If a backup with the same id already
-// exists, an ErrBackupAlreadyExists error is returned.
+// InitBackup initialized a new backup, to be stored with PackBackup. If a
+// backup with the same id already exists, an ErrBackupAlreadyExists error is
+// returned.
 func (d *DataDir) InitBackup(b *Backup) error {
 	// Check if backup already exists
 	exists, err := d.HasBackup(b.Id())
@@ -263,13 +429,61 @@ func (d *DataDir) InitBackup(b *Backup) error {
 		return fmt.Errorf("%w: %s", ErrBackupAlreadyExists, b.Id())
 	}
 	// Create backup directory if it does not exist
-	err = d.initBackupDir()
+	return d.initBackupDir()
+}
+
+// ImportBackup imports the backup tar file at the given path, like a backup
+// created by another host, and stores it in chunks like the local backups. The
+// tar file is validated with BackupFromTar, and it is not modified. If the
+// backup already exists, an ErrBackupAlreadyExists error is returned.
+func (d *DataDir) ImportBackup(src string, options PackOptions) (*Backup, error) {
+	b, err := BackupFromTar(d.fs, src)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.InitBackup(b); err != nil {
+		return nil, err
+	}
+	srcFile, err := d.fs.Open(src)
+	if err != nil {
+		return nil, err
+	}
+	defer srcFile.Close()
+	if err := d.PackBackup(context.Background(), b, srcFile, options); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// ExportBackup writes the backup with the given ID as a single tar file at the
+// given path, which can be imported with ImportBackup on another host. The tar
+// is read from the backup chunks, decrypted with the given passphrase if the
+// backup is encrypted. If the export fails, the file at the given path is
+// removed.
+func (d *DataDir) ExportBackup(ctx context.Context, backupId, passphrase, dst string) error {
+	r, err := d.OpenBackup(ctx, backupId, passphrase)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	dstFile, err := d.fs.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
 	if err != nil {
 		return err
 	}
-	// Initialize backup tar file
-	return backuptar.InitBackupTar(d.BackupPath(b.Id()))
-	// return utils.TarInit(d.fs, d.BackupPath(b.Id()))
+	_, err = io.Copy(dstFile, r)
+	if closeErr := dstFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		d.fs.Remove(dst)
+	}
+	return err
+}
+
+// BackupStore returns the store of the local backups, with the same layout as
+// the remote backup stores.
+func (d *DataDir) BackupStore() storage.BackupStore {
+	return storage.NewLocalStore(d.fs, d.backupsDir())
 }
 
 func (d *DataDir) backupsDir() string {
@@ -2,6 +2,8 @@ package data
 
 import (
 	"archive/tar"
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
@@ -52,9 +54,10 @@ func TestNewDataDir(t *testing.T) {
 				name: "path to absolute",
 				path: testDir,
 				dataDir: &DataDir{
-					path:   absPath,
-					fs:     fs,
-					locker: locker,
+					path:    absPath,
+					fs:      fs,
+					locker:  locker,
+					secrets: NewFileSecretStore(fs, filepath.Join(absPath, secretsDir)),
 				},
 				locker: locker,
 				err:    nil,
@@ -903,10 +906,13 @@ func TestDataDir_InitBackup(t *testing.T) {
 				assert.ErrorIs(t, err, tt.err)
 			} else {
 				require.NoError(t, err)
-				bStat, err := d.fs.Stat(d.BackupPath(backup.Id()))
+				ok, err := afero.DirExists(d.fs, d.backupsDir())
+				require.NoError(t, err)
+				assert.True(t, ok)
+				// The backup is stored by PackBackup
+				ok, err = d.HasBackup(backup.Id())
 				require.NoError(t, err)
-				require.Equal(t, bStat.Mode(), os.FileMode(0o644))
-				require.Equal(t, bStat.Size(), int64(1024))
+				assert.False(t, ok)
 			}
 		})
 	}
@@ -1059,13 +1065,18 @@ func TestDataDir_BackupList(t *testing.T) {
 				err = dataDir.InitBackup(&d.backup)
 				require.NoError(t, err)
 				backupTarPath := dataDir.BackupPath(d.backup.Id())
-				backupTarFile, err := fs.OpenFile(backupTarPath, os.O_WRONLY, 0o644)
+				backupTarFile, err := fs.Create(backupTarPath)
 				require.NoError(t, err)
 				tarWriter := tar.NewWriter(backupTarFile)
 				tarAddStateJson(t, tarWriter, d.state)
 				tarAddTimestamp(t, tarWriter, d.timestamp)
 				err = tarWriter.Close()
 				require.NoError(t, err)
+				// Backups stored as a single tar file are not compressed
+				tarStat, err := fs.Stat(backupTarPath)
+				require.NoError(t, err)
+				d.backup.Size = tarStat.Size()
+				d.backup.StoredSize = tarStat.Size()
 				backups = append(backups, d.backup)
 			}
 
@@ -1159,3 +1170,134 @@ func tarAddTimestamp(t *testing.T, tarWriter *tar.Writer, timestamp time.Time) {
 	_, err = tarWriter.Write([]byte(data))
 	require.NoError(t, err)
 }
+
+// newTestBackupTar creates a backup tar file in the given directory with the
+// given state.json and timestamp, and returns its path.
+func newTestBackupTar(t *testing.T, dir string, state []byte, timestamp time.Time) string {
+	t.Helper()
+	tarPath := filepath.Join(dir, "backup.tar")
+	tarFile, err := os.Create(tarPath)
+	require.NoError(t, err)
+	defer tarFile.Close()
+	tarWriter := tar.NewWriter(tarFile)
+	tarAddStateJson(t, tarWriter, state)
+	tarAddTimestamp(t, tarWriter, timestamp)
+	require.NoError(t, tarWriter.Close())
+	return tarPath
+}
+
+const testBackupState = `{"name": "mock-avs", "url": "https://github.com/NethermindEth/mock-avs-pkg", "version": "v5.5.0", "spec_version": "v0.1.0", "profile": "option-returner", "tag": "default", "future_field": {"enabled": true}}`
+
+func TestDataDir_ExtractInstanceFromTar(t *testing.T) {
+	fs := afero.NewOsFs()
+	dataDirPath := t.TempDir()
+	dataDir, err := NewDataDir(dataDirPath, fs, nil)
+	require.NoError(t, err)
+	tarPath := newTestBackupTar(t, t.TempDir(), []byte(testBackupState), time.Unix(1696367916, 0))
+	extract := func() (string, error) {
+		tarFile, err := os.Open(tarPath)
+		require.NoError(t, err)
+		defer tarFile.Close()
+		return dataDir.ExtractInstanceFromTar(tarFile, "restored")
+	}
+
+	instanceId, err := extract()
+	require.NoError(t, err)
+	assert.Equal(t, "mock-avs-restored", instanceId)
+	assert.True(t, dataDir.HasInstance("mock-avs-restored"))
+	assert.False(t, dataDir.HasInstance("mock-avs-default"))
+
+	stateData, err := os.ReadFile(filepath.Join(dataDirPath, nodesDirName, instanceId, "state.json"))
+	require.NoError(t, err)
+	var state map[string]any
+	require.NoError(t, json.Unmarshal(stateData, &state))
+	assert.Equal(t, "restored", state["tag"])
+	assert.Equal(t, "option-returner", state["profile"])
+	assert.Equal(t, map[string]any{"enabled": true}, state["future_field"])
+
+	_, err = extract()
+	assert.ErrorIs(t, err, ErrInstanceAlreadyExists)
+	// The extracted files are removed
+	entries, err := os.ReadDir(filepath.Join(dataDirPath, tempDir))
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestDataDir_ImportBackup(t *testing.T) {
+	fs := afero.NewOsFs()
+	dataDir, err := NewDataDir(t.TempDir(), fs, nil)
+	require.NoError(t, err)
+	timestamp := time.Unix(1696367916, 0)
+	tarPath := newTestBackupTar(t, t.TempDir(), []byte(testBackupState), timestamp)
+	content, err := os.ReadFile(tarPath)
+	require.NoError(t, err)
+
+	b, err := dataDir.ImportBackup(tarPath, PackOptions{Compression: CompressionGzip})
+	require.NoError(t, err)
+	assert.Equal(t, "mock-avs-default", b.InstanceId)
+	assert.True(t, timestamp.Equal(b.Timestamp))
+	assert.Equal(t, CompressionGzip, b.Compression)
+	// The imported tar file is kept
+	_, err = os.Stat(tarPath)
+	require.NoError(t, err)
+
+	backups, err := dataDir.BackupList()
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+	assert.Equal(t, b.Id(), backups[0].Id())
+	assert.Equal(t, content, readTestBackup(t, dataDir, b.Id(), ""))
+
+	_, err = dataDir.ImportBackup(tarPath, PackOptions{})
+	assert.ErrorIs(t, err, ErrBackupAlreadyExists)
+
+	invalid := filepath.Join(t.TempDir(), "invalid.tar")
+	require.NoError(t, os.WriteFile(invalid, []byte("not a tar file"), 0o644))
+	_, err = dataDir.ImportBackup(invalid, PackOptions{})
+	assert.Error(t, err)
+	backups, err = dataDir.BackupList()
+	require.NoError(t, err)
+	assert.Len(t, backups, 1)
+}
+
+func TestDataDir_ExportBackup(t *testing.T) {
+	fs := afero.NewOsFs()
+	dataDir, err := NewDataDir(t.TempDir(), fs, nil)
+	require.NoError(t, err)
+	tarPath := newTestBackupTar(t, t.TempDir(), []byte(testBackupState), time.Unix(1696367916, 0))
+	content, err := os.ReadFile(tarPath)
+	require.NoError(t, err)
+	b, err := dataDir.ImportBackup(tarPath, PackOptions{Passphrase: "secret passphrase"})
+	require.NoError(t, err)
+
+	exported := filepath.Join(t.TempDir(), "backup.tar")
+	err = dataDir.ExportBackup(context.Background(), b.Id(), "", exported)
+	assert.ErrorIs(t, err, ErrBackupEncrypted)
+	_, err = os.Stat(exported)
+	assert.True(t, os.IsNotExist(err))
+
+	require.NoError(t, dataDir.ExportBackup(context.Background(), b.Id(), "secret passphrase", exported))
+	got, err := os.ReadFile(exported)
+	require.NoError(t, err)
+	assert.Equal(t, content, got)
+	// The tar of the backup is not written in the data dir
+	_, err = os.Stat(dataDir.BackupPath(b.Id()))
+	assert.True(t, os.IsNotExist(err))
+
+	// The exported tar file can be imported on another host
+	other, err := NewDataDir(t.TempDir(), fs, nil)
+	require.NoError(t, err)
+	imported, err := other.ImportBackup(exported, PackOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, b.Id(), imported.Id())
+
+	err = dataDir.ExportBackup(context.Background(), "mock-avs-default-0", "", exported)
+	assert.ErrorIs(t, err, ErrBackupNotFound)
+
+	// The partial tar file of a corrupted backup is removed
+	require.NoError(t, afero.WriteFile(fs, dataDir.chunkPath(b, b.Chunks[0].Hash), []byte("corrupted"), 0o644))
+	corrupted := filepath.Join(t.TempDir(), "corrupted.tar")
+	err = dataDir.ExportBackup(context.Background(), b.Id(), "secret passphrase", corrupted)
+	assert.ErrorIs(t, err, ErrInvalidBackupChunk)
+	_, err = os.Stat(corrupted)
+	assert.True(t, os.IsNotExist(err))
+}
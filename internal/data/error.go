@@ -1,6 +1,11 @@
 package data
 
-import "errors"
+import (
+	"errors"
+	"strings"
+
+	"github.com/NethermindEth/eigenlayer/internal/profile"
+)
 
 var (
 	ErrInstanceAlreadyExists       = errors.New("instance already exists")
@@ -18,4 +23,36 @@ var (
 	ErrCreatingBackup              = errors.New("failed creating backup")
 	ErrInvalidBackupName           = errors.New("invalid backup name")
 	ErrBackupNotFound              = errors.New("backup not found")
+	ErrInvalidBackupManifest       = errors.New("invalid backup manifest")
+	ErrInvalidBackupChunk          = errors.New("invalid backup chunk")
+	ErrCorruptedBackup             = errors.New("corrupted backup")
+	ErrInvalidCompression          = errors.New("invalid compression")
+	ErrInvalidBackupConsistency    = errors.New("invalid backup consistency mode")
+	ErrInvalidBackupSchedule       = errors.New("invalid backup schedule")
+	ErrBackupScheduleNotFound      = errors.New("backup schedule not found")
+	ErrInvalidVolumeFilter         = errors.New("invalid volume filter")
+	ErrBackupEncrypted             = errors.New("backup is encrypted, a passphrase is required")
+	ErrInvalidPassphrase           = errors.New("invalid backup passphrase")
+	ErrInvalidSecrets              = errors.New("invalid secrets")
+	ErrSharedResources             = errors.New("resources shared with other instances")
+	ErrMissingSecrets              = errors.New("secrets without value")
 )
+
+// MissingSecretsError is returned when the active secret options of an
+// instance have no value, like when a backup is restored on another host, as
+// secrets are not included in backups.
+type MissingSecretsError struct {
+	Options []profile.Option
+}
+
+func (e MissingSecretsError) Error() string {
+	names := make([]string, len(e.Options))
+	for i, o := range e.Options {
+		names[i] = o.Name
+	}
+	return ErrMissingSecrets.Error() + ": " + strings.Join(names, ", ")
+}
+
+func (e MissingSecretsError) Unwrap() error {
+	return ErrMissingSecrets
+}
@@ -36,6 +36,8 @@ type Instance struct {
 	MonitoringTargets MonitoringTargets `json:"monitoring"`
 	APITarget         *APITarget        `json:"api,omitempty"`
 	Plugin            *Plugin           `json:"plugin,omitempty"`
+	Provenance        *Provenance       `json:"provenance,omitempty"`
+	HealthChecks      []HealthCheck     `json:"health_checks,omitempty"`
 	path              string
 	fs                afero.Fs
 	locker            locker.Locker
@@ -45,6 +47,15 @@ func (i *Instance) ID() string {
 	return InstanceId(i.Name, i.Tag)
 }
 
+// Provenance is the origin of an instance installed from a local package tarball.
+type Provenance struct {
+	// Digest is the digest of the package tarball, with format sha256:<hex>.
+	Digest string `json:"digest"`
+	// SignedBy is the fingerprint of the trusted key that signed the package
+	// tarball. It is empty if the signature was not verified.
+	SignedBy string `json:"signed_by,omitempty"`
+}
+
 type MonitoringTargets struct {
 	Targets []MonitoringTarget `json:"targets"`
 }
@@ -53,6 +64,38 @@ type MonitoringTarget struct {
 	Service string `json:"service"`
 	Port    string `json:"port"`
 	Path    string `json:"path"`
+	ScrapeOptions
+}
+
+// ScrapeOptions are the optional settings used by the monitoring stack to
+// scrape a monitoring target.
+type ScrapeOptions struct {
+	ScrapeInterval       string            `json:"scrape_interval,omitempty"`
+	ScrapeTimeout        string            `json:"scrape_timeout,omitempty"`
+	Scheme               string            `json:"scheme,omitempty"`
+	Labels               map[string]string `json:"labels,omitempty"`
+	TLSConfig            *TLSConfig        `json:"tls_config,omitempty"`
+	BasicAuth            *BasicAuth        `json:"basic_auth,omitempty"`
+	MetricRelabelConfigs []RelabelConfig   `json:"metric_relabel_configs,omitempty"`
+}
+
+type TLSConfig struct {
+	ServerName         string `json:"server_name,omitempty"`
+	InsecureSkipVerify bool   `json:"insecure_skip_verify,omitempty"`
+}
+
+type BasicAuth struct {
+	Username string `json:"username"`
+	Password string `json:"password,omitempty"`
+}
+
+type RelabelConfig struct {
+	SourceLabels []string `json:"source_labels,omitempty"`
+	Separator    string   `json:"separator,omitempty"`
+	Regex        string   `json:"regex,omitempty"`
+	TargetLabel  string   `json:"target_label,omitempty"`
+	Replacement  string   `json:"replacement,omitempty"`
+	Action       string   `json:"action,omitempty"`
 }
 
 type APITarget struct {
@@ -64,6 +107,19 @@ type Plugin struct {
 	Image string `json:"image"`
 }
 
+// HealthCheck is a health check of a service of the instance. See
+// profile.HealthCheck for the meaning of its fields.
+type HealthCheck struct {
+	Name           string   `json:"name"`
+	Service        string   `json:"service"`
+	Type           string   `json:"type"`
+	Port           string   `json:"port,omitempty"`
+	Path           string   `json:"path,omitempty"`
+	ExpectedStatus int      `json:"expected_status,omitempty"`
+	Command        []string `json:"command,omitempty"`
+	Timeout        string   `json:"timeout,omitempty"`
+}
+
 func (p *Plugin) validate() error {
 	if p.Image == "" {
 		return fmt.Errorf("%w: plugin image is empty", ErrInvalidInstance)
@@ -278,6 +334,24 @@ func (i *Instance) ProfileFile() (*profile.Profile, error) {
 	return &p, p.Validate()
 }
 
+// SetProfileFile replaces the profile.yml file of the instance with the given
+// profile. It is used to store profiles that extend other profiles resolved, as
+// their base profiles are not part of the instance.
+func (i *Instance) SetProfileFile(p *profile.Profile) error {
+	if err := i.lock(); err != nil {
+		return err
+	}
+	defer i.unlock()
+
+	resolved := *p
+	resolved.Extends = ""
+	data, err := yaml.Marshal(&resolved)
+	if err != nil {
+		return err
+	}
+	return afero.WriteFile(i.fs, filepath.Join(i.path, "profile.yml"), data, 0o644)
+}
+
 // Env returns the environment variables from the .env file of the instance.
 func (i *Instance) Env() (map[string]string, error) {
 	if err := i.lock(); err != nil {
@@ -10,6 +10,7 @@ import (
 	"github.com/NethermindEth/eigenlayer/internal/common"
 	"github.com/NethermindEth/eigenlayer/internal/data/testdata"
 	"github.com/NethermindEth/eigenlayer/internal/locker/mocks"
+	"github.com/NethermindEth/eigenlayer/internal/profile"
 	"github.com/golang/mock/gomock"
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
@@ -341,6 +342,50 @@ func TestInstance_Setup(t *testing.T) {
 	assert.Equal(t, []byte("VAR_1=value-1\n"), envData)
 }
 
+func TestInstance_SetProfileFile(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	instancePath, err := afero.TempDir(fs, "", "instance")
+	require.NoError(t, err)
+
+	ctrl := gomock.NewController(t)
+	locker := mocks.NewMockLocker(ctrl)
+	locker.EXPECT().New(filepath.Join(instancePath, ".lock")).Return(locker)
+	locker.EXPECT().Lock().Return(nil).Times(2)
+	locker.EXPECT().Locked().Return(true).Times(2)
+	locker.EXPECT().Unlock().Return(nil).Times(2)
+
+	i := Instance{
+		Name:    "mock-avs",
+		URL:     common.MockAvsPkg.Repo(),
+		Version: common.MockAvsPkg.Version(),
+		Commit:  common.MockAvsPkg.CommitHash(),
+		Profile: "holesky",
+		Tag:     "test-tag",
+	}
+	require.NoError(t, i.init(instancePath, fs, locker))
+
+	port := 9090
+	p := &profile.Profile{
+		Name:    "holesky",
+		Extends: "mainnet",
+		Options: []profile.Option{
+			{Name: "el-port", Target: "PORT", Type: "port", Default: "30303", Help: "Execution client port"},
+		},
+		Monitoring: profile.Monitoring{Targets: []profile.MonitoringTarget{
+			{Service: "main-service", Port: &port, Path: "/metrics"},
+		}},
+	}
+	require.NoError(t, i.SetProfileFile(p))
+
+	got, err := i.ProfileFile()
+	require.NoError(t, err)
+	assert.Empty(t, got.Extends)
+	assert.Equal(t, p.Options, got.Options)
+	assert.Equal(t, p.Monitoring, got.Monitoring)
+	// The given profile is not modified
+	assert.Equal(t, "mainnet", p.Extends)
+}
+
 func TestInstance_Env(t *testing.T) {
 	fs := afero.NewMemMapFs()
 	tc := []struct {
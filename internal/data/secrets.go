@@ -0,0 +1,142 @@
+package data
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/afero"
+)
+
+const (
+	secretsDir         = "secrets"
+	secretsKeyFileName = ".key"
+	secretsKeySize     = 32
+)
+
+// SecretStore stores the values of the secret options of the instances, outside
+// the plaintext .env file of the instance. Secrets are indexed by their target
+// environment variable.
+type SecretStore interface {
+	// Set replaces the secrets of the instance with the given id.
+	Set(instanceId string, secrets map[string]string) error
+	// Get returns the secrets of the instance with the given id, or nil if the
+	// instance has no secrets.
+	Get(instanceId string) (map[string]string, error)
+	// Delete removes the secrets of the instance with the given id, if any.
+	Delete(instanceId string) error
+}
+
+// fileSecretStore is a SecretStore that keeps the secrets of each instance in
+// a file encrypted with AES-256-GCM. The encryption key is generated on first
+// use and stored in the store directory, readable only by its owner.
+type fileSecretStore struct {
+	fs   afero.Fs
+	path string
+}
+
+var _ SecretStore = (*fileSecretStore)(nil)
+
+// NewFileSecretStore creates a new SecretStore that keeps encrypted secrets in
+// the given directory.
+func NewFileSecretStore(fs afero.Fs, path string) SecretStore {
+	return &fileSecretStore{fs: fs, path: path}
+}
+
+// Set implements SecretStore.Set.
+func (s *fileSecretStore) Set(instanceId string, secrets map[string]string) error {
+	if len(secrets) == 0 {
+		return s.Delete(instanceId)
+	}
+	gcm, err := s.cipher()
+	if err != nil {
+		return err
+	}
+	plaintext, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return err
+	}
+	// The instance id is used as additional data to bind the secrets to it
+	ciphertext := gcm.Seal(nonce, nonce, plaintext, []byte(instanceId))
+	return afero.WriteFile(s.fs, s.secretsPath(instanceId), ciphertext, 0o600)
+}
+
+// Get implements SecretStore.Get.
+func (s *fileSecretStore) Get(instanceId string) (map[string]string, error) {
+	ciphertext, err := afero.ReadFile(s.fs, s.secretsPath(instanceId))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	gcm, err := s.cipher()
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < gcm.NonceSize() {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidSecrets, instanceId)
+	}
+	nonce, ciphertext := ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, []byte(instanceId))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrInvalidSecrets, instanceId, err)
+	}
+	var secrets map[string]string
+	if err := json.Unmarshal(plaintext, &secrets); err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrInvalidSecrets, instanceId, err)
+	}
+	return secrets, nil
+}
+
+// Delete implements SecretStore.Delete.
+func (s *fileSecretStore) Delete(instanceId string) error {
+	err := s.fs.Remove(s.secretsPath(instanceId))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *fileSecretStore) secretsPath(instanceId string) string {
+	return filepath.Join(s.path, instanceId+".enc")
+}
+
+// cipher returns the AES-GCM cipher of the store, generating its key if it does
+// not exist yet.
+func (s *fileSecretStore) cipher() (cipher.AEAD, error) {
+	if err := s.fs.MkdirAll(s.path, 0o700); err != nil {
+		return nil, err
+	}
+	keyPath := filepath.Join(s.path, secretsKeyFileName)
+	key, err := afero.ReadFile(s.fs, keyPath)
+	if errors.Is(err, os.ErrNotExist) {
+		key = make([]byte, secretsKeySize)
+		if _, err := io.ReadFull(rand.Reader, key); err != nil {
+			return nil, err
+		}
+		if err := afero.WriteFile(s.fs, keyPath, key, 0o600); err != nil {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, err
+	}
+	if len(key) != secretsKeySize {
+		return nil, fmt.Errorf("%w: invalid key size %d", ErrInvalidSecrets, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
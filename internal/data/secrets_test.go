@@ -0,0 +1,57 @@
+package data
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileSecretStore(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	store := NewFileSecretStore(fs, "/data/secrets")
+
+	secrets, err := store.Get("mock-avs-default")
+	require.NoError(t, err)
+	assert.Nil(t, secrets)
+
+	want := map[string]string{"API_KEY": "secret-api-key"}
+	require.NoError(t, store.Set("mock-avs-default", want))
+	got, err := store.Get("mock-avs-default")
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	// Secrets are not stored in plaintext
+	content, err := afero.ReadFile(fs, "/data/secrets/mock-avs-default.enc")
+	require.NoError(t, err)
+	assert.NotContains(t, string(content), "secret-api-key")
+
+	// Secrets are bound to their instance
+	require.NoError(t, fs.Rename("/data/secrets/mock-avs-default.enc", "/data/secrets/mock-avs-other.enc"))
+	_, err = store.Get("mock-avs-other")
+	assert.ErrorIs(t, err, ErrInvalidSecrets)
+
+	require.NoError(t, store.Set("mock-avs-default", want))
+	require.NoError(t, store.Delete("mock-avs-default"))
+	secrets, err = store.Get("mock-avs-default")
+	require.NoError(t, err)
+	assert.Nil(t, secrets)
+	require.NoError(t, store.Delete("mock-avs-default"))
+}
+
+func TestDataDir_Secrets(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	dataDir, err := NewDataDir("/data", fs, nil)
+	require.NoError(t, err)
+
+	require.NoError(t, dataDir.Secrets().Set("mock-avs-default", map[string]string{"API_KEY": "secret"}))
+	exists, err := afero.Exists(fs, filepath.Join("/data", secretsDir, "mock-avs-default.enc"))
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	other := NewFileSecretStore(fs, "/vault")
+	dataDir.SetSecretStore(other)
+	assert.Equal(t, other, dataDir.Secrets())
+}
@@ -2,6 +2,7 @@ package docker
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"fmt"
 	"io"
@@ -15,6 +16,7 @@ import (
 	"github.com/docker/docker/api/types/mount"
 	"github.com/docker/docker/client"
 	"github.com/docker/docker/pkg/archive"
+	"github.com/docker/docker/pkg/stdcopy"
 	log "github.com/sirupsen/logrus"
 
 	"github.com/NethermindEth/eigenlayer/internal/common"
@@ -23,6 +25,11 @@ import (
 
 const NetworkHost = "host"
 
+const (
+	composeProjectLabel = "com.docker.compose.project"
+	composeServiceLabel = "com.docker.compose.service"
+)
+
 // NewDockerManager returns a new instance of DockerManager
 func NewDockerManager(dockerClient client.APIClient) *DockerManager {
 	return &DockerManager{dockerClient}
@@ -100,6 +107,27 @@ func (d *DockerManager) ContainerID(containerName string) (string, error) {
 	return "", fmt.Errorf("%w: %s", ErrContainerNotFound, containerName)
 }
 
+// ServiceContainerID retrieves the ID of the container of a service of a Docker
+// Compose project. The container is found by the project and service labels
+// that Docker Compose sets on it, so it does not depend on the container name.
+// If the service has more than one container, the first one is returned.
+func (d *DockerManager) ServiceContainerID(project, service string) (string, error) {
+	containers, err := d.dockerClient.ContainerList(context.Background(), types.ContainerListOptions{
+		All: true,
+		Filters: filters.NewArgs(
+			filters.Arg("label", composeProjectLabel+"="+project),
+			filters.Arg("label", composeServiceLabel+"="+service),
+		),
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(containers) == 0 {
+		return "", fmt.Errorf("%w: service %s of project %s", ErrContainerNotFound, service, project)
+	}
+	return containers[0].ID, nil
+}
+
 // Pull pulls a specified Docker image.
 // The function attempts to pull the image and returns any error that occurs during the process.
 func (d *DockerManager) Pull(image string) error {
@@ -418,6 +446,53 @@ func (d *DockerManager) ImageRemove(image string) error {
 	return err
 }
 
+// VolumeSizes returns the disk space used by each Docker volume in bytes, by
+// volume name. The size is -1 for the volumes whose size is not available.
+func (d *DockerManager) VolumeSizes() (map[string]int64, error) {
+	du, err := d.dockerClient.DiskUsage(context.Background(), types.DiskUsageOptions{
+		Types: []types.DiskUsageObject{types.VolumeObject},
+	})
+	if err != nil {
+		return nil, err
+	}
+	sizes := make(map[string]int64, len(du.Volumes))
+	for _, v := range du.Volumes {
+		sizes[v.Name] = -1
+		if v.UsageData != nil {
+			sizes[v.Name] = v.UsageData.Size
+		}
+	}
+	return sizes, nil
+}
+
+// CopyFromContainer returns a tar stream with the content of the given path in
+// the container, and the stat of the path. The path may be in a volume of the
+// container, which does not need to be running. The entries of the tar stream
+// are relative to the parent directory of the path.
+func (d *DockerManager) CopyFromContainer(ctx context.Context, container, srcPath string) (io.ReadCloser, types.ContainerPathStat, error) {
+	log.Debugf("Copying %s from container %s", srcPath, container)
+	return d.dockerClient.CopyFromContainer(ctx, container, srcPath)
+}
+
+// CopyToContainer extracts the given tar stream into the given directory of the
+// container, keeping the owner of the files of the tar stream. The directory
+// may be in a volume of the container, which does not need to be running.
+func (d *DockerManager) CopyToContainer(ctx context.Context, container, dstPath string, content io.Reader) error {
+	log.Debugf("Copying to %s of container %s", dstPath, container)
+	return d.dockerClient.CopyToContainer(ctx, container, dstPath, content, types.CopyToContainerOptions{})
+}
+
+// VolumeRemove removes the Docker volume with the given name. Volumes that do
+// not exist are ignored.
+func (d *DockerManager) VolumeRemove(name string) error {
+	log.Debugf("Removing volume %s", name)
+	err := d.dockerClient.VolumeRemove(context.Background(), name, false)
+	if client.IsErrNotFound(err) {
+		return nil
+	}
+	return err
+}
+
 type RunOptions struct {
 	Network     string
 	Args        []string
@@ -433,7 +508,9 @@ type RunOptions struct {
 // After the container starts, the function waits for the container to exit.
 // During the waiting process, it also listens for errors from the container.
 // If an error is received, it prints the container logs and returns the error.
-func (d *DockerManager) Run(image string, options RunOptions) (err error) {
+// If the context is done before the container exits, the container is killed
+// and the context error is returned.
+func (d *DockerManager) Run(ctx context.Context, image string, options RunOptions) (err error) {
 	log.Debugf("Creating container from image %s", image)
 	// Build mounts
 	hostConfig := &dockerCt.HostConfig{
@@ -490,6 +567,18 @@ func (d *DockerManager) Run(image string, options RunOptions) (err error) {
 	log.Debugf("Waiting for container to exit")
 	for {
 		select {
+		case <-ctx.Done():
+			log.Debugf("Killing container %s", createResponse.ID)
+			if err := d.dockerClient.ContainerKill(context.Background(), createResponse.ID, "KILL"); err != nil {
+				log.Errorf("Error killing container %s: %v", createResponse.ID, err)
+			} else {
+				// Wait for the container to exit, so it can be removed
+				select {
+				case <-waitChn:
+				case <-errChn:
+				}
+			}
+			return fmt.Errorf("running container %s: %w", createResponse.ID, ctx.Err())
 		case err := <-errChn:
 			if err != nil {
 				log.Debugf("Error while waiting for container to exit")
@@ -519,6 +608,48 @@ func (d *DockerManager) ImageExist(image string) (bool, error) {
 	return true, nil
 }
 
+// Exec runs the given command inside the given running container, and returns
+// its exit code and its combined standard output and error. The command output
+// is not waited for after the context is done.
+func (d *DockerManager) Exec(ctx context.Context, container string, cmd []string) (int, string, error) {
+	log.Debugf("Running command %v in container %s", cmd, container)
+	execResp, err := d.dockerClient.ContainerExecCreate(ctx, container, types.ExecConfig{
+		AttachStdout: true,
+		AttachStderr: true,
+		Cmd:          cmd,
+	})
+	if err != nil {
+		return 0, "", fmt.Errorf("%w %s: %w", ErrExecutingCommand, container, err)
+	}
+	attachResp, err := d.dockerClient.ContainerExecAttach(ctx, execResp.ID, types.ExecStartCheck{})
+	if err != nil {
+		return 0, "", fmt.Errorf("%w %s: %w", ErrExecutingCommand, container, err)
+	}
+	defer attachResp.Close()
+
+	// The output is owned by the copy goroutine until it is done
+	var output bytes.Buffer
+	copyDone := make(chan error, 1)
+	go func() {
+		_, err := stdcopy.StdCopy(&output, &output, attachResp.Reader)
+		copyDone <- err
+	}()
+	select {
+	case err := <-copyDone:
+		if err != nil {
+			return 0, "", fmt.Errorf("%w %s: %w", ErrExecutingCommand, container, err)
+		}
+	case <-ctx.Done():
+		return 0, "", fmt.Errorf("%w %s: %w", ErrExecutingCommand, container, ctx.Err())
+	}
+
+	inspect, err := d.dockerClient.ContainerExecInspect(ctx, execResp.ID)
+	if err != nil {
+		return 0, "", fmt.Errorf("%w %s: %w", ErrExecutingCommand, container, err)
+	}
+	return inspect.ExitCode, output.String(), nil
+}
+
 func containerLogs(dockerClient client.APIClient, containerID string) string {
 	logsReader, err := dockerClient.ContainerLogs(context.Background(), containerID, types.ContainerLogsOptions{ShowStdout: true, ShowStderr: true})
 	if err != nil {
@@ -1,11 +1,14 @@
 package docker
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"io"
+	"net"
+	"os"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -16,8 +19,10 @@ import (
 	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/api/types/mount"
 	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/volume"
 	"github.com/docker/docker/client"
 	"github.com/docker/docker/errdefs"
+	"github.com/docker/docker/pkg/stdcopy"
 	"github.com/golang/mock/gomock"
 	log "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
@@ -338,6 +343,53 @@ func TestContainerIdNotFound(t *testing.T) {
 	assert.Equal(t, "", id)
 }
 
+// ServiceContainerID tests
+
+func TestServiceContainerID(t *testing.T) {
+	tests := []struct {
+		name       string
+		containers []types.Container
+		listErr    error
+		wantId     string
+		err        error
+	}{
+		{
+			name:       "container found",
+			containers: []types.Container{{ID: "container-id", Names: []string{"/mock-avs-default-main-service-1"}}},
+			wantId:     "container-id",
+		},
+		{
+			name:       "container not found",
+			containers: []types.Container{},
+			err:        ErrContainerNotFound,
+		},
+		{
+			name:    "list error",
+			listErr: assert.AnError,
+			err:     assert.AnError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			dockerClient := mocks.NewMockAPIClient(ctrl)
+			dockerClient.EXPECT().
+				ContainerList(gomock.Any(), types.ContainerListOptions{
+					All: true,
+					Filters: filters.NewArgs(
+						filters.Arg("label", "com.docker.compose.project=mock-avs-default"),
+						filters.Arg("label", "com.docker.compose.service=main-service"),
+					),
+				}).
+				Return(tt.containers, tt.listErr)
+			dockerManager := NewDockerManager(dockerClient)
+			id, err := dockerManager.ServiceContainerID("mock-avs-default", "main-service")
+			assert.ErrorIs(t, err, tt.err)
+			assert.Equal(t, tt.wantId, id)
+		})
+	}
+}
+
 // Pull tests
 
 func TestPullError(t *testing.T) {
@@ -1691,7 +1743,7 @@ func TestDockerManager_Run(t *testing.T) {
 
 			dockerManager := NewDockerManager(dockerClient)
 
-			err := dockerManager.Run(tt.image, tt.options)
+			err := dockerManager.Run(context.Background(), tt.image, tt.options)
 
 			if tt.expectedError != nil {
 				assert.Error(t, err)
@@ -1703,6 +1755,128 @@ func TestDockerManager_Run(t *testing.T) {
 	}
 }
 
+func TestRun_Canceled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	dockerClient := mocks.NewMockAPIClient(ctrl)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	waitCh := make(chan container.WaitResponse, 1)
+	errCh := make(chan error, 1)
+
+	gomock.InOrder(
+		dockerClient.EXPECT().ContainerCreate(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(container.CreateResponse{ID: "containerID"}, nil),
+		dockerClient.EXPECT().ContainerWait(gomock.Any(), "containerID", container.WaitConditionNextExit).Return(waitCh, errCh),
+		dockerClient.EXPECT().ContainerStart(gomock.Any(), "containerID", gomock.Any()).DoAndReturn(
+			func(context.Context, string, types.ContainerStartOptions) error {
+				cancel()
+				return nil
+			}),
+		dockerClient.EXPECT().ContainerKill(gomock.Any(), "containerID", "KILL").DoAndReturn(
+			func(context.Context, string, string) error {
+				waitCh <- container.WaitResponse{StatusCode: 137}
+				return nil
+			}),
+		dockerClient.EXPECT().ContainerRemove(gomock.Any(), "containerID", gomock.Any()).Return(nil),
+	)
+
+	dockerManager := NewDockerManager(dockerClient)
+	err := dockerManager.Run(ctx, "my-image", RunOptions{Network: NetworkHost})
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestVolumeSizes(t *testing.T) {
+	tests := []struct {
+		name    string
+		du      types.DiskUsage
+		err     error
+		want    map[string]int64
+		wantErr bool
+	}{
+		{
+			name: "volumes",
+			du: types.DiskUsage{Volumes: []*volume.Volume{
+				{Name: "mock-avs-data", UsageData: &volume.UsageData{Size: 1024, RefCount: 1}},
+				{Name: "no-usage-data"},
+			}},
+			want: map[string]int64{"mock-avs-data": 1024, "no-usage-data": -1},
+		},
+		{
+			name:    "disk usage error",
+			err:     assert.AnError,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			dockerClient := mocks.NewMockAPIClient(ctrl)
+			dockerClient.EXPECT().DiskUsage(context.Background(), types.DiskUsageOptions{
+				Types: []types.DiskUsageObject{types.VolumeObject},
+			}).Return(tt.du, tt.err)
+
+			got, err := NewDockerManager(dockerClient).VolumeSizes()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestVolumeRemove(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		wantErr bool
+	}{
+		{
+			name: "removed",
+		},
+		{
+			name: "not found",
+			err:  notFoundError{},
+		},
+		{
+			name:    "error",
+			err:     assert.AnError,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			dockerClient := mocks.NewMockAPIClient(ctrl)
+			dockerClient.EXPECT().VolumeRemove(context.Background(), "mock-avs_data", false).Return(tt.err)
+
+			err := NewDockerManager(dockerClient).VolumeRemove("mock-avs_data")
+			if tt.wantErr {
+				assert.ErrorIs(t, err, tt.err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCopyContainer(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	dockerClient := mocks.NewMockAPIClient(ctrl)
+	stat := types.ContainerPathStat{Name: "data", Mode: os.ModeDir | 0o755}
+	gomock.InOrder(
+		dockerClient.EXPECT().CopyFromContainer(ctx, "main-service", "/data").Return(io.NopCloser(bytes.NewBufferString("tar")), stat, nil),
+		dockerClient.EXPECT().CopyToContainer(ctx, "main-service", "/", gomock.Any(), types.CopyToContainerOptions{}).Return(nil),
+	)
+	dockerManager := NewDockerManager(dockerClient)
+
+	r, gotStat, err := dockerManager.CopyFromContainer(ctx, "main-service", "/data")
+	require.NoError(t, err)
+	assert.Equal(t, stat, gotStat)
+	assert.NoError(t, dockerManager.CopyToContainer(ctx, "main-service", "/", r))
+}
+
 type notFoundError struct{}
 
 func (e notFoundError) NotFound() {}
@@ -1765,3 +1939,92 @@ func TestImageExist(t *testing.T) {
 		})
 	}
 }
+
+func TestExec(t *testing.T) {
+	ctx := context.Background()
+	ctName := "eigen"
+	cmd := []string{"worker", "status"}
+	execConfig := types.ExecConfig{AttachStdout: true, AttachStderr: true, Cmd: cmd}
+
+	// attachResponse returns a hijacked response with the given multiplexed
+	// stdout and stderr output.
+	attachResponse := func(t *testing.T, stdout, stderr string) types.HijackedResponse {
+		var buf bytes.Buffer
+		_, err := stdcopy.NewStdWriter(&buf, stdcopy.Stdout).Write([]byte(stdout))
+		require.NoError(t, err)
+		_, err = stdcopy.NewStdWriter(&buf, stdcopy.Stderr).Write([]byte(stderr))
+		require.NoError(t, err)
+		conn, peer := net.Pipe()
+		t.Cleanup(func() { peer.Close() })
+		return types.HijackedResponse{Conn: conn, Reader: bufio.NewReader(&buf)}
+	}
+
+	tc := []struct {
+		name     string
+		setup    func(*testing.T, *mocks.MockAPIClient)
+		exitCode int
+		output   string
+		err      error
+	}{
+		{
+			name: "success",
+			setup: func(t *testing.T, dockerClient *mocks.MockAPIClient) {
+				gomock.InOrder(
+					dockerClient.EXPECT().ContainerExecCreate(ctx, ctName, execConfig).Return(types.IDResponse{ID: "exec-id"}, nil),
+					dockerClient.EXPECT().ContainerExecAttach(ctx, "exec-id", types.ExecStartCheck{}).Return(attachResponse(t, "synced\n", ""), nil),
+					dockerClient.EXPECT().ContainerExecInspect(ctx, "exec-id").Return(types.ContainerExecInspect{ExitCode: 0}, nil),
+				)
+			},
+			exitCode: 0,
+			output:   "synced\n",
+		},
+		{
+			name: "non-zero exit code",
+			setup: func(t *testing.T, dockerClient *mocks.MockAPIClient) {
+				gomock.InOrder(
+					dockerClient.EXPECT().ContainerExecCreate(ctx, ctName, execConfig).Return(types.IDResponse{ID: "exec-id"}, nil),
+					dockerClient.EXPECT().ContainerExecAttach(ctx, "exec-id", types.ExecStartCheck{}).Return(attachResponse(t, "", "not synced\n"), nil),
+					dockerClient.EXPECT().ContainerExecInspect(ctx, "exec-id").Return(types.ContainerExecInspect{ExitCode: 3}, nil),
+				)
+			},
+			exitCode: 3,
+			output:   "not synced\n",
+		},
+		{
+			name: "create error",
+			setup: func(t *testing.T, dockerClient *mocks.MockAPIClient) {
+				dockerClient.EXPECT().ContainerExecCreate(ctx, ctName, execConfig).Return(types.IDResponse{}, assert.AnError)
+			},
+			err: ErrExecutingCommand,
+		},
+		{
+			name: "inspect error",
+			setup: func(t *testing.T, dockerClient *mocks.MockAPIClient) {
+				gomock.InOrder(
+					dockerClient.EXPECT().ContainerExecCreate(ctx, ctName, execConfig).Return(types.IDResponse{ID: "exec-id"}, nil),
+					dockerClient.EXPECT().ContainerExecAttach(ctx, "exec-id", types.ExecStartCheck{}).Return(attachResponse(t, "", ""), nil),
+					dockerClient.EXPECT().ContainerExecInspect(ctx, "exec-id").Return(types.ContainerExecInspect{}, assert.AnError),
+				)
+			},
+			err: ErrExecutingCommand,
+		},
+	}
+
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			dockerClient := mocks.NewMockAPIClient(ctrl)
+			tt.setup(t, dockerClient)
+			dockerManager := NewDockerManager(dockerClient)
+
+			exitCode, output, err := dockerManager.Exec(ctx, ctName, cmd)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.exitCode, exitCode)
+			assert.Equal(t, tt.output, output)
+		})
+	}
+}
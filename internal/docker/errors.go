@@ -7,4 +7,5 @@ var (
 	ErrContainerNotFound = errors.New("container not found")
 	ErrStoppingContainer = errors.New("error stopping container")
 	ErrNetworksNotFound  = errors.New("networks not found")
+	ErrExecutingCommand  = errors.New("error executing command in container")
 )
@@ -18,8 +18,7 @@ func NewFLock() Locker {
 }
 
 func (l *FLock) New(path string) Locker {
-	l.locker = flock.New(path)
-	return l
+	return &FLock{locker: flock.New(path)}
 }
 
 func (l *FLock) Lock() error {
@@ -17,6 +17,19 @@ var (
 	ErrNoPlugin                   = errors.New("no plugin found")
 	ErrProfileComposeFileNotFound = errors.New("profile compose file not found")
 	ErrBuildContextNotAllowed     = errors.New("build context not allowed")
+	ErrInvalidPackageOptions      = errors.New("invalid package options")
+	ErrPackageDirNotEmpty         = errors.New("package directory is not empty")
+	ErrUnknownSource              = errors.New("unknown package source")
+	ErrInvalidSourceState         = errors.New("invalid package source state")
+	ErrContentTooLarge            = errors.New("downloaded content too large")
+	ErrNoVersionCheckedOut        = errors.New("no version checked out")
+	ErrCommitsNotSupported        = errors.New("package source does not support commits")
+	ErrDigestMismatch             = errors.New("digest mismatch")
+	ErrUnexpectedHTTPStatus       = errors.New("unexpected HTTP status")
+	ErrResourceNotFound           = errors.New("resource not found")
+	ErrInvalidPackageIndex        = errors.New("invalid package index")
+	ErrPackageLayerNotFound       = errors.New("package layer not found")
+	ErrProfileExtendsCycle        = errors.New("profile extends cycle")
 )
 
 // PackageFileNotFoundError is returned when a package file is not found.
@@ -5,13 +5,15 @@ import (
 	"fmt"
 
 	"github.com/docker/distribution/reference"
+	"golang.org/x/mod/semver"
 )
 
 // Manifest represents the manifest file of a package
 type Manifest struct {
 	Version              string               `yaml:"version"`
 	Name                 string               `yaml:"name"`
-	Upgrade              string               `yaml:"upgrade"`
+	SpecVersions         []string             `yaml:"spec_versions"`
+	Upgrade              Upgrade              `yaml:"upgrade"`
 	HardwareRequirements hardwareRequirements `yaml:"hardware_requirements"`
 	Plugin               *Plugin              `yaml:"plugin"`
 	Profiles             []string             `yaml:"profiles"`
@@ -25,13 +27,25 @@ func (m *Manifest) validate() error {
 	if m.Name == "" {
 		missingFields = append(missingFields, "name")
 	}
-	if m.Upgrade == "" {
+	if m.Upgrade.isZero() {
 		missingFields = append(missingFields, "upgrade")
 	}
 	if len(m.Profiles) == 0 {
 		missingFields = append(missingFields, "profiles")
 	}
 
+	var invalidFields []string
+	for i, v := range m.SpecVersions {
+		if !semver.IsValid(v) {
+			invalidFields = append(invalidFields, fmt.Sprintf("spec_versions[%d] -> (invalid version %s)", i, v))
+		}
+	}
+
+	var upgradeErr error
+	if !m.Upgrade.isZero() {
+		upgradeErr = m.Upgrade.validate()
+	}
+
 	hardReqErr := m.HardwareRequirements.validate()
 
 	var pluginErr error
@@ -48,11 +62,15 @@ func (m *Manifest) validate() error {
 		}
 	}
 
-	if hardReqErr != nil || pluginErr != nil || invalidProfiles || len(missingFields) > 0 {
+	if upgradeErr != nil || hardReqErr != nil || pluginErr != nil || invalidProfiles || len(missingFields) > 0 || len(invalidFields) > 0 {
 		var err error = InvalidConfError{
 			message:       "Invalid manifest file",
+			invalidFields: invalidFields,
 			missingFields: missingFields,
 		}
+		if upgradeErr != nil {
+			err = fmt.Errorf("%w: %w", err, upgradeErr)
+		}
 		if hardReqErr != nil {
 			err = fmt.Errorf("%w: %w", err, hardReqErr)
 		}
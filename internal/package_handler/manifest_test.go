@@ -31,7 +31,7 @@ func TestManifest_ValidateFromYML(t *testing.T) {
 		{
 			name:      "Invalid Fields Manifest",
 			filePath:  "invalid-fields/pkg/manifest.yml",
-			wantError: "Invalid manifest file: Invalid hardware requirements -> invalid fields: hardware_requirements.min_cpu_cores -> (negative value), hardware_requirements.min_ram -> (negative value), hardware_requirements.min_free_space -> (negative value): Invalid plugin -> invalid fields: plugin.image -> (invalid docker image: invalid reference format)",
+			wantError: "Invalid manifest file -> invalid fields: spec_versions[0] -> (invalid version 0.1): Invalid hardware requirements -> invalid fields: hardware_requirements.min_cpu_cores -> (negative value), hardware_requirements.min_ram -> (negative value), hardware_requirements.min_free_space -> (negative value): Invalid plugin -> invalid fields: plugin.image -> (invalid docker image: invalid reference format)",
 		},
 		{
 			name:      "Minimal Manifest",
@@ -48,6 +48,16 @@ func TestManifest_ValidateFromYML(t *testing.T) {
 			filePath:  "missing-fields-profile/pkg/manifest.yml",
 			wantError: "Invalid manifest file -> missing fields: version, name, upgrade: invalid profiles: profile 0: profile 2",
 		},
+		{
+			name:      "Upgrade Policy Manifest",
+			filePath:  "upgrade-policy/pkg/manifest.yml",
+			wantError: "",
+		},
+		{
+			name:      "Invalid Upgrade Manifest",
+			filePath:  "invalid-upgrade/pkg/manifest.yml",
+			wantError: "Invalid manifest file: Invalid upgrade -> invalid fields: upgrade.policy -> (empty), upgrade.from[0] -> (invalid version 1.2.0 in range \">=1.2.0\"), upgrade.migrations[0].image -> (invalid docker image: invalid reference format)",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -84,7 +94,7 @@ func TestManifest_Validate(t *testing.T) {
 			manifest: &Manifest{
 				Version: "1.0.0",
 				Name:    "test-package",
-				Upgrade: "manual",
+				Upgrade: Upgrade{Policy: "manual"},
 				HardwareRequirements: hardwareRequirements{
 					MinCPUCores:                 2,
 					MinRAM:                      4096,
@@ -103,7 +113,7 @@ func TestManifest_Validate(t *testing.T) {
 			manifest: &Manifest{
 				Version: "1.0.0",
 				Name:    "",
-				Upgrade: "manual",
+				Upgrade: Upgrade{Policy: "manual"},
 				HardwareRequirements: hardwareRequirements{
 					MinCPUCores:                 -1,
 					MinRAM:                      4096,
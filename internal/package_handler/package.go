@@ -3,16 +3,14 @@ package package_handler
 import (
 	"errors"
 	"fmt"
-	"io"
 	"maps"
 	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/NethermindEth/eigenlayer/internal/env"
 	"github.com/NethermindEth/eigenlayer/internal/profile"
 	"github.com/compose-spec/compose-go/cli"
-	"github.com/go-git/go-git/v5"
-	"github.com/go-git/go-git/v5/plumbing"
-	"github.com/go-git/go-git/v5/plumbing/transport"
 	"github.com/go-git/go-git/v5/plumbing/transport/http"
 	"github.com/spf13/afero"
 	"golang.org/x/mod/semver"
@@ -31,20 +29,29 @@ const (
 // PackageHandler is used to interact with an AVS node software package at the given
 // path.
 type PackageHandler struct {
-	path string
-	afs  afero.Fs
+	path   string
+	afs    afero.Fs
+	source PackageSource
 }
 
 // NewPackageHandler creates a new PackageHandler instance for the given package path.
+// The package source is detected from the content of the path. If the source state
+// of the package is invalid, the methods using the package source return an
+// ErrInvalidSourceState error.
 func NewPackageHandler(path string) *PackageHandler {
-	return &PackageHandler{path: path, afs: afero.NewOsFs()}
+	afs := afero.NewOsFs()
+	source, err := detectSource(path, afs)
+	if err != nil {
+		source = &invalidSource{err: err}
+	}
+	return &PackageHandler{path: path, afs: afs, source: source}
 }
 
 // NewPackageHandlerOptions is used to provide options to the NewPackageHandlerFromURL
 type NewPackageHandlerOptions struct {
 	// Path is the path where the package will be cloned
 	Path string
-	// URL is the URL of the git repository
+	// URL is the URL of the package source
 	URL string
 	// GitAuth is used to provide authentication to a private git repository
 	GitAuth *GitAuth
@@ -84,27 +91,20 @@ func (g *NewPackageHandlerOptions) getAuth() *http.BasicAuth {
 	}
 }
 
-// NewPackageHandlerFromURL clones the package from the given URL and returns. The GitAuth
-// field could be used to provide authentication to a private git repository.
+// NewPackageHandlerFromURL initializes the package from the given URL in the
+// options path and returns its handler. Git repositories are cloned, and the
+// GitAuth field could be used to provide authentication to a private git
+// repository. URLs with the oci:// scheme are OCI artifacts in a container
+// registry, and URLs with the tar+https:// scheme are package indexes listing
+// versioned tarballs. The content of non-git sources is not available until a
+// version is checked out.
 func NewPackageHandlerFromURL(opts NewPackageHandlerOptions) (*PackageHandler, error) {
-	_, err := git.PlainClone(opts.Path, false, &git.CloneOptions{
-		URL:  opts.URL,
-		Auth: opts.getAuth(),
-	})
+	afs := afero.NewOsFs()
+	source, err := newSource(opts, afs)
 	if err != nil {
-		if errors.Is(err, transport.ErrAuthenticationRequired) {
-			return nil, RepositoryNotFoundOrPrivateError{
-				URL: opts.URL,
-			}
-		}
-		if errors.Is(err, transport.ErrRepositoryNotFound) {
-			return nil, RepositoryNotFoundError{
-				URL: opts.URL,
-			}
-		}
 		return nil, err
 	}
-	return NewPackageHandler(opts.Path), nil
+	return &PackageHandler{path: opts.Path, afs: afs, source: source}, nil
 }
 
 // Check validates a package. It returns an error if the package is invalid.
@@ -125,30 +125,11 @@ func (p *PackageHandler) Check() error {
 	return p.checkSum()
 }
 
-// Versions returns the descending sorted list of available versions for the package.
-// A version is a git tag that matches the regex `^v\d+\.\d+\.\d+$`.
+// Versions returns the ascending sorted list of available versions for the package.
+// A version is a git tag, or a version of a non-git source, that matches the regex
+// `^v\d+\.\d+\.\d+$`.
 func (p *PackageHandler) Versions() ([]string, error) {
-	pkgRepo, err := git.PlainOpen(p.path)
-	if err != nil {
-		return nil, err
-	}
-	tagIter, err := pkgRepo.Tags()
-	if err != nil {
-		return nil, err
-	}
-	var versions []string
-	tagIter.ForEach(func(ref *plumbing.Reference) error {
-		tag := ref.Name().Short()
-		if semver.IsValid(tag) {
-			versions = append(versions, tag)
-		}
-		return nil
-	})
-	if len(versions) == 0 {
-		return nil, ErrNoVersionsFound
-	}
-	semver.Sort(versions)
-	return versions, nil
+	return p.source.Versions()
 }
 
 // HasVersion returns an error if the given version is not available for the package.
@@ -165,20 +146,22 @@ func (p *PackageHandler) HasVersion(version string) error {
 	return fmt.Errorf("%w: %s", ErrVersionNotFound, version)
 }
 
+// SupportsCommits returns true if the package source has a commit history, like
+// git repositories. Otherwise, CheckoutCommit and CommitPrecedence return
+// ErrCommitsNotSupported.
+func (p *PackageHandler) SupportsCommits() bool {
+	_, ok := p.source.(commitSource)
+	return ok
+}
+
 // CheckoutCommit checkout the cloned repository to the given commit hash. If
 // the commit hash is not found, it returns an error.
 func (p *PackageHandler) CheckoutCommit(commitHash string) error {
-	pkgRepo, err := git.PlainOpen(p.path)
-	if err != nil {
-		return err
-	}
-	wt, err := pkgRepo.Worktree()
-	if err != nil {
-		return err
+	cs, ok := p.source.(commitSource)
+	if !ok {
+		return ErrCommitsNotSupported
 	}
-	return wt.Checkout(&git.CheckoutOptions{
-		Hash: plumbing.NewHash(commitHash),
-	})
+	return cs.CheckoutCommit(commitHash)
 }
 
 // LatestVersion returns the latest version of the package.
@@ -193,127 +176,31 @@ func (p *PackageHandler) LatestVersion() (string, error) {
 // CommitPrecedence returns true if the new commit hash is a descendant of the
 // old commit hash. It returns an error if the commit hashes are not found.
 func (p *PackageHandler) CommitPrecedence(oldCommitHash, newCommitHash string) (bool, error) {
-	err := p.CheckoutCommit(newCommitHash)
-	if err != nil {
-		return false, err
-	}
-	gitRepo, err := git.PlainOpen(p.path)
-	if err != nil {
-		return false, err
-	}
-	commitIter, err := gitRepo.Log(&git.LogOptions{
-		From: plumbing.NewHash(newCommitHash),
-	})
-	if err != nil {
-		return false, err
-	}
-	// Skip first commit, because it is the new commit hash itself
-	_, err = commitIter.Next()
-	if err != nil {
-		if errors.Is(err, io.EOF) {
-			return false, nil
-		}
-		return false, err
-	}
-	for {
-		c, err := commitIter.Next()
-		if err != nil {
-			if errors.Is(err, io.EOF) {
-				return false, nil
-			}
-			return false, err
-		}
-		if c.Hash.String() == oldCommitHash {
-			return true, nil
-		}
+	cs, ok := p.source.(commitSource)
+	if !ok {
+		return false, ErrCommitsNotSupported
 	}
+	return cs.CommitPrecedence(oldCommitHash, newCommitHash)
 }
 
-// CheckoutVersion checks out the cloned repository to the given version (tag).
+// CheckoutVersion checks out the package to the given version.
 func (p *PackageHandler) CheckoutVersion(version string) error {
 	if !semver.IsValid(version) {
 		return ErrInvalidVersion
 	}
-	gitRepo, err := git.PlainOpen(p.path)
-	if err != nil {
-		return err
-	}
-	tagIter, err := gitRepo.Tags()
-	if err != nil {
-		return err
-	}
-	defer tagIter.Close()
-	for {
-		tag, err := tagIter.Next()
-		if err != nil {
-			if errors.Is(err, io.EOF) {
-				return ErrNoVersionsFound
-			}
-			return err
-		}
-		if tag.Name().Short() == version {
-			worktree, err := gitRepo.Worktree()
-			if err != nil {
-				return fmt.Errorf("error getting worktree: %w", err)
-			}
-			err = worktree.Checkout(&git.CheckoutOptions{
-				Branch: tag.Name(),
-			})
-			if err != nil {
-				return err
-			}
-			break
-		}
-	}
-	return nil
+	return p.source.Checkout(version)
 }
 
-// CurrentVersion returns the current version of the package, which is tha latest
-// tag with version format that points to the current HEAD.
+// CurrentVersion returns the current version of the package. For git repositories,
+// it is the latest tag with version format that points to the current HEAD.
 func (p *PackageHandler) CurrentVersion() (string, error) {
-	gitRepo, err := git.PlainOpen(p.path)
-	if err != nil {
-		return "", err
-	}
-	head, err := gitRepo.Head()
-	if err != nil {
-		return "", err
-	}
-	tagIter, err := gitRepo.TagObjects()
-	if err != nil {
-		return "", err
-	}
-	var headVersions []string
-	for {
-		tag, err := tagIter.Next()
-		if err != nil {
-			if errors.Is(err, io.EOF) {
-				break
-			}
-			return "", err
-		}
-		if semver.IsValid(tag.Name) && head.Hash() == tag.Target {
-			headVersions = append(headVersions, tag.Name)
-		}
-	}
-	if len(headVersions) == 0 {
-		return "", ErrNoVersionsFound
-	}
-	semver.Sort(headVersions)
-	return headVersions[len(headVersions)-1], nil
+	return p.source.CurrentVersion()
 }
 
-// CurrentHash returns the git hash of the package HEAD
+// CurrentHash returns the git hash of the package HEAD, or the digest of the
+// checked out content for non-git sources.
 func (p *PackageHandler) CurrentCommitHash() (string, error) {
-	gitRepo, err := git.PlainOpen(p.path)
-	if err != nil {
-		return "", err
-	}
-	head, err := gitRepo.Head()
-	if err != nil {
-		return "", err
-	}
-	return head.Hash().String(), nil
+	return p.source.CurrentRevision()
 }
 
 // Profiles returns the list of profiles defined in the package for the current version.
@@ -441,6 +328,38 @@ func (p *PackageHandler) Plugin() (*Plugin, error) {
 	return manifest.Plugin, nil
 }
 
+// ProfilePlugin returns the plugin of the given profile, that is the plugin of
+// the package with the plugin overrides of the profile applied. If neither the
+// package nor the profile define a plugin, ErrNoPlugin is returned.
+func (p *PackageHandler) ProfilePlugin(profileName string) (*Plugin, error) {
+	manifest, err := p.parseManifest()
+	if err != nil {
+		return nil, err
+	}
+	profile, err := p.parseProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+
+	if profile.PluginOverrides.Image != "" {
+		return &Plugin{Image: profile.PluginOverrides.Image}, nil
+	}
+	if manifest.Plugin == nil {
+		return nil, ErrNoPlugin
+	}
+	return manifest.Plugin, nil
+}
+
+// Upgrade returns the upgrade policy of the package.
+func (p *PackageHandler) Upgrade() (*Upgrade, error) {
+	manifest, err := p.parseManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	return &manifest.Upgrade, nil
+}
+
 func (p *PackageHandler) parseManifest() (*Manifest, error) {
 	manifestPath := filepath.Join(p.path, pkgDirName, manifestFileName)
 	// Validate YAML Schema
@@ -518,7 +437,35 @@ func (p *PackageHandler) profilesNames() ([]string, error) {
 	return manifest.Profiles, nil
 }
 
+// parseProfile parses the profile with the given name. If the profile extends
+// another profile, the result is the profile applied over its base profile.
 func (p *PackageHandler) parseProfile(profileName string) (*profile.Profile, error) {
+	return p.resolveProfile(profileName, nil)
+}
+
+// resolveProfile parses the profile with the given name and resolves its base
+// profiles. The chain is the list of profiles extending the profile, used to
+// detect cycles.
+func (p *PackageHandler) resolveProfile(profileName string, chain []string) (*profile.Profile, error) {
+	chain = append(chain, profileName)
+	if slices.Contains(chain[:len(chain)-1], profileName) {
+		return nil, fmt.Errorf("%w: %s", ErrProfileExtendsCycle, strings.Join(chain, " -> "))
+	}
+	pkgProfile, err := p.readProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	if pkgProfile.Extends == "" {
+		return pkgProfile, nil
+	}
+	base, err := p.resolveProfile(pkgProfile.Extends, chain)
+	if err != nil {
+		return nil, err
+	}
+	return pkgProfile.Extend(base), nil
+}
+
+func (p *PackageHandler) readProfile(profileName string) (*profile.Profile, error) {
 	profilePath := filepath.Join(p.path, pkgDirName, profileName, profileFileName)
 	// Validate YAML Schemas
 	// TODO: Fix the relative path
@@ -565,17 +512,19 @@ func (p *PackageHandler) checkSum() error {
 	return nil
 }
 
-func (p *PackageHandler) SpecVersion() (string, error) {
+// SpecVersions returns the versions of the AVS Node Specification the package
+// targets. The list is empty if the package does not declare them.
+func (p *PackageHandler) SpecVersions() ([]string, error) {
 	manifest, err := p.parseManifest()
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	if err := manifest.validate(); err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return manifest.Version, nil
+	return manifest.SpecVersions, nil
 }
 
 func (p *PackageHandler) Name() (string, error) {
@@ -1,6 +1,7 @@
 package package_handler
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -325,6 +326,12 @@ func TestProfiles(t *testing.T) {
 			want:    []profile.Profile{},
 			err:     fmt.Errorf("Invalid profile: invalid options: %w: %w: invalid monitoring: %w", InvalidConfError{message: "Option #1 is invalid", invalidFields: []string{"options.default"}}, InvalidConfError{message: "Option #2 is invalid", missingFields: []string{"options.type", "options.help"}}, InvalidConfError{message: "Monitoring target #1 is invalid", missingFields: []string{"monitoring.targets.port", "monitoring.targets.path"}}),
 		},
+		{
+			name:    "invalid overrides",
+			pkgPath: "invalid-overrides",
+			want:    []profile.Profile{},
+			err:     errors.New("Invalid profile: Invalid hardware requirements overrides -> invalid fields: hardware_requirements_overrides.min_cpu_cores -> (negative value): Invalid plugin overrides -> invalid fields: plugin_overrides.image -> (invalid docker image"),
+		},
 	}
 
 	for _, tc := range ts {
@@ -960,6 +967,110 @@ func TestCurrentVersion(t *testing.T) {
 	}
 }
 
+func TestProfile_Extends(t *testing.T) {
+	afs := afero.NewOsFs()
+	testDir, err := afero.TempDir(afs, "", "test")
+	require.NoError(t, err)
+	testdata.SetupDir(t, "packages", testDir, afs)
+
+	pkgHandler := NewPackageHandler(filepath.Join(testDir, "packages", "extended-profiles"))
+	elPort := profile.Option{Name: "el-port", Target: "PORT", Type: "port", Default: "30303", Help: "Execution client port"}
+	graffiti := profile.Option{Name: "graffiti", Target: "GRAFFITI", Type: "str", Help: "Graffiti of the node"}
+	monitoring := profile.Monitoring{
+		Targets: []profile.MonitoringTarget{
+			{Service: "main-service", Port: intP(9090), Path: "/metrics"},
+			{Service: "exporter", Port: intP(9200), Path: "/metrics"},
+		},
+	}
+	api := &profile.APITarget{Service: "main-service", Port: 8080}
+
+	mainnet, err := pkgHandler.Profile("mainnet")
+	require.NoError(t, err)
+	assert.Equal(t, &profile.Profile{
+		Name:       "mainnet",
+		Extends:    "base",
+		Options:    []profile.Option{elPort, graffiti},
+		Monitoring: monitoring,
+		API:        api,
+	}, mainnet)
+
+	holesky, err := pkgHandler.Profile("holesky")
+	require.NoError(t, err)
+	assert.Equal(t, &profile.Profile{
+		Name:    "holesky",
+		Extends: "mainnet",
+		HardwareRequirementsOverrides: &profile.HardwareRequirementsOverrides{
+			MinCPUCores:  2,
+			MinRAM:       2048,
+			MinFreeSpace: 5120,
+		},
+		Options: []profile.Option{
+			elPort,
+			graffiti,
+			{Name: "network-id", Target: "NETWORK_ID", Type: "int", Default: "17000", Help: "Network id"},
+		},
+		Monitoring: monitoring,
+		API:        api,
+	}, holesky)
+
+	// Hardware requirements are inherited too
+	hr, err := pkgHandler.HardwareRequirements("holesky")
+	require.NoError(t, err)
+	assert.Equal(t, hardwareRequirements{MinCPUCores: 2, MinRAM: 2048, MinFreeSpace: 5120}, hr)
+
+	// Cycles are detected
+	pkgHandler = NewPackageHandler(filepath.Join(testDir, "packages", "cyclic-profiles"))
+	_, err = pkgHandler.Profiles()
+	assert.ErrorIs(t, err, ErrProfileExtendsCycle)
+	assert.ErrorContains(t, err, "a -> b -> a")
+}
+
+func TestProfilePlugin(t *testing.T) {
+	afs := afero.NewOsFs()
+	testDir, err := afero.TempDir(afs, "", "test")
+	require.NoError(t, err)
+	testdata.SetupDir(t, "packages", testDir, afs)
+
+	ts := []struct {
+		name    string
+		pkgPath string
+		profile string
+		want    *Plugin
+		err     error
+	}{
+		{
+			name:    "manifest plugin",
+			pkgPath: "plugin-overrides",
+			profile: "default",
+			want:    &Plugin{Image: "your-organization/plugin-service:latest"},
+		},
+		{
+			name:    "overridden plugin",
+			pkgPath: "plugin-overrides",
+			profile: "custom",
+			want:    &Plugin{Image: "your-organization/custom-plugin:v1.0.0"},
+		},
+		{
+			name:    "no plugin",
+			pkgPath: "extended-profiles",
+			profile: "mainnet",
+			err:     ErrNoPlugin,
+		},
+	}
+	for _, tc := range ts {
+		t.Run(tc.name, func(t *testing.T) {
+			pkgHandler := NewPackageHandler(filepath.Join(testDir, "packages", tc.pkgPath))
+			plugin, err := pkgHandler.ProfilePlugin(tc.profile)
+			if tc.err != nil {
+				assert.ErrorIs(t, err, tc.err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tc.want, plugin)
+			}
+		})
+	}
+}
+
 func intP(i int) *int {
 	return &i
 }
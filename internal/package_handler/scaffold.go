@@ -0,0 +1,191 @@
+package package_handler
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"text/template"
+
+	"github.com/spf13/afero"
+
+	"github.com/NethermindEth/eigenlayer/internal/common"
+)
+
+const (
+	dotEnvFileName  = ".env"
+	composeFileName = "docker-compose.yml"
+)
+
+// NewPackageOptions is used to provide options to NewPackage.
+type NewPackageOptions struct {
+	// Name is the name of the AVS represented by the package.
+	Name string
+	// Profiles is the list of profile names to create. At least one profile is
+	// required.
+	Profiles []string
+	// Force allows creating the package in a non-empty directory, overwriting
+	// existing files with the same name.
+	Force bool
+}
+
+// NewPackage creates a new minimal and valid package at the given path, ready to
+// be customized by the package author. It creates the manifest file, a profile
+// directory with its profile file, docker-compose file and .env file for each
+// profile, and the checksum file.
+func NewPackage(path string, opts NewPackageOptions) (*PackageHandler, error) {
+	p := NewPackageHandler(path)
+	return p, p.scaffold(opts)
+}
+
+func (p *PackageHandler) scaffold(opts NewPackageOptions) error {
+	if opts.Name == "" {
+		return fmt.Errorf("%w: package name is empty", ErrInvalidPackageOptions)
+	}
+	if len(opts.Profiles) == 0 {
+		return fmt.Errorf("%w: at least one profile is required", ErrInvalidPackageOptions)
+	}
+	for _, profileName := range opts.Profiles {
+		if profileName == "" || filepath.Base(profileName) != profileName {
+			return fmt.Errorf("%w: invalid profile name \"%s\"", ErrInvalidPackageOptions, profileName)
+		}
+	}
+
+	if !opts.Force {
+		empty, err := p.isEmptyDir()
+		if err != nil {
+			return err
+		}
+		if !empty {
+			return fmt.Errorf("%w: %s", ErrPackageDirNotEmpty, p.path)
+		}
+	}
+
+	data := scaffoldData{
+		Name:        opts.Name,
+		SpecVersion: common.SpecVersion,
+		Profiles:    opts.Profiles,
+	}
+	err := p.writeTemplate(filepath.Join(pkgDirName, manifestFileName), manifestTemplate, data)
+	if err != nil {
+		return err
+	}
+	for _, profileName := range opts.Profiles {
+		data.Profile = profileName
+		profileFiles := map[string]string{
+			profileFileName: profileTemplate,
+			composeFileName: composeTemplate,
+			dotEnvFileName:  dotEnvTemplate,
+		}
+		for fileName, tmpl := range profileFiles {
+			err := p.writeTemplate(filepath.Join(pkgDirName, profileName, fileName), tmpl, data)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return p.WriteChecksum()
+}
+
+// WriteChecksum computes the checksums of all the files in the package and writes
+// them into the checksum.txt file, replacing its previous content. The format
+// is the same used by the sha256sum tool, and the one expected by Check.
+func (p *PackageHandler) WriteChecksum() error {
+	if err := checkPackageDirExist(p.path, pkgDirName, p.afs); err != nil {
+		return err
+	}
+	hashes, err := packageHashes(p.path, p.afs)
+	if err != nil {
+		return err
+	}
+	files := make([]string, 0, len(hashes))
+	for file := range hashes {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	var checksums bytes.Buffer
+	for _, file := range files {
+		fmt.Fprintf(&checksums, "%s  %s\n", hashes[file], file)
+	}
+	return afero.WriteFile(p.afs, filepath.Join(p.path, checksumFileName), checksums.Bytes(), 0o644)
+}
+
+func (p *PackageHandler) isEmptyDir() (bool, error) {
+	exists, err := afero.DirExists(p.afs, p.path)
+	if err != nil {
+		return false, err
+	}
+	if !exists {
+		return true, nil
+	}
+	return afero.IsEmpty(p.afs, p.path)
+}
+
+func (p *PackageHandler) writeTemplate(relativePath, tmpl string, data scaffoldData) error {
+	t, err := template.New(filepath.Base(relativePath)).Parse(tmpl)
+	if err != nil {
+		return err
+	}
+	var out bytes.Buffer
+	if err := t.Execute(&out, data); err != nil {
+		return err
+	}
+	filePath := filepath.Join(p.path, relativePath)
+	if err := p.afs.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+		return err
+	}
+	return afero.WriteFile(p.afs, filePath, out.Bytes(), os.FileMode(0o644))
+}
+
+type scaffoldData struct {
+	Name        string
+	SpecVersion string
+	Profiles    []string
+	Profile     string
+}
+
+const manifestTemplate = `version: v0.1.0
+name: {{ .Name }}
+spec_versions:
+  - {{ .SpecVersion }}
+upgrade: recommended
+hardware_requirements:
+  min_cpu_cores: 0
+  min_ram: 0
+  min_free_space: 0
+  stop_if_requirements_are_not_met: false
+profiles:
+{{- range .Profiles }}
+  - {{ . }}
+{{- end }}
+`
+
+const profileTemplate = `options:
+  - name: main-container-name
+    target: MAIN_CONTAINER_NAME
+    type: str
+    default: {{ .Name }}-{{ .Profile }}-main
+    help: Name of the main container
+monitoring:
+  targets:
+    - service: main
+      port: 9090
+      path: /metrics
+api:
+  service: main
+  port: 8080
+`
+
+const composeTemplate = `services:
+  main:
+    container_name: ${MAIN_CONTAINER_NAME}
+    image: ${MAIN_IMAGE}
+    restart: unless-stopped
+`
+
+const dotEnvTemplate = `MAIN_IMAGE=busybox:latest
+MAIN_CONTAINER_NAME={{ .Name }}-{{ .Profile }}-main
+`
@@ -0,0 +1,153 @@
+package package_handler
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/NethermindEth/eigenlayer/internal/common"
+)
+
+func TestNewPackage(t *testing.T) {
+	type testCase struct {
+		name    string
+		pkgPath string
+		opts    NewPackageOptions
+		err     error
+	}
+	ts := []testCase{
+		{
+			name:    "single profile",
+			pkgPath: filepath.Join(t.TempDir(), "pkg"),
+			opts: NewPackageOptions{
+				Name:     "mock-avs",
+				Profiles: []string{"default"},
+			},
+		},
+		{
+			name:    "multiple profiles",
+			pkgPath: t.TempDir(),
+			opts: NewPackageOptions{
+				Name:     "mock-avs",
+				Profiles: []string{"mainnet", "holesky"},
+			},
+		},
+		{
+			name:    "empty name",
+			pkgPath: t.TempDir(),
+			opts: NewPackageOptions{
+				Profiles: []string{"default"},
+			},
+			err: ErrInvalidPackageOptions,
+		},
+		{
+			name:    "no profiles",
+			pkgPath: t.TempDir(),
+			opts: NewPackageOptions{
+				Name: "mock-avs",
+			},
+			err: ErrInvalidPackageOptions,
+		},
+		{
+			name:    "invalid profile name",
+			pkgPath: t.TempDir(),
+			opts: NewPackageOptions{
+				Name:     "mock-avs",
+				Profiles: []string{"main/net"},
+			},
+			err: ErrInvalidPackageOptions,
+		},
+		func() testCase {
+			pkgPath := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(pkgPath, "README.md"), []byte("# mock-avs"), 0o644))
+			return testCase{
+				name:    "non-empty directory",
+				pkgPath: pkgPath,
+				opts: NewPackageOptions{
+					Name:     "mock-avs",
+					Profiles: []string{"default"},
+				},
+				err: ErrPackageDirNotEmpty,
+			}
+		}(),
+		func() testCase {
+			pkgPath := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(pkgPath, "README.md"), []byte("# mock-avs"), 0o644))
+			return testCase{
+				name:    "non-empty directory with force",
+				pkgPath: pkgPath,
+				opts: NewPackageOptions{
+					Name:     "mock-avs",
+					Profiles: []string{"default"},
+					Force:    true,
+				},
+			}
+		}(),
+	}
+	for _, tc := range ts {
+		t.Run(tc.name, func(t *testing.T) {
+			pkgHandler, err := NewPackage(tc.pkgPath, tc.opts)
+			if tc.err != nil {
+				assert.ErrorIs(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.NoError(t, pkgHandler.Check())
+			name, err := pkgHandler.Name()
+			require.NoError(t, err)
+			assert.Equal(t, tc.opts.Name, name)
+			specVersions, err := pkgHandler.SpecVersions()
+			require.NoError(t, err)
+			assert.Equal(t, []string{common.SpecVersion}, specVersions)
+			profiles, err := pkgHandler.Profiles()
+			require.NoError(t, err)
+			require.Len(t, profiles, len(tc.opts.Profiles))
+			for i, profile := range profiles {
+				assert.Equal(t, tc.opts.Profiles[i], profile.Name)
+				env, err := pkgHandler.DotEnv(profile.Name)
+				require.NoError(t, err)
+				assert.NoError(t, pkgHandler.CheckComposeProject(profile.Name, env))
+			}
+		})
+	}
+}
+
+func TestWriteChecksum(t *testing.T) {
+	afs := afero.NewOsFs()
+	pkgPath := t.TempDir()
+	pkgHandler, err := NewPackage(pkgPath, NewPackageOptions{
+		Name:     "mock-avs",
+		Profiles: []string{"default"},
+	})
+	require.NoError(t, err)
+
+	// Edit a package file by hand
+	composePath := filepath.Join(pkgHandler.ProfilePath("default"), "docker-compose.yml")
+	f, err := afs.OpenFile(composePath, os.O_APPEND|os.O_WRONLY, 0o644)
+	require.NoError(t, err)
+	_, err = f.WriteString("    stop_grace_period: 30s\n")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	assert.ErrorIs(t, pkgHandler.Check(), ErrInvalidChecksum)
+
+	// Regenerate checksums
+	require.NoError(t, pkgHandler.WriteChecksum())
+	assert.NoError(t, pkgHandler.Check())
+
+	checksums, err := parseChecksumFile(filepath.Join(pkgPath, checksumFileName), afs)
+	require.NoError(t, err)
+	assert.Len(t, checksums, 4)
+	assert.Contains(t, checksums, filepath.Join("pkg", "default", "docker-compose.yml"))
+}
+
+func TestWriteChecksum_NoPkgDir(t *testing.T) {
+	pkgHandler := NewPackageHandler(t.TempDir())
+	err := pkgHandler.WriteChecksum()
+	var dirNotFoundErr PackageDirNotFoundError
+	assert.ErrorAs(t, err, &dirNotFoundErr)
+}
@@ -0,0 +1,289 @@
+package package_handler
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+
+	"github.com/NethermindEth/eigenlayer/internal/utils"
+)
+
+const (
+	// sourceStateFileName is the name of the file, placed in the package path,
+	// where non-git sources store their URL and the checked out version.
+	sourceStateFileName = ".source.json"
+
+	sourceTypeOCI     = "oci"
+	sourceTypeTarball = "tarball"
+
+	ociScheme     = "oci://"
+	tarballScheme = "tar+"
+
+	// maxPackageSize is the maximum size of the package content downloaded by
+	// remote sources.
+	maxPackageSize = 512 << 20
+	// maxMetadataSize is the maximum size of the indexes, manifests and other
+	// metadata downloaded by remote sources.
+	maxMetadataSize = 4 << 20
+)
+
+// PackageSource is a source of versioned package contents. The source places
+// the content of the checked out version in the package path, where it is
+// read by the PackageHandler.
+type PackageSource interface {
+	// Versions returns the ascending sorted list of available versions. A version
+	// must match the regex `^v\d+\.\d+\.\d+$`.
+	Versions() ([]string, error)
+
+	// Checkout places the content of the given version in the package path.
+	Checkout(version string) error
+
+	// CurrentVersion returns the checked out version.
+	CurrentVersion() (string, error)
+
+	// CurrentRevision returns an immutable identifier of the checked out content,
+	// like a git commit hash or a content digest.
+	CurrentRevision() (string, error)
+}
+
+// commitSource is implemented by sources with a commit history, like git.
+type commitSource interface {
+	CheckoutCommit(commitHash string) error
+	CommitPrecedence(oldCommitHash, newCommitHash string) (bool, error)
+}
+
+// sourceState is the content of the source state file.
+type sourceState struct {
+	Type     string `json:"type"`
+	URL      string `json:"url"`
+	Version  string `json:"version,omitempty"`
+	Revision string `json:"revision,omitempty"`
+}
+
+// newSource initializes the source for the given URL in the package path.
+// Supported URLs are:
+//
+//   - oci://<registry>/<repository>: OCI artifacts in a container registry,
+//     where each tag with version format is a version of the package.
+//
+//   - tar+https://<host>/<path>/index.yml: an index file listing the versions
+//     of the package and the URLs of their tarballs.
+//
+//   - Any other URL is considered a git repository, where each tag with version
+//     format is a version of the package.
+func newSource(opts NewPackageHandlerOptions, afs afero.Fs) (PackageSource, error) {
+	var state sourceState
+	switch {
+	case strings.HasPrefix(opts.URL, ociScheme):
+		state = sourceState{Type: sourceTypeOCI, URL: opts.URL}
+	case strings.HasPrefix(opts.URL, tarballScheme):
+		state = sourceState{Type: sourceTypeTarball, URL: opts.URL}
+	default:
+		return cloneGitSource(opts)
+	}
+	if err := afs.MkdirAll(opts.Path, 0o755); err != nil {
+		return nil, err
+	}
+	if err := writeSourceState(opts.Path, afs, &state); err != nil {
+		return nil, err
+	}
+	return sourceFromState(opts.Path, afs, &state)
+}
+
+// detectSource returns the source of the package in the given path. Packages
+// without a source state file are considered git repositories. An error is
+// returned if the source state file can not be read or is invalid.
+func detectSource(path string, afs afero.Fs) (PackageSource, error) {
+	state, err := readSourceState(path, afs)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &gitSource{path: path}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrInvalidSourceState, path, err)
+	}
+	source, err := sourceFromState(path, afs, state)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrInvalidSourceState, path, err)
+	}
+	return source, nil
+}
+
+func sourceFromState(path string, afs afero.Fs, state *sourceState) (PackageSource, error) {
+	switch state.Type {
+	case sourceTypeOCI:
+		return newOCISource(path, afs, state)
+	case sourceTypeTarball:
+		return newTarballSource(path, afs, state)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownSource, state.Type)
+	}
+}
+
+// invalidSource is the source of packages whose source state is invalid. All
+// its methods return the error found reading the source state.
+type invalidSource struct {
+	err error
+}
+
+// Versions implements PackageSource.Versions.
+func (s *invalidSource) Versions() ([]string, error) {
+	return nil, s.err
+}
+
+// Checkout implements PackageSource.Checkout.
+func (s *invalidSource) Checkout(string) error {
+	return s.err
+}
+
+// CurrentVersion implements PackageSource.CurrentVersion.
+func (s *invalidSource) CurrentVersion() (string, error) {
+	return "", s.err
+}
+
+// CurrentRevision implements PackageSource.CurrentRevision.
+func (s *invalidSource) CurrentRevision() (string, error) {
+	return "", s.err
+}
+
+func readSourceState(path string, afs afero.Fs) (*sourceState, error) {
+	data, err := afero.ReadFile(afs, filepath.Join(path, sourceStateFileName))
+	if err != nil {
+		return nil, err
+	}
+	var state sourceState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+func writeSourceState(path string, afs afero.Fs, state *sourceState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return afero.WriteFile(afs, filepath.Join(path, sourceStateFileName), data, 0o644)
+}
+
+// replaceContent replaces the content of the package path, except the source
+// state file, with the content of the given tar.gz package. The package is
+// untrusted, so entries outside of the package path and links are rejected.
+func replaceContent(path string, afs afero.Fs, pkgTar io.Reader) error {
+	entries, err := afero.ReadDir(afs, path)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.Name() == sourceStateFileName {
+			continue
+		}
+		if err := afs.RemoveAll(filepath.Join(path, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return utils.DecompressUntrustedTarGz(pkgTar, path)
+}
+
+// remoteSource holds the common logic of non-git sources, which download the
+// content of the checked out version into the package path.
+type remoteSource struct {
+	path   string
+	afs    afero.Fs
+	state  *sourceState
+	client *http.Client
+}
+
+// CurrentVersion implements PackageSource.CurrentVersion.
+func (r *remoteSource) CurrentVersion() (string, error) {
+	if r.state.Version == "" {
+		return "", ErrNoVersionCheckedOut
+	}
+	return r.state.Version, nil
+}
+
+// CurrentRevision implements PackageSource.CurrentRevision, returning the digest
+// of the checked out content.
+func (r *remoteSource) CurrentRevision() (string, error) {
+	if r.state.Version == "" {
+		return "", ErrNoVersionCheckedOut
+	}
+	return r.state.Revision, nil
+}
+
+// store replaces the content of the package path with the given tar.gz package
+// content, and stores the checked out version and revision in the source state
+// file.
+func (r *remoteSource) store(version, revision string, content []byte) error {
+	if err := replaceContent(r.path, r.afs, bytes.NewReader(content)); err != nil {
+		return err
+	}
+	r.state.Version = version
+	r.state.Revision = revision
+	return writeSourceState(r.path, r.afs, r.state)
+}
+
+// httpGet performs a GET request returning the response only if its status
+// is successful.
+func httpGet(client *http.Client, url string) (*http.Response, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkResponse(resp, url); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// checkResponse returns an error, closing the response body, if the status of
+// the response is not successful.
+func checkResponse(resp *http.Response, url string) error {
+	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+		return nil
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: %s", ErrResourceNotFound, url)
+	}
+	return fmt.Errorf("%w: GET %s returned %d", ErrUnexpectedHTTPStatus, url, resp.StatusCode)
+}
+
+// readVerified reads all the content of the given reader, up to maxPackageSize
+// bytes, checking that it matches the given digest with format `sha256:<hex>`.
+func readVerified(r io.Reader, digest string) ([]byte, error) {
+	if !strings.HasPrefix(digest, "sha256:") {
+		return nil, fmt.Errorf("%w: unsupported digest %s", ErrDigestMismatch, digest)
+	}
+	data, err := readLimited(r, maxPackageSize)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(data)
+	if got := "sha256:" + hex.EncodeToString(sum[:]); got != digest {
+		return nil, fmt.Errorf("%w: expected %s, got %s", ErrDigestMismatch, digest, got)
+	}
+	return data, nil
+}
+
+// readLimited reads all the content of the given reader, returning an
+// ErrContentTooLarge error if it is larger than the given limit.
+func readLimited(r io.Reader, limit int64) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("%w: more than %d bytes", ErrContentTooLarge, limit)
+	}
+	return data, nil
+}
@@ -0,0 +1,210 @@
+package package_handler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"golang.org/x/mod/semver"
+)
+
+// gitSource is a PackageSource backed by a git repository cloned in the package
+// path. Versions are the git tags with version format.
+type gitSource struct {
+	path string
+}
+
+var (
+	_ PackageSource = (*gitSource)(nil)
+	_ commitSource  = (*gitSource)(nil)
+)
+
+// cloneGitSource clones the repository from the options URL into the options path.
+func cloneGitSource(opts NewPackageHandlerOptions) (*gitSource, error) {
+	_, err := git.PlainClone(opts.Path, false, &git.CloneOptions{
+		URL:  opts.URL,
+		Auth: opts.getAuth(),
+	})
+	if err != nil {
+		if errors.Is(err, transport.ErrAuthenticationRequired) {
+			return nil, RepositoryNotFoundOrPrivateError{
+				URL: opts.URL,
+			}
+		}
+		if errors.Is(err, transport.ErrRepositoryNotFound) {
+			return nil, RepositoryNotFoundError{
+				URL: opts.URL,
+			}
+		}
+		return nil, err
+	}
+	return &gitSource{path: opts.Path}, nil
+}
+
+// Versions implements PackageSource.Versions.
+func (g *gitSource) Versions() ([]string, error) {
+	pkgRepo, err := git.PlainOpen(g.path)
+	if err != nil {
+		return nil, err
+	}
+	tagIter, err := pkgRepo.Tags()
+	if err != nil {
+		return nil, err
+	}
+	var versions []string
+	tagIter.ForEach(func(ref *plumbing.Reference) error {
+		tag := ref.Name().Short()
+		if semver.IsValid(tag) {
+			versions = append(versions, tag)
+		}
+		return nil
+	})
+	if len(versions) == 0 {
+		return nil, ErrNoVersionsFound
+	}
+	semver.Sort(versions)
+	return versions, nil
+}
+
+// Checkout implements PackageSource.Checkout, checking out the tag of the given
+// version.
+func (g *gitSource) Checkout(version string) error {
+	gitRepo, err := git.PlainOpen(g.path)
+	if err != nil {
+		return err
+	}
+	tagIter, err := gitRepo.Tags()
+	if err != nil {
+		return err
+	}
+	defer tagIter.Close()
+	for {
+		tag, err := tagIter.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return ErrNoVersionsFound
+			}
+			return err
+		}
+		if tag.Name().Short() == version {
+			worktree, err := gitRepo.Worktree()
+			if err != nil {
+				return fmt.Errorf("error getting worktree: %w", err)
+			}
+			err = worktree.Checkout(&git.CheckoutOptions{
+				Branch: tag.Name(),
+			})
+			if err != nil {
+				return err
+			}
+			break
+		}
+	}
+	return nil
+}
+
+// CurrentVersion implements PackageSource.CurrentVersion, returning the latest
+// tag with version format that points to the current HEAD.
+func (g *gitSource) CurrentVersion() (string, error) {
+	gitRepo, err := git.PlainOpen(g.path)
+	if err != nil {
+		return "", err
+	}
+	head, err := gitRepo.Head()
+	if err != nil {
+		return "", err
+	}
+	tagIter, err := gitRepo.TagObjects()
+	if err != nil {
+		return "", err
+	}
+	var headVersions []string
+	for {
+		tag, err := tagIter.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return "", err
+		}
+		if semver.IsValid(tag.Name) && head.Hash() == tag.Target {
+			headVersions = append(headVersions, tag.Name)
+		}
+	}
+	if len(headVersions) == 0 {
+		return "", ErrNoVersionsFound
+	}
+	semver.Sort(headVersions)
+	return headVersions[len(headVersions)-1], nil
+}
+
+// CurrentRevision implements PackageSource.CurrentRevision, returning the hash
+// of the HEAD commit.
+func (g *gitSource) CurrentRevision() (string, error) {
+	gitRepo, err := git.PlainOpen(g.path)
+	if err != nil {
+		return "", err
+	}
+	head, err := gitRepo.Head()
+	if err != nil {
+		return "", err
+	}
+	return head.Hash().String(), nil
+}
+
+// CheckoutCommit checks out the repository to the given commit hash.
+func (g *gitSource) CheckoutCommit(commitHash string) error {
+	pkgRepo, err := git.PlainOpen(g.path)
+	if err != nil {
+		return err
+	}
+	wt, err := pkgRepo.Worktree()
+	if err != nil {
+		return err
+	}
+	return wt.Checkout(&git.CheckoutOptions{
+		Hash: plumbing.NewHash(commitHash),
+	})
+}
+
+// CommitPrecedence returns true if the new commit hash is a descendant of the
+// old commit hash.
+func (g *gitSource) CommitPrecedence(oldCommitHash, newCommitHash string) (bool, error) {
+	err := g.CheckoutCommit(newCommitHash)
+	if err != nil {
+		return false, err
+	}
+	gitRepo, err := git.PlainOpen(g.path)
+	if err != nil {
+		return false, err
+	}
+	commitIter, err := gitRepo.Log(&git.LogOptions{
+		From: plumbing.NewHash(newCommitHash),
+	})
+	if err != nil {
+		return false, err
+	}
+	// Skip first commit, because it is the new commit hash itself
+	_, err = commitIter.Next()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return false, nil
+		}
+		return false, err
+	}
+	for {
+		c, err := commitIter.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return false, nil
+			}
+			return false, err
+		}
+		if c.Hash.String() == oldCommitHash {
+			return true, nil
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package package_handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/spf13/afero"
+	"golang.org/x/mod/semver"
+)
+
+// PackageLayerMediaType is the media type of the OCI artifact layer containing
+// the package tarball. Layers with the standard gzip layer media type are also
+// supported.
+const PackageLayerMediaType = "application/vnd.eigenlayer.package.v1.tar+gzip"
+
+var authParamRegex = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// ociSource is a PackageSource backed by an OCI artifact repository in a
+// container registry, where each tag with version format is a version of the
+// package. The source URL has the format `oci://<registry>/<repository>`, and
+// the artifact manifest must have a layer with the package tarball. Registries
+// in localhost are accessed using plain HTTP.
+type ociSource struct {
+	remoteSource
+	registry   string
+	repository string
+	scheme     string
+	token      string
+}
+
+var _ PackageSource = (*ociSource)(nil)
+
+func newOCISource(path string, afs afero.Fs, state *sourceState) (*ociSource, error) {
+	ref := strings.TrimPrefix(state.URL, ociScheme)
+	registry, repository, ok := strings.Cut(ref, "/")
+	if !ok || registry == "" || repository == "" {
+		return nil, fmt.Errorf("%w: invalid OCI source %s", ErrUnknownSource, state.URL)
+	}
+	if registry == "docker.io" {
+		registry = "registry-1.docker.io"
+	}
+	scheme := "https"
+	if host := strings.Split(registry, ":")[0]; host == "localhost" || host == "127.0.0.1" {
+		scheme = "http"
+	}
+	return &ociSource{
+		remoteSource: remoteSource{
+			path:   path,
+			afs:    afs,
+			state:  state,
+			client: http.DefaultClient,
+		},
+		registry:   registry,
+		repository: repository,
+		scheme:     scheme,
+	}, nil
+}
+
+// Versions implements PackageSource.Versions.
+func (o *ociSource) Versions() ([]string, error) {
+	resp, err := o.get(o.endpoint("tags/list"), "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := readLimited(resp.Body, maxMetadataSize)
+	if err != nil {
+		return nil, err
+	}
+	var tagList struct {
+		Tags []string `json:"tags"`
+	}
+	if err := json.Unmarshal(data, &tagList); err != nil {
+		return nil, err
+	}
+	var versions []string
+	for _, tag := range tagList.Tags {
+		if semver.IsValid(tag) {
+			versions = append(versions, tag)
+		}
+	}
+	if len(versions) == 0 {
+		return nil, ErrNoVersionsFound
+	}
+	semver.Sort(versions)
+	return versions, nil
+}
+
+// Checkout implements PackageSource.Checkout, downloading the package layer of
+// the artifact tagged with the given version and checking its digest.
+func (o *ociSource) Checkout(version string) error {
+	resp, err := o.get(o.endpoint("manifests/"+version), ocispec.MediaTypeImageManifest)
+	if err != nil {
+		if errors.Is(err, ErrResourceNotFound) {
+			return fmt.Errorf("%w: %s", ErrVersionNotFound, version)
+		}
+		return err
+	}
+	defer resp.Body.Close()
+	manifestData, err := readLimited(resp.Body, maxMetadataSize)
+	if err != nil {
+		return err
+	}
+	manifestSum := sha256.Sum256(manifestData)
+	manifestDigest := "sha256:" + hex.EncodeToString(manifestSum[:])
+
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(manifestData, &manifest); err != nil {
+		return err
+	}
+	var layer *ocispec.Descriptor
+	for i, l := range manifest.Layers {
+		if l.MediaType == PackageLayerMediaType || l.MediaType == ocispec.MediaTypeImageLayerGzip {
+			layer = &manifest.Layers[i]
+			break
+		}
+	}
+	if layer == nil {
+		return fmt.Errorf("%w: %s:%s", ErrPackageLayerNotFound, o.state.URL, version)
+	}
+
+	blobResp, err := o.get(o.endpoint("blobs/"+layer.Digest.String()), "")
+	if err != nil {
+		return err
+	}
+	defer blobResp.Body.Close()
+	content, err := readVerified(blobResp.Body, layer.Digest.String())
+	if err != nil {
+		return err
+	}
+	return o.store(version, manifestDigest, content)
+}
+
+func (o *ociSource) endpoint(path string) string {
+	return fmt.Sprintf("%s://%s/v2/%s/%s", o.scheme, o.registry, o.repository, path)
+}
+
+// get performs a GET request to the registry. If the registry requires a bearer
+// token, an anonymous token is requested and the request is retried.
+func (o *ociSource) get(u, accept string) (*http.Response, error) {
+	header := http.Header{}
+	if accept != "" {
+		header.Set("Accept", accept)
+	}
+	if o.token != "" {
+		header.Set("Authorization", "Bearer "+o.token)
+	}
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header = header
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized && o.token == "" {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		resp.Body.Close()
+		if err := o.authenticate(challenge); err != nil {
+			return nil, err
+		}
+		return o.get(u, accept)
+	}
+	if err := checkResponse(resp, u); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// authenticate requests an anonymous bearer token following the given
+// WWW-Authenticate challenge.
+func (o *ociSource) authenticate(challenge string) error {
+	if !strings.HasPrefix(challenge, "Bearer ") {
+		return fmt.Errorf("%w: unsupported registry authentication %q", ErrUnexpectedHTTPStatus, challenge)
+	}
+	params := make(map[string]string)
+	for _, m := range authParamRegex.FindAllStringSubmatch(challenge, -1) {
+		params[m[1]] = m[2]
+	}
+	realm, err := url.Parse(params["realm"])
+	if err != nil || params["realm"] == "" {
+		return fmt.Errorf("%w: invalid registry authentication realm %q", ErrUnexpectedHTTPStatus, params["realm"])
+	}
+	q := realm.Query()
+	if service, ok := params["service"]; ok {
+		q.Set("service", service)
+	}
+	if scope, ok := params["scope"]; ok {
+		q.Set("scope", scope)
+	} else {
+		q.Set("scope", "repository:"+o.repository+":pull")
+	}
+	realm.RawQuery = q.Encode()
+	resp, err := httpGet(o.client, realm.String())
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := readLimited(resp.Body, maxMetadataSize)
+	if err != nil {
+		return err
+	}
+	var tokenResp struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.Unmarshal(data, &tokenResp); err != nil {
+		return err
+	}
+	o.token = tokenResp.Token
+	if o.token == "" {
+		o.token = tokenResp.AccessToken
+	}
+	if o.token == "" {
+		return fmt.Errorf("%w: empty registry token", ErrUnexpectedHTTPStatus)
+	}
+	return nil
+}
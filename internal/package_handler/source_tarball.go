@@ -0,0 +1,140 @@
+package package_handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/spf13/afero"
+	"golang.org/x/mod/semver"
+	"gopkg.in/yaml.v3"
+)
+
+// tarballSource is a PackageSource backed by an index file served over HTTP(S),
+// listing the versions of the package and the URL and digest of their tarballs.
+// The source URL is the index URL prefixed with `tar+`, for instance
+// `tar+https://example.com/mock-avs/index.yml`. The index file format is:
+//
+//	versions:
+//	  - version: v1.0.0
+//	    url: mock-avs-v1.0.0.tar.gz
+//	    digest: sha256:<hex>
+//
+// Tarball URLs could be absolute or relative to the index URL.
+type tarballSource struct {
+	remoteSource
+	indexURL string
+}
+
+var _ PackageSource = (*tarballSource)(nil)
+
+type packageIndex struct {
+	Versions []packageIndexEntry `yaml:"versions"`
+}
+
+type packageIndexEntry struct {
+	Version string `yaml:"version"`
+	URL     string `yaml:"url"`
+	Digest  string `yaml:"digest"`
+}
+
+func newTarballSource(path string, afs afero.Fs, state *sourceState) (*tarballSource, error) {
+	indexURL := strings.TrimPrefix(state.URL, tarballScheme)
+	u, err := url.Parse(indexURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "https" && u.Scheme != "http" {
+		return nil, fmt.Errorf("%w: unsupported tarball source scheme %s", ErrUnknownSource, state.URL)
+	}
+	return &tarballSource{
+		remoteSource: remoteSource{
+			path:   path,
+			afs:    afs,
+			state:  state,
+			client: http.DefaultClient,
+		},
+		indexURL: indexURL,
+	}, nil
+}
+
+// Versions implements PackageSource.Versions.
+func (t *tarballSource) Versions() ([]string, error) {
+	index, err := t.index()
+	if err != nil {
+		return nil, err
+	}
+	versions := make([]string, 0, len(index.Versions))
+	for _, entry := range index.Versions {
+		versions = append(versions, entry.Version)
+	}
+	if len(versions) == 0 {
+		return nil, ErrNoVersionsFound
+	}
+	semver.Sort(versions)
+	return versions, nil
+}
+
+// Checkout implements PackageSource.Checkout, downloading the tarball of the given
+// version and checking its digest.
+func (t *tarballSource) Checkout(version string) error {
+	index, err := t.index()
+	if err != nil {
+		return err
+	}
+	var entry *packageIndexEntry
+	for i := range index.Versions {
+		if index.Versions[i].Version == version {
+			entry = &index.Versions[i]
+			break
+		}
+	}
+	if entry == nil {
+		return fmt.Errorf("%w: %s", ErrVersionNotFound, version)
+	}
+	base, err := url.Parse(t.indexURL)
+	if err != nil {
+		return err
+	}
+	ref, err := url.Parse(entry.URL)
+	if err != nil {
+		return err
+	}
+	tarballURL := base.ResolveReference(ref).String()
+	resp, err := httpGet(t.client, tarballURL)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	content, err := readVerified(resp.Body, entry.Digest)
+	if err != nil {
+		return err
+	}
+	return t.store(version, entry.Digest, content)
+}
+
+func (t *tarballSource) index() (*packageIndex, error) {
+	resp, err := httpGet(t.client, t.indexURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := readLimited(resp.Body, maxMetadataSize)
+	if err != nil {
+		return nil, err
+	}
+	var index packageIndex
+	if err := yaml.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidPackageIndex, err)
+	}
+	for i, entry := range index.Versions {
+		if !semver.IsValid(entry.Version) {
+			return nil, fmt.Errorf("%w: invalid version %s in entry %d", ErrInvalidPackageIndex, entry.Version, i)
+		}
+		if entry.URL == "" || entry.Digest == "" {
+			return nil, fmt.Errorf("%w: missing url or digest for version %s", ErrInvalidPackageIndex, entry.Version)
+		}
+	}
+	return &index, nil
+}
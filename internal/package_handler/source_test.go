@@ -0,0 +1,362 @@
+package package_handler
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/NethermindEth/eigenlayer/internal/utils"
+)
+
+// packageTarball creates a new package with the given name and returns its
+// tar.gz content and digest.
+func packageTarball(t *testing.T, name string) ([]byte, string) {
+	t.Helper()
+	pkgPath := t.TempDir()
+	_, err := NewPackage(pkgPath, NewPackageOptions{
+		Name:     name,
+		Profiles: []string{"default"},
+	})
+	require.NoError(t, err)
+	var content bytes.Buffer
+	require.NoError(t, utils.CompressToTarGz(pkgPath, &content))
+	sum := sha256.Sum256(content.Bytes())
+	return content.Bytes(), "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func TestTarballSource(t *testing.T) {
+	v1Content, v1Digest := packageTarball(t, "mock-avs-v1")
+	v2Content, v2Digest := packageTarball(t, "mock-avs-v2")
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/pkg/index.yml", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `versions:
+  - version: v1.0.0
+    url: mock-avs-v1.0.0.tar.gz
+    digest: %s
+  - version: v2.0.0
+    url: /files/mock-avs-v2.0.0.tar.gz
+    digest: %s
+  - version: v3.0.0
+    url: mock-avs-v1.0.0.tar.gz
+    digest: %s
+`, v1Digest, v2Digest, v2Digest)
+	})
+	mux.HandleFunc("/pkg/mock-avs-v1.0.0.tar.gz", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(v1Content)
+	})
+	mux.HandleFunc("/files/mock-avs-v2.0.0.tar.gz", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(v2Content)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	pkgPath := t.TempDir()
+	pkgHandler, err := NewPackageHandlerFromURL(NewPackageHandlerOptions{
+		Path: pkgPath,
+		URL:  "tar+" + server.URL + "/pkg/index.yml",
+	})
+	require.NoError(t, err)
+	assert.False(t, pkgHandler.SupportsCommits())
+	assert.ErrorIs(t, pkgHandler.CheckoutCommit("b64c50c15e53ae7afebbdbe210b834d1ee471043"), ErrCommitsNotSupported)
+	_, err = pkgHandler.CurrentVersion()
+	assert.ErrorIs(t, err, ErrNoVersionCheckedOut)
+
+	versions, err := pkgHandler.Versions()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v1.0.0", "v2.0.0", "v3.0.0"}, versions)
+
+	for _, tc := range []struct {
+		version string
+		name    string
+		digest  string
+	}{
+		{version: "v1.0.0", name: "mock-avs-v1", digest: v1Digest},
+		{version: "v2.0.0", name: "mock-avs-v2", digest: v2Digest},
+	} {
+		require.NoError(t, pkgHandler.CheckoutVersion(tc.version))
+		require.NoError(t, pkgHandler.Check())
+		name, err := pkgHandler.Name()
+		require.NoError(t, err)
+		assert.Equal(t, tc.name, name)
+
+		// The source is detected from the package path
+		pkgHandler := NewPackageHandler(pkgPath)
+		version, err := pkgHandler.CurrentVersion()
+		require.NoError(t, err)
+		assert.Equal(t, tc.version, version)
+		revision, err := pkgHandler.CurrentCommitHash()
+		require.NoError(t, err)
+		assert.Equal(t, tc.digest, revision)
+	}
+
+	assert.ErrorIs(t, pkgHandler.CheckoutVersion("v3.0.0"), ErrDigestMismatch)
+	assert.ErrorIs(t, pkgHandler.CheckoutVersion("v4.0.0"), ErrVersionNotFound)
+}
+
+func TestTarballSource_InvalidIndex(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/index.yml" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, "versions:\n  - version: 1.0.0\n    url: pkg.tar.gz\n    digest: sha256:abc\n")
+		if r.URL.Query().Has("large") {
+			fmt.Fprintf(w, "# %s\n", strings.Repeat("x", maxMetadataSize))
+		}
+	}))
+	defer server.Close()
+
+	pkgHandler, err := NewPackageHandlerFromURL(NewPackageHandlerOptions{
+		Path: t.TempDir(),
+		URL:  "tar+" + server.URL + "/index.yml",
+	})
+	require.NoError(t, err)
+	_, err = pkgHandler.Versions()
+	assert.ErrorIs(t, err, ErrInvalidPackageIndex)
+
+	pkgHandler, err = NewPackageHandlerFromURL(NewPackageHandlerOptions{
+		Path: t.TempDir(),
+		URL:  "tar+" + server.URL + "/missing.yml",
+	})
+	require.NoError(t, err)
+	_, err = pkgHandler.Versions()
+	assert.ErrorIs(t, err, ErrResourceNotFound)
+
+	pkgHandler, err = NewPackageHandlerFromURL(NewPackageHandlerOptions{
+		Path: t.TempDir(),
+		URL:  "tar+" + server.URL + "/index.yml?large",
+	})
+	require.NoError(t, err)
+	_, err = pkgHandler.Versions()
+	assert.ErrorIs(t, err, ErrContentTooLarge)
+}
+
+func TestTarballSource_MaliciousTarball(t *testing.T) {
+	for _, h := range []tar.Header{
+		{Name: "../../evil", Typeflag: tar.TypeReg},
+		{Name: "/evil", Typeflag: tar.TypeReg},
+		{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "/etc/passwd"},
+		{Name: "hardlink", Typeflag: tar.TypeLink, Linkname: "../../evil"},
+	} {
+		t.Run(h.Name, func(t *testing.T) {
+			var content bytes.Buffer
+			gw := gzip.NewWriter(&content)
+			tw := tar.NewWriter(gw)
+			h.Mode = 0o644
+			require.NoError(t, tw.WriteHeader(&h))
+			require.NoError(t, tw.Close())
+			require.NoError(t, gw.Close())
+			sum := sha256.Sum256(content.Bytes())
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/pkg/index.yml":
+					fmt.Fprintf(w, "versions:\n  - version: v1.0.0\n    url: pkg.tar.gz\n    digest: sha256:%s\n", hex.EncodeToString(sum[:]))
+				case "/pkg/pkg.tar.gz":
+					w.Write(content.Bytes())
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+			defer server.Close()
+
+			dir := t.TempDir()
+			pkgPath := filepath.Join(dir, "a", "b", "pkg")
+			pkgHandler, err := NewPackageHandlerFromURL(NewPackageHandlerOptions{
+				Path: pkgPath,
+				URL:  "tar+" + server.URL + "/pkg/index.yml",
+			})
+			require.NoError(t, err)
+			assert.ErrorIs(t, pkgHandler.CheckoutVersion("v1.0.0"), utils.ErrUnsafeTarEntry)
+			assert.NoFileExists(t, filepath.Join(dir, "a", "evil"))
+			assert.NoFileExists(t, filepath.Join(pkgPath, "link"))
+			assert.NoFileExists(t, filepath.Join(pkgPath, "hardlink"))
+		})
+	}
+}
+
+// ociRegistry is a minimal OCI registry serving package artifacts from the
+// mock-avs repository, and requiring an anonymous bearer token.
+type ociRegistry struct {
+	tags      map[string][]byte
+	blobs     map[string][]byte
+	serverURL string
+}
+
+func newOCIRegistry(t *testing.T) (*ociRegistry, *httptest.Server) {
+	r := &ociRegistry{
+		tags:  make(map[string][]byte),
+		blobs: make(map[string][]byte),
+	}
+	server := httptest.NewServer(r)
+	r.serverURL = server.URL
+	t.Cleanup(server.Close)
+	return r, server
+}
+
+func (r *ociRegistry) push(t *testing.T, tag string, layer []byte, mediaType string) string {
+	t.Helper()
+	layerSum := sha256.Sum256(layer)
+	layerDigest := "sha256:" + hex.EncodeToString(layerSum[:])
+	r.blobs[layerDigest] = layer
+	manifest, err := json.Marshal(map[string]interface{}{
+		"schemaVersion": 2,
+		"mediaType":     ocispec.MediaTypeImageManifest,
+		"layers": []map[string]interface{}{
+			{"mediaType": mediaType, "digest": layerDigest, "size": len(layer)},
+		},
+	})
+	require.NoError(t, err)
+	r.tags[tag] = manifest
+	manifestSum := sha256.Sum256(manifest)
+	return "sha256:" + hex.EncodeToString(manifestSum[:])
+}
+
+func (r *ociRegistry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.URL.Path == "/token" {
+		fmt.Fprint(w, `{"token":"mock-token"}`)
+		return
+	}
+	if req.Header.Get("Authorization") != "Bearer mock-token" {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="`+r.serverURL+`/token",service="mock-registry"`)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	const prefix = "/v2/nethermindeth/mock-avs/"
+	if !strings.HasPrefix(req.URL.Path, prefix) {
+		http.NotFound(w, req)
+		return
+	}
+	path := strings.TrimPrefix(req.URL.Path, prefix)
+	switch {
+	case path == "tags/list":
+		tags := []string{"latest"}
+		for tag := range r.tags {
+			tags = append(tags, tag)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"name": "nethermindeth/mock-avs", "tags": tags})
+	case strings.HasPrefix(path, "manifests/"):
+		manifest, ok := r.tags[strings.TrimPrefix(path, "manifests/")]
+		if !ok {
+			http.NotFound(w, req)
+			return
+		}
+		w.Header().Set("Content-Type", ocispec.MediaTypeImageManifest)
+		w.Write(manifest)
+	case strings.HasPrefix(path, "blobs/"):
+		blob, ok := r.blobs[strings.TrimPrefix(path, "blobs/")]
+		if !ok {
+			http.NotFound(w, req)
+			return
+		}
+		w.Write(blob)
+	default:
+		http.NotFound(w, req)
+	}
+}
+
+func TestOCISource(t *testing.T) {
+	registry, server := newOCIRegistry(t)
+	v1Content, _ := packageTarball(t, "mock-avs-v1")
+	v2Content, _ := packageTarball(t, "mock-avs-v2")
+	v1Digest := registry.push(t, "v1.0.0", v1Content, PackageLayerMediaType)
+	v2Digest := registry.push(t, "v2.0.0", v2Content, ocispec.MediaTypeImageLayerGzip)
+	registry.push(t, "v3.0.0", v2Content, ocispec.MediaTypeImageLayer)
+
+	pkgPath := t.TempDir()
+	pkgHandler, err := NewPackageHandlerFromURL(NewPackageHandlerOptions{
+		Path: pkgPath,
+		URL:  "oci://" + strings.TrimPrefix(server.URL, "http://") + "/nethermindeth/mock-avs",
+	})
+	require.NoError(t, err)
+	assert.False(t, pkgHandler.SupportsCommits())
+
+	versions, err := pkgHandler.Versions()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v1.0.0", "v2.0.0", "v3.0.0"}, versions)
+	latest, err := pkgHandler.LatestVersion()
+	require.NoError(t, err)
+	assert.Equal(t, "v3.0.0", latest)
+
+	for _, tc := range []struct {
+		version string
+		name    string
+		digest  string
+	}{
+		{version: "v1.0.0", name: "mock-avs-v1", digest: v1Digest},
+		{version: "v2.0.0", name: "mock-avs-v2", digest: v2Digest},
+	} {
+		require.NoError(t, pkgHandler.CheckoutVersion(tc.version))
+		require.NoError(t, pkgHandler.Check())
+		name, err := pkgHandler.Name()
+		require.NoError(t, err)
+		assert.Equal(t, tc.name, name)
+		version, err := pkgHandler.CurrentVersion()
+		require.NoError(t, err)
+		assert.Equal(t, tc.version, version)
+		revision, err := pkgHandler.CurrentCommitHash()
+		require.NoError(t, err)
+		assert.Equal(t, tc.digest, revision)
+	}
+	// The source state is kept between checkouts
+	exists, err := afero.Exists(afero.NewOsFs(), filepath.Join(pkgPath, sourceStateFileName))
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	assert.ErrorIs(t, pkgHandler.CheckoutVersion("v3.0.0"), ErrPackageLayerNotFound)
+	assert.ErrorIs(t, pkgHandler.CheckoutVersion("v4.0.0"), ErrVersionNotFound)
+}
+
+func TestNewSource_InvalidURL(t *testing.T) {
+	_, err := NewPackageHandlerFromURL(NewPackageHandlerOptions{
+		Path: t.TempDir(),
+		URL:  "oci://ghcr.io",
+	})
+	assert.ErrorIs(t, err, ErrUnknownSource)
+	_, err = NewPackageHandlerFromURL(NewPackageHandlerOptions{
+		Path: t.TempDir(),
+		URL:  "tar+ftp://example.com/index.yml",
+	})
+	assert.ErrorIs(t, err, ErrUnknownSource)
+}
+
+func TestDetectSource_InvalidState(t *testing.T) {
+	for _, state := range []string{`{"type": "oci"`, `{"type": "svn", "url": "svn://example.com/pkg"}`} {
+		pkgPath := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(pkgPath, sourceStateFileName), []byte(state), 0o644))
+
+		_, err := detectSource(pkgPath, afero.NewOsFs())
+		assert.ErrorIs(t, err, ErrInvalidSourceState)
+
+		pkgHandler := NewPackageHandler(pkgPath)
+		_, err = pkgHandler.Versions()
+		assert.ErrorIs(t, err, ErrInvalidSourceState)
+		_, err = pkgHandler.CurrentVersion()
+		assert.ErrorIs(t, err, ErrInvalidSourceState)
+		assert.ErrorIs(t, pkgHandler.CheckoutVersion("v1.0.0"), ErrInvalidSourceState)
+	}
+}
+
+func TestReadLimited(t *testing.T) {
+	data, err := readLimited(strings.NewReader("1234"), 4)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("1234"), data)
+	_, err = readLimited(strings.NewReader("12345"), 4)
+	assert.ErrorIs(t, err, ErrContentTooLarge)
+}
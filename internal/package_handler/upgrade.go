@@ -0,0 +1,198 @@
+package package_handler
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/docker/distribution/reference"
+	"golang.org/x/mod/semver"
+	"gopkg.in/yaml.v3"
+)
+
+// Upgrade represents the upgrade field of the manifest, which defines the upgrade
+// policy of the package version. For backward compatibility, the upgrade field
+// could also be defined as a plain string, which is stored in the Policy field.
+type Upgrade struct {
+	// Policy is the upgrade recommendation, for instance "recommended" or "required".
+	Policy string `yaml:"policy"`
+	// From is the list of version ranges the package version supports upgrading
+	// from. A version range is a space separated list of comparisons like
+	// ">=v1.0.0 <v2.0.0", and all of them must match. If empty, upgrading from
+	// any previous version is supported.
+	From []string `yaml:"from,omitempty"`
+	// VolumesCompatible is false if the data volumes of previous versions can not
+	// be reused by this version. Defaults to true.
+	VolumesCompatible *bool `yaml:"volumes_compatible,omitempty"`
+	// Migrations is the list of migrations to run between the stop of the previous
+	// version and the start of this one.
+	Migrations []Migration `yaml:"migrations,omitempty"`
+}
+
+// Migration is a container image that is run with the data volumes of the instance
+// mounted, to migrate its data between versions.
+type Migration struct {
+	// Image is the docker image of the migration.
+	Image string `yaml:"image"`
+	// Args are the arguments passed to the migration container.
+	Args []string `yaml:"args,omitempty"`
+	// From is an optional version range. If set, the migration only runs when
+	// upgrading from a version inside the range.
+	From string `yaml:"from,omitempty"`
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler to support both the plain string and
+// the object formats of the upgrade field.
+func (u *Upgrade) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*u = Upgrade{Policy: value.Value}
+		return nil
+	}
+	type upgrade Upgrade
+	var out upgrade
+	if err := value.Decode(&out); err != nil {
+		return err
+	}
+	*u = Upgrade(out)
+	return nil
+}
+
+func (u *Upgrade) isZero() bool {
+	return u.Policy == "" && len(u.From) == 0 && u.VolumesCompatible == nil && len(u.Migrations) == 0
+}
+
+func (u *Upgrade) validate() error {
+	var invalidFields []string
+	if u.Policy == "" {
+		invalidFields = append(invalidFields, "upgrade.policy -> (empty)")
+	}
+	for i, r := range u.From {
+		if _, err := parseVersionRange(r); err != nil {
+			invalidFields = append(invalidFields, fmt.Sprintf("upgrade.from[%d] -> (%v)", i, err))
+		}
+	}
+	for i, m := range u.Migrations {
+		if m.Image == "" {
+			invalidFields = append(invalidFields, fmt.Sprintf("upgrade.migrations[%d].image -> (empty)", i))
+		} else if _, err := reference.ParseNormalizedNamed(m.Image); err != nil {
+			invalidFields = append(invalidFields, fmt.Sprintf("upgrade.migrations[%d].image -> (invalid docker image: %v)", i, err))
+		}
+		if m.From != "" {
+			if _, err := parseVersionRange(m.From); err != nil {
+				invalidFields = append(invalidFields, fmt.Sprintf("upgrade.migrations[%d].from -> (%v)", i, err))
+			}
+		}
+	}
+	if len(invalidFields) > 0 {
+		return InvalidConfError{
+			message:       "Invalid upgrade",
+			invalidFields: invalidFields,
+		}
+	}
+	return nil
+}
+
+// SupportsUpgradeFrom returns true if upgrading from the given version to the
+// package version is supported.
+func (u *Upgrade) SupportsUpgradeFrom(version string) (bool, error) {
+	if len(u.From) == 0 {
+		return true, nil
+	}
+	if !semver.IsValid(version) {
+		return false, fmt.Errorf("%w: %s", ErrInvalidVersion, version)
+	}
+	for _, r := range u.From {
+		vr, err := parseVersionRange(r)
+		if err != nil {
+			return false, err
+		}
+		if vr.contains(version) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// DataCompatible returns true if the data volumes of previous versions can be
+// reused by the package version.
+func (u *Upgrade) DataCompatible() bool {
+	return u.VolumesCompatible == nil || *u.VolumesCompatible
+}
+
+// MigrationsFrom returns the migrations to run when upgrading from the given
+// version. If the version is not a valid semantic version, only the migrations
+// without a version range are returned.
+func (u *Upgrade) MigrationsFrom(version string) ([]Migration, error) {
+	var migrations []Migration
+	for _, m := range u.Migrations {
+		if m.From == "" {
+			migrations = append(migrations, m)
+			continue
+		}
+		if !semver.IsValid(version) {
+			continue
+		}
+		vr, err := parseVersionRange(m.From)
+		if err != nil {
+			return nil, err
+		}
+		if vr.contains(version) {
+			migrations = append(migrations, m)
+		}
+	}
+	return migrations, nil
+}
+
+type versionComparison struct {
+	op      string
+	version string
+}
+
+// versionRange is a list of comparisons that a version must satisfy.
+type versionRange []versionComparison
+
+var versionOperators = []string{">=", "<=", ">", "<", "="}
+
+func parseVersionRange(r string) (versionRange, error) {
+	fields := strings.Fields(r)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("empty version range")
+	}
+	out := make(versionRange, 0, len(fields))
+	for _, field := range fields {
+		c := versionComparison{op: "=", version: field}
+		for _, op := range versionOperators {
+			if strings.HasPrefix(field, op) {
+				c = versionComparison{op: op, version: strings.TrimPrefix(field, op)}
+				break
+			}
+		}
+		if !semver.IsValid(c.version) {
+			return nil, fmt.Errorf("invalid version %s in range \"%s\"", c.version, r)
+		}
+		out = append(out, c)
+	}
+	return out, nil
+}
+
+func (vr versionRange) contains(version string) bool {
+	for _, c := range vr {
+		cmp := semver.Compare(version, c.version)
+		var ok bool
+		switch c.op {
+		case ">=":
+			ok = cmp >= 0
+		case "<=":
+			ok = cmp <= 0
+		case ">":
+			ok = cmp > 0
+		case "<":
+			ok = cmp < 0
+		default:
+			ok = cmp == 0
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
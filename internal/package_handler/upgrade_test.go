@@ -0,0 +1,154 @@
+package package_handler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestUpgrade_UnmarshalYAML(t *testing.T) {
+	volumesCompatible := false
+	tests := []struct {
+		name string
+		data string
+		want Upgrade
+	}{
+		{
+			name: "legacy string",
+			data: "upgrade: required",
+			want: Upgrade{Policy: "required"},
+		},
+		{
+			name: "full object",
+			data: `upgrade:
+  policy: recommended
+  from: [">=v1.0.0 <v2.0.0"]
+  volumes_compatible: false
+  migrations:
+    - image: migrate:v1
+      args: ["--dry-run"]
+      from: "<v1.5.0"`,
+			want: Upgrade{
+				Policy:            "recommended",
+				From:              []string{">=v1.0.0 <v2.0.0"},
+				VolumesCompatible: &volumesCompatible,
+				Migrations: []Migration{
+					{Image: "migrate:v1", Args: []string{"--dry-run"}, From: "<v1.5.0"},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out struct {
+				Upgrade Upgrade `yaml:"upgrade"`
+			}
+			require.NoError(t, yaml.Unmarshal([]byte(tt.data), &out))
+			assert.Equal(t, tt.want, out.Upgrade)
+		})
+	}
+}
+
+func TestUpgrade_SupportsUpgradeFrom(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    []string
+		version string
+		want    bool
+		err     error
+	}{
+		{
+			name:    "no ranges",
+			version: "v0.1.0",
+			want:    true,
+		},
+		{
+			name:    "inside range",
+			from:    []string{">=v1.0.0 <v2.0.0"},
+			version: "v1.5.3",
+			want:    true,
+		},
+		{
+			name:    "outside range",
+			from:    []string{">=v1.0.0 <v2.0.0"},
+			version: "v2.0.0",
+			want:    false,
+		},
+		{
+			name:    "exact version in second range",
+			from:    []string{">v1.0.0", "v0.9.1"},
+			version: "v0.9.1",
+			want:    true,
+		},
+		{
+			name:    "invalid version",
+			from:    []string{">=v1.0.0"},
+			version: "local",
+			err:     ErrInvalidVersion,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := Upgrade{Policy: "required", From: tt.from}
+			got, err := u.SupportsUpgradeFrom(tt.version)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestUpgrade_MigrationsFrom(t *testing.T) {
+	u := Upgrade{
+		Policy: "required",
+		Migrations: []Migration{
+			{Image: "migrate-all"},
+			{Image: "migrate-v1", From: "<v2.0.0"},
+			{Image: "migrate-v2", From: ">=v2.0.0 <v3.0.0"},
+		},
+	}
+	tests := []struct {
+		name    string
+		version string
+		want    []string
+	}{
+		{
+			name:    "from v1",
+			version: "v1.2.0",
+			want:    []string{"migrate-all", "migrate-v1"},
+		},
+		{
+			name:    "from v2",
+			version: "v2.1.0",
+			want:    []string{"migrate-all", "migrate-v2"},
+		},
+		{
+			name:    "not semver",
+			version: "local",
+			want:    []string{"migrate-all"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrations, err := u.MigrationsFrom(tt.version)
+			require.NoError(t, err)
+			var images []string
+			for _, m := range migrations {
+				images = append(images, m.Image)
+			}
+			assert.Equal(t, tt.want, images)
+		})
+	}
+}
+
+func TestUpgrade_DataCompatible(t *testing.T) {
+	compatible, incompatible := true, false
+	assert.True(t, (&Upgrade{}).DataCompatible())
+	assert.True(t, (&Upgrade{VolumesCompatible: &compatible}).DataCompatible())
+	assert.False(t, (&Upgrade{VolumesCompatible: &incompatible}).DataCompatible())
+}
@@ -0,0 +1,131 @@
+package profile
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var clauseRe = regexp.MustCompile(`^(!?)\s*([A-Za-z0-9_.-]+)\s*(?:(==|!=)\s*(.*))?$`)
+
+// Condition is the condition for an option to be asked, built from its
+// depends_on and when fields. The zero value is always true.
+type Condition struct {
+	// anyOf is a list of alternatives where all the clauses of an alternative
+	// must hold.
+	anyOf [][]clause
+}
+
+type clause struct {
+	option string
+	// op is one of ==, != for comparisons, or empty to check if the option is
+	// truthy, or ! to check if it is falsy.
+	op    string
+	value string
+}
+
+// Condition parses the condition of the option. The option is asked only if all
+// the options in depends_on are truthy and the when expression holds.
+//
+// A when expression is a list of alternatives separated by `||`, where each
+// alternative is a list of clauses separated by `&&`. A clause could be:
+//
+//   - `<option> == <value>` or `<option> != <value>`, comparing the value of the
+//     option. Values could be quoted.
+//   - `<option>`, true if the option is truthy, this is, set with a non-empty
+//     value that is not false.
+//   - `!<option>`, true if the option is not truthy.
+func (o *Option) Condition() (Condition, error) {
+	var dependsOn []clause
+	for _, name := range o.DependsOn {
+		dependsOn = append(dependsOn, clause{option: name})
+	}
+	var c Condition
+	if strings.TrimSpace(o.When) == "" {
+		if len(dependsOn) > 0 {
+			c.anyOf = [][]clause{dependsOn}
+		}
+		return c, nil
+	}
+	for _, alternative := range strings.Split(o.When, "||") {
+		clauses := append([]clause{}, dependsOn...)
+		for _, expr := range strings.Split(alternative, "&&") {
+			m := clauseRe.FindStringSubmatch(strings.TrimSpace(expr))
+			if m == nil || (m[1] == "!" && m[3] != "") {
+				return Condition{}, fmt.Errorf("%w: %q", ErrInvalidCondition, expr)
+			}
+			cl := clause{option: m[2], op: m[3], value: unquote(strings.TrimSpace(m[4]))}
+			if m[1] == "!" {
+				cl.op = "!"
+			}
+			clauses = append(clauses, cl)
+		}
+		c.anyOf = append(c.anyOf, clauses)
+	}
+	return c, nil
+}
+
+// Options returns the names of the options referenced by the condition.
+func (c Condition) Options() []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, alternative := range c.anyOf {
+		for _, cl := range alternative {
+			if !seen[cl.option] {
+				seen[cl.option] = true
+				names = append(names, cl.option)
+			}
+		}
+	}
+	return names
+}
+
+// Eval evaluates the condition with the given option values, by option name.
+// Options without value are considered empty.
+func (c Condition) Eval(values map[string]string) bool {
+	if len(c.anyOf) == 0 {
+		return true
+	}
+	for _, alternative := range c.anyOf {
+		holds := true
+		for _, cl := range alternative {
+			if !cl.eval(values) {
+				holds = false
+				break
+			}
+		}
+		if holds {
+			return true
+		}
+	}
+	return false
+}
+
+func (cl clause) eval(values map[string]string) bool {
+	v := values[cl.option]
+	switch cl.op {
+	case "==":
+		return v == cl.value
+	case "!=":
+		return v != cl.value
+	case "!":
+		return !truthy(v)
+	default:
+		return truthy(v)
+	}
+}
+
+func truthy(v string) bool {
+	if b, err := strconv.ParseBool(v); err == nil {
+		return b
+	}
+	return v != ""
+}
+
+func unquote(v string) string {
+	if len(v) >= 2 && (v[0] == '"' && v[len(v)-1] == '"' || v[0] == '\'' && v[len(v)-1] == '\'') {
+		return v[1 : len(v)-1]
+	}
+	return v
+}
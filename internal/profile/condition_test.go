@@ -0,0 +1,167 @@
+package profile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptionCondition(t *testing.T) {
+	tests := []struct {
+		name    string
+		option  Option
+		values  map[string]string
+		want    bool
+		options []string
+		wantErr bool
+	}{
+		{
+			name:   "no condition",
+			option: Option{},
+			values: map[string]string{},
+			want:   true,
+		},
+		{
+			name:    "depends_on truthy",
+			option:  Option{DependsOn: []string{"metrics"}},
+			values:  map[string]string{"metrics": "true"},
+			want:    true,
+			options: []string{"metrics"},
+		},
+		{
+			name:    "depends_on false",
+			option:  Option{DependsOn: []string{"metrics"}},
+			values:  map[string]string{"metrics": "false"},
+			want:    false,
+			options: []string{"metrics"},
+		},
+		{
+			name:    "depends_on missing",
+			option:  Option{DependsOn: []string{"metrics"}},
+			values:  map[string]string{},
+			want:    false,
+			options: []string{"metrics"},
+		},
+		{
+			name:    "when equal",
+			option:  Option{When: `network == "holesky"`},
+			values:  map[string]string{"network": "holesky"},
+			want:    true,
+			options: []string{"network"},
+		},
+		{
+			name:    "when not equal",
+			option:  Option{When: "network != mainnet"},
+			values:  map[string]string{"network": "mainnet"},
+			want:    false,
+			options: []string{"network"},
+		},
+		{
+			name:    "when negation",
+			option:  Option{When: "!external-rpc"},
+			values:  map[string]string{"external-rpc": ""},
+			want:    true,
+			options: []string{"external-rpc"},
+		},
+		{
+			name:    "when and",
+			option:  Option{When: "network == holesky && metrics"},
+			values:  map[string]string{"network": "holesky", "metrics": "0"},
+			want:    false,
+			options: []string{"network", "metrics"},
+		},
+		{
+			name:    "when or",
+			option:  Option{When: "network == holesky || network == 'goerli'"},
+			values:  map[string]string{"network": "goerli"},
+			want:    true,
+			options: []string{"network"},
+		},
+		{
+			name:    "depends_on and when",
+			option:  Option{DependsOn: []string{"metrics"}, When: "network == holesky || network == goerli"},
+			values:  map[string]string{"network": "goerli", "metrics": "false"},
+			want:    false,
+			options: []string{"metrics", "network"},
+		},
+		{
+			name:    "invalid when",
+			option:  Option{When: "network = holesky"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid negated comparison",
+			option:  Option{When: "!network == holesky"},
+			wantErr: true,
+		},
+		{
+			name:    "empty clause",
+			option:  Option{When: "metrics && "},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := tt.option.Condition()
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidCondition)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, c.Eval(tt.values))
+			assert.Equal(t, tt.options, c.Options())
+		})
+	}
+}
+
+func TestOptionValidateCondition(t *testing.T) {
+	message := "Option #2 is invalid"
+	previous := []string{"network"}
+
+	tests := []struct {
+		name   string
+		option Option
+		want   error
+	}{
+		{
+			name:   "valid",
+			option: Option{DependsOn: []string{"network"}, When: "network == holesky"},
+			want:   nil,
+		},
+		{
+			name:   "depends_on unknown option",
+			option: Option{DependsOn: []string{"metrics"}},
+			want: InvalidProfileError{
+				message:       message,
+				invalidFields: []string{"options.depends_on"},
+			},
+		},
+		{
+			name:   "when unknown option",
+			option: Option{When: "metrics"},
+			want: InvalidProfileError{
+				message:       message,
+				invalidFields: []string{"options.when"},
+			},
+		},
+		{
+			name:   "when invalid expression",
+			option: Option{When: "network ="},
+			want: InvalidProfileError{
+				message:       message,
+				invalidFields: []string{"options.when"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.option.validateCondition(1, previous)
+			if tt.want == nil {
+				assert.NoError(t, got)
+			} else {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
@@ -1,6 +1,14 @@
 package profile
 
-import "strings"
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	ErrInvalidCondition = errors.New("invalid option condition")
+	ErrOneOfNotSet      = errors.New("one of these options must be set")
+)
 
 type InvalidProfileError struct {
 	message       string
@@ -0,0 +1,129 @@
+package profile
+
+// Extend returns the profile resulting of overriding the given base profile with
+// the profile fields:
+//
+//   - Options are matched by name. The fields set in an option of the profile
+//     override the ones of the base option with the same name, and options not
+//     present in the base profile are added after the base options.
+//   - Monitoring targets are matched by service. The targets of a service in the
+//     profile replace all the targets of that service in the base profile.
+//   - Health checks are matched by name. The health checks of the profile
+//     replace the ones of the base profile with the same name, and the rest
+//     are added after the base health checks.
+//   - The one_of groups of both profiles are kept.
+//   - The API target, hardware requirements overrides and plugin overrides of
+//     the profile replace the ones of the base profile, if set.
+//
+// Neither the profile nor the base profile are modified.
+func (p *Profile) Extend(base *Profile) *Profile {
+	extended := &Profile{
+		Name:                          p.Name,
+		Extends:                       p.Extends,
+		HardwareRequirementsOverrides: base.HardwareRequirementsOverrides,
+		PluginOverrides:               base.PluginOverrides,
+		API:                           base.API,
+	}
+	if p.HardwareRequirementsOverrides != nil {
+		extended.HardwareRequirementsOverrides = p.HardwareRequirementsOverrides
+	}
+	if p.PluginOverrides.Image != "" {
+		extended.PluginOverrides = p.PluginOverrides
+	}
+	if p.API != nil {
+		extended.API = p.API
+	}
+
+	// Options
+	overridden := make(map[string]bool, len(p.Options))
+	for _, baseOption := range base.Options {
+		option := baseOption
+		for _, o := range p.Options {
+			if o.Name == baseOption.Name {
+				option = baseOption.override(o)
+				overridden[o.Name] = true
+				break
+			}
+		}
+		extended.Options = append(extended.Options, option)
+	}
+	for _, o := range p.Options {
+		if !overridden[o.Name] {
+			extended.Options = append(extended.Options, o)
+		}
+	}
+
+	// The one_of groups of both profiles must hold
+	if len(base.OneOf)+len(p.OneOf) > 0 {
+		extended.OneOf = append(append([][]string{}, base.OneOf...), p.OneOf...)
+	}
+
+	// Monitoring targets
+	services := make(map[string]bool, len(p.Monitoring.Targets))
+	for _, t := range p.Monitoring.Targets {
+		services[t.Service] = true
+	}
+	for _, t := range base.Monitoring.Targets {
+		if !services[t.Service] {
+			extended.Monitoring.Targets = append(extended.Monitoring.Targets, t)
+		}
+	}
+	extended.Monitoring.Targets = append(extended.Monitoring.Targets, p.Monitoring.Targets...)
+
+	// Health checks
+	checks := make(map[string]HealthCheck, len(p.HealthChecks))
+	for _, h := range p.HealthChecks {
+		checks[h.Name] = h
+	}
+	for _, baseCheck := range base.HealthChecks {
+		if h, ok := checks[baseCheck.Name]; ok {
+			extended.HealthChecks = append(extended.HealthChecks, h)
+			delete(checks, baseCheck.Name)
+		} else {
+			extended.HealthChecks = append(extended.HealthChecks, baseCheck)
+		}
+	}
+	for _, h := range p.HealthChecks {
+		if _, ok := checks[h.Name]; ok {
+			extended.HealthChecks = append(extended.HealthChecks, h)
+		}
+	}
+
+	return extended
+}
+
+// override returns the option with the fields set in the given option replaced.
+// Boolean fields could only be enabled.
+func (o Option) override(with Option) Option {
+	if with.Target != "" {
+		o.Target = with.Target
+	}
+	if with.Type != "" {
+		o.Type = with.Type
+	}
+	if with.Default != "" {
+		o.Default = with.Default
+	}
+	if with.Help != "" {
+		o.Help = with.Help
+	}
+	if with.Hidden {
+		o.Hidden = true
+	}
+	if with.ValidateDef != nil {
+		o.ValidateDef = with.ValidateDef
+	}
+	if with.DependsOn != nil {
+		o.DependsOn = with.DependsOn
+	}
+	if with.When != "" {
+		o.When = with.When
+	}
+	if with.RenamedFrom != nil {
+		o.RenamedFrom = with.RenamedFrom
+	}
+	if with.ValueMap != nil {
+		o.ValueMap = with.ValueMap
+	}
+	return o
+}
@@ -0,0 +1,70 @@
+package profile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProfileExtend(t *testing.T) {
+	port := 9090
+	base := &Profile{
+		Name:            "base",
+		PluginOverrides: PluginOverrides{Image: "plugin:base"},
+		Options: []Option{
+			{Name: "network", Target: "NETWORK", Type: "select", Default: "mainnet", Help: "Network", ValidateDef: &Validate{Options: []string{"mainnet", "holesky"}}},
+			{Name: "api-key", Target: "API_KEY", Type: "str", Help: "API key"},
+		},
+		Monitoring: Monitoring{Targets: []MonitoringTarget{
+			{Service: "node", Port: &port, Path: "/metrics"},
+			{Service: "exporter", Port: &port, Path: "/metrics"},
+		}},
+		API: &APITarget{Service: "node", Port: 8080},
+		HealthChecks: []HealthCheck{
+			{Name: "node", Service: "node", Type: HealthCheckHTTP, Port: 8080, Path: "/eigen/node/health"},
+			{Name: "db", Service: "db", Type: HealthCheckTCP, Port: 5432},
+		},
+	}
+	child := &Profile{
+		Name:    "holesky",
+		Extends: "base",
+		Options: []Option{
+			{Name: "network", Default: "holesky"},
+			{Name: "api-key", Hidden: true, When: "network == mainnet"},
+			{Name: "metrics", Target: "METRICS", Type: "bool", Default: "true", Help: "Metrics"},
+		},
+		Monitoring: Monitoring{Targets: []MonitoringTarget{
+			{Service: "node", Port: &port, Path: "/node/metrics"},
+		}},
+		HealthChecks: []HealthCheck{
+			{Name: "relayer", Service: "relayer", Type: HealthCheckCommand, Command: []string{"relayer", "status"}},
+			{Name: "db", Service: "db", Type: HealthCheckCommand, Command: []string{"pg_isready"}},
+		},
+	}
+
+	got := child.Extend(base)
+	assert.Equal(t, &Profile{
+		Name:            "holesky",
+		Extends:         "base",
+		PluginOverrides: PluginOverrides{Image: "plugin:base"},
+		Options: []Option{
+			{Name: "network", Target: "NETWORK", Type: "select", Default: "holesky", Help: "Network", ValidateDef: &Validate{Options: []string{"mainnet", "holesky"}}},
+			{Name: "api-key", Target: "API_KEY", Type: "str", Help: "API key", Hidden: true, When: "network == mainnet"},
+			{Name: "metrics", Target: "METRICS", Type: "bool", Default: "true", Help: "Metrics"},
+		},
+		Monitoring: Monitoring{Targets: []MonitoringTarget{
+			{Service: "exporter", Port: &port, Path: "/metrics"},
+			{Service: "node", Port: &port, Path: "/node/metrics"},
+		}},
+		API: &APITarget{Service: "node", Port: 8080},
+		HealthChecks: []HealthCheck{
+			{Name: "node", Service: "node", Type: HealthCheckHTTP, Port: 8080, Path: "/eigen/node/health"},
+			{Name: "db", Service: "db", Type: HealthCheckCommand, Command: []string{"pg_isready"}},
+			{Name: "relayer", Service: "relayer", Type: HealthCheckCommand, Command: []string{"relayer", "status"}},
+		},
+	}, got)
+
+	// The base profile is not modified
+	assert.Equal(t, "mainnet", base.Options[0].Default)
+	assert.False(t, base.Options[1].Hidden)
+}
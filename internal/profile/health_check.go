@@ -0,0 +1,114 @@
+package profile
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+)
+
+const (
+	HealthCheckHTTP    = "http"
+	HealthCheckTCP     = "tcp"
+	HealthCheckCommand = "command"
+)
+
+// HealthCheck represents a health check within the health_checks field of a
+// profile. Depending on its type, a health check is an HTTP request expecting
+// a status code, a TCP connection to a port, or a command run inside the
+// service container expecting a zero exit code.
+type HealthCheck struct {
+	Name    string `yaml:"name"`
+	Service string `yaml:"service"`
+	Type    string `yaml:"type"`
+	// Port is the port of the service to check, required by http and tcp
+	// health checks.
+	Port int `yaml:"port,omitempty"`
+	// Path is the path of the HTTP request of http health checks.
+	Path string `yaml:"path,omitempty"`
+	// ExpectedStatus is the status code expected by http health checks, 200 by
+	// default.
+	ExpectedStatus int `yaml:"expected_status,omitempty"`
+	// Command is the command run by command health checks.
+	Command []string `yaml:"command,omitempty"`
+	// Timeout is the maximum duration of the health check, 10s by default.
+	Timeout string `yaml:"timeout,omitempty"`
+}
+
+func validateHealthChecks(checks []HealthCheck) error {
+	err := errors.New("invalid health checks")
+	ok := true
+	names := make([]string, 0, len(checks))
+	for i, check := range checks {
+		if valErr := check.validate(i, names); valErr != nil {
+			ok = false
+			err = fmt.Errorf("%w: %w", err, valErr)
+		}
+		names = append(names, check.Name)
+	}
+	if !ok {
+		return err
+	}
+	return nil
+}
+
+// validate validates the health check. The names are the names of the
+// previous health checks of the profile, used to check the name is unique.
+func (h *HealthCheck) validate(idx int, names []string) error {
+	var missingFields, invalidFields []string
+
+	if h.Name == "" {
+		missingFields = append(missingFields, "health_checks.name")
+	} else if slices.Contains(names, h.Name) {
+		invalidFields = append(invalidFields, "health_checks.name")
+	}
+	if h.Service == "" {
+		missingFields = append(missingFields, "health_checks.service")
+	}
+
+	switch h.Type {
+	case "":
+		missingFields = append(missingFields, "health_checks.type")
+	case HealthCheckHTTP, HealthCheckTCP:
+		if h.Port == 0 {
+			missingFields = append(missingFields, "health_checks.port")
+		} else if h.Port < 0 || h.Port > math.MaxUint16 {
+			invalidFields = append(invalidFields, "health_checks.port")
+		}
+	case HealthCheckCommand:
+		if len(h.Command) == 0 {
+			missingFields = append(missingFields, "health_checks.command")
+		}
+	default:
+		invalidFields = append(invalidFields, "health_checks.type")
+	}
+
+	if h.Type == HealthCheckHTTP {
+		if h.Path == "" {
+			missingFields = append(missingFields, "health_checks.path")
+		} else if _, err := url.Parse("http://localhost:8080" + h.Path); err != nil {
+			invalidFields = append(invalidFields, "health_checks.path")
+		}
+		if h.ExpectedStatus != 0 && (h.ExpectedStatus < 100 || h.ExpectedStatus > 599) {
+			invalidFields = append(invalidFields, "health_checks.expected_status")
+		}
+	}
+
+	if h.Timeout != "" {
+		if d, err := time.ParseDuration(h.Timeout); err != nil || d <= 0 {
+			invalidFields = append(invalidFields, "health_checks.timeout")
+		}
+	}
+
+	if len(missingFields) > 0 || len(invalidFields) > 0 {
+		return InvalidProfileError{
+			message:       "Health check #" + strconv.Itoa(idx+1) + " is invalid",
+			missingFields: missingFields,
+			invalidFields: invalidFields,
+		}
+	}
+	return nil
+}
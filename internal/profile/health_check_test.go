@@ -0,0 +1,97 @@
+package profile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthCheckValidate(t *testing.T) {
+	message := "Health check #2 is invalid"
+	previous := []string{"node"}
+
+	tests := []struct {
+		name  string
+		check HealthCheck
+		want  error
+	}{
+		{
+			name:  "valid http",
+			check: HealthCheck{Name: "api", Service: "api", Type: HealthCheckHTTP, Port: 8080, Path: "/health", ExpectedStatus: 204, Timeout: "5s"},
+		},
+		{
+			name:  "valid tcp",
+			check: HealthCheck{Name: "db", Service: "db", Type: HealthCheckTCP, Port: 5432},
+		},
+		{
+			name:  "valid command",
+			check: HealthCheck{Name: "db", Service: "db", Type: HealthCheckCommand, Command: []string{"pg_isready", "-U", "postgres"}},
+		},
+		{
+			name:  "missing fields",
+			check: HealthCheck{},
+			want: InvalidProfileError{
+				message:       message,
+				missingFields: []string{"health_checks.name", "health_checks.service", "health_checks.type"},
+			},
+		},
+		{
+			name:  "duplicated name",
+			check: HealthCheck{Name: "node", Service: "node", Type: HealthCheckTCP, Port: 8080},
+			want: InvalidProfileError{
+				message:       message,
+				invalidFields: []string{"health_checks.name"},
+			},
+		},
+		{
+			name:  "unknown type",
+			check: HealthCheck{Name: "api", Service: "api", Type: "grpc", Port: 8080},
+			want: InvalidProfileError{
+				message:       message,
+				invalidFields: []string{"health_checks.type"},
+			},
+		},
+		{
+			name:  "http without port and path",
+			check: HealthCheck{Name: "api", Service: "api", Type: HealthCheckHTTP},
+			want: InvalidProfileError{
+				message:       message,
+				missingFields: []string{"health_checks.port", "health_checks.path"},
+			},
+		},
+		{
+			name:  "http invalid status and timeout",
+			check: HealthCheck{Name: "api", Service: "api", Type: HealthCheckHTTP, Port: 8080, Path: "/health", ExpectedStatus: 42, Timeout: "soon"},
+			want: InvalidProfileError{
+				message:       message,
+				invalidFields: []string{"health_checks.expected_status", "health_checks.timeout"},
+			},
+		},
+		{
+			name:  "tcp invalid port",
+			check: HealthCheck{Name: "db", Service: "db", Type: HealthCheckTCP, Port: 70000},
+			want: InvalidProfileError{
+				message:       message,
+				invalidFields: []string{"health_checks.port"},
+			},
+		},
+		{
+			name:  "command without command",
+			check: HealthCheck{Name: "db", Service: "db", Type: HealthCheckCommand},
+			want: InvalidProfileError{
+				message:       message,
+				missingFields: []string{"health_checks.command"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.check.validate(1, previous)
+			if tt.want == nil {
+				assert.NoError(t, got)
+			} else {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
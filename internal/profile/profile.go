@@ -7,8 +7,13 @@ import (
 	"net/url"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
+
+	"github.com/docker/distribution/reference"
+	"github.com/prometheus/common/model"
 
 	"github.com/NethermindEth/eigenlayer/internal/utils"
 )
@@ -18,11 +23,14 @@ var pathRe = regexp.MustCompile(`^(/|./|../|[^/ ]([^/ ]*/)*[^/ ]*$)`)
 // Profile represents a profile file of a package
 type Profile struct {
 	Name                          string                         `yaml:"-"`
+	Extends                       string                         `yaml:"extends,omitempty"`
 	HardwareRequirementsOverrides *HardwareRequirementsOverrides `yaml:"hardware_requirements_overrides,omitempty"`
 	PluginOverrides               PluginOverrides                `yaml:"plugin_overrides"`
 	Options                       []Option                       `yaml:"options"`
+	OneOf                         [][]string                     `yaml:"one_of,omitempty"`
 	Monitoring                    Monitoring                     `yaml:"monitoring"`
 	API                           *APITarget                     `yaml:"api,omitempty"`
+	HealthChecks                  []HealthCheck                  `yaml:"health_checks,omitempty"`
 }
 
 // Validate validates the profile file
@@ -34,16 +42,38 @@ func (p *Profile) Validate() error {
 
 	invalidOptionsErr := errors.New("invalid options")
 	invalidOptions := false
+	previousNames := make([]string, 0, len(p.Options))
 	for i, option := range p.Options {
 		if err := option.validate(i); err != nil {
 			invalidOptions = true
 			invalidOptionsErr = fmt.Errorf("%w: %w", invalidOptionsErr, err)
 		}
+		if err := option.validateCondition(i, previousNames); err != nil {
+			invalidOptions = true
+			invalidOptionsErr = fmt.Errorf("%w: %w", invalidOptionsErr, err)
+		}
+		if err := option.validateRenames(i, p.Options); err != nil {
+			invalidOptions = true
+			invalidOptionsErr = fmt.Errorf("%w: %w", invalidOptionsErr, err)
+		}
+		previousNames = append(previousNames, option.Name)
+	}
+
+	if err := p.validateOneOf(); err != nil {
+		invalidOptions = true
+		invalidOptionsErr = fmt.Errorf("%w: %w", invalidOptionsErr, err)
 	}
 
 	invalidMonitoringErr := p.Monitoring.validate()
 
-	if len(missingFields) > 0 || invalidOptions || invalidMonitoringErr != nil {
+	var invalidHardwareErr error
+	if p.HardwareRequirementsOverrides != nil {
+		invalidHardwareErr = p.HardwareRequirementsOverrides.validate()
+	}
+	invalidPluginErr := p.PluginOverrides.validate()
+	invalidHealthChecksErr := validateHealthChecks(p.HealthChecks)
+
+	if len(missingFields) > 0 || invalidOptions || invalidMonitoringErr != nil || invalidHardwareErr != nil || invalidPluginErr != nil || invalidHealthChecksErr != nil {
 		var err error = InvalidProfileError{
 			message:       "Invalid profile",
 			missingFields: missingFields,
@@ -54,12 +84,57 @@ func (p *Profile) Validate() error {
 		if invalidMonitoringErr != nil {
 			err = fmt.Errorf("%w: %w", err, invalidMonitoringErr)
 		}
+		if invalidHardwareErr != nil {
+			err = fmt.Errorf("%w: %w", err, invalidHardwareErr)
+		}
+		if invalidPluginErr != nil {
+			err = fmt.Errorf("%w: %w", err, invalidPluginErr)
+		}
+		if invalidHealthChecksErr != nil {
+			err = fmt.Errorf("%w: %w", err, invalidHealthChecksErr)
+		}
 		return err
 	}
 
 	return nil
 }
 
+// validateOneOf validates the one_of groups of the profile. Each group must
+// have at least two options, and all of them must be options of the profile.
+func (p *Profile) validateOneOf() error {
+	var invalidFields []string
+	for _, group := range p.OneOf {
+		invalid := len(group) < 2
+		for _, name := range group {
+			if !slices.ContainsFunc(p.Options, func(o Option) bool { return o.Name == name }) {
+				invalid = true
+			}
+		}
+		if invalid {
+			invalidFields = append(invalidFields, "one_of["+strings.Join(group, ", ")+"]")
+		}
+	}
+	if len(invalidFields) > 0 {
+		return InvalidProfileError{
+			message:       "Invalid one_of groups",
+			invalidFields: invalidFields,
+		}
+	}
+	return nil
+}
+
+// CheckOneOf checks that at least one option of each one_of group of the
+// profile is set with the given option values, by option name. An option is
+// set if its value is truthy, as in depends_on.
+func (p *Profile) CheckOneOf(values map[string]string) error {
+	for _, group := range p.OneOf {
+		if !slices.ContainsFunc(group, func(name string) bool { return truthy(values[name]) }) {
+			return fmt.Errorf("%w: %s", ErrOneOfNotSet, strings.Join(group, ", "))
+		}
+	}
+	return nil
+}
+
 // HardwareRequirementsOverrides represents the hardware requirements overrides field of a profile
 type HardwareRequirementsOverrides struct {
 	MinCPUCores                 int  `yaml:"min_cpu_cores"`
@@ -68,14 +143,44 @@ type HardwareRequirementsOverrides struct {
 	StopIfRequirementsAreNotMet bool `yaml:"stop_if_requirements_are_not_met"`
 }
 
-// TODO: add validation for hardware requirements overrides
+func (h *HardwareRequirementsOverrides) validate() error {
+	var invalidFields []string
+	if h.MinCPUCores < 0 {
+		invalidFields = append(invalidFields, "hardware_requirements_overrides.min_cpu_cores -> (negative value)")
+	}
+	if h.MinRAM < 0 {
+		invalidFields = append(invalidFields, "hardware_requirements_overrides.min_ram -> (negative value)")
+	}
+	if h.MinFreeSpace < 0 {
+		invalidFields = append(invalidFields, "hardware_requirements_overrides.min_free_space -> (negative value)")
+	}
+	if len(invalidFields) > 0 {
+		return InvalidProfileError{
+			message:       "Invalid hardware requirements overrides",
+			invalidFields: invalidFields,
+		}
+	}
+	return nil
+}
 
 // PluginOverrides represents the plugin overrides field of a profile
 type PluginOverrides struct {
 	Image string `yaml:"image"`
 }
 
-// TODO: add validation for plugin overrides
+func (p *PluginOverrides) validate() error {
+	// An empty image means the plugin of the manifest is not overridden
+	if p.Image == "" {
+		return nil
+	}
+	if _, err := reference.ParseNormalizedNamed(p.Image); err != nil {
+		return InvalidProfileError{
+			message:       "Invalid plugin overrides",
+			invalidFields: []string{fmt.Sprintf("plugin_overrides.image -> (invalid docker image: %v)", err)},
+		}
+	}
+	return nil
+}
 
 // Option represents an option within the options field of a profile
 type Option struct {
@@ -86,6 +191,18 @@ type Option struct {
 	Help        string    `yaml:"help"`
 	Hidden      bool      `yaml:"hidden"`
 	ValidateDef *Validate `yaml:"validate,omitempty"`
+	// DependsOn is the list of names of the options that must be truthy for
+	// this option to be asked.
+	DependsOn []string `yaml:"depends_on,omitempty"`
+	// When is an expression that must hold for this option to be asked. See
+	// Option.Condition for its syntax.
+	When string `yaml:"when,omitempty"`
+	// RenamedFrom is the list of names the option had in previous versions of
+	// the profile, used to keep its value on updates.
+	RenamedFrom []string `yaml:"renamed_from,omitempty"`
+	// ValueMap maps values of previous versions of the option to the values
+	// to use in this version, applied on updates before validating them.
+	ValueMap map[string]string `yaml:"value_map,omitempty"`
 }
 
 // Validate validates the option
@@ -105,7 +222,12 @@ func (o *Option) validate(idx int) error {
 	}
 
 	var invalidDefault bool
-	if o.Default != "" {
+	if IsTemplate(o.Default) {
+		// Template defaults are rendered on install, so their value is
+		// validated then. Secrets can't have a default value.
+		_, err := ParseTemplate(o.Name, o.Default)
+		invalidDefault = err != nil || o.Type == "secret"
+	} else if o.Default != "" {
 		switch o.Type {
 		case "str":
 			if o.ValidateDef != nil {
@@ -159,6 +281,27 @@ func (o *Option) validate(idx int) error {
 			} else {
 				invalidDefault = !utils.Contains(o.ValidateDef.Options, o.Default)
 			}
+		case "secret":
+			// Secrets can't have a default value, they must be set by the user
+			invalidDefault = true
+		case "eth_address":
+			invalidDefault = !utils.IsEthAddress(o.Default)
+		case "duration":
+			_, err := time.ParseDuration(o.Default)
+			invalidDefault = err != nil
+		case "byte_size":
+			_, err := utils.ParseByteSize(o.Default)
+			invalidDefault = err != nil
+		case "list":
+			// Each item is validated as a default of the item type
+			item := o.ListItem()
+			for _, v := range utils.SplitList(o.Default) {
+				item.Default = v
+				if item.validate(idx) != nil {
+					invalidDefault = true
+					break
+				}
+			}
 		default:
 			invalidDefault = true
 		}
@@ -166,6 +309,9 @@ func (o *Option) validate(idx int) error {
 	if invalidDefault {
 		invalidFields = append(invalidFields, "options.default")
 	}
+	if o.Type == "list" && !utils.Contains(ListItemTypes, o.ListItem().Type) {
+		invalidFields = append(invalidFields, "options.validate.item_type")
+	}
 
 	if len(missingFields) > 0 || len(invalidFields) > 0 {
 		return InvalidProfileError{
@@ -178,6 +324,72 @@ func (o *Option) validate(idx int) error {
 	return nil
 }
 
+// ListItemTypes are the option types supported as items of list options.
+var ListItemTypes = []string{"str", "int", "float", "bool", "uri", "select", "port", "path_dir", "path_file", "eth_address", "duration", "byte_size"}
+
+// ListItem returns the option describing each item of a list option, with the
+// item type and the validation rules of the list. The item type defaults to str.
+func (o *Option) ListItem() Option {
+	item := *o
+	item.Type = "str"
+	if o.ValidateDef != nil && o.ValidateDef.ItemType != "" {
+		item.Type = o.ValidateDef.ItemType
+	}
+	return item
+}
+
+// validateCondition validates the depends_on and when fields of the option. The
+// options referenced by the condition must be declared before the option, given
+// by the previous names.
+func (o *Option) validateCondition(idx int, previousNames []string) error {
+	var invalidFields []string
+	for _, name := range o.DependsOn {
+		if !utils.Contains(previousNames, name) {
+			invalidFields = append(invalidFields, "options.depends_on")
+			break
+		}
+	}
+	c, err := o.Condition()
+	if err != nil {
+		invalidFields = append(invalidFields, "options.when")
+	} else {
+		for _, name := range c.Options() {
+			if !utils.Contains(previousNames, name) && !utils.Contains(o.DependsOn, name) {
+				invalidFields = append(invalidFields, "options.when")
+				break
+			}
+		}
+	}
+	if len(invalidFields) > 0 {
+		return InvalidProfileError{
+			message:       "Option #" + strconv.Itoa(idx+1) + " is invalid",
+			invalidFields: invalidFields,
+		}
+	}
+	return nil
+}
+
+// validateRenames validates the renamed_from field of the option. A previous
+// name must not be the name of an option of the profile, nor a previous name of
+// another option.
+func (o *Option) validateRenames(idx int, options []Option) error {
+	for _, oldName := range o.RenamedFrom {
+		invalid := oldName == ""
+		for i, other := range options {
+			if other.Name == oldName || (i != idx && utils.Contains(other.RenamedFrom, oldName)) {
+				invalid = true
+			}
+		}
+		if invalid {
+			return InvalidProfileError{
+				message:       "Option #" + strconv.Itoa(idx+1) + " is invalid",
+				invalidFields: []string{"options.renamed_from"},
+			}
+		}
+	}
+	return nil
+}
+
 // Validate represents the validate field of an option
 type Validate struct {
 	Re2Regex  string   `yaml:"re2_regex"`
@@ -186,6 +398,7 @@ type Validate struct {
 	MinValue  *float64 `yaml:"min_value,omitempty"`
 	MaxValue  *float64 `yaml:"max_value,omitempty"`
 	Options   []string `yaml:"options"`
+	ItemType  string   `yaml:"item_type,omitempty"`
 }
 
 // Monitoring represents the monitoring field of a profile
@@ -218,6 +431,80 @@ type MonitoringTarget struct {
 	Service string `yaml:"service"`
 	Port    *int   `yaml:"port"`
 	Path    string `yaml:"path"`
+	// ScrapeInterval is how often the target is scraped, overriding the global
+	// scrape interval of the monitoring stack. It uses the Prometheus duration
+	// format, e.g. 15s or 1m.
+	ScrapeInterval string `yaml:"scrape_interval,omitempty"`
+	// ScrapeTimeout is the timeout of each scrape of the target. It can not be
+	// greater than the scrape interval.
+	ScrapeTimeout string `yaml:"scrape_timeout,omitempty"`
+	// Scheme is the protocol used to scrape the target, http or https.
+	Scheme string `yaml:"scheme,omitempty"`
+	// Labels are static labels added to all the metrics of the target.
+	Labels map[string]string `yaml:"labels,omitempty"`
+	// TLSConfig is the TLS configuration used to scrape the target.
+	TLSConfig *MonitoringTLSConfig `yaml:"tls_config,omitempty"`
+	// BasicAuth is the basic authentication used to scrape the target.
+	BasicAuth *MonitoringBasicAuth `yaml:"basic_auth,omitempty"`
+	// MetricRelabelConfigs are the relabeling rules applied to the scraped
+	// metrics before storing them.
+	MetricRelabelConfigs []RelabelConfig `yaml:"metric_relabel_configs,omitempty"`
+}
+
+// MonitoringTLSConfig represents the TLS configuration of a monitoring target
+type MonitoringTLSConfig struct {
+	ServerName         string `yaml:"server_name,omitempty"`
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify,omitempty"`
+}
+
+// MonitoringBasicAuth represents the basic authentication of a monitoring target
+type MonitoringBasicAuth struct {
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+}
+
+// RelabelConfig represents a Prometheus metric relabeling rule of a monitoring
+// target. The action defaults to replace.
+type RelabelConfig struct {
+	SourceLabels []string `yaml:"source_labels,omitempty"`
+	Separator    string   `yaml:"separator,omitempty"`
+	Regex        string   `yaml:"regex,omitempty"`
+	TargetLabel  string   `yaml:"target_label,omitempty"`
+	Replacement  string   `yaml:"replacement,omitempty"`
+	Action       string   `yaml:"action,omitempty"`
+}
+
+var relabelActions = []string{"replace", "keep", "drop", "keepequal", "dropequal", "labelmap", "labeldrop", "labelkeep", "lowercase", "uppercase"}
+
+func (r *RelabelConfig) validate() bool {
+	action := r.Action
+	if action == "" {
+		action = "replace"
+	}
+	if !slices.Contains(relabelActions, action) {
+		return false
+	}
+	for _, l := range r.SourceLabels {
+		if !model.LabelName(l).IsValid() {
+			return false
+		}
+	}
+	if r.Regex != "" {
+		if _, err := regexp.Compile("^(?:" + r.Regex + ")$"); err != nil {
+			return false
+		}
+	}
+	switch action {
+	case "replace", "keepequal", "dropequal", "lowercase", "uppercase":
+		if r.TargetLabel == "" {
+			return false
+		}
+	case "labeldrop", "labelkeep":
+		if len(r.SourceLabels) > 0 || r.TargetLabel != "" {
+			return false
+		}
+	}
+	return true
 }
 
 func (m *MonitoringTarget) validate(idx int) error {
@@ -244,6 +531,44 @@ func (m *MonitoringTarget) validate(idx int) error {
 		}
 	}
 
+	var interval time.Duration
+	if m.ScrapeInterval != "" {
+		d, err := model.ParseDuration(m.ScrapeInterval)
+		if err != nil || d == 0 {
+			invalidFields = append(invalidFields, "monitoring.targets.scrape_interval")
+		}
+		interval = time.Duration(d)
+	}
+	if m.ScrapeTimeout != "" {
+		d, err := model.ParseDuration(m.ScrapeTimeout)
+		if err != nil || d == 0 || (interval > 0 && time.Duration(d) > interval) {
+			invalidFields = append(invalidFields, "monitoring.targets.scrape_timeout")
+		}
+	}
+
+	if m.Scheme != "" && m.Scheme != "http" && m.Scheme != "https" {
+		invalidFields = append(invalidFields, "monitoring.targets.scheme")
+	}
+
+	for name := range m.Labels {
+		// Labels starting with __ are reserved for Prometheus internal use
+		if !model.LabelName(name).IsValid() || strings.HasPrefix(name, model.ReservedLabelPrefix) {
+			invalidFields = append(invalidFields, "monitoring.targets.labels")
+			break
+		}
+	}
+
+	if m.BasicAuth != nil && m.BasicAuth.Username == "" {
+		missingFields = append(missingFields, "monitoring.targets.basic_auth.username")
+	}
+
+	for _, r := range m.MetricRelabelConfigs {
+		if !r.validate() {
+			invalidFields = append(invalidFields, "monitoring.targets.metric_relabel_configs")
+			break
+		}
+	}
+
 	if len(missingFields) > 0 || len(invalidFields) > 0 {
 		return InvalidProfileError{
 			message:       "Monitoring target #" + strconv.Itoa(idx+1) + " is invalid",
@@ -232,6 +232,79 @@ func TestOptionValidate(t *testing.T) {
 			filePath: "check-type-str/pkg/option.yml",
 			want:     nil,
 		},
+		{
+			name:     "Check valid type eth_address",
+			filePath: "check-valid-eth-address/pkg/option.yml",
+			want:     nil,
+		},
+		{
+			name:     "Check invalid type eth_address checksum",
+			filePath: "check-invalid-eth-address/pkg/option.yml",
+			want: InvalidProfileError{
+				message:       message,
+				invalidFields: []string{"options.default"},
+			},
+		},
+		{
+			name:     "Check valid type duration",
+			filePath: "check-valid-duration/pkg/option.yml",
+			want:     nil,
+		},
+		{
+			name:     "Check invalid type duration",
+			filePath: "check-invalid-duration/pkg/option.yml",
+			want: InvalidProfileError{
+				message:       message,
+				invalidFields: []string{"options.default"},
+			},
+		},
+		{
+			name:     "Check valid type byte_size",
+			filePath: "check-valid-byte-size/pkg/option.yml",
+			want:     nil,
+		},
+		{
+			name:     "Check invalid type byte_size",
+			filePath: "check-invalid-byte-size/pkg/option.yml",
+			want: InvalidProfileError{
+				message:       message,
+				invalidFields: []string{"options.default"},
+			},
+		},
+		{
+			name:     "Check valid type list",
+			filePath: "check-valid-list/pkg/option.yml",
+			want:     nil,
+		},
+		{
+			name:     "Check invalid type list item",
+			filePath: "check-invalid-list/pkg/option.yml",
+			want: InvalidProfileError{
+				message:       message,
+				invalidFields: []string{"options.default"},
+			},
+		},
+		{
+			name:     "Check invalid type list item type",
+			filePath: "check-invalid-list-item-type/pkg/option.yml",
+			want: InvalidProfileError{
+				message:       message,
+				invalidFields: []string{"options.validate.item_type"},
+			},
+		},
+		{
+			name:     "Check valid type secret",
+			filePath: "check-valid-secret/pkg/option.yml",
+			want:     nil,
+		},
+		{
+			name:     "Check invalid type secret with default",
+			filePath: "check-invalid-secret-default/pkg/option.yml",
+			want: InvalidProfileError{
+				message:       message,
+				invalidFields: []string{"options.default"},
+			},
+		},
 		{
 			name:     "Check valid type path_dir",
 			filePath: "check-valid-path_dir/pkg/option.yml",
@@ -377,6 +450,34 @@ func TestMonitoringTargetValidate(t *testing.T) {
 			name:     "Multiple Targets Monitoring Target",
 			filePath: "multiple-targets/pkg/target.yml",
 		},
+		{
+			name:     "Scrape Config Monitoring Target",
+			filePath: "scrape-config/pkg/target.yml",
+		},
+		{
+			name:     "Invalid Scrape Config Monitoring Target",
+			filePath: "invalid-scrape-config/pkg/target.yml",
+			want: InvalidProfileError{
+				message:       message,
+				invalidFields: []string{"monitoring.targets.scrape_timeout", "monitoring.targets.scheme", "monitoring.targets.labels"},
+			},
+		},
+		{
+			name:     "Invalid Relabel Config Monitoring Target",
+			filePath: "invalid-relabel-config/pkg/target.yml",
+			want: InvalidProfileError{
+				message:       message,
+				invalidFields: []string{"monitoring.targets.scrape_interval", "monitoring.targets.metric_relabel_configs"},
+			},
+		},
+		{
+			name:     "Missing Basic Auth Username Monitoring Target",
+			filePath: "missing-basic-auth-username/pkg/target.yml",
+			want: InvalidProfileError{
+				message:       message,
+				missingFields: []string{"monitoring.targets.basic_auth.username"},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -402,3 +503,246 @@ func TestMonitoringTargetValidate(t *testing.T) {
 		})
 	}
 }
+
+func TestOptionValidateRenames(t *testing.T) {
+	invalid := InvalidProfileError{
+		message:       "Option #1 is invalid",
+		invalidFields: []string{"options.renamed_from"},
+	}
+	tests := []struct {
+		name    string
+		options []Option
+		want    error
+	}{
+		{
+			name: "valid",
+			options: []Option{
+				{Name: "rpc-url", RenamedFrom: []string{"eth-rpc", "rpc"}},
+				{Name: "network", RenamedFrom: []string{"chain"}},
+			},
+		},
+		{
+			name: "renamed from current option",
+			options: []Option{
+				{Name: "rpc-url", RenamedFrom: []string{"network"}},
+				{Name: "network"},
+			},
+			want: invalid,
+		},
+		{
+			name: "renamed from itself",
+			options: []Option{
+				{Name: "rpc-url", RenamedFrom: []string{"rpc-url"}},
+			},
+			want: invalid,
+		},
+		{
+			name: "renamed from previous name of another option",
+			options: []Option{
+				{Name: "rpc-url", RenamedFrom: []string{"rpc"}},
+				{Name: "rpc-port", RenamedFrom: []string{"rpc"}},
+			},
+			want: invalid,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.options[0].validateRenames(0, tt.options)
+			if tt.want == nil {
+				assert.NoError(t, got)
+			} else {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestProfileValidateOneOf(t *testing.T) {
+	options := []Option{{Name: "rpc-url"}, {Name: "ws-url"}}
+	tests := []struct {
+		name  string
+		oneOf [][]string
+		want  error
+	}{
+		{
+			name:  "valid",
+			oneOf: [][]string{{"rpc-url", "ws-url"}},
+		},
+		{
+			name:  "unknown option",
+			oneOf: [][]string{{"rpc-url", "ipc-path"}},
+			want: InvalidProfileError{
+				message:       "Invalid one_of groups",
+				invalidFields: []string{"one_of[rpc-url, ipc-path]"},
+			},
+		},
+		{
+			name:  "single option",
+			oneOf: [][]string{{"rpc-url"}},
+			want: InvalidProfileError{
+				message:       "Invalid one_of groups",
+				invalidFields: []string{"one_of[rpc-url]"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := Profile{Options: options, OneOf: tt.oneOf}
+			got := p.validateOneOf()
+			if tt.want == nil {
+				assert.NoError(t, got)
+			} else {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestProfileCheckOneOf(t *testing.T) {
+	p := Profile{
+		Options: []Option{{Name: "rpc-url"}, {Name: "ws-url"}, {Name: "metrics"}},
+		OneOf:   [][]string{{"rpc-url", "ws-url"}},
+	}
+	assert.NoError(t, p.CheckOneOf(map[string]string{"rpc-url": "http://localhost:8545"}))
+	assert.NoError(t, p.CheckOneOf(map[string]string{"rpc-url": "", "ws-url": "ws://localhost:8546"}))
+	assert.ErrorIs(t, p.CheckOneOf(map[string]string{"rpc-url": "", "metrics": "true"}), ErrOneOfNotSet)
+}
+
+func TestHardwareRequirementsOverridesValidate(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides HardwareRequirementsOverrides
+		want      error
+	}{
+		{
+			name:      "valid",
+			overrides: HardwareRequirementsOverrides{MinCPUCores: 2, MinRAM: 4096, MinFreeSpace: 10240, StopIfRequirementsAreNotMet: true},
+		},
+		{
+			name:      "zero values",
+			overrides: HardwareRequirementsOverrides{},
+		},
+		{
+			name:      "negative values",
+			overrides: HardwareRequirementsOverrides{MinCPUCores: -1, MinRAM: -1, MinFreeSpace: -1},
+			want: InvalidProfileError{
+				message: "Invalid hardware requirements overrides",
+				invalidFields: []string{
+					"hardware_requirements_overrides.min_cpu_cores -> (negative value)",
+					"hardware_requirements_overrides.min_ram -> (negative value)",
+					"hardware_requirements_overrides.min_free_space -> (negative value)",
+				},
+			},
+		},
+		{
+			name:      "negative free space",
+			overrides: HardwareRequirementsOverrides{MinCPUCores: 2, MinFreeSpace: -100},
+			want: InvalidProfileError{
+				message:       "Invalid hardware requirements overrides",
+				invalidFields: []string{"hardware_requirements_overrides.min_free_space -> (negative value)"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.overrides.validate()
+			if tt.want == nil {
+				assert.NoError(t, got)
+			} else {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestPluginOverridesValidate(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides PluginOverrides
+		wantErr   bool
+	}{
+		{
+			name:      "empty image",
+			overrides: PluginOverrides{},
+		},
+		{
+			name:      "valid image",
+			overrides: PluginOverrides{Image: "nethermind/eigenlayer-plugin:v1.0.0"},
+		},
+		{
+			name:      "valid image with registry",
+			overrides: PluginOverrides{Image: "ghcr.io/nethermindeth/plugin@sha256:2d6a2d5b4c7f0b9a1e3d6c8f7e5a4b3c2d1e0f9a8b7c6d5e4f3a2b1c0d9e8f7a"},
+		},
+		{
+			name:      "invalid image",
+			overrides: PluginOverrides{Image: "Invalid Image:latest"},
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.overrides.validate()
+			if tt.wantErr {
+				var profileErr InvalidProfileError
+				assert.ErrorAs(t, err, &profileErr)
+				assert.Contains(t, err.Error(), "plugin_overrides.image -> (invalid docker image")
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRelabelConfigValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		config RelabelConfig
+		want   bool
+	}{
+		{
+			name:   "replace",
+			config: RelabelConfig{SourceLabels: []string{"job"}, Regex: "(.*)", TargetLabel: "service", Replacement: "$1"},
+			want:   true,
+		},
+		{
+			name:   "replace without target label",
+			config: RelabelConfig{SourceLabels: []string{"job"}, Regex: "(.*)"},
+			want:   false,
+		},
+		{
+			name:   "drop",
+			config: RelabelConfig{SourceLabels: []string{"__name__"}, Regex: "go_.*", Action: "drop"},
+			want:   true,
+		},
+		{
+			name:   "labeldrop",
+			config: RelabelConfig{Regex: "pod_.*", Action: "labeldrop"},
+			want:   true,
+		},
+		{
+			name:   "labeldrop with source labels",
+			config: RelabelConfig{SourceLabels: []string{"pod"}, Action: "labeldrop"},
+			want:   false,
+		},
+		{
+			name:   "unknown action",
+			config: RelabelConfig{SourceLabels: []string{"job"}, Action: "rename"},
+			want:   false,
+		},
+		{
+			name:   "invalid regex",
+			config: RelabelConfig{SourceLabels: []string{"job"}, Regex: "(", Action: "keep"},
+			want:   false,
+		},
+		{
+			name:   "invalid source label",
+			config: RelabelConfig{SourceLabels: []string{"job-name"}, Action: "keep"},
+			want:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.config.validate())
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package profile
+
+import (
+	"bytes"
+	"strings"
+	"text/template"
+)
+
+// IsTemplate returns true if the given option default is a Go template, that
+// is, it contains at least one action delimited by "{{" and "}}".
+func IsTemplate(value string) bool {
+	return strings.Contains(value, "{{")
+}
+
+// ParseTemplate parses the given option default as a Go template. Rendering a
+// template that references a missing field is an error.
+func ParseTemplate(name, value string) (*template.Template, error) {
+	return template.New(name).Option("missingkey=error").Parse(value)
+}
+
+// RenderTemplate renders the given option default with the given data. Values
+// that are not templates are returned as they are.
+func RenderTemplate(name, value string, data any) (string, error) {
+	if !IsTemplate(value) {
+		return value, nil
+	}
+	tmpl, err := ParseTemplate(name, value)
+	if err != nil {
+		return "", err
+	}
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
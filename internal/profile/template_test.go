@@ -0,0 +1,128 @@
+package profile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	data := struct {
+		DataDir string
+		Tag     string
+		Options map[string]string
+	}{
+		DataDir: "/data",
+		Tag:     "mainnet",
+		Options: map[string]string{"main-port": "8080"},
+	}
+
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "not a template",
+			value: "/data/default",
+			want:  "/data/default",
+		},
+		{
+			name:  "fields",
+			value: "{{ .DataDir }}/{{ .Tag }}",
+			want:  "/data/mainnet",
+		},
+		{
+			name:  "option value",
+			value: `http://localhost:{{ index .Options "main-port" }}`,
+			want:  "http://localhost:8080",
+		},
+		{
+			name:    "missing field",
+			value:   "{{ .Hostname }}",
+			wantErr: true,
+		},
+		{
+			name:    "invalid template",
+			value:   "{{ .DataDir",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RenderTemplate("option", tt.value, data)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestOptionValidateTemplate(t *testing.T) {
+	tests := []struct {
+		name    string
+		option  Option
+		wantErr bool
+	}{
+		{
+			name: "int template",
+			option: Option{
+				Name:    "cpus",
+				Target:  "CPUS",
+				Type:    "int",
+				Default: "{{ .CPUs }}",
+				Help:    "CPUs",
+			},
+		},
+		{
+			name: "path_dir template",
+			option: Option{
+				Name:    "data-dir",
+				Target:  "DATA_DIR",
+				Type:    "path_dir",
+				Default: "{{ .DataDir }}/{{ .Tag }}",
+				Help:    "Data dir",
+			},
+		},
+		{
+			name: "invalid template",
+			option: Option{
+				Name:    "data-dir",
+				Target:  "DATA_DIR",
+				Type:    "path_dir",
+				Default: "{{ .DataDir }/{{ .Tag }}",
+				Help:    "Data dir",
+			},
+			wantErr: true,
+		},
+		{
+			name: "secret template",
+			option: Option{
+				Name:    "password",
+				Target:  "PASSWORD",
+				Type:    "secret",
+				Default: "{{ .Hostname }}",
+				Help:    "Password",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.option.validate(0)
+			if tt.wantErr {
+				assert.Equal(t, InvalidProfileError{
+					message:       "Option #1 is invalid",
+					invalidFields: []string{"options.default"},
+				}, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,10 @@
+package storage
+
+import "errors"
+
+var (
+	ErrFileNotFound     = errors.New("file not found in backup store")
+	ErrInvalidKey       = errors.New("invalid backup store key")
+	ErrUnsupportedStore = errors.New("unsupported backup store")
+	ErrInvalidStoreURL  = errors.New("invalid backup store URL")
+)
@@ -0,0 +1,131 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// LocalStore is a backup store in a local directory, like a mounted network
+// share or an external disk.
+type LocalStore struct {
+	fs   afero.Fs
+	root string
+}
+
+// NewLocalStore creates a new LocalStore with the given directory as root. The
+// directory is created on the first Put if it does not exist.
+func NewLocalStore(fs afero.Fs, root string) *LocalStore {
+	return &LocalStore{
+		fs:   fs,
+		root: root,
+	}
+}
+
+// Put implements BackupStore.Put. The data is written to a temporary file
+// that is renamed once complete.
+func (s *LocalStore) Put(key string, r io.Reader) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := s.fs.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	tmp, err := afero.TempFile(s.fs, filepath.Dir(p), filepath.Base(p)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer s.fs.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return s.fs.Rename(tmp.Name(), p)
+}
+
+// Get implements BackupStore.Get.
+func (s *LocalStore) Get(key string) (io.ReadCloser, error) {
+	p, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := s.fs.Open(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrFileNotFound, key)
+	}
+	return f, err
+}
+
+// Exists implements BackupStore.Exists.
+func (s *LocalStore) Exists(key string) (bool, error) {
+	p, err := s.path(key)
+	if err != nil {
+		return false, err
+	}
+	return afero.Exists(s.fs, p)
+}
+
+// List implements BackupStore.List.
+func (s *LocalStore) List(prefix string) ([]string, error) {
+	ok, err := afero.DirExists(s.fs, s.root)
+	if err != nil || !ok {
+		return nil, err
+	}
+	var keys []string
+	err = afero.Walk(s.fs, s.root, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || strings.Contains(info.Name(), ".tmp-") {
+			return nil
+		}
+		rel, err := filepath.Rel(s.root, p)
+		if err != nil {
+			return err
+		}
+		if key := filepath.ToSlash(rel); strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// Delete implements BackupStore.Delete.
+func (s *LocalStore) Delete(key string) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	err = s.fs.Remove(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// Close implements BackupStore.Close.
+func (s *LocalStore) Close() error {
+	return nil
+}
+
+func (s *LocalStore) path(key string) (string, error) {
+	if err := checkKey(key); err != nil {
+		return "", err
+	}
+	return filepath.Join(s.root, filepath.FromSlash(key)), nil
+}
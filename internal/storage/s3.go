@@ -0,0 +1,186 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// defaultS3Region is the region used when it is not set in the store URL nor
+// in the AWS configuration. S3-compatible services like MinIO accept it.
+const defaultS3Region = "us-east-1"
+
+// S3Store is a backup store in a bucket of Amazon S3 or an S3-compatible
+// service, like MinIO. The files are stored as objects with the key prefixed
+// by the store prefix.
+type S3Store struct {
+	client   *s3.Client
+	uploader *manager.Uploader
+	bucket   string
+	prefix   string
+}
+
+// NewS3Store creates a new S3Store in the given bucket, with the given key
+// prefix.
+func NewS3Store(client *s3.Client, bucket, prefix string) *S3Store {
+	return &S3Store{
+		client:   client,
+		uploader: manager.NewUploader(client),
+		bucket:   bucket,
+		prefix:   strings.Trim(prefix, "/"),
+	}
+}
+
+// OpenS3 opens the S3 store of the given s3:// URL.
+func OpenS3(u *url.URL) (*S3Store, error) {
+	if u.Host == "" {
+		return nil, fmt.Errorf("%w: missing bucket: %s", ErrInvalidStoreURL, u.Redacted())
+	}
+	query := u.Query()
+	var loadOptions []func(*config.LoadOptions) error
+	if region := query.Get("region"); region != "" {
+		loadOptions = append(loadOptions, config.WithRegion(region))
+	}
+	cfg, err := config.LoadDefaultConfig(context.Background(), loadOptions...)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Region == "" {
+		cfg.Region = defaultS3Region
+	}
+	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+		if endpoint := query.Get("endpoint"); endpoint != "" {
+			o.BaseEndpoint = aws.String(endpoint)
+			// S3-compatible services do not support bucket subdomains by default
+			o.UsePathStyle = true
+		}
+	})
+	return NewS3Store(client, u.Host, u.Path), nil
+}
+
+// Put implements BackupStore.Put. Large files are uploaded in parts.
+func (s *S3Store) Put(key string, r io.Reader) error {
+	objectKey, err := s.objectKey(key)
+	if err != nil {
+		return err
+	}
+	_, err = s.uploader.Upload(context.Background(), &s3.PutObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(objectKey),
+		Body:   r,
+	})
+	return err
+}
+
+// Get implements BackupStore.Get.
+func (s *S3Store) Get(key string) (io.ReadCloser, error) {
+	objectKey, err := s.objectKey(key)
+	if err != nil {
+		return nil, err
+	}
+	out, err := s.client.GetObject(context.Background(), &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(objectKey),
+	})
+	if isS3NotFound(err) {
+		return nil, fmt.Errorf("%w: %s", ErrFileNotFound, key)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return out.Body, nil
+}
+
+// Exists implements BackupStore.Exists.
+func (s *S3Store) Exists(key string) (bool, error) {
+	objectKey, err := s.objectKey(key)
+	if err != nil {
+		return false, err
+	}
+	_, err = s.client.HeadObject(context.Background(), &s3.HeadObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(objectKey),
+	})
+	if isS3NotFound(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// List implements BackupStore.List.
+func (s *S3Store) List(prefix string) ([]string, error) {
+	objectPrefix := prefix
+	if s.prefix != "" {
+		objectPrefix = s.prefix + "/" + prefix
+	}
+	var keys []string
+	pages := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(objectPrefix),
+	})
+	for pages.HasMorePages() {
+		page, err := pages.NextPage(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		for _, object := range page.Contents {
+			key := aws.ToString(object.Key)
+			if s.prefix != "" {
+				key = strings.TrimPrefix(key, s.prefix+"/")
+			}
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// Delete implements BackupStore.Delete.
+func (s *S3Store) Delete(key string) error {
+	objectKey, err := s.objectKey(key)
+	if err != nil {
+		return err
+	}
+	_, err = s.client.DeleteObject(context.Background(), &s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(objectKey),
+	})
+	if isS3NotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// Close implements BackupStore.Close.
+func (s *S3Store) Close() error {
+	return nil
+}
+
+func (s *S3Store) objectKey(key string) (string, error) {
+	if err := checkKey(key); err != nil {
+		return "", err
+	}
+	return path.Join(s.prefix, key), nil
+}
+
+func isS3NotFound(err error) bool {
+	var respErr *awshttp.ResponseError
+	if errors.As(err, &respErr) && respErr.HTTPStatusCode() == http.StatusNotFound {
+		return true
+	}
+	var noSuchKey *types.NoSuchKey
+	return errors.As(err, &noSuchKey)
+}
@@ -0,0 +1,213 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeS3 is a minimal stand-in of an S3-compatible service like MinIO, with
+// path-style bucket addressing. It supports the object operations and
+// multipart uploads used by S3Store, and it does not check authentication.
+type fakeS3 struct {
+	mu      sync.Mutex
+	buckets map[string]map[string][]byte
+	uploads map[string]map[int][]byte
+}
+
+func newFakeS3(buckets ...string) *fakeS3 {
+	f := &fakeS3{
+		buckets: make(map[string]map[string][]byte),
+		uploads: make(map[string]map[int][]byte),
+	}
+	for _, b := range buckets {
+		f.buckets[b] = make(map[string][]byte)
+	}
+	return f
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	bucketName, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	bucket, ok := f.buckets[bucketName]
+	if !ok {
+		writeS3Error(w, http.StatusNotFound, "NoSuchBucket")
+		return
+	}
+	query := r.URL.Query()
+	switch {
+	case r.Method == http.MethodGet && key == "" && query.Get("list-type") == "2":
+		f.list(w, bucketName, bucket, query.Get("prefix"))
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		uploadId := strconv.Itoa(len(f.uploads) + 1)
+		f.uploads[uploadId] = make(map[int][]byte)
+		writeXML(w, struct {
+			XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+			Bucket   string
+			Key      string
+			UploadId string
+		}{Bucket: bucketName, Key: key, UploadId: uploadId})
+	case r.Method == http.MethodPut && query.Has("uploadId"):
+		parts, ok := f.uploads[query.Get("uploadId")]
+		if !ok {
+			writeS3Error(w, http.StatusNotFound, "NoSuchUpload")
+			return
+		}
+		partNumber, _ := strconv.Atoi(query.Get("partNumber"))
+		data, _ := io.ReadAll(r.Body)
+		parts[partNumber] = data
+		w.Header().Set("ETag", etag(data))
+	case r.Method == http.MethodPost && query.Has("uploadId"):
+		parts, ok := f.uploads[query.Get("uploadId")]
+		if !ok {
+			writeS3Error(w, http.StatusNotFound, "NoSuchUpload")
+			return
+		}
+		numbers := make([]int, 0, len(parts))
+		for n := range parts {
+			numbers = append(numbers, n)
+		}
+		sort.Ints(numbers)
+		var data []byte
+		for _, n := range numbers {
+			data = append(data, parts[n]...)
+		}
+		bucket[key] = data
+		delete(f.uploads, query.Get("uploadId"))
+		writeXML(w, struct {
+			XMLName xml.Name `xml:"CompleteMultipartUploadResult"`
+			Bucket  string
+			Key     string
+			ETag    string
+		}{Bucket: bucketName, Key: key, ETag: etag(data)})
+	case r.Method == http.MethodDelete && query.Has("uploadId"):
+		delete(f.uploads, query.Get("uploadId"))
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPut:
+		data, _ := io.ReadAll(r.Body)
+		bucket[key] = data
+		w.Header().Set("ETag", etag(data))
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		data, ok := bucket[key]
+		if !ok {
+			writeS3Error(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		w.Header().Set("ETag", etag(data))
+		if r.Method == http.MethodGet {
+			w.Write(data)
+		}
+	case r.Method == http.MethodDelete:
+		delete(bucket, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeS3Error(w, http.StatusNotImplemented, "NotImplemented")
+	}
+}
+
+func (f *fakeS3) list(w http.ResponseWriter, bucketName string, bucket map[string][]byte, prefix string) {
+	type object struct {
+		Key  string
+		Size int
+	}
+	result := struct {
+		XMLName     xml.Name `xml:"ListBucketResult"`
+		Name        string
+		Prefix      string
+		KeyCount    int
+		IsTruncated bool
+		Contents    []object
+	}{Name: bucketName, Prefix: prefix}
+	for key, data := range bucket {
+		if strings.HasPrefix(key, prefix) {
+			result.Contents = append(result.Contents, object{Key: key, Size: len(data)})
+		}
+	}
+	sort.Slice(result.Contents, func(i, j int) bool { return result.Contents[i].Key < result.Contents[j].Key })
+	result.KeyCount = len(result.Contents)
+	writeXML(w, result)
+}
+
+func writeXML(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/xml")
+	data, _ := xml.Marshal(v)
+	w.Write(data)
+}
+
+func writeS3Error(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, "<Error><Code>%s</Code><Message>%s</Message></Error>", code, code)
+}
+
+func etag(data []byte) string {
+	sum := md5.Sum(data)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+func newTestS3Store(t *testing.T, fake *fakeS3, bucket, prefix string) *S3Store {
+	t.Helper()
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	client := s3.New(s3.Options{
+		BaseEndpoint: aws.String(server.URL),
+		Region:       defaultS3Region,
+		Credentials:  credentials.NewStaticCredentialsProvider("minioadmin", "minioadmin", ""),
+		UsePathStyle: true,
+	})
+	return NewS3Store(client, bucket, prefix)
+}
+
+func TestS3Store(t *testing.T) {
+	t.Run("bucket root", func(t *testing.T) {
+		testBackupStore(t, newTestS3Store(t, newFakeS3("backups"), "backups", ""))
+	})
+	t.Run("with prefix", func(t *testing.T) {
+		fake := newFakeS3("backups")
+		testBackupStore(t, newTestS3Store(t, fake, "backups", "/node-1/"))
+		for key := range fake.buckets["backups"] {
+			assert.True(t, strings.HasPrefix(key, "node-1/"), key)
+		}
+	})
+	t.Run("missing bucket", func(t *testing.T) {
+		s := newTestS3Store(t, newFakeS3(), "backups", "")
+		assert.Error(t, s.Put("backup-id.json", bytes.NewReader([]byte("manifest"))))
+	})
+}
+
+func TestOpenS3(t *testing.T) {
+	fake := newFakeS3("backups")
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	t.Setenv("AWS_ACCESS_KEY_ID", "minioadmin")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "minioadmin")
+	t.Setenv("AWS_REGION", "")
+
+	s, err := Open("s3://backups/node-1?endpoint=" + url.QueryEscape(server.URL))
+	require.NoError(t, err)
+	require.IsType(t, &S3Store{}, s)
+	assert.Equal(t, defaultS3Region, s.(*S3Store).client.Options().Region)
+
+	require.NoError(t, s.Put("backup-id.json", bytes.NewReader([]byte("manifest"))))
+	assert.Equal(t, []byte("manifest"), fake.buckets["backups"]["node-1/backup-id.json"])
+}
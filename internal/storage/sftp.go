@@ -0,0 +1,275 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"os/user"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// posixRenameExt is the SFTP extension to rename a file replacing the target.
+const posixRenameExt = "posix-rename@openssh.com"
+
+// SFTPStore is a backup store in a directory of an SFTP server.
+type SFTPStore struct {
+	client *sftp.Client
+	// conn is the SSH connection of the client, closed with the store.
+	conn io.Closer
+	root string
+}
+
+// NewSFTPStore creates a new SFTPStore with the given directory of the server
+// as root. The directory is created on the first Put if it does not exist.
+func NewSFTPStore(client *sftp.Client, root string) *SFTPStore {
+	if root == "" {
+		root = "."
+	}
+	return &SFTPStore{
+		client: client,
+		root:   path.Clean(root),
+	}
+}
+
+// OpenSFTP connects to the SFTP server of the given sftp:// URL, like
+// `sftp://user@host:22/path`. The user defaults to the current user and the
+// port to 22. The client authenticates with the password of the URL if any, the
+// keys of the SSH agent at SSH_AUTH_SOCK, and the private key file set with the
+// `identity` query parameter, or else the default keys in ~/.ssh. The server
+// host key is checked against the known hosts file set with the `known_hosts`
+// query parameter, by default ~/.ssh/known_hosts.
+func OpenSFTP(u *url.URL) (*SFTPStore, error) {
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("%w: missing host: %s", ErrInvalidStoreURL, u.Redacted())
+	}
+	config, closeAgent, err := sshClientConfig(u)
+	if err != nil {
+		return nil, err
+	}
+	defer closeAgent()
+	address := u.Host
+	if u.Port() == "" {
+		address = net.JoinHostPort(u.Hostname(), "22")
+	}
+	conn, err := ssh.Dial("tcp", address, config)
+	if err != nil {
+		return nil, err
+	}
+	client, err := sftp.NewClient(conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	s := NewSFTPStore(client, u.Path)
+	s.conn = conn
+	return s, nil
+}
+
+// sshClientConfig returns the SSH client configuration of the given sftp://
+// URL, and a function to close the connection with the SSH agent once the
+// client is authenticated.
+func sshClientConfig(u *url.URL) (*ssh.ClientConfig, func(), error) {
+	closeAgent := func() {}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, closeAgent, err
+	}
+	query := u.Query()
+
+	username := u.User.Username()
+	if username == "" {
+		current, err := user.Current()
+		if err != nil {
+			return nil, closeAgent, err
+		}
+		username = current.Username
+	}
+
+	var auth []ssh.AuthMethod
+	if password, ok := u.User.Password(); ok {
+		auth = append(auth, ssh.Password(password))
+	}
+	if socket := os.Getenv("SSH_AUTH_SOCK"); socket != "" {
+		if agentConn, err := net.Dial("unix", socket); err == nil {
+			closeAgent = func() { agentConn.Close() }
+			auth = append(auth, ssh.PublicKeysCallback(agent.NewClient(agentConn).Signers))
+		}
+	}
+	identities := []string{query.Get("identity")}
+	if identities[0] == "" {
+		identities = []string{
+			filepath.Join(homeDir, ".ssh", "id_ed25519"),
+			filepath.Join(homeDir, ".ssh", "id_ecdsa"),
+			filepath.Join(homeDir, ".ssh", "id_rsa"),
+		}
+	}
+	var signers []ssh.Signer
+	for _, identity := range identities {
+		key, err := os.ReadFile(identity)
+		if errors.Is(err, os.ErrNotExist) && query.Get("identity") == "" {
+			continue
+		}
+		if err != nil {
+			closeAgent()
+			return nil, func() {}, err
+		}
+		signer, err := ssh.ParsePrivateKey(key)
+		if err != nil {
+			closeAgent()
+			return nil, func() {}, fmt.Errorf("failed to load SSH key %s: %w", identity, err)
+		}
+		signers = append(signers, signer)
+	}
+	if len(signers) > 0 {
+		auth = append(auth, ssh.PublicKeys(signers...))
+	}
+
+	knownHostsPath := query.Get("known_hosts")
+	if knownHostsPath == "" {
+		knownHostsPath = filepath.Join(homeDir, ".ssh", "known_hosts")
+	}
+	hostKeyCallback, err := knownhosts.New(knownHostsPath)
+	if err != nil {
+		closeAgent()
+		return nil, func() {}, fmt.Errorf("failed to load known hosts: %w", err)
+	}
+
+	return &ssh.ClientConfig{
+		User:            username,
+		Auth:            auth,
+		HostKeyCallback: hostKeyCallback,
+	}, closeAgent, nil
+}
+
+// Put implements BackupStore.Put. The data is written to a temporary file
+// that is renamed once complete.
+func (s *SFTPStore) Put(key string, r io.Reader) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := s.client.MkdirAll(path.Dir(p)); err != nil {
+		return err
+	}
+	tmpPath := p + ".tmp"
+	f, err := s.client.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	if _, err := f.ReadFrom(r); err != nil {
+		f.Close()
+		s.client.Remove(tmpPath)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		s.client.Remove(tmpPath)
+		return err
+	}
+	if _, ok := s.client.HasExtension(posixRenameExt); ok {
+		err = s.client.PosixRename(tmpPath, p)
+	} else {
+		// Plain SFTP renames fail if the target exists
+		if err := s.client.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		err = s.client.Rename(tmpPath, p)
+	}
+	if err != nil {
+		s.client.Remove(tmpPath)
+	}
+	return err
+}
+
+// Get implements BackupStore.Get.
+func (s *SFTPStore) Get(key string) (io.ReadCloser, error) {
+	p, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := s.client.Open(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrFileNotFound, key)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Exists implements BackupStore.Exists.
+func (s *SFTPStore) Exists(key string) (bool, error) {
+	p, err := s.path(key)
+	if err != nil {
+		return false, err
+	}
+	_, err = s.client.Stat(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// List implements BackupStore.List.
+func (s *SFTPStore) List(prefix string) ([]string, error) {
+	if _, err := s.client.Stat(s.root); errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	var keys []string
+	walker := s.client.Walk(s.root)
+	for walker.Step() {
+		if err := walker.Err(); err != nil {
+			return nil, err
+		}
+		if walker.Stat().IsDir() || strings.HasSuffix(walker.Path(), ".tmp") {
+			continue
+		}
+		key := strings.TrimPrefix(walker.Path(), s.root+"/")
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// Delete implements BackupStore.Delete.
+func (s *SFTPStore) Delete(key string) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	err = s.client.Remove(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// Close implements BackupStore.Close, closing the connection with the server.
+func (s *SFTPStore) Close() error {
+	err := s.client.Close()
+	if s.conn != nil {
+		if closeErr := s.conn.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+func (s *SFTPStore) path(key string) (string, error) {
+	if err := checkKey(key); err != nil {
+		return "", err
+	}
+	return path.Join(s.root, key), nil
+}
@@ -0,0 +1,173 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/sftp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// newTestSFTPClient returns an SFTP client connected through pipes to an
+// in-process SFTP server of the local file system.
+func newTestSFTPClient(t *testing.T) *sftp.Client {
+	t.Helper()
+	clientReader, serverWriter := io.Pipe()
+	serverReader, clientWriter := io.Pipe()
+	server, err := sftp.NewServer(struct {
+		io.Reader
+		io.WriteCloser
+	}{serverReader, serverWriter})
+	require.NoError(t, err)
+	go func() {
+		server.Serve()
+		// The server does not close the connection when the client does
+		serverWriter.Close()
+	}()
+	client, err := sftp.NewClientPipe(clientReader, clientWriter)
+	require.NoError(t, err)
+	return client
+}
+
+func TestSFTPStore(t *testing.T) {
+	testBackupStore(t, NewSFTPStore(newTestSFTPClient(t), filepath.ToSlash(filepath.Join(t.TempDir(), "backups"))))
+}
+
+// startSSHServer starts an SSH server with the SFTP subsystem that accepts the
+// given user and password, and returns its address and host key.
+func startSSHServer(t *testing.T, username, password string) (string, ssh.PublicKey) {
+	t.Helper()
+	_, hostKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	hostSigner, err := ssh.NewSignerFromKey(hostKey)
+	require.NoError(t, err)
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(conn ssh.ConnMetadata, p []byte) (*ssh.Permissions, error) {
+			if conn.User() == username && string(p) == password {
+				return nil, nil
+			}
+			return nil, assert.AnError
+		},
+	}
+	config.AddHostKey(hostSigner)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveSSH(conn, config)
+		}
+	}()
+	return listener.Addr().String(), hostSigner.PublicKey()
+}
+
+func serveSSH(conn net.Conn, config *ssh.ServerConfig) {
+	_, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+	for newChannel := range chans {
+		if newChannel.ChannelType() != "session" {
+			newChannel.Reject(ssh.UnknownChannelType, "unknown channel type")
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			for req := range requests {
+				// The payload is the length-prefixed subsystem name
+				ok := req.Type == "subsystem" && string(req.Payload[4:]) == "sftp"
+				req.Reply(ok, nil)
+				if ok {
+					server, err := sftp.NewServer(channel)
+					if err == nil {
+						server.Serve()
+					}
+					channel.Close()
+				}
+			}
+		}()
+	}
+}
+
+func TestOpenSFTP(t *testing.T) {
+	// Isolate the test from the SSH agent and keys of the user
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("SSH_AUTH_SOCK", "")
+
+	addr, hostKey := startSSHServer(t, "egn", "secret")
+	knownHosts := filepath.Join(t.TempDir(), "known_hosts")
+	line := knownhosts.Line([]string{knownhosts.Normalize(addr)}, hostKey)
+	require.NoError(t, os.WriteFile(knownHosts, []byte(line+"\n"), 0o600))
+	_, otherKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	otherSigner, err := ssh.NewSignerFromKey(otherKey)
+	require.NoError(t, err)
+	otherKnownHosts := filepath.Join(t.TempDir(), "known_hosts")
+	otherLine := knownhosts.Line([]string{knownhosts.Normalize(addr)}, otherSigner.PublicKey())
+	require.NoError(t, os.WriteFile(otherKnownHosts, []byte(otherLine+"\n"), 0o600))
+
+	root := filepath.ToSlash(filepath.Join(t.TempDir(), "backups"))
+	storeURL := func(userinfo, knownHosts string) string {
+		return "sftp://" + userinfo + "@" + addr + root + "?known_hosts=" + url.QueryEscape(knownHosts)
+	}
+
+	tests := []struct {
+		name     string
+		location string
+		wantErr  bool
+	}{
+		{
+			name:     "password",
+			location: storeURL("egn:secret", knownHosts),
+		},
+		{
+			name:     "wrong password",
+			location: storeURL("egn:wrong", knownHosts),
+			wantErr:  true,
+		},
+		{
+			name:     "unknown host key",
+			location: storeURL("egn:secret", otherKnownHosts),
+			wantErr:  true,
+		},
+		{
+			name:     "missing known hosts file",
+			location: storeURL("egn:secret", filepath.Join(t.TempDir(), "missing")),
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Open(tt.location)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.NoError(t, s.Put("backup-id.json", bytes.NewReader([]byte("manifest"))))
+			require.NoError(t, s.Close())
+			data, err := os.ReadFile(filepath.Join(filepath.FromSlash(root), "backup-id.json"))
+			require.NoError(t, err)
+			assert.Equal(t, []byte("manifest"), data)
+		})
+	}
+}
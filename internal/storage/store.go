@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// BackupStore is a store of backup files, identified by keys. Keys are
+// slash-separated paths relative to the root of the store, like
+// `chunks/mock-avs-default/ab/ab01...zst`.
+type BackupStore interface {
+	// Put stores the data read from r with the given key, replacing the file
+	// if it already exists. Partially written files are never visible with
+	// the given key.
+	Put(key string, r io.Reader) error
+	// Get returns a reader of the file with the given key. The reader must be
+	// closed by the caller. If the file does not exist, an ErrFileNotFound
+	// error is returned.
+	Get(key string) (io.ReadCloser, error)
+	// Exists returns true if there is a file with the given key.
+	Exists(key string) (bool, error)
+	// List returns the keys of all the files with the given key prefix.
+	List(prefix string) ([]string, error)
+	// Delete removes the file with the given key. Removing a file that does
+	// not exist is not an error.
+	Delete(key string) error
+	// Close releases the resources of the store, like network connections.
+	Close() error
+}
+
+// Open opens the backup store at the given location, which is one of:
+//
+//   - A local directory path, or a file:// URL like `file:///mnt/backups`.
+//   - An S3-compatible bucket, like `s3://bucket/prefix`. The `endpoint` query
+//     parameter sets the URL of S3-compatible services like MinIO, for instance
+//     `s3://bucket/prefix?endpoint=http://localhost:9000`, and the `region`
+//     query parameter sets the bucket region. Credentials are loaded from the
+//     standard AWS environment variables and shared credentials file.
+//   - An SFTP server directory, like `sftp://user@host:22/path`. See OpenSFTP
+//     for the supported authentication methods.
+func Open(location string) (BackupStore, error) {
+	u, err := url.Parse(location)
+	if err != nil || u.Scheme == "" || len(u.Scheme) == 1 {
+		// Local path, including Windows paths with drive letter
+		return NewLocalStore(afero.NewOsFs(), location), nil
+	}
+	switch u.Scheme {
+	case "file":
+		return NewLocalStore(afero.NewOsFs(), u.Path), nil
+	case "s3":
+		return OpenS3(u)
+	case "sftp":
+		return OpenSFTP(u)
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedStore, u.Scheme)
+}
+
+// checkKey returns an error if the given key is not a clean relative path
+// inside the store.
+func checkKey(key string) error {
+	if key == "" || path.Clean(key) != key || path.IsAbs(key) || key == ".." || strings.HasPrefix(key, "../") {
+		return fmt.Errorf("%w: %q", ErrInvalidKey, key)
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package storage
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testBackupStore checks the behavior shared by all the BackupStore
+// implementations, using the given empty store.
+func testBackupStore(t *testing.T, s BackupStore) {
+	t.Helper()
+	get := func(key string) []byte {
+		t.Helper()
+		r, err := s.Get(key)
+		require.NoError(t, err)
+		defer r.Close()
+		data, err := io.ReadAll(r)
+		require.NoError(t, err)
+		return data
+	}
+
+	keys, err := s.List("")
+	require.NoError(t, err)
+	assert.Empty(t, keys, "store should be empty")
+
+	// Put and get
+	require.NoError(t, s.Put("backup-id.json", bytes.NewReader([]byte("manifest"))))
+	require.NoError(t, s.Put("chunks/mock-avs-default/ab/abcd.zst", bytes.NewReader([]byte("chunk 1"))))
+	require.NoError(t, s.Put("chunks/mock-avs-default/cd/cdef.zst", bytes.NewReader([]byte("chunk 2"))))
+	assert.Equal(t, []byte("manifest"), get("backup-id.json"))
+	assert.Equal(t, []byte("chunk 1"), get("chunks/mock-avs-default/ab/abcd.zst"))
+
+	// Put replaces the file
+	require.NoError(t, s.Put("backup-id.json", bytes.NewReader([]byte("new manifest"))))
+	assert.Equal(t, []byte("new manifest"), get("backup-id.json"))
+
+	// Large files
+	large := make([]byte, 12*1024*1024)
+	rand.New(rand.NewSource(1)).Read(large)
+	require.NoError(t, s.Put("backup-id.tar", bytes.NewReader(large)))
+	assert.Equal(t, large, get("backup-id.tar"))
+
+	// Exists
+	ok, err := s.Exists("chunks/mock-avs-default/ab/abcd.zst")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = s.Exists("chunks/mock-avs-default/ab/missing.zst")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// List
+	keys, err = s.List("")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"backup-id.json",
+		"backup-id.tar",
+		"chunks/mock-avs-default/ab/abcd.zst",
+		"chunks/mock-avs-default/cd/cdef.zst",
+	}, keys)
+	keys, err = s.List("chunks/")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"chunks/mock-avs-default/ab/abcd.zst",
+		"chunks/mock-avs-default/cd/cdef.zst",
+	}, keys)
+
+	// Missing files
+	_, err = s.Get("missing.json")
+	assert.ErrorIs(t, err, ErrFileNotFound)
+
+	// Delete
+	require.NoError(t, s.Delete("chunks/mock-avs-default/ab/abcd.zst"))
+	ok, err = s.Exists("chunks/mock-avs-default/ab/abcd.zst")
+	require.NoError(t, err)
+	assert.False(t, ok)
+	require.NoError(t, s.Delete("chunks/mock-avs-default/ab/abcd.zst"), "deleting a missing file should not fail")
+
+	// Invalid keys
+	for _, key := range []string{"", "../backup-id.json", "/backup-id.json", "chunks/../../backup-id.json"} {
+		assert.ErrorIs(t, s.Put(key, bytes.NewReader(nil)), ErrInvalidKey, key)
+		_, err := s.Get(key)
+		assert.ErrorIs(t, err, ErrInvalidKey, key)
+	}
+
+	assert.NoError(t, s.Close())
+}
+
+func TestLocalStore(t *testing.T) {
+	testBackupStore(t, NewLocalStore(afero.NewOsFs(), filepath.Join(t.TempDir(), "backups")))
+}
+
+func TestOpen(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name     string
+		location string
+		want     BackupStore
+		wantErr  error
+	}{
+		{
+			name:     "local path",
+			location: dir,
+			want:     NewLocalStore(afero.NewOsFs(), dir),
+		},
+		{
+			name:     "relative local path",
+			location: "backups",
+			want:     NewLocalStore(afero.NewOsFs(), "backups"),
+		},
+		{
+			name:     "file URL",
+			location: "file://" + filepath.ToSlash(dir),
+			want:     NewLocalStore(afero.NewOsFs(), filepath.ToSlash(dir)),
+		},
+		{
+			name:     "s3 without bucket",
+			location: "s3:///prefix",
+			wantErr:  ErrInvalidStoreURL,
+		},
+		{
+			name:     "sftp without host",
+			location: "sftp:///backups",
+			wantErr:  ErrInvalidStoreURL,
+		},
+		{
+			name:     "unsupported scheme",
+			location: "ftp://host/backups",
+			wantErr:  ErrUnsupportedStore,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Open(tt.location)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
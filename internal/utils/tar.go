@@ -8,10 +8,15 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 
 	log "github.com/sirupsen/logrus"
 )
 
+// ErrUnsafeTarEntry is returned when a tar entry would be extracted outside of
+// the destination directory, or when an untrusted tar has link entries.
+var ErrUnsafeTarEntry = errors.New("unsafe tar entry")
+
 func CompressToTarGz(srcDir string, tarFile io.Writer) error {
 	gw := gzip.NewWriter(tarFile)
 	defer gw.Close()
@@ -54,7 +59,21 @@ func CompressToTarGz(srcDir string, tarFile io.Writer) error {
 	return err
 }
 
+// DecompressTarGz extracts the directories and regular files of the given
+// tar.gz into destDir. Entries that would be extracted outside of destDir are
+// rejected with ErrUnsafeTarEntry, and link entries are skipped.
 func DecompressTarGz(tarFile io.Reader, destDir string) error {
+	return decompressTarGz(tarFile, destDir, false)
+}
+
+// DecompressUntrustedTarGz is like DecompressTarGz, but it also rejects the
+// symlink and hardlink entries with ErrUnsafeTarEntry. It must be used for tar
+// files downloaded from remote sources.
+func DecompressUntrustedTarGz(tarFile io.Reader, destDir string) error {
+	return decompressTarGz(tarFile, destDir, true)
+}
+
+func decompressTarGz(tarFile io.Reader, destDir string, rejectLinks bool) error {
 	log.Debugf("Decompressing tar file to %s", destDir)
 	gr, err := gzip.NewReader(tarFile)
 	if err != nil {
@@ -73,7 +92,10 @@ func DecompressTarGz(tarFile io.Reader, destDir string) error {
 		case header == nil:
 			continue
 		}
-		target := filepath.Join(destDir, header.Name)
+		target, err := tarEntryPath(destDir, header.Name)
+		if err != nil {
+			return err
+		}
 		switch header.Typeflag {
 		case tar.TypeDir:
 			targetInfo, err := os.Stat(target)
@@ -109,6 +131,24 @@ func DecompressTarGz(tarFile io.Reader, destDir string) error {
 			if err != nil {
 				return err
 			}
+		case tar.TypeSymlink, tar.TypeLink:
+			if rejectLinks {
+				return fmt.Errorf("%w: link %s", ErrUnsafeTarEntry, header.Name)
+			}
 		}
 	}
 }
+
+// tarEntryPath returns the path where the tar entry with the given name is
+// extracted into destDir, or ErrUnsafeTarEntry if it is outside of destDir.
+func tarEntryPath(destDir, name string) (string, error) {
+	if filepath.IsAbs(name) {
+		return "", fmt.Errorf("%w: absolute path %s", ErrUnsafeTarEntry, name)
+	}
+	target := filepath.Join(destDir, name)
+	rel, err := filepath.Rel(destDir, target)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%w: path %s outside of the destination directory", ErrUnsafeTarEntry, name)
+	}
+	return target, nil
+}
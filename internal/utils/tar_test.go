@@ -1,6 +1,9 @@
 package utils
 
 import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -85,3 +88,97 @@ func assertEqualFiles(t *testing.T, f1, f2 string) {
 	require.NoError(t, err, "failed to read file %s", f2)
 	assert.Equal(t, file1, file2)
 }
+
+func TestDecompressTarGz_Unsafe(t *testing.T) {
+	tests := []struct {
+		name      string
+		header    tar.Header
+		untrusted bool
+		wantErr   bool
+		wantFile  bool
+	}{
+		{
+			name:      "regular file",
+			header:    tar.Header{Name: "dir/file", Typeflag: tar.TypeReg},
+			untrusted: true,
+			wantFile:  true,
+		},
+		{
+			name:    "parent directory traversal",
+			header:  tar.Header{Name: "../../evil", Typeflag: tar.TypeReg},
+			wantErr: true,
+		},
+		{
+			name:    "nested parent directory traversal",
+			header:  tar.Header{Name: "dir/../../evil", Typeflag: tar.TypeReg},
+			wantErr: true,
+		},
+		{
+			name:    "absolute path",
+			header:  tar.Header{Name: "/evil", Typeflag: tar.TypeReg},
+			wantErr: true,
+		},
+		{
+			name:    "directory traversal",
+			header:  tar.Header{Name: "../evil-dir/", Typeflag: tar.TypeDir},
+			wantErr: true,
+		},
+		{
+			name:      "symlink, untrusted",
+			header:    tar.Header{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "/etc/passwd"},
+			untrusted: true,
+			wantErr:   true,
+		},
+		{
+			name:      "hardlink, untrusted",
+			header:    tar.Header{Name: "link", Typeflag: tar.TypeLink, Linkname: "../evil"},
+			untrusted: true,
+			wantErr:   true,
+		},
+		{
+			name:   "symlink, skipped",
+			header: tar.Header{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "/etc/passwd"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			gw := gzip.NewWriter(&buf)
+			tw := tar.NewWriter(gw)
+			tt.header.Mode = 0o644
+			tt.header.Size = int64(len("content"))
+			if tt.header.Typeflag != tar.TypeReg {
+				tt.header.Size = 0
+			}
+			require.NoError(t, tw.WriteHeader(&tt.header))
+			if tt.header.Typeflag == tar.TypeReg {
+				_, err := tw.Write([]byte("content"))
+				require.NoError(t, err)
+			}
+			require.NoError(t, tw.Close())
+			require.NoError(t, gw.Close())
+
+			testDir := t.TempDir()
+			outDir := filepath.Join(testDir, "out", "sub")
+			var err error
+			if tt.untrusted {
+				err = DecompressUntrustedTarGz(&buf, outDir)
+			} else {
+				err = DecompressTarGz(&buf, outDir)
+			}
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrUnsafeTarEntry)
+			} else {
+				assert.NoError(t, err)
+			}
+			// Nothing is written outside of the destination directory
+			assert.NoFileExists(t, filepath.Join(testDir, "evil"))
+			assert.NoFileExists(t, filepath.Join(testDir, "out", "evil"))
+			assert.NoDirExists(t, filepath.Join(testDir, "out", "evil-dir"))
+			assert.NoFileExists(t, filepath.Join(outDir, "link"))
+			if tt.wantFile {
+				assert.FileExists(t, filepath.Join(outDir, "dir", "file"))
+			}
+		})
+	}
+}
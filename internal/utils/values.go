@@ -0,0 +1,81 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var (
+	ErrInvalidByteSize = errors.New("invalid byte size")
+
+	byteSizeRe = regexp.MustCompile(`^([0-9]+(?:\.[0-9]+)?)\s*([a-zA-Z]*)$`)
+
+	byteSizeUnits = map[string]float64{
+		"":    1,
+		"B":   1,
+		"KB":  1e3,
+		"MB":  1e6,
+		"GB":  1e9,
+		"TB":  1e12,
+		"KiB": 1 << 10,
+		"MiB": 1 << 20,
+		"GiB": 1 << 30,
+		"TiB": 1 << 40,
+	}
+)
+
+// IsEthAddress returns true if the given string is a hex encoded Ethereum
+// address with the 0x prefix. Mixed case addresses must have a valid EIP-55
+// checksum.
+func IsEthAddress(s string) bool {
+	if !strings.HasPrefix(s, "0x") || !common.IsHexAddress(s) {
+		return false
+	}
+	hex := s[2:]
+	if hex == strings.ToLower(hex) || hex == strings.ToUpper(hex) {
+		return true
+	}
+	return common.HexToAddress(s).Hex() == s
+}
+
+// ParseByteSize parses a size in bytes with an optional decimal (KB, MB, GB, TB)
+// or binary (KiB, MiB, GiB, TiB) unit, like `512MB` or `10GiB`. Values without
+// unit are bytes.
+func ParseByteSize(s string) (uint64, error) {
+	m := byteSizeRe.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return 0, fmt.Errorf("%w: %s", ErrInvalidByteSize, s)
+	}
+	unit, ok := byteSizeUnits[m[2]]
+	if !ok {
+		return 0, fmt.Errorf("%w: unknown unit %s", ErrInvalidByteSize, m[2])
+	}
+	value, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s", ErrInvalidByteSize, s)
+	}
+	size := value * unit
+	if size > math.MaxUint64 {
+		return 0, fmt.Errorf("%w: %s is too big", ErrInvalidByteSize, s)
+	}
+	return uint64(size), nil
+}
+
+// SplitList splits a comma separated list, trimming the spaces around the items.
+// An empty string is an empty list.
+func SplitList(s string) []string {
+	if strings.TrimSpace(s) == "" {
+		return []string{}
+	}
+	items := strings.Split(s, ",")
+	for i := range items {
+		items[i] = strings.TrimSpace(items[i])
+	}
+	return items
+}
@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsEthAddress(t *testing.T) {
+	tests := []struct {
+		address string
+		want    bool
+	}{
+		{address: "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", want: true},
+		{address: "0x5AAEB6053F3E94C9B9A09F33669435E7EF1BEAED", want: true},
+		{address: "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", want: true},
+		{address: "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD", want: false},
+		{address: "5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", want: false},
+		{address: "0x5aaeb6053f3e94c9b9a09f33669435e7ef1bea", want: false},
+		{address: "0xzaaeb6053f3e94c9b9a09f33669435e7ef1beaed", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsEthAddress(tt.address))
+		})
+	}
+}
+
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		size    string
+		want    uint64
+		wantErr bool
+	}{
+		{size: "1024", want: 1024},
+		{size: "10B", want: 10},
+		{size: "512MB", want: 512_000_000},
+		{size: "10GiB", want: 10 << 30},
+		{size: "1.5 KiB", want: 1536},
+		{size: "2TB", want: 2_000_000_000_000},
+		{size: "10gb", wantErr: true},
+		{size: "GiB", wantErr: true},
+		{size: "-1GiB", wantErr: true},
+		{size: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.size, func(t *testing.T) {
+			got, err := ParseByteSize(tt.size)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidByteSize)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSplitList(t *testing.T) {
+	assert.Equal(t, []string{}, SplitList(" "))
+	assert.Equal(t, []string{"a"}, SplitList("a"))
+	assert.Equal(t, []string{"https://a.io", "https://b.io"}, SplitList("https://a.io, https://b.io"))
+}
@@ -1,7 +1,23 @@
 package daemon
 
+import (
+	"context"
+
+	"github.com/NethermindEth/eigenlayer/internal/backup"
+)
+
 type BackupManager interface {
-	// BackupInstance creates a backup of the instance with the given ID.
-	BackupInstance(instanceId string) (string, error)
-	RestoreInstance(backupId string) error
+	// BackupInstance creates a backup of the instance with the given ID. The
+	// backup is canceled when the context is done.
+	BackupInstance(ctx context.Context, instanceId string, options backup.BackupOptions) (string, error)
+	// RestoreInstance restores the backup with the given ID, and returns the ID
+	// of the restored instance, also if the restore fails after restoring the
+	// instance data. The restore is canceled when the context is done.
+	RestoreInstance(ctx context.Context, backupId string, options backup.RestoreOptions) (string, error)
+	// PushBackup copies the local backup with the given ID to the backup store
+	// at the given location.
+	PushBackup(backupId, location string) error
+	// PullBackup copies the backup with the given ID from the backup store at
+	// the given location to the local backups.
+	PullBackup(backupId, location string) error
 }
@@ -2,6 +2,7 @@ package daemon
 
 import (
 	"context"
+	"crypto/ed25519"
 	"fmt"
 	"io"
 	"time"
@@ -42,8 +43,9 @@ type Daemon interface {
 	Stop(instanceId string) error
 
 	// Uninstall stops and removes the instance with the given ID. If there is no
-	// installed instance with the given ID an error will be returned.
-	Uninstall(instanceId string) error
+	// installed instance with the given ID an error will be returned. The volumes
+	// of the instance are removed too, unless options.KeepVolumes is set.
+	Uninstall(instanceId string, options UninstallOptions) error
 
 	// InitMonitoring initializes the MonitoringStack. If install is true, the
 	// MonitoringStack will be installed if it is not already installed. If run
@@ -67,9 +69,11 @@ type Daemon interface {
 	// ListInstances returns a list of all the installed instances and their health.
 	ListInstances() ([]ListInstanceItem, error)
 
-	// LocalInstall installs a node software package from a local tarball. This
-	// installation method is only intended for development purposes and is not
-	// secure. It returns the instance ID of the installed package.
+	// LocalInstall installs a node software package from a local tarball. Unless
+	// options.Secure is set, this installation method is only intended for
+	// development purposes and is not secure. The digest of the tarball is recorded
+	// as the provenance of the instance. It returns the instance ID of the installed
+	// package.
 	LocalInstall(pkgTar io.Reader, options LocalInstallOptions) (string, error)
 
 	// NodeLogs returns the logs of the node with the given ID. If there is no
@@ -78,17 +82,94 @@ type Daemon interface {
 
 	// Backup creates a backup of the instance with the given ID and returns the
 	// backup ID. If there is no installed instance with the given ID an error
-	// will be returned.
-	Backup(instanceId string) (backupId string, err error)
+	// will be returned. The running services of the instance are stopped or
+	// paused while their volumes are backed up, and then they are resumed.
+	// The backup is canceled when the context is done, and the partial backup
+	// is removed.
+	Backup(ctx context.Context, instanceId string, options BackupOptions) (backupId string, err error)
 
 	// Restore restores the backup with the given ID. If the AVS instance id of
 	// the backup exists, then the command will uninstall it before restoring
 	// the backup. If the AVS instance does not exist, then the command will
-	// create it. If run is true, the instance will be run after the restore.
-	Restore(backupId string, run bool) error
+	// create it. If the backup is not stored locally and options.From is set,
+	// the backup is pulled from that backup store first. If the backup is
+	// encrypted and options.Passphrase is empty or wrong, ErrBackupEncrypted
+	// or ErrInvalidBackupPassphrase is returned before changing the instance.
+	// If options.Tag is set, the backup is restored as a new instance with that
+	// tag instead, and ErrInstanceAlreadyExists is returned if it exists.
+	// ErrSharedResources is returned if the new instance would use resources of
+	// other instances, like bind mounts outside the instance directory, or
+	// external or explicitly named volumes. The new instance does not keep the
+	// fixed container names of the backup.
+	// The restore is canceled when the context is done, which may leave the
+	// instance partially restored. A new instance restored with options.Tag
+	// is removed if the restore fails or is canceled.
+	Restore(ctx context.Context, backupId string, options RestoreOptions) error
 
 	// BackupList returns a list of all the backups and their information.
 	BackupList() ([]BackupInfo, error)
+
+	// BackupImport imports the backup tar file at the given path, like a backup
+	// tar file copied from another host, and returns its information. The
+	// backup is stored like the local backups. If the backup already exists,
+	// ErrBackupAlreadyExists is returned.
+	BackupImport(path string) (BackupInfo, error)
+
+	// BackupExport writes the backup with the given ID as a single tar file at
+	// the given path, which can be imported with BackupImport on another host.
+	// The passphrase decrypts the data of encrypted backups, and the exported
+	// tar file is not encrypted. If the backup does not exist,
+	// ErrBackupNotFound is returned.
+	BackupExport(backupId, path, passphrase string) error
+
+	// BackupVerify checks that the backup with the given ID is restorable,
+	// validating its data, the checksums of its files and the instance state.
+	// If options.Restore is set, the backup is also restored as a temporary
+	// instance, which is removed afterwards. If the backup is corrupted,
+	// ErrCorruptedBackup is returned, and ErrTrialRestoreFailed if the trial
+	// restore fails.
+	BackupVerify(backupId string, options BackupVerifyOptions) (BackupVerification, error)
+
+	// BackupPush copies the local backup with the given ID to the backup store
+	// at the given location, like a local directory, an s3:// URL or an
+	// sftp:// URL. Only the backup data missing in the store is uploaded.
+	BackupPush(backupId, location string) error
+
+	// BackupPull copies the backup with the given ID from the backup store at
+	// the given location to the local backups. Only the backup data missing
+	// locally is downloaded.
+	BackupPull(backupId, location string) error
+
+	// SetBackupSchedule sets the backup schedule and the backup retention
+	// policy of the instance with the given ID, replacing its previous
+	// schedule if any. If the schedule has a passphrase file, it is read to
+	// check that it has a passphrase.
+	SetBackupSchedule(instanceId string, schedule BackupSchedule) error
+
+	// RemoveBackupSchedule removes the backup schedule of the instance with the
+	// given ID. The existing backups of the instance are not removed.
+	RemoveBackupSchedule(instanceId string) error
+
+	// BackupSchedules returns the backup schedules of all the instances.
+	BackupSchedules() ([]BackupScheduleInfo, error)
+
+	// RunScheduledBackups creates the backups that are due according to the
+	// backup schedules, and then prunes the backups of each instance with its
+	// retention policy. A failed backup does not stop the other scheduled
+	// backups, its error is returned in its result. Each run is recorded
+	// before its backup, so concurrent calls do not run the same backup twice.
+	RunScheduledBackups() ([]ScheduledBackupResult, error)
+
+	// PruneBackups removes the backups not kept by the retention policies of
+	// the backup schedules, and the backup data not used by the remaining
+	// backups. It returns the removed backups, or the backups that would be
+	// removed if options.DryRun is true.
+	PruneBackups(options PruneBackupsOptions) ([]BackupInfo, error)
+
+	// RunMigrations runs the given migrations, in order, with the volumes of the
+	// instance with the given ID mounted. The instance should be stopped before
+	// running the migrations.
+	RunMigrations(instanceId string, migrations []Migration) error
 }
 
 type PullTarget struct {
@@ -111,6 +192,18 @@ type ListInstanceItem struct {
 	Health  NodeHealth
 	Running bool
 	Comment string
+	// HealthChecks are the results of the health checks of the instance
+	// profile, if any. The Health of the instance is their aggregate.
+	HealthChecks []HealthCheckResult
+}
+
+// HealthCheckResult is the result of a health check of an instance.
+type HealthCheckResult struct {
+	Name    string
+	Service string
+	Healthy bool
+	// Detail describes why the health check failed.
+	Detail string
 }
 
 // NodeHealth is the health of a node, matching the HTTP status codes.
@@ -160,13 +253,20 @@ type PullResult struct {
 	// Commit hash of the pulled package.
 	Commit string
 
-	// HasPlugin is true if the package has a plugin.
+	// HasPlugin is true if any profile of the package has a plugin.
 	HasPlugin bool
 
+	// PluginImages is a map of profile names to the image of their plugin,
+	// with the plugin overrides of the profile applied. Profiles without a
+	// plugin are not included.
+	PluginImages map[string]string
+
 	// Options is map of profile names to their options.
 	Options map[string][]Option
 
-	// HardwareRequirements is the hardware requirements specified in the package manifest.
+	// HardwareRequirements is a map of profile names to their hardware
+	// requirements, with the hardware requirements overrides of the profile
+	// applied.
 	HardwareRequirements map[string]HardwareRequirements
 }
 
@@ -181,15 +281,26 @@ type PullUpdateResult struct {
 	// NweVersion is the version of the new package.
 	NewVersion string
 
+	// OldSpecVersion is the AVS Node Specification version of the old package.
+	OldSpecVersion string
+
+	// NewSpecVersion is the AVS Node Specification version of the new package.
+	NewSpecVersion string
+
 	// OldCommit is the commit hash of the old package.
 	OldCommit string
 
 	// NewCommit is the commit hash of the new package.
 	NewCommit string
 
-	// HasPlugin is true if the package has a plugin.
+	// HasPlugin is true if the profile of the instance has a plugin in the new
+	// package.
 	HasPlugin bool
 
+	// PluginImage is the image of the plugin of the instance profile in the
+	// new package, with the plugin overrides of the profile applied.
+	PluginImage string
+
 	// OldOptions is the list of options of the old package.
 	OldOptions []Option
 
@@ -201,8 +312,68 @@ type PullUpdateResult struct {
 	// and should be filled by the user.
 	MergedOptions []Option
 
-	// HardwareRequirements is the hardware requirements specified in the package manifest.
+	// OptionChanges reports how the values of the old options are carried over
+	// to the merged options.
+	OptionChanges []OptionChange
+
+	// HardwareRequirements is the hardware requirements of the instance profile
+	// in the new package, with the hardware requirements overrides of the
+	// profile applied.
 	HardwareRequirements HardwareRequirements
+
+	// IncompatibleData is true if the new package can not reuse the data volumes
+	// of the old package.
+	IncompatibleData bool
+
+	// Migrations is the list of migrations declared by the new package that must
+	// be run between the stop of the old instance and the start of the new one.
+	Migrations []Migration
+}
+
+// OptionChangeKind is the kind of change of an option value in an update.
+type OptionChangeKind string
+
+const (
+	// OptionKept is an option whose old value is kept.
+	OptionKept OptionChangeKind = "kept"
+	// OptionRenamed is an option renamed in the new version whose old value
+	// is kept.
+	OptionRenamed OptionChangeKind = "renamed"
+	// OptionReset is an option whose old value is not valid in the new
+	// version, and must be set again.
+	OptionReset OptionChangeKind = "reset"
+	// OptionNew is an option that does not exist in the old version.
+	OptionNew OptionChangeKind = "new"
+	// OptionRemoved is an option of the old version that does not exist in the
+	// new version.
+	OptionRemoved OptionChangeKind = "removed"
+)
+
+// OptionChange describes how the value of an option is carried over in an update.
+type OptionChange struct {
+	// Name is the name of the option in the new version, or in the old
+	// version if the option was removed.
+	Name string
+	// OldName is the name of the option in the old version, if it was renamed.
+	OldName string
+	// Kind is the kind of change.
+	Kind OptionChangeKind
+	// OldValue is the value of the option in the old version. Hidden values are
+	// redacted.
+	OldValue string
+	// NewValue is the value of the option in the new version, empty if it is
+	// not set yet. Hidden values are redacted.
+	NewValue string
+	// Reason is the reason why the option was reset.
+	Reason string
+}
+
+// Migration is a container that migrates the data of an instance between versions.
+type Migration struct {
+	// Image is the docker image of the migration.
+	Image string
+	// Args are the arguments passed to the migration container.
+	Args []string
 }
 
 // InstallOptions is a set of options for installing a node software package.
@@ -239,7 +410,8 @@ type InstallOptions struct {
 // LocalInstallOptions is a set of options for installing a node software package
 // from a local tarball.
 type LocalInstallOptions struct {
-	// Name is the name of the package.
+	// Name is the name of the package. If empty, the name in the package
+	// manifest is used.
 	Name string
 
 	// Tag is the tag to use for the instance, required to build the instance id
@@ -249,10 +421,28 @@ type LocalInstallOptions struct {
 	// Profile is the name of the profile to use for the instance.
 	Profile string
 
-	// Options is the list of options to use for the instance. These options are
-	// passed as strings because the local installation method is for development
-	// purposes only, and the user is responsible for passing the correct options.
+	// Options is the list of options to use for the instance, by option name.
+	// Values are validated against the option types of the selected profile.
 	Options map[string]string
+
+	// Secure enables the checks of the remote installation flow: package
+	// integrity check, hardware requirements check and signature verification.
+	Secure bool
+
+	// Signature is the detached ed25519 signature of the package tarball. It is
+	// required if Secure is set.
+	Signature []byte
+
+	// TrustedKeys is the list of public keys trusted to sign the package tarball.
+	TrustedKeys []ed25519.PublicKey
+}
+
+// UninstallOptions is a set of options for uninstalling an instance.
+type UninstallOptions struct {
+	// KeepVolumes keeps the volumes of the instance, to be reused by an
+	// instance with the same ID installed afterwards, like the new version of
+	// the instance on update.
+	KeepVolumes bool
 }
 
 type HardwareRequirements struct {
@@ -266,12 +456,187 @@ func (h HardwareRequirements) String() string {
 	return fmt.Sprintf("CPU: %d Cores, RAM: %d Mb, Disk Space: %d Mb", h.MinCPUCores, h.MinRAM, h.MinFreeSpace)
 }
 
+// BackupOptions are the options of a new backup.
+type BackupOptions struct {
+	// Compression is the compression of the backup data: zstd (default), gzip
+	// or none.
+	Compression string
+	// Passphrase encrypts the backup data if it is not empty. It is required
+	// to restore the backup.
+	Passphrase string
+	// Consistency is how the running services of the instance are quiesced
+	// while their volumes are backed up: stop (default) stops them, and pause
+	// pauses them for a shorter downtime. The services are always returned to
+	// their running state after the backup.
+	Consistency string
+	// Include are the volumes to back up, with the format <service> or
+	// <service>:<volume>, where <volume> is the volume name or its mount
+	// target. All the volumes are backed up if it is empty.
+	Include []string
+	// Exclude are the volumes not to back up, with the same format as
+	// Include.
+	Exclude []string
+	// ConfigOnly backs up the instance configuration without any volume,
+	// without stopping or pausing the instance services.
+	ConfigOnly bool
+	// Scheduled marks the backup as created by the backup schedule of the
+	// instance. Only scheduled backups are removed by the retention policy of
+	// the schedule.
+	Scheduled bool
+	// Progress receives the progress of the backup of each volume, if it is
+	// not nil.
+	Progress func(BackupProgress)
+}
+
+// RestoreOptions are the options to restore a backup.
+type RestoreOptions struct {
+	// Run runs the instance after restoring it.
+	Run bool
+	// From is the location of the backup store to pull the backup from, if
+	// it is not stored locally.
+	From string
+	// Passphrase decrypts the data of encrypted backups.
+	Passphrase string
+	// Include are the volumes to restore, with the same format as
+	// BackupOptions.Include. All the volumes of the backup are restored if it
+	// is empty.
+	Include []string
+	// Exclude are the volumes not to restore.
+	Exclude []string
+	// ConfigOnly restores the instance configuration without any volume.
+	ConfigOnly bool
+	// Secrets are the values of the active secret options of the instance
+	// that are not stored on this host, by option name. Secrets are not
+	// included in backups, so MissingSecretsError is returned with the
+	// secret options without value before any volume is restored.
+	Secrets map[string]string
+	// Tag restores the backup as a new instance with the given tag, without
+	// changing the instance of the backup, for example to test the backup.
+	// The new instance gets the secrets of the instance of the backup if it
+	// is installed. Instances with resources that are not namespaced to them,
+	// which would be shared with the instance of the backup, are not
+	// restored with a new tag.
+	Tag string
+	// Progress receives the progress of the restore of each volume, if it is
+	// not nil.
+	Progress func(BackupProgress)
+}
+
+// BackupProgress is the progress of the backup or the restore of a volume.
+// The volumes are reported one after the other, and the last event of each
+// volume, and only that one, has Processed equal to Total.
+type BackupProgress struct {
+	// Service is the service of the volume.
+	Service string
+	// Volume is the mount target of the volume in the service.
+	Volume string
+	// Processed is the number of bytes of the volume processed so far.
+	Processed int64
+	// Total is the size of the volume in bytes, or -1 if it is unknown. The
+	// size of the volumes being backed up is an estimate.
+	Total int64
+}
+
+// BackupVerifyOptions are the options to verify a backup.
+type BackupVerifyOptions struct {
+	// Passphrase decrypts the data of encrypted backups.
+	Passphrase string
+	// Restore restores the backup as a temporary instance, which is removed
+	// afterwards with its volumes. Backups of instances with resources that
+	// would be shared with the temporary instance are not restored, and
+	// ErrSharedResources is returned. Fixed container names are removed from
+	// the temporary instance. If the instance has secrets that are not stored
+	// on this host, ErrMissingSecrets is returned.
+	Restore bool
+}
+
+// BackupVerification is the result of the verification of a backup.
+type BackupVerification struct {
+	// Files is the number of files in the backup.
+	Files int
+	// Checksums is true if the checksums of the files were verified. Backups
+	// created before checksums were recorded do not have them.
+	Checksums bool
+	// Restored is true if the trial restore succeeded.
+	Restored bool
+}
+
+// BackupSchedule is the schedule of the automatic backups of an instance, and
+// the retention policy of its backups.
+type BackupSchedule struct {
+	// Cron is a standard cron expression with five fields, like "0 3 * * *",
+	// or a descriptor like "@daily", evaluated in local time.
+	Cron string
+	// Compression is the compression of the scheduled backups.
+	Compression string
+	// Consistency is the consistency mode of the scheduled backups.
+	Consistency string
+	// PassphraseFile is the absolute path of the file whose first line is the
+	// passphrase encrypting the scheduled backups, empty if they are not
+	// encrypted. The file is read each time a scheduled backup runs, so it
+	// must stay readable by the user running the scheduled backups.
+	PassphraseFile string
+	// Retention is the retention policy of all the backups of the instance,
+	// including the backups that were not scheduled.
+	Retention BackupRetention
+}
+
+// BackupRetention is the retention policy of the backups of an instance. A
+// backup is kept if any of the rules keeps it, and all the backups are kept if
+// all the rules are zero.
+type BackupRetention struct {
+	// KeepLast is the number of latest backups to keep.
+	KeepLast int
+	// KeepDaily is the number of days to keep the latest backup of.
+	KeepDaily int
+	// KeepWeekly is the number of weeks to keep the latest backup of.
+	KeepWeekly int
+	// KeepMonthly is the number of months to keep the latest backup of.
+	KeepMonthly int
+}
+
+// BackupScheduleInfo is the backup schedule of an instance and its state.
+type BackupScheduleInfo struct {
+	InstanceId string
+	BackupSchedule
+	// LastRun is the time of the last scheduled backup, zero if no scheduled
+	// backup has run yet.
+	LastRun time.Time
+	// NextRun is the time of the next scheduled backup.
+	NextRun time.Time
+}
+
+// ScheduledBackupResult is the result of a scheduled backup.
+type ScheduledBackupResult struct {
+	InstanceId string
+	// BackupId is the ID of the new backup, empty if the backup failed.
+	BackupId string
+	// Pruned are the backups of the instance removed by its retention policy
+	// after the backup.
+	Pruned []BackupInfo
+	// Err is the error of the backup or the pruning, if any.
+	Err error
+}
+
+// PruneBackupsOptions are the options to prune backups.
+type PruneBackupsOptions struct {
+	// InstanceIds are the instances to prune the backups of. If empty, the
+	// backups of all the instances with a backup schedule are pruned.
+	InstanceIds []string
+	// DryRun only returns the backups that would be removed.
+	DryRun bool
+}
+
 type BackupInfo struct {
 	Id        string
 	Instance  string
 	Timestamp time.Time
+	// SizeBytes is the logical size of the backup.
 	SizeBytes int64
-	Version   string
-	Commit    string
-	Url       string
+	// StoredSizeBytes is the size of the data stored by the backup. The data
+	// already stored by previous backups of the instance is not included.
+	StoredSizeBytes int64
+	Version         string
+	Commit          string
+	Url             string
 }
@@ -20,8 +20,9 @@ type DockerManager interface {
 	// LoadImageContext loads the given context.
 	LoadImageContext(path string) (io.ReadCloser, error)
 
-	// Run runs the given image with the given network and arguments.
-	Run(image string, options docker.RunOptions) error
+	// Run runs the given image with the given network and arguments. The
+	// container is killed if the context is done before it exits.
+	Run(ctx context.Context, image string, options docker.RunOptions) error
 
 	// ContainerLogsMerged returns the merge of the logs of the given services.
 	ContainerLogsMerged(ctx context.Context, w io.Writer, services map[string]string, opts docker.ContainerLogsMergedOptions) error
@@ -31,4 +32,8 @@ type DockerManager interface {
 
 	// ImageExists checks if the given image exists.
 	ImageExist(image string) (bool, error)
+
+	// Exec runs the given command inside the given container, returning its
+	// exit code and output.
+	Exec(ctx context.Context, container string, cmd []string) (int, string, error)
 }
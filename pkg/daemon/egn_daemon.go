@@ -1,23 +1,28 @@
 package daemon
 
 import (
+	"bytes"
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"os"
 	"path"
 	"path/filepath"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	log "github.com/sirupsen/logrus"
 	"golang.org/x/exp/maps"
 	"golang.org/x/mod/semver"
 
+	"github.com/NethermindEth/eigenlayer/internal/backup"
 	"github.com/NethermindEth/eigenlayer/internal/common"
 	"github.com/NethermindEth/eigenlayer/internal/compose"
 	"github.com/NethermindEth/eigenlayer/internal/data"
@@ -196,6 +201,10 @@ func (d *EgnDaemon) instanceHealth(instanceId string) (out ListInstanceItem) {
 		return
 	}
 
+	if len(instance.HealthChecks) > 0 {
+		return d.runHealthChecks(instance)
+	}
+
 	if instance.APITarget == nil {
 		// Instance does not have an API target
 		out.Comment = "Instance's package does not specifies an API target for the AVS Specification Metrics's API"
@@ -259,6 +268,131 @@ func checkHealth(ip string, port string) (NodeHealth, error) {
 	}
 }
 
+// runHealthChecks runs the health checks of the given instance and aggregates
+// their results: the instance is healthy if all the checks pass, unhealthy if
+// none of them pass, and partially healthy otherwise.
+func (d *EgnDaemon) runHealthChecks(instance *data.Instance) (out ListInstanceItem) {
+	out.ID = instance.ID()
+	var (
+		passed int
+		failed []string
+	)
+	for _, check := range instance.HealthChecks {
+		result := d.runHealthCheck(instance, check)
+		if result.Healthy {
+			passed++
+		} else {
+			failed = append(failed, fmt.Sprintf("%s (%s)", result.Name, result.Detail))
+		}
+		out.HealthChecks = append(out.HealthChecks, result)
+	}
+
+	switch passed {
+	case len(instance.HealthChecks):
+		out.Health = NodeHealthy
+	case 0:
+		out.Health = NodeUnhealthy
+	default:
+		out.Health = NodePartiallyHealthy
+	}
+	if len(failed) > 0 {
+		out.Comment = "Failed health checks: " + strings.Join(failed, ", ")
+	}
+	return
+}
+
+// runHealthCheck runs the given health check in the service container of the
+// instance.
+func (d *EgnDaemon) runHealthCheck(instance *data.Instance, check data.HealthCheck) HealthCheckResult {
+	result := HealthCheckResult{
+		Name:    check.Name,
+		Service: check.Service,
+	}
+	timeout := 10 * time.Second
+	if check.Timeout != "" {
+		if t, err := time.ParseDuration(check.Timeout); err == nil {
+			timeout = t
+		}
+	}
+
+	psServices, err := d.dockerCompose.PS(compose.DockerComposePsOptions{
+		ServiceName: check.Service,
+		Path:        instance.ComposePath(),
+		Format:      "json",
+		All:         true,
+	})
+	if err != nil {
+		result.Detail = fmt.Sprintf("failed to get container status: %v", err)
+		return result
+	}
+	if len(psServices) == 0 {
+		result.Detail = "no container found"
+		return result
+	}
+	if psServices[0].State != "running" {
+		result.Detail = "container is " + psServices[0].State
+		return result
+	}
+	containerID := psServices[0].Id
+
+	switch check.Type {
+	case profile.HealthCheckHTTP, profile.HealthCheckTCP:
+		ip, err := d.docker.ContainerIP(containerID)
+		if err != nil {
+			result.Detail = fmt.Sprintf("failed to get container IP: %v", err)
+			return result
+		}
+		address := net.JoinHostPort(ip, check.Port)
+		if check.Type == profile.HealthCheckTCP {
+			conn, err := net.DialTimeout("tcp", address, timeout)
+			if err != nil {
+				result.Detail = err.Error()
+				return result
+			}
+			conn.Close()
+			result.Healthy = true
+			return result
+		}
+		expected := http.StatusOK
+		if check.ExpectedStatus != 0 {
+			expected = check.ExpectedStatus
+		}
+		client := &http.Client{Timeout: timeout}
+		url := fmt.Sprintf("http://%s%s", address, check.Path)
+		log.Debug("Checking health of service at ", url)
+		resp, err := client.Get(url)
+		if err != nil {
+			result.Detail = err.Error()
+			return result
+		}
+		resp.Body.Close()
+		if resp.StatusCode != expected {
+			result.Detail = fmt.Sprintf("unexpected status code: %d", resp.StatusCode)
+			return result
+		}
+		result.Healthy = true
+	case profile.HealthCheckCommand:
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		exitCode, output, err := d.docker.Exec(ctx, containerID, check.Command)
+		if err != nil {
+			result.Detail = err.Error()
+			return result
+		}
+		if exitCode != 0 {
+			result.Detail = fmt.Sprintf("exit code %d", exitCode)
+			if output = strings.TrimSpace(output); output != "" {
+				result.Detail += ": " + output
+			}
+			return result
+		}
+		result.Healthy = true
+	default:
+		result.Detail = "unknown health check type " + check.Type
+	}
+	return result
+}
+
 // Pull implements Daemon.Pull.
 func (d *EgnDaemon) Pull(url string, ref PullTarget, force bool) (result PullResult, err error) {
 	pkgHandler, err := d.pullPackage(url, force)
@@ -295,7 +429,7 @@ func (d *EgnDaemon) Pull(url string, ref PullTarget, force bool) (result PullRes
 		return
 	}
 	// Get Spec version
-	result.SpecVersion, err = pkgHandler.SpecVersion()
+	result.SpecVersion, err = packageSpecVersion(pkgHandler)
 	if err != nil {
 		return
 	}
@@ -321,24 +455,31 @@ func (d *EgnDaemon) Pull(url string, ref PullTarget, force bool) (result PullRes
 		profileOptions[profile.Name] = options
 	}
 	result.Options = profileOptions
-	result.HasPlugin, err = pkgHandler.HasPlugin()
+
+	pluginImages := make(map[string]string, len(profiles))
+	for _, profile := range profiles {
+		plugin, err := d.getPluginData(pkgHandler, profile.Name)
+		if err != nil {
+			return PullResult{}, err
+		}
+		if plugin != nil {
+			pluginImages[profile.Name] = plugin.Image
+		}
+	}
+	result.PluginImages = pluginImages
+	result.HasPlugin = len(pluginImages) > 0
 
 	requirements := make(map[string]HardwareRequirements, len(profiles))
 	for _, profile := range profiles {
-		req, err := pkgHandler.HardwareRequirements(profile.Name)
+		req, err := profileHardwareRequirements(pkgHandler, profile.Name)
 		if err != nil {
 			continue
 		}
-		requirements[profile.Name] = HardwareRequirements{
-			MinCPUCores:                 req.MinCPUCores,
-			MinRAM:                      req.MinRAM,
-			MinFreeSpace:                req.MinFreeSpace,
-			StopIfRequirementsAreNotMet: req.StopIfRequirementsAreNotMet,
-		}
+		requirements[profile.Name] = req
 	}
 	result.HardwareRequirements = requirements
 
-	return result, err
+	return result, nil
 }
 
 func (d *EgnDaemon) PullUpdate(instanceID string, ref PullTarget) (PullUpdateResult, error) {
@@ -398,16 +539,28 @@ func (d *EgnDaemon) PullUpdate(instanceID string, ref PullTarget) (PullUpdateRes
 		return PullUpdateResult{}, fmt.Errorf("%w: %s", ErrVersionAlreadyInstalled, newCommit)
 	}
 
-	// Check commit precedence
-	ok, err := pkgHandler.CommitPrecedence(instance.Commit, newCommit)
+	// Check commit precedence. Sources without commit history, like OCI artifacts,
+	// only rely on the version check.
+	if pkgHandler.SupportsCommits() {
+		ok, err := pkgHandler.CommitPrecedence(instance.Commit, newCommit)
+		if err != nil {
+			return PullUpdateResult{}, err
+		}
+		if !ok {
+			return PullUpdateResult{}, fmt.Errorf("%w: current commit %s is not previous to the update commit %s", ErrInvalidUpdateCommit, instance.Commit, ref.Commit)
+		}
+	}
+	// Get new version
+	newVersion, err := pkgHandler.CurrentVersion()
 	if err != nil {
 		return PullUpdateResult{}, err
 	}
-	if !ok {
-		return PullUpdateResult{}, fmt.Errorf("%w: current commit %s is not previous to the update commit %s", ErrInvalidUpdateCommit, instance.Commit, ref.Commit)
+
+	dataCompatible, migrations, err := checkUpgrade(pkgHandler, instance.Version)
+	if err != nil {
+		return PullUpdateResult{}, err
 	}
-	// Get new version
-	newVersion, err := pkgHandler.CurrentVersion()
+	newSpecVersion, err := packageSpecVersion(pkgHandler)
 	if err != nil {
 		return PullUpdateResult{}, err
 	}
@@ -434,35 +587,50 @@ func (d *EgnDaemon) PullUpdate(instanceID string, ref PullTarget) (PullUpdateRes
 	if err != nil {
 		return PullUpdateResult{}, err
 	}
-	for _, o := range optionsOld {
-		if v, ok := valuesOld[o.Target()]; ok {
-			err := o.Set(v)
-			if err != nil {
-				return PullUpdateResult{}, fmt.Errorf("error setting old option value: %v", err)
-			}
-		} else {
-			return PullUpdateResult{}, fmt.Errorf("%w: old option %s", ErrOptionWithoutValue, o.Name())
-		}
+	secretsOld, err := d.dataDir.Secrets().Get(instance.ID())
+	if err != nil {
+		return PullUpdateResult{}, err
+	}
+	maps.Copy(valuesOld, secretsOld)
+	if err := setOldOptionValues(optionsOld, valuesOld); err != nil {
+		return PullUpdateResult{}, err
+	}
+
+	mergedOptions, optionChanges, err := mergeOptions(optionsOld, optionsNew)
+	if err != nil {
+		return PullUpdateResult{}, err
 	}
 
-	mergedOptions, err := mergeOptions(optionsOld, optionsNew)
+	// Get new plugin and hardware requirements
+	plugin, err := d.getPluginData(pkgHandler, instance.Profile)
+	if err != nil {
+		return PullUpdateResult{}, err
+	}
+	requirements, err := profileHardwareRequirements(pkgHandler, instance.Profile)
 	if err != nil {
 		return PullUpdateResult{}, err
 	}
 
 	return PullUpdateResult{
-		Name:          instance.Name,
-		Tag:           instance.Tag,
-		Url:           instance.URL,
-		Profile:       instance.Profile,
-		HasPlugin:     instance.Plugin != nil,
-		OldVersion:    instance.Version,
-		NewVersion:    newVersion,
-		OldCommit:     instance.Commit,
-		NewCommit:     newCommit,
-		OldOptions:    optionsOld,
-		NewOptions:    optionsNew,
-		MergedOptions: mergedOptions,
+		Name:                 instance.Name,
+		Tag:                  instance.Tag,
+		Url:                  instance.URL,
+		Profile:              instance.Profile,
+		HasPlugin:            plugin != nil,
+		PluginImage:          pluginImage(plugin),
+		HardwareRequirements: requirements,
+		OldVersion:           instance.Version,
+		OldSpecVersion:       instance.SpecVersion,
+		NewSpecVersion:       newSpecVersion,
+		NewVersion:           newVersion,
+		OldCommit:            instance.Commit,
+		NewCommit:            newCommit,
+		OldOptions:           optionsOld,
+		NewOptions:           optionsNew,
+		MergedOptions:        mergedOptions,
+		OptionChanges:        optionChanges,
+		IncompatibleData:     !dataCompatible,
+		Migrations:           migrations,
 	}, nil
 }
 
@@ -494,6 +662,15 @@ func (d *EgnDaemon) LocalPullUpdate(instanceID string, pkgTar io.Reader) (PullUp
 	// done. In this case we can not do that check because is a local update
 	// designed for development purposes.
 
+	dataCompatible, migrations, err := checkUpgrade(pkgHandler, instance.Version)
+	if err != nil {
+		return PullUpdateResult{}, err
+	}
+	newSpecVersion, err := packageSpecVersion(pkgHandler)
+	if err != nil {
+		return PullUpdateResult{}, err
+	}
+
 	// Get new options
 	profileNew, err := pkgHandler.Profile(instance.Profile)
 	if err != nil {
@@ -516,87 +693,253 @@ func (d *EgnDaemon) LocalPullUpdate(instanceID string, pkgTar io.Reader) (PullUp
 	if err != nil {
 		return PullUpdateResult{}, err
 	}
-	for _, o := range optionsOld {
-		if v, ok := valuesOld[o.Target()]; ok {
-			err := o.Set(v)
-			if err != nil {
-				return PullUpdateResult{}, fmt.Errorf("error setting old option value: %v", err)
-			}
-		} else {
-			return PullUpdateResult{}, fmt.Errorf("%w: old option %s", ErrOptionWithoutValue, o.Name())
-		}
+	secretsOld, err := d.dataDir.Secrets().Get(instance.ID())
+	if err != nil {
+		return PullUpdateResult{}, err
+	}
+	maps.Copy(valuesOld, secretsOld)
+	if err := setOldOptionValues(optionsOld, valuesOld); err != nil {
+		return PullUpdateResult{}, err
 	}
 
-	mergedOptions, err := mergeOptions(optionsOld, optionsNew)
+	mergedOptions, optionChanges, err := mergeOptions(optionsOld, optionsNew)
+	if err != nil {
+		return PullUpdateResult{}, err
+	}
+
+	// Get new plugin and hardware requirements
+	plugin, err := d.getPluginData(pkgHandler, instance.Profile)
+	if err != nil {
+		return PullUpdateResult{}, err
+	}
+	requirements, err := profileHardwareRequirements(pkgHandler, instance.Profile)
 	if err != nil {
 		return PullUpdateResult{}, err
 	}
 
 	return PullUpdateResult{
-		Name:          instance.Name,
-		Tag:           instance.Tag,
-		Url:           instance.URL,
-		Profile:       instance.Profile,
-		HasPlugin:     instance.Plugin != nil,
-		OldVersion:    instance.Version,
-		NewVersion:    "local",
-		OldCommit:     instance.Commit,
-		NewCommit:     "local",
-		OldOptions:    optionsOld,
-		NewOptions:    optionsNew,
-		MergedOptions: mergedOptions,
+		Name:                 instance.Name,
+		Tag:                  instance.Tag,
+		Url:                  instance.URL,
+		Profile:              instance.Profile,
+		HasPlugin:            plugin != nil,
+		PluginImage:          pluginImage(plugin),
+		HardwareRequirements: requirements,
+		OldVersion:           instance.Version,
+		OldSpecVersion:       instance.SpecVersion,
+		NewSpecVersion:       newSpecVersion,
+		NewVersion:           "local",
+		OldCommit:            instance.Commit,
+		NewCommit:            "local",
+		OldOptions:           optionsOld,
+		NewOptions:           optionsNew,
+		MergedOptions:        mergedOptions,
+		OptionChanges:        optionChanges,
+		IncompatibleData:     !dataCompatible,
+		Migrations:           migrations,
 	}, nil
 }
 
+// checkUpgrade checks that the package supports being installed as an update of
+// the given version, and returns whether the data of the old version can be
+// reused and the migrations to run. If the old version is not a semantic version,
+// the version ranges of the package can not be checked and only a warning is
+// logged.
+func checkUpgrade(pkgHandler *package_handler.PackageHandler, oldVersion string) (bool, []Migration, error) {
+	upgrade, err := pkgHandler.Upgrade()
+	if err != nil {
+		return false, nil, err
+	}
+	if semver.IsValid(oldVersion) {
+		ok, err := upgrade.SupportsUpgradeFrom(oldVersion)
+		if err != nil {
+			return false, nil, err
+		}
+		if !ok {
+			return false, nil, fmt.Errorf("%w: upgrading from version %s is not supported by the new package, supported versions: %s", ErrIncompatibleUpgrade, oldVersion, strings.Join(upgrade.From, ", "))
+		}
+	} else if len(upgrade.From) > 0 {
+		log.Warnf("Current version %s is not a valid semantic version, skipping upgrade compatibility check", oldVersion)
+	}
+	pkgMigrations, err := upgrade.MigrationsFrom(oldVersion)
+	if err != nil {
+		return false, nil, err
+	}
+	migrations := make([]Migration, 0, len(pkgMigrations))
+	for _, m := range pkgMigrations {
+		migrations = append(migrations, Migration{
+			Image: m.Image,
+			Args:  m.Args,
+		})
+	}
+	return upgrade.DataCompatible(), migrations, nil
+}
+
 // mergeOptions merges the old options with the new ones following the next rules:
 //
 //  1. New option is not present in the old options: New option is added to the
 //     merged options without a value.
 //
-// 2. New option is present in the old options:
+// 2. New option is present in the old options, with the same name or with one
+// of its previous names (renamed_from):
 //
-//	2.1: Old option value is valid for the new option: the new option is added
-//	     to the merged options using the old option value.
+//	2.1: Old option value, translated with the value map of the new option if
+//	     any, is valid for the new option: the new option is added to the
+//	     merged options using that value.
 //
 //	2.2: Old option value is not valid for the new option: the new option is
 //	     added to the merged options without a value and probably the user
 //	     will need to fill it again or use its default value.
-func mergeOptions(oldOptions, newOptions []Option) ([]Option, error) {
+//
+// It also returns a report of how each option value was carried over, including
+// the old options that were removed.
+func mergeOptions(oldOptions, newOptions []Option) ([]Option, []OptionChange, error) {
 	// mergedOptions will contain the result of merging the new options with the
 	// old ones
 	var mergedOptions []Option
+	var changes []OptionChange
+	merged := make(map[string]bool, len(oldOptions))
+	oldValues := optionValues(oldOptions)
 	for _, oNew := range newOptions {
-		var oOld Option
-		for _, o := range oldOptions {
-			// XXX: Do we need to check the option equality with the Name or the Target?
-			if o.Name() == oNew.Name() {
-				oOld = o
-				break
-			}
-		}
+		oOld, renamed := findOldOption(oldOptions, oNew)
+		mergedOptions = append(mergedOptions, oNew)
 		if oOld == nil {
 			// Option does not exist previously
-			mergedOptions = append(mergedOptions, oNew)
+			changes = append(changes, OptionChange{Name: oNew.Name(), Kind: OptionNew})
+			continue
+		}
+		// Option exists previously. Try to set the old value
+		merged[oOld.Name()] = true
+		if !oOld.IsSet() && (isSecret(oOld) || !oOld.Active(oldValues)) {
+			// Secret that was never stored or inactive option without value,
+			// it is handled as a new option.
+			changes = append(changes, OptionChange{Name: oNew.Name(), Kind: OptionNew})
+			continue
+		}
+		oldValue, err := oOld.Value()
+		if err != nil {
+			// Old option is expected to have a value but it does not.
+			return mergedOptions, changes, err
+		}
+		change := OptionChange{
+			Name:     oNew.Name(),
+			Kind:     OptionKept,
+			OldValue: displayValue(oOld, oldValue),
+		}
+		if renamed {
+			change.Kind = OptionRenamed
+			change.OldName = oOld.Name()
+		}
+		newValue := migrateValue(oNew, oldValue)
+		err = oNew.Set(newValue)
+		if err != nil {
+			// Old value is not valid for same option in the new version. This
+			// option should be filled by the user again.
+			log.Debugf("Option %s value %s is not valid for the new version. error: %s", oOld.Name(), change.OldValue, err.Error())
+			change.Kind = OptionReset
+			change.Reason = err.Error()
 		} else {
-			// Option exists previously. Try to set the old value
-			oldValue, err := oOld.Value()
-			if err != nil {
-				// Old option is expected to have a value but it does not.
-				return mergedOptions, err
+			// Old value is valid for the new version and we can use it.
+			log.Debugf("Option %s value %s is valid for the new version", oOld.Name(), change.OldValue)
+		}
+		changes = append(changes, change)
+	}
+	// Options that are not set and are inactive with the merged values are not
+	// asked, so they take their default value when it is valid.
+	values := optionValues(mergedOptions)
+	for _, o := range mergedOptions {
+		if o.IsSet() || o.Active(values) || o.Default() == "" || IsDefaultTemplate(o) {
+			// Template defaults are rendered on install
+			continue
+		}
+		if err := o.Set(o.Default()); err != nil {
+			log.Debugf("Default value of inactive option %s is not valid. error: %s", o.Name(), err.Error())
+		}
+	}
+	for i, o := range mergedOptions {
+		if v, err := o.Value(); err == nil {
+			changes[i].NewValue = displayValue(o, v)
+		}
+	}
+	for _, oOld := range oldOptions {
+		if merged[oOld.Name()] {
+			continue
+		}
+		change := OptionChange{Name: oOld.Name(), Kind: OptionRemoved}
+		if v, err := oOld.Value(); err == nil {
+			change.OldValue = displayValue(oOld, v)
+		}
+		changes = append(changes, change)
+	}
+	return mergedOptions, changes, nil
+}
+
+// setOldOptionValues sets the values of the options of the installed version
+// of an instance. Secret options without a stored value and inactive options
+// with an empty value are left unset, as they were never provided.
+func setOldOptionValues(options []Option, values map[string]string) error {
+	optionValues := make(map[string]string, len(options))
+	for _, o := range options {
+		v, ok := values[o.Target()]
+		if !ok {
+			if isSecret(o) {
+				optionValues[o.Name()] = ""
+				continue
 			}
-			err = oNew.Set(oldValue)
-			if err != nil {
-				// Old value is not valid for same option in the new version. This
-				// option should be filled by the user again.
-				log.Debugf("Option %s value %s is not valid for the new version. error: %s", oOld.Name(), oldValue, err.Error())
-			} else {
-				// Old value is valid for the new version and we can use it.
-				log.Debugf("Option %s value %s is valid for the new version", oOld.Name(), oldValue)
+			return fmt.Errorf("%w: old option %s", ErrOptionWithoutValue, o.Name())
+		}
+		if v == "" && !o.Active(optionValues) {
+			optionValues[o.Name()] = o.Default()
+			continue
+		}
+		if err := o.Set(v); err != nil {
+			return fmt.Errorf("error setting old option value: %v", err)
+		}
+		optionValues[o.Name()] = v
+	}
+	return nil
+}
+
+// findOldOption returns the old option that matches the new option by name or,
+// if there is none, by one of the previous names of the new option. The second
+// result is true if the option was renamed.
+func findOldOption(oldOptions []Option, oNew Option) (Option, bool) {
+	for _, o := range oldOptions {
+		if o.Name() == oNew.Name() {
+			return o, false
+		}
+	}
+	base, ok := oNew.(optionBase)
+	if !ok {
+		return nil, false
+	}
+	for _, oldName := range base.base().renamedFrom {
+		for _, o := range oldOptions {
+			if o.Name() == oldName {
+				return o, true
 			}
-			mergedOptions = append(mergedOptions, oNew)
 		}
 	}
-	return mergedOptions, nil
+	return nil, false
+}
+
+// migrateValue maps an old value of the option with its value map, if any.
+func migrateValue(o Option, oldValue string) string {
+	if base, ok := o.(optionBase); ok {
+		if v, ok := base.base().valueMap[oldValue]; ok {
+			return v
+		}
+	}
+	return oldValue
+}
+
+// displayValue returns the value of the option to show in logs and reports,
+// redacting hidden values like secrets.
+func displayValue(o Option, value string) string {
+	if o.Hidden() && value != "" {
+		return "<redacted>"
+	}
+	return value
 }
 
 func (d *EgnDaemon) pullPackage(url string, force bool) (*package_handler.PackageHandler, error) {
@@ -629,19 +972,47 @@ func (d *EgnDaemon) LocalInstall(pkgTar io.Reader, options LocalInstallOptions)
 }
 
 func (d *EgnDaemon) localInstall(pkgTar io.Reader, options LocalInstallOptions) (string, string, error) {
+	// Read package tarball and compute its digest
+	content, err := io.ReadAll(pkgTar)
+	if err != nil {
+		return "", "", err
+	}
+	sum := sha256.Sum256(content)
+	provenance := &data.Provenance{
+		Digest: "sha256:" + hex.EncodeToString(sum[:]),
+	}
+	if options.Secure {
+		provenance.SignedBy, err = verifySignature(content, options.Signature, options.TrustedKeys)
+		if err != nil {
+			return "", "", err
+		}
+		log.Infof("Package signature verified with key %s", provenance.SignedBy)
+	}
+
 	// Decompress package to temp folder
-	tID := tempID(options.Name)
+	tID := tempID(provenance.Digest)
 	tempPath, err := d.dataDir.InitTemp(tID)
 	if err != nil {
 		return "", tID, err
 	}
-	err = utils.DecompressTarGz(pkgTar, tempPath)
+	err = utils.DecompressTarGz(bytes.NewReader(content), tempPath)
 	if err != nil {
 		return "", tID, err
 	}
 
 	// Init package handler from temp path
 	pkgHandler := package_handler.NewPackageHandler(tempPath)
+	if options.Secure {
+		if err := pkgHandler.Check(); err != nil {
+			return "", tID, err
+		}
+	}
+	if options.Name == "" {
+		options.Name, err = pkgHandler.Name()
+		if err != nil {
+			return "", tID, err
+		}
+	}
 
 	// Get Instance ID
 	instanceID := data.InstanceId(options.Name, options.Tag)
@@ -651,7 +1022,7 @@ func (d *EgnDaemon) localInstall(pkgTar io.Reader, options LocalInstallOptions)
 	}
 
 	// Get Spec version
-	specVersion, err := pkgHandler.SpecVersion()
+	specVersion, err := packageSpecVersion(pkgHandler)
 	if err != nil {
 		return instanceID, tID, err
 	}
@@ -676,36 +1047,21 @@ func (d *EgnDaemon) localInstall(pkgTar io.Reader, options LocalInstallOptions)
 	if err != nil {
 		return instanceID, tID, err
 	}
-	// Build profile options
-	profileOptions := make(map[string]Option, len(selectedProfile.Options))
-	for _, o := range selectedProfile.Options {
-		switch o.Type {
-		case "str":
-			profileOptions[o.Name] = NewOptionString(o)
-		case "int":
-			profileOptions[o.Name], err = NewOptionInt(o)
-		case "float":
-			profileOptions[o.Name], err = NewOptionFloat(o)
-		case "bool":
-			profileOptions[o.Name], err = NewOptionBool(o)
-		case "path_dir":
-			profileOptions[o.Name] = NewOptionPathDir(o)
-		case "path_file":
-			profileOptions[o.Name] = NewOptionPathFile(o)
-		case "uri":
-			profileOptions[o.Name] = NewOptionURI(o)
-		case "select":
-			profileOptions[o.Name] = NewOptionSelect(o)
-		case "port":
-			profileOptions[o.Name], err = NewOptionPort(o)
-		default:
-			err = errors.New("unknown option type: " + o.Type)
+	if options.Secure {
+		if err := d.checkLocalHardwareRequirements(pkgHandler, selectedProfile.Name); err != nil {
 			return instanceID, tID, err
 		}
 	}
+	// Build profile options
+	profileOptions, err := optionsFromProfile(selectedProfile)
 	if err != nil {
 		return instanceID, tID, err
 	}
+	for name := range options.Options {
+		if !slices.ContainsFunc(profileOptions, func(o Option) bool { return o.Name() == name }) {
+			return instanceID, tID, fmt.Errorf("%w: %s", ErrUnknownOption, name)
+		}
+	}
 
 	// Build environment variables
 	env, err := pkgHandler.DotEnv(selectedProfile.Name)
@@ -713,20 +1069,51 @@ func (d *EgnDaemon) localInstall(pkgTar io.Reader, options LocalInstallOptions)
 		return instanceID, tID, err
 	}
 	optionsEnv := make(map[string]string, len(options.Options))
+	var secrets map[string]string
+	values := make(map[string]string, len(profileOptions))
+	tdata := d.defaultTemplateData(options.Name, options.Tag, selectedProfile.Name)
 	for _, o := range profileOptions {
-		if v, ok := options.Options[o.Name()]; ok {
+		if _, ok := options.Options[o.Name()]; !ok && IsDefaultTemplate(o) {
+			if err := setDefaultTemplate(o, tdata, profileOptions, values); err != nil {
+				return instanceID, tID, err
+			}
+			v, _ := o.Value()
+			values[o.Name()] = v
+			optionsEnv[o.Target()] = v
+		} else if v, ok := options.Options[o.Name()]; ok {
 			err := o.Set(v)
 			if err != nil {
 				return instanceID, tID, err
 			}
-			optionsEnv[o.Target()] = v
+			values[o.Name()] = v
+			if isSecret(o) {
+				if secrets == nil {
+					secrets = make(map[string]string)
+				}
+				secrets[o.Target()] = v
+			} else {
+				optionsEnv[o.Target()] = v
+			}
+		} else if !o.Active(values) {
+			// Inactive options are not required and keep their default value,
+			// if any
+			values[o.Name()] = o.Default()
+			if !isSecret(o) && o.Default() != "" {
+				optionsEnv[o.Target()] = o.Default()
+			}
 		} else if o.Default() != "" && !o.Hidden() {
+			values[o.Name()] = o.Default()
 			optionsEnv[o.Target()] = o.Default()
+		} else if options.Secure {
+			return instanceID, tID, fmt.Errorf("%w: %s", ErrOptionNotSet, o.Name())
 		} else {
 			log.Warn("Option ", o.Name(), " does not have a default value. Using empty string as value.")
 			optionsEnv[o.Target()] = "\"\""
 		}
 	}
+	if err := selectedProfile.CheckOneOf(values); err != nil {
+		return instanceID, tID, err
+	}
 	maps.Copy(env, optionsEnv)
 
 	installOptions := InstallOptions{
@@ -737,7 +1124,37 @@ func (d *EgnDaemon) localInstall(pkgTar io.Reader, options LocalInstallOptions)
 		SpecVersion: specVersion,
 		Commit:      "local",
 	}
-	return d.install(options.Name, instanceID, tID, pkgHandler, selectedProfile, env, installOptions)
+	return d.install(options.Name, instanceID, tID, pkgHandler, selectedProfile, env, secrets, installOptions, provenance)
+}
+
+// checkLocalHardwareRequirements checks the hardware requirements of the given
+// profile, returning an error only if the requirements are not met and the
+// profile requires stopping the installation.
+func (d *EgnDaemon) checkLocalHardwareRequirements(pkgHandler *package_handler.PackageHandler, profileName string) error {
+	req, err := pkgHandler.HardwareRequirements(profileName)
+	if err != nil {
+		return err
+	}
+	requirements := HardwareRequirements{
+		MinCPUCores:                 req.MinCPUCores,
+		MinRAM:                      req.MinRAM,
+		MinFreeSpace:                req.MinFreeSpace,
+		StopIfRequirementsAreNotMet: req.StopIfRequirementsAreNotMet,
+	}
+	ok, err := d.CheckHardwareRequirements(requirements)
+	if err != nil {
+		return err
+	}
+	if ok {
+		log.Infof("Profile %s meets the hardware requirements", profileName)
+		return nil
+	}
+	log.Printf("Hardware requirements: %s", requirements)
+	if requirements.StopIfRequirementsAreNotMet {
+		return fmt.Errorf("%w: profile %s", ErrHardwareRequirementsNotMet, profileName)
+	}
+	log.Warnf("Profile %s does not meet the hardware requirements", profileName)
+	return nil
 }
 
 func (d *EgnDaemon) remoteInstall(options InstallOptions) (string, string, error) {
@@ -773,6 +1190,13 @@ func (d *EgnDaemon) remoteInstall(options InstallOptions) (string, string, error
 		return instanceID, tID, fmt.Errorf("%w: %s", ErrVersionOrCommitNotSet, options.URL)
 	}
 
+	// The spec version is negotiated again from the checked out package, because
+	// the one in the options could be missing or outdated.
+	options.SpecVersion, err = packageSpecVersion(pkgHandler)
+	if err != nil {
+		return instanceID, tID, err
+	}
+
 	pkgProfiles, err := pkgHandler.Profiles()
 	if err != nil {
 		return instanceID, tID, err
@@ -795,16 +1219,43 @@ func (d *EgnDaemon) remoteInstall(options InstallOptions) (string, string, error
 		return instanceID, tID, err
 	}
 	optionsEnv := make(map[string]string, len(options.Options))
+	var secrets map[string]string
+	values := make(map[string]string, len(options.Options))
+	tdata := d.defaultTemplateData(options.Name, options.Tag, selectedProfile.Name)
 	for _, o := range options.Options {
+		if !o.IsSet() && IsDefaultTemplate(o) {
+			if err := setDefaultTemplate(o, tdata, options.Options, values); err != nil {
+				return instanceID, tID, err
+			}
+		}
+		if !o.IsSet() && !o.Active(values) {
+			// Inactive options are not asked and keep their default value, if any
+			values[o.Name()] = o.Default()
+			if !isSecret(o) && o.Default() != "" {
+				env[o.Target()] = o.Default()
+			}
+			continue
+		}
 		oValue, err := o.Value()
 		if err != nil {
 			return instanceID, tID, err
 		}
+		values[o.Name()] = oValue
+		if isSecret(o) {
+			if secrets == nil {
+				secrets = make(map[string]string)
+			}
+			secrets[o.Target()] = oValue
+			continue
+		}
 		env[o.Target()] = oValue
 	}
+	if err := selectedProfile.CheckOneOf(values); err != nil {
+		return instanceID, tID, err
+	}
 	maps.Copy(env, optionsEnv)
 
-	return d.install(options.Name, instanceID, tID, pkgHandler, selectedProfile, env, options)
+	return d.install(options.Name, instanceID, tID, pkgHandler, selectedProfile, env, secrets, options, nil)
 }
 
 func (d *EgnDaemon) install(
@@ -812,9 +1263,15 @@ func (d *EgnDaemon) install(
 	pkgHandler *package_handler.PackageHandler,
 	selectedProfile *profile.Profile,
 	env map[string]string,
+	secrets map[string]string,
 	options InstallOptions,
+	provenance *data.Provenance,
 ) (string, string, error) {
-	err := pkgHandler.CheckComposeProject(selectedProfile.Name, env)
+	// Secrets are checked with the rest of the environment, but they are not
+	// written to the .env file of the instance.
+	projectEnv := maps.Clone(env)
+	maps.Copy(projectEnv, secrets)
+	err := pkgHandler.CheckComposeProject(selectedProfile.Name, projectEnv)
 	if err != nil {
 		return instanceID, tID, err
 	}
@@ -826,19 +1283,38 @@ func (d *EgnDaemon) install(
 			return "", tID, ErrMonitoringTargetPortNotSet
 		}
 		mt := data.MonitoringTarget{
-			Service: target.Service,
-			Port:    strconv.Itoa(*target.Port),
-			Path:    target.Path,
+			Service:       target.Service,
+			Port:          strconv.Itoa(*target.Port),
+			Path:          target.Path,
+			ScrapeOptions: scrapeOptions(target),
 		}
 		monitoringTargets = append(monitoringTargets, mt)
 	}
 
 	// Build plugin info
-	plugin, err := d.getPluginData(d.dataDir, pkgHandler, instanceID)
+	plugin, err := d.getPluginData(pkgHandler, selectedProfile.Name)
 	if err != nil {
 		return instanceID, tID, err
 	}
 
+	// Build health checks info
+	var healthChecks []data.HealthCheck
+	for _, h := range selectedProfile.HealthChecks {
+		check := data.HealthCheck{
+			Name:           h.Name,
+			Service:        h.Service,
+			Type:           h.Type,
+			Path:           h.Path,
+			ExpectedStatus: h.ExpectedStatus,
+			Command:        h.Command,
+			Timeout:        h.Timeout,
+		}
+		if h.Port != 0 {
+			check.Port = strconv.Itoa(h.Port)
+		}
+		healthChecks = append(healthChecks, check)
+	}
+
 	// Build API target info
 	var apiTarget *data.APITarget
 	if selectedProfile.API != nil {
@@ -860,6 +1336,8 @@ func (d *EgnDaemon) install(
 		MonitoringTargets: data.MonitoringTargets{Targets: monitoringTargets},
 		APITarget:         apiTarget,
 		Plugin:            plugin,
+		Provenance:        provenance,
+		HealthChecks:      healthChecks,
 	}
 	if err = d.dataDir.InitInstance(&instance); err != nil {
 		return instanceID, tID, err
@@ -868,12 +1346,22 @@ func (d *EgnDaemon) install(
 	if err = instance.Setup(env, pkgHandler.ProfilePath(instance.Profile)); err != nil {
 		return instanceID, tID, err
 	}
+	if selectedProfile.Extends != "" {
+		// The base profiles are not copied to the instance
+		if err = instance.SetProfileFile(selectedProfile); err != nil {
+			return instanceID, tID, err
+		}
+	}
+	if err = d.dataDir.Secrets().Set(instanceID, secrets); err != nil {
+		return instanceID, tID, err
+	}
 
 	// Create containers
 	// TODO: Log Create output and log to wait as containers might be built
 	if err = d.dockerCompose.Create(compose.DockerComposeCreateOptions{
 		Path:  instance.ComposePath(),
 		Build: true,
+		Env:   secrets,
 	}); err != nil {
 		return instanceID, tID, err
 	}
@@ -881,15 +1369,13 @@ func (d *EgnDaemon) install(
 	return instanceID, tID, nil
 }
 
-func (d *EgnDaemon) getPluginData(dataDir *data.DataDir, pkgHandler *package_handler.PackageHandler, instanceID string) (*data.Plugin, error) {
-	hasPlugin, err := pkgHandler.HasPlugin()
-	if err != nil {
-		return nil, err
-	}
-	if !hasPlugin {
+// getPluginData returns the plugin of the given profile, with the plugin
+// overrides of the profile applied, or nil if the profile has no plugin.
+func (d *EgnDaemon) getPluginData(pkgHandler *package_handler.PackageHandler, profileName string) (*data.Plugin, error) {
+	pkgPlugin, err := pkgHandler.ProfilePlugin(profileName)
+	if errors.Is(err, package_handler.ErrNoPlugin) {
 		return nil, nil
 	}
-	pkgPlugin, err := pkgHandler.Plugin()
 	if err != nil {
 		return nil, err
 	}
@@ -898,10 +1384,34 @@ func (d *EgnDaemon) getPluginData(dataDir *data.DataDir, pkgHandler *package_han
 	}, nil
 }
 
+// pluginImage returns the image of the given plugin, or an empty string if
+// the plugin is nil.
+func pluginImage(plugin *data.Plugin) string {
+	if plugin == nil {
+		return ""
+	}
+	return plugin.Image
+}
+
+// profileHardwareRequirements returns the hardware requirements of the given
+// profile, with the hardware requirements overrides of the profile applied.
+func profileHardwareRequirements(pkgHandler *package_handler.PackageHandler, profileName string) (HardwareRequirements, error) {
+	req, err := pkgHandler.HardwareRequirements(profileName)
+	if err != nil {
+		return HardwareRequirements{}, err
+	}
+	return HardwareRequirements{
+		MinCPUCores:                 req.MinCPUCores,
+		MinRAM:                      req.MinRAM,
+		MinFreeSpace:                req.MinFreeSpace,
+		StopIfRequirementsAreNotMet: req.StopIfRequirementsAreNotMet,
+	}, nil
+}
+
 func (d *EgnDaemon) postInstallation(instanceId string, tempDirID string, installErr error) error {
 	if installErr != nil && !errors.Is(installErr, ErrInstanceAlreadyExists) {
 		// Cleanup if Install fails
-		if cerr := d.uninstall(instanceId, false); cerr != nil {
+		if cerr := d.uninstall(instanceId, false, false); cerr != nil {
 			return fmt.Errorf("install failed: %w. Failed to cleanup after installation failure: %w", installErr, cerr)
 		}
 	}
@@ -928,8 +1438,14 @@ func (d *EgnDaemon) Run(instanceID string) error {
 		return err
 	}
 	composePath := path.Join(instancePath, "docker-compose.yml")
+	// Secrets are injected at compose up time because they are not in the .env file
+	secrets, err := d.dataDir.Secrets().Get(instanceID)
+	if err != nil {
+		return err
+	}
 	if err := d.dockerCompose.Up(compose.DockerComposeUpOptions{
 		Path: composePath,
+		Env:  secrets,
 	}); err != nil {
 		return err
 	}
@@ -950,11 +1466,14 @@ func (d *EgnDaemon) Stop(instanceID string) error {
 }
 
 // Uninstall implements Daemon.Uninstall.
-func (d *EgnDaemon) Uninstall(instanceID string) error {
-	return d.uninstall(instanceID, true)
+func (d *EgnDaemon) Uninstall(instanceID string, options UninstallOptions) error {
+	return d.uninstall(instanceID, true, !options.KeepVolumes)
 }
 
-func (d *EgnDaemon) uninstall(instanceID string, down bool) error {
+// uninstall removes the instance with the given ID. If down is true, the
+// compose project of the instance is brought down, removing its volumes too if
+// volumes is true.
+func (d *EgnDaemon) uninstall(instanceID string, down, volumes bool) error {
 	instancePath, err := d.dataDir.InstancePath(instanceID)
 	if err != nil {
 		if errors.Is(err, data.ErrInstanceNotFound) {
@@ -981,14 +1500,17 @@ func (d *EgnDaemon) uninstall(instanceID string, down bool) error {
 		// docker compose down
 		if err = d.dockerCompose.Down(compose.DockerComposeDownOptions{
 			Path:    composePath,
-			Volumes: true,
+			Volumes: volumes,
 		}); err != nil {
 			return err
 		}
 	}
 
 	// remove instance directory
-	return d.dataDir.RemoveInstance(instanceID)
+	if err := d.dataDir.RemoveInstance(instanceID); err != nil {
+		return err
+	}
+	return d.dataDir.Secrets().Delete(instanceID)
 }
 
 // CheckHardwareRequirements implements Daemon.CheckHardwareRequirements
@@ -1085,13 +1607,54 @@ func (d *EgnDaemon) RunPlugin(instanceId string, pluginArgs []string, options Ru
 			Target: dst,
 		})
 	}
-	return d.docker.Run(instance.Plugin.Image, docker.RunOptions{
+	return d.docker.Run(context.Background(), instance.Plugin.Image, docker.RunOptions{
 		Network: network,
 		Args:    pluginArgs,
 		Mounts:  mounts,
 	})
 }
 
+// RunMigrations implements Daemon.RunMigrations.
+func (d *EgnDaemon) RunMigrations(instanceId string, migrations []Migration) error {
+	instance, err := d.dataDir.Instance(instanceId)
+	if err != nil {
+		return err
+	}
+	psServices, err := d.dockerCompose.PS(compose.DockerComposePsOptions{
+		Path:   instance.ComposePath(),
+		Format: "json",
+		All:    true,
+	})
+	if err != nil {
+		return err
+	}
+	containers := make([]string, 0, len(psServices))
+	for _, ps := range psServices {
+		containers = append(containers, ps.Id)
+	}
+	for _, m := range migrations {
+		ok, err := d.docker.ImageExist(m.Image)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			if err := d.docker.Pull(m.Image); err != nil {
+				return err
+			}
+		}
+		log.Infof("Running migration with image %s", m.Image)
+		err = d.docker.Run(context.Background(), m.Image, docker.RunOptions{
+			Args:        m.Args,
+			VolumesFrom: containers,
+			AutoRemove:  true,
+		})
+		if err != nil {
+			return fmt.Errorf("migration %s failed: %w", m.Image, err)
+		}
+	}
+	return nil
+}
+
 // NodeLogs implements Daemon.NodeLogs.
 func (d *EgnDaemon) NodeLogs(ctx context.Context, w io.Writer, instanceID string, opts NodeLogsOptions) error {
 	i, err := d.dataDir.Instance(instanceID)
@@ -1120,48 +1683,135 @@ func (d *EgnDaemon) NodeLogs(ctx context.Context, w io.Writer, instanceID string
 	})
 }
 
-func (d *EgnDaemon) Backup(instanceId string) (string, error) {
+func (d *EgnDaemon) Backup(ctx context.Context, instanceId string, options BackupOptions) (string, error) {
 	if !d.HasInstance(instanceId) {
 		return "", fmt.Errorf("%w: %s", ErrInstanceNotFound, instanceId)
 	}
-	log.Infof("Stopping instance %s", instanceId)
-	err := d.Stop(instanceId)
+	backupId, err := d.backupManager.BackupInstance(ctx, instanceId, backup.BackupOptions{
+		Compression: options.Compression,
+		Passphrase:  options.Passphrase,
+		Consistency: options.Consistency,
+		Volumes:     data.VolumeFilter{Include: options.Include, Exclude: options.Exclude},
+		ConfigOnly:  options.ConfigOnly,
+		Scheduled:   options.Scheduled,
+		Progress:    backupProgress(options.Progress),
+	})
 	if err != nil {
 		return "", err
 	}
-	return d.backupManager.BackupInstance(instanceId)
+	if options.Consistency != data.BackupConsistencyPause && !options.ConfigOnly {
+		// Restarted containers may have new IPs, so the monitoring targets
+		// of the instance are replaced, as adding a target that already
+		// exists does not update it. The backup is already created, so
+		// errors are only logged.
+		running, err := d.instanceRunning(instanceId)
+		if err == nil && running {
+			err = d.removeTarget(instanceId)
+		}
+		if err == nil && running {
+			err = d.addTarget(instanceId)
+		}
+		if err != nil {
+			log.Warnf("Failed to update the monitoring targets of instance %s: %v", instanceId, err)
+		}
+	}
+	return backupId, nil
 }
 
-func (d *EgnDaemon) Restore(backupId string, run bool) error {
+func (d *EgnDaemon) Restore(ctx context.Context, backupId string, options RestoreOptions) (err error) {
 	// Check if the backup exists
 	ok, err := d.dataDir.HasBackup(backupId)
 	if err != nil {
 		return err
 	}
-	if !ok {
+	if !ok && options.From != "" {
+		if err := d.BackupPull(backupId, options.From); err != nil {
+			return err
+		}
+	} else if !ok {
 		return fmt.Errorf("%w: %s", ErrBackupNotFound, backupId)
 	}
 	// Get backup information
-	backup, err := d.dataDir.Backup(backupId)
+	b, err := d.dataDir.Backup(backupId)
 	if err != nil {
 		return err
 	}
-	// Check if the instance exists
-	if d.dataDir.HasInstance(backup.InstanceId) {
-		log.Infof("Instance %s already exists. Uninstalling it", backup.InstanceId)
-		err = d.Uninstall(backup.InstanceId)
+	// Check the passphrase before uninstalling the current instance
+	if err := checkBackupPassphrase(b, options.Passphrase); err != nil {
+		return err
+	}
+	// Check the volumes to restore before uninstalling the current instance
+	filter := data.VolumeFilter{Include: options.Include, Exclude: options.Exclude}
+	if err := filter.Validate(); err != nil {
+		return err
+	}
+	if b.Selection != nil {
+		if err := filter.CheckIncluded(b.Selection.Volumes); err != nil {
+			return err
+		}
+	}
+	// Volumes not restored from a partial backup, or not selected to restore,
+	// are kept from the current instance.
+	partial := b.Selection != nil || options.ConfigOnly || !filter.IsZero()
+	// Check if the instance exists. Backups restored with a new tag do not
+	// replace the instance of the backup.
+	if options.Tag == "" && d.dataDir.HasInstance(b.InstanceId) {
+		// Secrets are not included in backups, so they are kept from the current
+		// instance.
+		secrets, err := d.dataDir.Secrets().Get(b.InstanceId)
+		if err != nil {
+			return err
+		}
+		log.Infof("Instance %s already exists. Uninstalling it", b.InstanceId)
+		if partial {
+			log.Info("Volumes not restored are kept")
+		}
+		err = d.uninstall(b.InstanceId, true, !partial)
 		if err != nil {
 			return err
 		}
 		log.Info("Instance uninstalled")
+		if err := d.dataDir.Secrets().Set(b.InstanceId, secrets); err != nil {
+			return err
+		}
 	}
 
-	err = d.backupManager.RestoreInstance(backupId)
-	if err != nil {
+	instanceId, err := d.backupManager.RestoreInstance(ctx, backupId, backup.RestoreOptions{
+		Passphrase: options.Passphrase,
+		Volumes:    filter,
+		ConfigOnly: options.ConfigOnly,
+		Tag:        options.Tag,
+		Secrets:    options.Secrets,
+		Progress:   backupProgress(options.Progress),
+	})
+	if options.Tag != "" && instanceId != "" {
+		// A failed restore with a new tag does not leave a partial instance
+		defer func() {
+			if err == nil {
+				return
+			}
+			log.Infof("Removing partially restored instance %s", instanceId)
+			if rmErr := d.uninstall(instanceId, true, true); rmErr != nil {
+				log.Errorf("Error removing instance %s: %v", instanceId, rmErr)
+			}
+		}()
+	}
+	var missingErr data.MissingSecretsError
+	if errors.Is(err, data.ErrInstanceAlreadyExists) {
+		return fmt.Errorf("%w: restoring backup %s with tag %s", ErrInstanceAlreadyExists, backupId, options.Tag)
+	} else if errors.Is(err, data.ErrSharedResources) {
+		return fmt.Errorf("%w: %s: %w", ErrSharedResources, backupId, err)
+	} else if errors.As(err, &missingErr) {
+		missing, optErr := optionsFromProfile(&profile.Profile{Options: missingErr.Options})
+		if optErr != nil {
+			return optErr
+		}
+		return MissingSecretsError{Options: missing}
+	} else if err != nil {
 		return err
 	}
-	if run {
-		err = d.Run(backup.InstanceId)
+	if options.Run {
+		err = d.Run(instanceId)
 		if err != nil {
 			return err
 		}
@@ -1176,23 +1826,333 @@ func (d *EgnDaemon) BackupList() ([]BackupInfo, error) {
 	}
 	out := make([]BackupInfo, len(backups))
 	for i, b := range backups {
-		size, err := d.dataDir.BackupSize(b.Id())
+		out[i] = backupInfo(b)
+	}
+	return out, nil
+}
+
+// checkBackupPassphrase returns ErrBackupEncrypted or ErrInvalidBackupPassphrase
+// if the given passphrase is empty or wrong for the given backup.
+func checkBackupPassphrase(b *data.Backup, passphrase string) error {
+	err := b.CheckPassphrase(passphrase)
+	if errors.Is(err, data.ErrBackupEncrypted) {
+		return fmt.Errorf("%w: %s", ErrBackupEncrypted, b.Id())
+	} else if errors.Is(err, data.ErrInvalidPassphrase) {
+		return fmt.Errorf("%w: %s", ErrInvalidBackupPassphrase, b.Id())
+	}
+	return err
+}
+
+// BackupVerify implements Daemon.BackupVerify.
+func (d *EgnDaemon) BackupVerify(backupId string, options BackupVerifyOptions) (BackupVerification, error) {
+	b, err := d.dataDir.Backup(backupId)
+	if errors.Is(err, data.ErrBackupNotFound) {
+		return BackupVerification{}, fmt.Errorf("%w: %s", ErrBackupNotFound, backupId)
+	} else if err != nil {
+		return BackupVerification{}, err
+	}
+	if err := checkBackupPassphrase(b, options.Passphrase); err != nil {
+		return BackupVerification{}, err
+	}
+	v, err := d.dataDir.VerifyBackup(backupId, options.Passphrase)
+	if errors.Is(err, data.ErrCorruptedBackup) || errors.Is(err, data.ErrInvalidBackupChunk) {
+		return BackupVerification{}, fmt.Errorf("%w: %s: %w", ErrCorruptedBackup, backupId, err)
+	} else if err != nil {
+		return BackupVerification{}, err
+	}
+	result := BackupVerification{Files: v.Files, Checksums: v.Checksums}
+	if options.Restore {
+		if err := d.trialRestore(b, options.Passphrase); err != nil {
+			return result, fmt.Errorf("%w: %s: %w", ErrTrialRestoreFailed, backupId, err)
+		}
+		result.Restored = true
+	}
+	return result, nil
+}
+
+// trialRestore restores the given backup as a temporary instance, and removes
+// the instance afterwards, even if the restore fails. Backups of instances
+// with resources shared with other instances are not restored, so removing the
+// temporary instance with its volumes never removes the data of the instance
+// of the backup.
+func (d *EgnDaemon) trialRestore(b *data.Backup, passphrase string) (err error) {
+	tag := fmt.Sprintf("verify-%d", time.Now().Unix())
+	log.Infof("Restoring backup %s as a temporary instance with tag %s", b.Id(), tag)
+	instanceId, err := d.backupManager.RestoreInstance(context.Background(), b.Id(), backup.RestoreOptions{
+		Passphrase: passphrase,
+		Tag:        tag,
+	})
+	if errors.Is(err, data.ErrSharedResources) {
+		return fmt.Errorf("%w: %w", ErrSharedResources, err)
+	} else if errors.Is(err, data.ErrMissingSecrets) {
+		// Secrets can not be asked while verifying a backup
+		return fmt.Errorf("%w: %w", ErrMissingSecrets, err)
+	}
+	if instanceId != "" {
+		defer func() {
+			log.Infof("Removing temporary instance %s", instanceId)
+			err = errors.Join(err, d.uninstall(instanceId, true, true))
+		}()
+	}
+	return err
+}
+
+// BackupImport implements Daemon.BackupImport.
+func (d *EgnDaemon) BackupImport(path string) (BackupInfo, error) {
+	b, err := d.dataDir.ImportBackup(path, data.PackOptions{})
+	if errors.Is(err, data.ErrBackupAlreadyExists) {
+		return BackupInfo{}, fmt.Errorf("%w: %s", ErrBackupAlreadyExists, path)
+	} else if err != nil {
+		return BackupInfo{}, err
+	}
+	return backupInfo(*b), nil
+}
+
+func (d *EgnDaemon) BackupExport(backupId, path, passphrase string) error {
+	b, err := d.dataDir.Backup(backupId)
+	if errors.Is(err, data.ErrBackupNotFound) {
+		return fmt.Errorf("%w: %s", ErrBackupNotFound, backupId)
+	} else if err != nil {
+		return err
+	}
+	if err := checkBackupPassphrase(b, passphrase); err != nil {
+		return err
+	}
+	err = d.dataDir.ExportBackup(context.Background(), backupId, passphrase, path)
+	if errors.Is(err, data.ErrCorruptedBackup) || errors.Is(err, data.ErrInvalidBackupChunk) {
+		return fmt.Errorf("%w: %s: %w", ErrCorruptedBackup, backupId, err)
+	}
+	return err
+}
+
+func backupInfo(b data.Backup) BackupInfo {
+	return BackupInfo{
+		Id:              b.Id(),
+		Instance:        b.InstanceId,
+		Timestamp:       b.Timestamp,
+		SizeBytes:       b.Size,
+		StoredSizeBytes: b.StoredSize,
+		Version:         b.Version,
+		Commit:          b.Commit,
+		Url:             b.Url,
+	}
+}
+
+// backupProgress returns a progress function of the backup manager that calls
+// the given function, or nil if it is nil.
+func backupProgress(fn func(BackupProgress)) backup.ProgressFunc {
+	if fn == nil {
+		return nil
+	}
+	return func(p backup.Progress) {
+		fn(BackupProgress{
+			Service:   p.Service,
+			Volume:    p.Volume,
+			Processed: p.Processed,
+			Total:     p.Total,
+		})
+	}
+}
+
+func (d *EgnDaemon) BackupPush(backupId, location string) error {
+	ok, err := d.dataDir.HasBackup(backupId)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrBackupNotFound, backupId)
+	}
+	return d.backupManager.PushBackup(backupId, location)
+}
+
+func (d *EgnDaemon) BackupPull(backupId, location string) error {
+	err := d.backupManager.PullBackup(backupId, location)
+	if errors.Is(err, data.ErrBackupNotFound) {
+		return fmt.Errorf("%w: %s in %s", ErrBackupNotFound, backupId, location)
+	}
+	return err
+}
+
+// SetBackupSchedule implements Daemon.SetBackupSchedule.
+func (d *EgnDaemon) SetBackupSchedule(instanceId string, schedule BackupSchedule) error {
+	if !d.HasInstance(instanceId) {
+		return fmt.Errorf("%w: %s", ErrInstanceNotFound, instanceId)
+	}
+	if schedule.PassphraseFile != "" {
+		if _, err := readBackupPassphraseFile(schedule.PassphraseFile); err != nil {
+			return err
+		}
+	}
+	return d.dataDir.SetBackupSchedule(instanceId, &data.BackupSchedule{
+		Cron:           schedule.Cron,
+		Compression:    schedule.Compression,
+		Consistency:    schedule.Consistency,
+		PassphraseFile: schedule.PassphraseFile,
+		Retention:      data.BackupRetention(schedule.Retention),
+		CreatedAt:      time.Now(),
+	})
+}
+
+// RemoveBackupSchedule implements Daemon.RemoveBackupSchedule.
+func (d *EgnDaemon) RemoveBackupSchedule(instanceId string) error {
+	err := d.dataDir.RemoveBackupSchedule(instanceId)
+	if errors.Is(err, data.ErrBackupScheduleNotFound) {
+		return fmt.Errorf("%w: %s", ErrBackupScheduleNotFound, instanceId)
+	}
+	return err
+}
+
+// BackupSchedules implements Daemon.BackupSchedules.
+func (d *EgnDaemon) BackupSchedules() ([]BackupScheduleInfo, error) {
+	schedules, err := d.dataDir.BackupSchedules()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]BackupScheduleInfo, 0, len(schedules))
+	for instanceId, s := range schedules {
+		next, err := s.Next()
 		if err != nil {
 			return nil, err
 		}
-		out[i] = BackupInfo{
-			Id:        b.Id(),
-			Instance:  b.InstanceId,
-			Timestamp: b.Timestamp,
-			SizeBytes: size,
-			Version:   b.Version,
-			Commit:    b.Commit,
-			Url:       b.Url,
-		}
+		out = append(out, BackupScheduleInfo{
+			InstanceId: instanceId,
+			BackupSchedule: BackupSchedule{
+				Cron:           s.Cron,
+				Compression:    s.Compression,
+				Consistency:    s.Consistency,
+				PassphraseFile: s.PassphraseFile,
+				Retention:      BackupRetention(s.Retention),
+			},
+			LastRun: s.LastRun,
+			NextRun: next,
+		})
 	}
+	slices.SortFunc(out, func(a, b BackupScheduleInfo) int {
+		return strings.Compare(a.InstanceId, b.InstanceId)
+	})
 	return out, nil
 }
 
+// RunScheduledBackups implements Daemon.RunScheduledBackups.
+func (d *EgnDaemon) RunScheduledBackups() ([]ScheduledBackupResult, error) {
+	schedules, err := d.dataDir.BackupSchedules()
+	if err != nil {
+		return nil, err
+	}
+	instanceIds := maps.Keys(schedules)
+	slices.Sort(instanceIds)
+
+	var results []ScheduledBackupResult
+	for _, instanceId := range instanceIds {
+		// The run is recorded before the backup, so a failing backup is not
+		// retried until its next scheduled time. The schedule is read again,
+		// as it may have changed while the previous backups ran.
+		schedule, due, err := d.dataDir.StartScheduledBackup(instanceId, time.Now())
+		if err != nil {
+			return results, err
+		}
+		if !due {
+			continue
+		}
+
+		log.Infof("Running scheduled backup of instance %s", instanceId)
+		result := ScheduledBackupResult{InstanceId: instanceId}
+		options := BackupOptions{
+			Compression: schedule.Compression,
+			Consistency: schedule.Consistency,
+			Scheduled:   true,
+		}
+		if schedule.PassphraseFile != "" {
+			options.Passphrase, result.Err = readBackupPassphraseFile(schedule.PassphraseFile)
+		}
+		if result.Err == nil {
+			result.BackupId, result.Err = d.Backup(context.Background(), instanceId, options)
+		}
+		if result.Err == nil {
+			result.Pruned, result.Err = d.pruneBackups(instanceId, schedule.Retention, false)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// readBackupPassphraseFile reads a backup passphrase from the first line of
+// the given file.
+func readBackupPassphraseFile(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	passphrase, _, _ := strings.Cut(string(content), "\n")
+	passphrase = strings.TrimSuffix(passphrase, "\r")
+	if passphrase == "" {
+		return "", fmt.Errorf("%w: %s", ErrEmptyBackupPassphrase, path)
+	}
+	return passphrase, nil
+}
+
+// PruneBackups implements Daemon.PruneBackups.
+func (d *EgnDaemon) PruneBackups(options PruneBackupsOptions) ([]BackupInfo, error) {
+	schedules, err := d.dataDir.BackupSchedules()
+	if err != nil {
+		return nil, err
+	}
+	instanceIds := options.InstanceIds
+	if len(instanceIds) == 0 {
+		instanceIds = maps.Keys(schedules)
+		slices.Sort(instanceIds)
+	}
+	var pruned []BackupInfo
+	for _, instanceId := range instanceIds {
+		schedule, ok := schedules[instanceId]
+		if !ok {
+			return pruned, fmt.Errorf("%w: %s", ErrBackupScheduleNotFound, instanceId)
+		}
+		removed, err := d.pruneBackups(instanceId, schedule.Retention, options.DryRun)
+		pruned = append(pruned, removed...)
+		if err != nil {
+			return pruned, err
+		}
+	}
+	return pruned, nil
+}
+
+// pruneBackups removes the backups of the given instance not kept by the given
+// retention policy, and then the backup chunks of the instance that are not
+// used anymore.
+func (d *EgnDaemon) pruneBackups(instanceId string, retention data.BackupRetention, dryRun bool) ([]BackupInfo, error) {
+	backups, err := d.dataDir.BackupList()
+	if err != nil {
+		return nil, err
+	}
+	instanceBackups := make([]data.Backup, 0, len(backups))
+	for _, b := range backups {
+		if b.InstanceId == instanceId {
+			instanceBackups = append(instanceBackups, b)
+		}
+	}
+	_, remove := retention.Apply(instanceBackups)
+	var removed []BackupInfo
+	for _, b := range remove {
+		if !dryRun {
+			log.Infof("Removing backup %s of instance %s", b.Id(), instanceId)
+			if err := d.dataDir.RemoveBackup(b.Id()); err != nil {
+				return removed, err
+			}
+		}
+		removed = append(removed, backupInfo(b))
+	}
+	if dryRun || len(removed) == 0 {
+		return removed, nil
+	}
+	chunks, size, err := d.dataDir.RemoveUnusedBackupChunks(instanceId)
+	if err != nil {
+		return removed, err
+	}
+	log.Infof("Removed %d unused backup chunks of instance %s, %d bytes", chunks, instanceId, size)
+	return removed, nil
+}
+
 func tempID(url string) string {
 	tempHash := sha256.Sum256([]byte(url))
 	return hex.EncodeToString(tempHash[:])
@@ -1298,9 +2258,11 @@ func (d *EgnDaemon) addTarget(instanceID string) error {
 			monitoring.SpecVersionLabel: instance.SpecVersion,
 		}
 		if err = d.monitoringMgr.AddTarget(types.MonitoringTarget{
-			Host: endpoint,
-			Port: uint16(port),
-			Path: target.Path,
+			Host:          endpoint,
+			Port:          uint16(port),
+			Path:          target.Path,
+			Service:       target.Service,
+			ScrapeOptions: target.ScrapeOptions,
 		}, labels, networks[0]); err != nil {
 			return err
 		}
@@ -1,8 +1,10 @@
 package daemon
 
 import (
+	"archive/tar"
 	"bytes"
 	"context"
+	"crypto/ed25519"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -13,8 +15,11 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	log "github.com/sirupsen/logrus"
@@ -23,6 +28,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"golang.org/x/exp/slices"
 
+	"github.com/NethermindEth/eigenlayer/internal/backup"
 	"github.com/NethermindEth/eigenlayer/internal/common"
 	"github.com/NethermindEth/eigenlayer/internal/compose"
 	"github.com/NethermindEth/eigenlayer/internal/data"
@@ -30,6 +36,8 @@ import (
 	"github.com/NethermindEth/eigenlayer/internal/locker"
 	mock_locker "github.com/NethermindEth/eigenlayer/internal/locker/mocks"
 	"github.com/NethermindEth/eigenlayer/internal/package_handler"
+	"github.com/NethermindEth/eigenlayer/internal/profile"
+	"github.com/NethermindEth/eigenlayer/internal/utils"
 	"github.com/NethermindEth/eigenlayer/pkg/daemon/mocks"
 	"github.com/NethermindEth/eigenlayer/pkg/monitoring"
 	"github.com/NethermindEth/eigenlayer/pkg/monitoring/services/types"
@@ -780,7 +788,7 @@ func Test_MergeOptions(t *testing.T) {
 		},
 	}
 	for _, tt := range tc {
-		mergedOptions, err := mergeOptions(tt.oldOptions, tt.newOptions)
+		mergedOptions, _, err := mergeOptions(tt.oldOptions, tt.newOptions)
 		if tt.wantErr {
 			require.Error(t, err)
 		} else {
@@ -796,6 +804,48 @@ func Test_MergeOptions(t *testing.T) {
 	}
 }
 
+func Test_MergeOptions_Changes(t *testing.T) {
+	oldOptions := []Option{
+		&OptionString{option: option{name: "eth-rpc", target: "RPC"}, value: stringP("https://rpc.example.com")},
+		&OptionString{option: option{name: "chain", target: "CHAIN"}, value: stringP("goerli")},
+		&OptionString{option: option{name: "log-level", target: "LOG_LEVEL"}, value: stringP("verbose")},
+		&OptionInt{option: option{name: "port", target: "PORT"}, value: intP(8080)},
+		&OptionSecret{option: option{name: "api-key", target: "API_KEY", hidden: true}, value: stringP("secret")},
+		&OptionString{option: option{name: "legacy", target: "LEGACY"}, value: stringP("value")},
+	}
+	newOptions := []Option{
+		&OptionURI{option: option{name: "rpc-url", target: "RPC_URL", renamedFrom: []string{"eth-rpc"}}},
+		&OptionSelect{
+			option:   option{name: "network", target: "NETWORK", renamedFrom: []string{"chain"}, valueMap: map[string]string{"goerli": "holesky"}},
+			validate: true,
+			Options:  []string{"holesky", "mainnet"},
+		},
+		&OptionSelect{option: option{name: "log-level", target: "LOG_LEVEL"}, validate: true, Options: []string{"info", "debug"}},
+		&OptionPort{option: option{name: "port", target: "PORT"}},
+		&OptionSecret{option: option{name: "api-key", target: "API_KEY", hidden: true}},
+		&OptionString{option: option{name: "metrics", target: "METRICS"}},
+	}
+
+	merged, changes, err := mergeOptions(oldOptions, newOptions)
+	require.NoError(t, err)
+	require.Len(t, merged, len(newOptions))
+	for i := range changes {
+		if changes[i].Kind == OptionReset {
+			assert.NotEmpty(t, changes[i].Reason)
+			changes[i].Reason = ""
+		}
+	}
+	assert.Equal(t, []OptionChange{
+		{Name: "rpc-url", OldName: "eth-rpc", Kind: OptionRenamed, OldValue: "https://rpc.example.com", NewValue: "https://rpc.example.com"},
+		{Name: "network", OldName: "chain", Kind: OptionRenamed, OldValue: "goerli", NewValue: "holesky"},
+		{Name: "log-level", Kind: OptionReset, OldValue: "verbose"},
+		{Name: "port", Kind: OptionKept, OldValue: "8080", NewValue: "8080"},
+		{Name: "api-key", Kind: OptionKept, OldValue: "<redacted>", NewValue: "<redacted>"},
+		{Name: "metrics", Kind: OptionNew},
+		{Name: "legacy", Kind: OptionRemoved, OldValue: "value"},
+	}, changes)
+}
+
 func intP(i int) *int {
 	return &i
 }
@@ -1189,9 +1239,10 @@ func TestRun(t *testing.T) {
 					dockerManager.EXPECT().ContainerIP("1").Return("168.66.44.1", nil),
 					dockerManager.EXPECT().ContainerNetworks("1").Return([]string{"eigenlayer"}, nil),
 					monitoringManager.EXPECT().AddTarget(types.MonitoringTarget{
-						Host: "168.66.44.1",
-						Port: 8090,
-						Path: "/metrics",
+						Host:    "168.66.44.1",
+						Port:    8090,
+						Path:    "/metrics",
+						Service: "main-service",
 					}, labels, "eigenlayer").Return(nil),
 				)
 			},
@@ -1234,9 +1285,10 @@ func TestRun(t *testing.T) {
 					dockerManager.EXPECT().ContainerIP("1").Return("168.66.44.1", nil),
 					dockerManager.EXPECT().ContainerNetworks("1").Return([]string{"eigenlayer"}, nil),
 					monitoringManager.EXPECT().AddTarget(types.MonitoringTarget{
-						Host: "168.66.44.1",
-						Port: 8090,
-						Path: "/metrics",
+						Host:    "168.66.44.1",
+						Port:    8090,
+						Path:    "/metrics",
+						Service: "main-service",
 					}, labels, "eigenlayer").Return(assert.AnError),
 				)
 			},
@@ -1526,6 +1578,7 @@ func TestUninstall(t *testing.T) {
 		instanceID string
 		mocker     func(string, *mocks.MockComposeManager, *mocks.MockDockerManager, *mock_locker.MockLocker, *mocks.MockMonitoringManager)
 		options    *InstallOptions
+		uninstall  UninstallOptions
 		wantErr    bool
 	}{
 		{
@@ -1556,6 +1609,33 @@ func TestUninstall(t *testing.T) {
 				Tag:     "default",
 			},
 		},
+		{
+			name:       "success, keep volumes",
+			instanceID: "mock-avs-default",
+			mocker: func(tmp string, composeManager *mocks.MockComposeManager, dockerManager *mocks.MockDockerManager, locker *mock_locker.MockLocker, monitoringManager *mocks.MockMonitoringManager) {
+				path := filepath.Join(tmp, "nodes", "mock-avs-default", "docker-compose.yml")
+
+				// Init and install
+				gomock.InOrder(
+					locker.EXPECT().New(filepath.Join(tmp, "nodes", "mock-avs-default", ".lock")).Return(locker),
+					locker.EXPECT().Lock().Return(nil),
+					locker.EXPECT().Locked().Return(true),
+					locker.EXPECT().Unlock().Return(nil),
+					composeManager.EXPECT().Create(compose.DockerComposeCreateOptions{Path: path, Build: true}).Return(nil),
+					// Uninstall without removing the volumes
+					monitoringManager.EXPECT().InstallationStatus().Return(common.NotInstalled, nil),
+					composeManager.EXPECT().Down(compose.DockerComposeDownOptions{Path: path, Volumes: false}).Return(nil),
+				)
+			},
+			options: &InstallOptions{
+				Name:    MockAVSName,
+				URL:     common.MockAvsPkg.Repo(),
+				Version: common.MockAvsPkg.Version(),
+				Profile: "health-checker",
+				Tag:     "default",
+			},
+			uninstall: UninstallOptions{KeepVolumes: true},
+		},
 		{
 			name:       "success, monitoring stack not installed",
 			instanceID: "mock-avs-default",
@@ -1689,7 +1769,7 @@ func TestUninstall(t *testing.T) {
 				require.NoError(t, err)
 			}
 
-			err = daemon.Uninstall(tt.instanceID)
+			err = daemon.Uninstall(tt.instanceID, tt.uninstall)
 			if tt.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -2640,6 +2720,136 @@ func TestListInstances(t *testing.T) {
 			},
 			err: nil,
 		},
+		{
+			name: "1 instance running with health checks, all passing",
+			mocker: func(t *testing.T, d *mockerData) {
+				apiServer, apiServerURL := httptestHealth(t, http.StatusOK)
+				t.Cleanup(apiServer.Close)
+				initInstanceDir(t, d.fs, d.dataDirPath, "mock-avs-default", `{
+					"name": "`+MockAVSName+`",
+					"tag": "default",
+					"version": "`+common.MockAvsPkg.Version()+`",
+					"commit": "`+common.MockAvsPkg.CommitHash()+`",
+					"profile": "option-returner",
+					"url": "`+common.MockAvsPkg.Repo()+`",
+					"health_checks": [
+						{"name": "api", "service": "main-service", "type": "http", "port": "`+apiServerURL.Port()+`", "path": "/eigen/node/health"},
+						{"name": "api-port", "service": "main-service", "type": "tcp", "port": "`+apiServerURL.Port()+`"},
+						{"name": "worker", "service": "worker", "type": "command", "command": ["worker", "status"], "timeout": "5s"}
+					]
+				}`)
+				composePath := filepath.Join(d.dataDirPath, "nodes", "mock-avs-default", "docker-compose.yml")
+
+				d.locker.EXPECT().New(filepath.Join(d.dataDirPath, "nodes", "mock-avs-default", ".lock")).Return(d.locker).Times(3)
+				gomock.InOrder(
+					d.composeManager.EXPECT().PS(compose.DockerComposePsOptions{
+						Path:          composePath,
+						Format:        "json",
+						FilterRunning: true,
+					}).Return([]compose.ComposeService{{Id: "abc123", State: "running"}}, nil),
+					d.composeManager.EXPECT().PS(compose.DockerComposePsOptions{
+						ServiceName: "main-service",
+						Path:        composePath,
+						Format:      "json",
+						All:         true,
+					}).Return([]compose.ComposeService{{Id: "abc123", State: "running"}}, nil),
+					d.dockerManager.EXPECT().ContainerIP("abc123").Return(apiServerURL.Hostname(), nil),
+					d.composeManager.EXPECT().PS(compose.DockerComposePsOptions{
+						ServiceName: "main-service",
+						Path:        composePath,
+						Format:      "json",
+						All:         true,
+					}).Return([]compose.ComposeService{{Id: "abc123", State: "running"}}, nil),
+					d.dockerManager.EXPECT().ContainerIP("abc123").Return(apiServerURL.Hostname(), nil),
+					d.composeManager.EXPECT().PS(compose.DockerComposePsOptions{
+						ServiceName: "worker",
+						Path:        composePath,
+						Format:      "json",
+						All:         true,
+					}).Return([]compose.ComposeService{{Id: "def456", State: "running"}}, nil),
+					d.dockerManager.EXPECT().Exec(gomock.Any(), "def456", []string{"worker", "status"}).Return(0, "ok", nil),
+				)
+			},
+			out: []ListInstanceItem{
+				{
+					ID:      "mock-avs-default",
+					Health:  NodeHealthy,
+					Running: true,
+					Version: common.MockAvsPkg.Version(),
+					Commit:  common.MockAvsPkg.CommitHash(),
+					HealthChecks: []HealthCheckResult{
+						{Name: "api", Service: "main-service", Healthy: true},
+						{Name: "api-port", Service: "main-service", Healthy: true},
+						{Name: "worker", Service: "worker", Healthy: true},
+					},
+				},
+			},
+		},
+		{
+			name: "1 instance running with health checks, some failing",
+			mocker: func(t *testing.T, d *mockerData) {
+				apiServer, apiServerURL := httptestHealth(t, http.StatusServiceUnavailable)
+				t.Cleanup(apiServer.Close)
+				initInstanceDir(t, d.fs, d.dataDirPath, "mock-avs-default", `{
+					"name": "`+MockAVSName+`",
+					"tag": "default",
+					"version": "`+common.MockAvsPkg.Version()+`",
+					"commit": "`+common.MockAvsPkg.CommitHash()+`",
+					"profile": "option-returner",
+					"url": "`+common.MockAvsPkg.Repo()+`",
+					"health_checks": [
+						{"name": "api", "service": "main-service", "type": "http", "port": "`+apiServerURL.Port()+`", "path": "/eigen/node/health", "expected_status": 503},
+						{"name": "db", "service": "db", "type": "tcp", "port": "5432"},
+						{"name": "worker", "service": "worker", "type": "command", "command": ["worker", "status"]}
+					]
+				}`)
+				composePath := filepath.Join(d.dataDirPath, "nodes", "mock-avs-default", "docker-compose.yml")
+
+				d.locker.EXPECT().New(filepath.Join(d.dataDirPath, "nodes", "mock-avs-default", ".lock")).Return(d.locker).Times(3)
+				gomock.InOrder(
+					d.composeManager.EXPECT().PS(compose.DockerComposePsOptions{
+						Path:          composePath,
+						Format:        "json",
+						FilterRunning: true,
+					}).Return([]compose.ComposeService{{Id: "abc123", State: "running"}}, nil),
+					d.composeManager.EXPECT().PS(compose.DockerComposePsOptions{
+						ServiceName: "main-service",
+						Path:        composePath,
+						Format:      "json",
+						All:         true,
+					}).Return([]compose.ComposeService{{Id: "abc123", State: "running"}}, nil),
+					d.dockerManager.EXPECT().ContainerIP("abc123").Return(apiServerURL.Hostname(), nil),
+					d.composeManager.EXPECT().PS(compose.DockerComposePsOptions{
+						ServiceName: "db",
+						Path:        composePath,
+						Format:      "json",
+						All:         true,
+					}).Return([]compose.ComposeService{{Id: "ghi789", State: "exited"}}, nil),
+					d.composeManager.EXPECT().PS(compose.DockerComposePsOptions{
+						ServiceName: "worker",
+						Path:        composePath,
+						Format:      "json",
+						All:         true,
+					}).Return([]compose.ComposeService{{Id: "def456", State: "running"}}, nil),
+					d.dockerManager.EXPECT().Exec(gomock.Any(), "def456", []string{"worker", "status"}).Return(1, "not synced\n", nil),
+				)
+			},
+			out: []ListInstanceItem{
+				{
+					ID:      "mock-avs-default",
+					Health:  NodePartiallyHealthy,
+					Running: true,
+					Comment: "Failed health checks: db (container is exited), worker (exit code 1: not synced)",
+					Version: common.MockAvsPkg.Version(),
+					Commit:  common.MockAvsPkg.CommitHash(),
+					HealthChecks: []HealthCheckResult{
+						{Name: "api", Service: "main-service", Healthy: true},
+						{Name: "db", Service: "db", Detail: "container is exited"},
+						{Name: "worker", Service: "worker", Detail: "exit code 1: not synced"},
+					},
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -2892,7 +3102,7 @@ func TestRunPlugin(t *testing.T) {
 					}, nil),
 					d.dockerManager.EXPECT().ContainerNetworks("abc123").Return([]string{"network-el"}, nil),
 					d.dockerManager.EXPECT().ImageExist(common.PluginImage.FullImage()).Return(true, nil),
-					d.dockerManager.EXPECT().Run(common.PluginImage.FullImage(), docker.RunOptions{
+					d.dockerManager.EXPECT().Run(gomock.Any(), common.PluginImage.FullImage(), docker.RunOptions{
 						Network: "network-el",
 						Args:    []string{"arg1", "arg2"},
 						Mounts: []docker.Mount{
@@ -2949,7 +3159,7 @@ func TestRunPlugin(t *testing.T) {
 					d.dockerManager.EXPECT().ContainerNetworks("abc123").Return([]string{"network-el"}, nil),
 					d.dockerManager.EXPECT().ImageExist(common.PluginImage.FullImage()).Return(false, nil),
 					d.dockerManager.EXPECT().Pull(common.PluginImage.FullImage()).Return(nil),
-					d.dockerManager.EXPECT().Run(common.PluginImage.FullImage(), docker.RunOptions{
+					d.dockerManager.EXPECT().Run(gomock.Any(), common.PluginImage.FullImage(), docker.RunOptions{
 						Network: "network-el",
 						Args:    []string{"arg1", "arg2"},
 						Mounts: []docker.Mount{
@@ -3037,7 +3247,7 @@ func TestRunPlugin(t *testing.T) {
 				gomock.InOrder(
 					d.locker.EXPECT().New(filepath.Join(d.dataDir.Path(), "nodes", "mock-avs-default", ".lock")).Return(d.locker),
 					d.dockerManager.EXPECT().ImageExist(common.PluginImage.FullImage()).Return(true, nil),
-					d.dockerManager.EXPECT().Run(common.PluginImage.FullImage(), docker.RunOptions{
+					d.dockerManager.EXPECT().Run(gomock.Any(), common.PluginImage.FullImage(), docker.RunOptions{
 						Network: docker.NetworkHost,
 						Args:    []string{"arg1", "arg2"},
 						Mounts: []docker.Mount{
@@ -3223,11 +3433,134 @@ func TestRunPlugin(t *testing.T) {
 	}
 }
 
+func TestRunMigrations(t *testing.T) {
+	afs := afero.NewOsFs()
+	type mockerData struct {
+		dataDir        *data.DataDir
+		fs             afero.Fs
+		composeManager *mocks.MockComposeManager
+		dockerManager  *mocks.MockDockerManager
+		locker         *mock_locker.MockLocker
+	}
+	migrations := []Migration{
+		{Image: "mock-migration-1:latest", Args: []string{"--data", "/data"}},
+		{Image: "mock-migration-2:latest"},
+	}
+	tc := []struct {
+		name       string
+		instanceId string
+		wantErr    bool
+		mocker     func(t *testing.T, d *mockerData)
+	}{
+		{
+			name:       "run migrations",
+			instanceId: "mock-avs-default",
+			mocker: func(t *testing.T, d *mockerData) {
+				initInstanceDir(t, d.fs, d.dataDir.Path(), "mock-avs-default", `{
+					"name": "mock-avs",
+					"tag": "default",
+					"version": "`+common.MockAvsPkg.Version()+`",
+					"profile": "option-returner",
+					"url": "`+common.MockAvsPkg.Repo()+`"
+				}`)
+				gomock.InOrder(
+					d.locker.EXPECT().New(filepath.Join(d.dataDir.Path(), "nodes", "mock-avs-default", ".lock")).Return(d.locker),
+					d.composeManager.EXPECT().PS(compose.DockerComposePsOptions{
+						Path:   filepath.Join(d.dataDir.Path(), "nodes", "mock-avs-default", "docker-compose.yml"),
+						Format: "json",
+						All:    true,
+					}).Return([]compose.ComposeService{{Id: "abc123"}, {Id: "def456"}}, nil),
+					d.dockerManager.EXPECT().ImageExist("mock-migration-1:latest").Return(true, nil),
+					d.dockerManager.EXPECT().Run(gomock.Any(), "mock-migration-1:latest", docker.RunOptions{
+						Args:        []string{"--data", "/data"},
+						VolumesFrom: []string{"abc123", "def456"},
+						AutoRemove:  true,
+					}).Return(nil),
+					d.dockerManager.EXPECT().ImageExist("mock-migration-2:latest").Return(false, nil),
+					d.dockerManager.EXPECT().Pull("mock-migration-2:latest").Return(nil),
+					d.dockerManager.EXPECT().Run(gomock.Any(), "mock-migration-2:latest", docker.RunOptions{
+						VolumesFrom: []string{"abc123", "def456"},
+						AutoRemove:  true,
+					}).Return(nil),
+				)
+			},
+		},
+		{
+			name:       "migration fails",
+			instanceId: "mock-avs-default",
+			wantErr:    true,
+			mocker: func(t *testing.T, d *mockerData) {
+				initInstanceDir(t, d.fs, d.dataDir.Path(), "mock-avs-default", `{
+					"name": "mock-avs",
+					"tag": "default",
+					"version": "`+common.MockAvsPkg.Version()+`",
+					"profile": "option-returner",
+					"url": "`+common.MockAvsPkg.Repo()+`"
+				}`)
+				gomock.InOrder(
+					d.locker.EXPECT().New(filepath.Join(d.dataDir.Path(), "nodes", "mock-avs-default", ".lock")).Return(d.locker),
+					d.composeManager.EXPECT().PS(compose.DockerComposePsOptions{
+						Path:   filepath.Join(d.dataDir.Path(), "nodes", "mock-avs-default", "docker-compose.yml"),
+						Format: "json",
+						All:    true,
+					}).Return([]compose.ComposeService{{Id: "abc123"}}, nil),
+					d.dockerManager.EXPECT().ImageExist("mock-migration-1:latest").Return(true, nil),
+					d.dockerManager.EXPECT().Run(gomock.Any(), "mock-migration-1:latest", docker.RunOptions{
+						Args:        []string{"--data", "/data"},
+						VolumesFrom: []string{"abc123"},
+						AutoRemove:  true,
+					}).Return(assert.AnError),
+				)
+			},
+		},
+		{
+			name:       "instance not found",
+			instanceId: "mock-avs-default",
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create mocks
+			ctrl := gomock.NewController(t)
+			composeManager := mocks.NewMockComposeManager(ctrl)
+			dockerManager := mocks.NewMockDockerManager(ctrl)
+			locker := mock_locker.NewMockLocker(ctrl)
+			monitoringManager := mocks.NewMockMonitoringManager(ctrl)
+			backupMgr := mocks.NewMockBackupManager(ctrl)
+
+			tmp, err := afero.TempDir(afs, "", "egn-test-migrations")
+			require.NoError(t, err)
+			dataDir, err := data.NewDataDir(tmp, afs, locker)
+			require.NoError(t, err)
+
+			if tt.mocker != nil {
+				tt.mocker(t, &mockerData{
+					dataDir:        dataDir,
+					fs:             afs,
+					composeManager: composeManager,
+					dockerManager:  dockerManager,
+					locker:         locker,
+				})
+			}
+
+			daemon, err := NewEgnDaemon(dataDir, composeManager, dockerManager, monitoringManager, backupMgr, locker)
+			require.NoError(t, err)
+
+			err = daemon.RunMigrations(tt.instanceId, migrations)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
 func TestGetPluginData(t *testing.T) {
 	type args struct {
-		dataDir    *data.DataDir
 		pkgHandler *package_handler.PackageHandler
-		instanceID string
+		profile    string
 	}
 	type want struct {
 		plugin *data.Plugin
@@ -3259,7 +3592,6 @@ func TestGetPluginData(t *testing.T) {
 	require.NoError(t, err, "failed to initialize daemon")
 
 	// Tests
-	// TODO: remove table-driven test schema because it's only one test case
 	tests := []testCase{
 		func(t *testing.T) testCase {
 			name := "plugin with remote image"
@@ -3274,9 +3606,8 @@ func TestGetPluginData(t *testing.T) {
 				name:   name,
 				daemon: daemon,
 				args: args{
-					dataDir:    dataDir,
 					pkgHandler: package_handler.NewPackageHandler(pkgFolder),
-					instanceID: "mock-avs-remote-image",
+					profile:    "option-returner",
 				},
 				want: want{
 					plugin: &data.Plugin{
@@ -3286,11 +3617,40 @@ func TestGetPluginData(t *testing.T) {
 				},
 			}
 		}(t),
+		func(t *testing.T) testCase {
+			name := "plugin overridden by the profile"
+			pkgFolder := t.TempDir()
+			err := exec.Command("git", "clone", "--single-branch", "-b", common.MockAvsPkg.Version(), common.MockAvsPkg.Repo(), pkgFolder).Run()
+			require.NoError(t, err, "failed to clone mock-avs repo")
+			pkgHandler := package_handler.NewPackageHandler(pkgFolder)
+			changeManifestPluginBuildFrom(t, fs, pkgHandler.ManifestFilePath(), package_handler.Plugin{
+				Image: "busybox:3.16",
+			})
+			profileFile, err := fs.OpenFile(filepath.Join(pkgHandler.ProfilePath("option-returner"), "profile.yml"), os.O_APPEND|os.O_WRONLY, 0o644)
+			require.NoError(t, err, "failed to open profile file")
+			_, err = profileFile.WriteString("\nplugin_overrides:\n  image: busybox:3.17\n")
+			require.NoError(t, err, "failed to write profile file")
+			require.NoError(t, profileFile.Close(), "failed to close profile file")
+			return testCase{
+				name:   name,
+				daemon: daemon,
+				args: args{
+					pkgHandler: pkgHandler,
+					profile:    "option-returner",
+				},
+				want: want{
+					plugin: &data.Plugin{
+						Image: "busybox:3.17",
+					},
+					err: nil,
+				},
+			}
+		}(t),
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			plugin, err := tt.daemon.getPluginData(tt.args.dataDir, tt.args.pkgHandler, tt.args.instanceID)
+			plugin, err := tt.daemon.getPluginData(tt.args.pkgHandler, tt.args.profile)
 			assert.ErrorIs(t, err, tt.want.err, "unexpected error")
 			assert.Equal(t, tt.want.plugin, plugin, "unexpected plugin")
 		})
@@ -3316,3 +3676,1035 @@ func changeManifestPluginBuildFrom(t *testing.T, fs afero.Fs, manifestPath strin
 	_, err = manifestFile.Write(manifestData)
 	require.NoError(t, err, "failed to write manifest file")
 }
+
+func TestLocalInstall_Secure(t *testing.T) {
+	afs := afero.NewOsFs()
+
+	pkgPath := t.TempDir()
+	_, err := package_handler.NewPackage(pkgPath, package_handler.NewPackageOptions{
+		Name:     "mock-avs",
+		Profiles: []string{"default"},
+	})
+	require.NoError(t, err)
+	var pkgTar bytes.Buffer
+	require.NoError(t, utils.CompressToTarGz(pkgPath, &pkgTar))
+
+	pubKey, privKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	otherKey, _, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	signature := ed25519.Sign(privKey, pkgTar.Bytes())
+
+	tests := []struct {
+		name    string
+		options LocalInstallOptions
+		wantErr error
+	}{
+		{
+			name: "signature required",
+			options: LocalInstallOptions{
+				Secure:      true,
+				TrustedKeys: []ed25519.PublicKey{pubKey},
+			},
+			wantErr: ErrSignatureRequired,
+		},
+		{
+			name: "untrusted key",
+			options: LocalInstallOptions{
+				Secure:      true,
+				Signature:   signature,
+				TrustedKeys: []ed25519.PublicKey{otherKey},
+			},
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name: "valid signature, unknown option",
+			options: LocalInstallOptions{
+				Secure:      true,
+				Signature:   signature,
+				TrustedKeys: []ed25519.PublicKey{otherKey, pubKey},
+				Options:     map[string]string{"unknown-option": "value"},
+			},
+			wantErr: ErrUnknownOption,
+		},
+		{
+			name: "insecure, unknown option",
+			options: LocalInstallOptions{
+				Options: map[string]string{"unknown-option": "value"},
+			},
+			wantErr: ErrUnknownOption,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			locker := mock_locker.NewMockLocker(ctrl)
+			dataDir, err := data.NewDataDir(t.TempDir(), afs, locker)
+			require.NoError(t, err)
+			daemon, err := NewEgnDaemon(dataDir, nil, nil, nil, nil, locker)
+			require.NoError(t, err)
+
+			tt.options.Name = "mock-avs"
+			tt.options.Tag = "default"
+			tt.options.Profile = "default"
+			_, _, err = daemon.localInstall(bytes.NewReader(pkgTar.Bytes()), tt.options)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestLocalInstall_Secrets(t *testing.T) {
+	afs := afero.NewOsFs()
+
+	// Create a package with a secret option
+	pkgPath := t.TempDir()
+	_, err := package_handler.NewPackage(pkgPath, package_handler.NewPackageOptions{
+		Name:     "mock-avs",
+		Profiles: []string{"default"},
+	})
+	require.NoError(t, err)
+	profilePath := filepath.Join(pkgPath, "pkg", "default", "profile.yml")
+	profileData, err := afero.ReadFile(afs, profilePath)
+	require.NoError(t, err)
+	profileData = bytes.Replace(profileData, []byte("monitoring:"), []byte(`  - name: api-key
+    target: API_KEY
+    type: secret
+    help: API key of the node
+monitoring:`), 1)
+	require.NoError(t, afero.WriteFile(afs, profilePath, profileData, 0o644))
+	composePath := filepath.Join(pkgPath, "pkg", "default", "docker-compose.yml")
+	composeData, err := afero.ReadFile(afs, composePath)
+	require.NoError(t, err)
+	composeData = append(composeData, []byte("    environment:\n      - API_KEY=${API_KEY}\n")...)
+	require.NoError(t, afero.WriteFile(afs, composePath, composeData, 0o644))
+	var pkgTar bytes.Buffer
+	require.NoError(t, utils.CompressToTarGz(pkgPath, &pkgTar))
+
+	tmp := t.TempDir()
+	ctrl := gomock.NewController(t)
+	composeManager := mocks.NewMockComposeManager(ctrl)
+	monitoringManager := mocks.NewMockMonitoringManager(ctrl)
+	locker := mock_locker.NewMockLocker(ctrl)
+	dataDir, err := data.NewDataDir(tmp, afs, locker)
+	require.NoError(t, err)
+	daemon, err := NewEgnDaemon(dataDir, composeManager, nil, monitoringManager, nil, locker)
+	require.NoError(t, err)
+
+	instancePath := filepath.Join(tmp, "nodes", "mock-avs-default")
+	secrets := map[string]string{"API_KEY": "secret-api-key"}
+	gomock.InOrder(
+		locker.EXPECT().New(filepath.Join(instancePath, ".lock")).Return(locker),
+		locker.EXPECT().Lock().Return(nil),
+		locker.EXPECT().Locked().Return(true),
+		locker.EXPECT().Unlock().Return(nil),
+		composeManager.EXPECT().Create(compose.DockerComposeCreateOptions{
+			Path:  filepath.Join(instancePath, "docker-compose.yml"),
+			Build: true,
+			Env:   secrets,
+		}).Return(nil),
+		monitoringManager.EXPECT().InstallationStatus().Return(common.NotInstalled, nil),
+	)
+
+	instanceID, err := daemon.LocalInstall(bytes.NewReader(pkgTar.Bytes()), LocalInstallOptions{
+		Name:    "mock-avs",
+		Tag:     "default",
+		Profile: "default",
+		Options: map[string]string{"api-key": "secret-api-key"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "mock-avs-default", instanceID)
+
+	// The secret is not in the .env file, but in the secret store
+	env, err := afero.ReadFile(afs, filepath.Join(instancePath, ".env"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(env), "secret-api-key")
+	stored, err := dataDir.Secrets().Get(instanceID)
+	require.NoError(t, err)
+	assert.Equal(t, secrets, stored)
+
+	// Secrets are removed with the instance
+	require.NoError(t, daemon.uninstall(instanceID, false, false))
+	stored, err = dataDir.Secrets().Get(instanceID)
+	require.NoError(t, err)
+	assert.Nil(t, stored)
+}
+
+func TestLocalInstall_DefaultTemplates(t *testing.T) {
+	afs := afero.NewOsFs()
+
+	// Create a package with template defaults
+	pkgPath := t.TempDir()
+	_, err := package_handler.NewPackage(pkgPath, package_handler.NewPackageOptions{
+		Name:     "mock-avs",
+		Profiles: []string{"default"},
+	})
+	require.NoError(t, err)
+	profilePath := filepath.Join(pkgPath, "pkg", "default", "profile.yml")
+	profileData, err := afero.ReadFile(afs, profilePath)
+	require.NoError(t, err)
+	profileData = bytes.Replace(profileData, []byte("monitoring:"), []byte(`  - name: data-dir
+    target: DATA_DIR
+    type: path_dir
+    default: "{{ .DataDir }}/{{ .Tag }}"
+    help: Data directory
+  - name: workers
+    target: WORKERS
+    type: int
+    default: "{{ .CPUs }}"
+    help: Number of workers
+  - name: node-name
+    target: NODE_NAME
+    type: str
+    default: '{{ index .Options "main-container-name" }}-{{ .InstanceID }}'
+    help: Name of the node
+monitoring:`), 1)
+	require.NoError(t, afero.WriteFile(afs, profilePath, profileData, 0o644))
+	var pkgTar bytes.Buffer
+	require.NoError(t, utils.CompressToTarGz(pkgPath, &pkgTar))
+
+	tmp := t.TempDir()
+	ctrl := gomock.NewController(t)
+	composeManager := mocks.NewMockComposeManager(ctrl)
+	locker := mock_locker.NewMockLocker(ctrl)
+	dataDir, err := data.NewDataDir(tmp, afs, locker)
+	require.NoError(t, err)
+	daemon, err := NewEgnDaemon(dataDir, composeManager, nil, nil, nil, locker)
+	require.NoError(t, err)
+
+	instancePath := filepath.Join(tmp, "nodes", "mock-avs-mainnet")
+	gomock.InOrder(
+		locker.EXPECT().New(filepath.Join(instancePath, ".lock")).Return(locker),
+		locker.EXPECT().Lock().Return(nil),
+		locker.EXPECT().Locked().Return(true),
+		locker.EXPECT().Unlock().Return(nil),
+		composeManager.EXPECT().Create(compose.DockerComposeCreateOptions{
+			Path:  filepath.Join(instancePath, "docker-compose.yml"),
+			Build: true,
+		}).Return(nil),
+	)
+
+	// The name of the package is read from its manifest
+	instanceID, err := daemon.LocalInstall(bytes.NewReader(pkgTar.Bytes()), LocalInstallOptions{
+		Tag:     "mainnet",
+		Profile: "default",
+		Options: map[string]string{"main-container-name": "main"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "mock-avs-mainnet", instanceID)
+
+	env, err := afero.ReadFile(afs, filepath.Join(instancePath, ".env"))
+	require.NoError(t, err)
+	assert.Contains(t, string(env), "DATA_DIR="+filepath.Join(tmp, "mainnet")+"\n")
+	assert.Contains(t, string(env), "WORKERS="+strconv.Itoa(runtime.NumCPU())+"\n")
+	assert.Contains(t, string(env), "NODE_NAME=main-mock-avs-mainnet\n")
+}
+
+// initBackupManifest writes the manifest of a chunked backup of the given
+// instance in the backups directory, and returns the backup ID.
+func initBackupManifest(t *testing.T, dataDir *data.DataDir, instanceId string) string {
+	t.Helper()
+	return initBackupManifestAt(t, dataDir, instanceId, time.Unix(1696420902, 0))
+}
+
+func initBackupManifestAt(t *testing.T, dataDir *data.DataDir, instanceId string, timestamp time.Time) string {
+	t.Helper()
+	return newBackupManifest(t, dataDir, instanceId, timestamp, false)
+}
+
+// initScheduledBackupManifestAt is like initBackupManifestAt, for a backup
+// created by the backup schedule of the instance.
+func initScheduledBackupManifestAt(t *testing.T, dataDir *data.DataDir, instanceId string, timestamp time.Time) string {
+	t.Helper()
+	return newBackupManifest(t, dataDir, instanceId, timestamp, true)
+}
+
+func newBackupManifest(t *testing.T, dataDir *data.DataDir, instanceId string, timestamp time.Time, scheduled bool) string {
+	t.Helper()
+	b := data.Backup{
+		InstanceId:  instanceId,
+		Timestamp:   timestamp,
+		Version:     common.MockAvsPkg.Version(),
+		Compression: data.CompressionZstd,
+		Scheduled:   scheduled,
+	}
+	manifest, err := json.Marshal(b)
+	require.NoError(t, err)
+	require.NoError(t, dataDir.BackupStore().Put(data.BackupManifestKey(b.Id()), bytes.NewReader(manifest)))
+	return b.Id()
+}
+
+func TestBackupPush(t *testing.T) {
+	tc := []struct {
+		name    string
+		backup  bool
+		pushErr error
+		wantErr error
+	}{
+		{
+			name:   "push backup",
+			backup: true,
+		},
+		{
+			name:    "push fails",
+			backup:  true,
+			pushErr: assert.AnError,
+			wantErr: assert.AnError,
+		},
+		{
+			name:    "backup not found",
+			wantErr: ErrBackupNotFound,
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			backupMgr := mocks.NewMockBackupManager(ctrl)
+			dataDir, err := data.NewDataDir(t.TempDir(), afero.NewOsFs(), nil)
+			require.NoError(t, err)
+
+			backupId := "backup-id"
+			if tt.backup {
+				backupId = initBackupManifest(t, dataDir, "mock-avs-default")
+				backupMgr.EXPECT().PushBackup(backupId, "s3://bucket/backups").Return(tt.pushErr)
+			}
+
+			daemon, err := NewEgnDaemon(dataDir, nil, nil, nil, backupMgr, nil)
+			require.NoError(t, err)
+
+			err = daemon.BackupPush(backupId, "s3://bucket/backups")
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRestore_From(t *testing.T) {
+	tc := []struct {
+		name    string
+		local   bool
+		pullErr error
+		wantErr error
+	}{
+		{
+			name: "pull and restore",
+		},
+		{
+			name:  "local backup is not pulled",
+			local: true,
+		},
+		{
+			name:    "backup not found in store",
+			pullErr: fmt.Errorf("%w: backup-id", data.ErrBackupNotFound),
+			wantErr: ErrBackupNotFound,
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			backupMgr := mocks.NewMockBackupManager(ctrl)
+			dataDir, err := data.NewDataDir(t.TempDir(), afero.NewOsFs(), nil)
+			require.NoError(t, err)
+
+			// The ID of the backup, created locally or by the pull
+			remote, err := data.NewDataDir(t.TempDir(), afero.NewOsFs(), nil)
+			require.NoError(t, err)
+			backupId := initBackupManifest(t, remote, "mock-avs-default")
+			if tt.local {
+				initBackupManifest(t, dataDir, "mock-avs-default")
+			} else {
+				backupMgr.EXPECT().PullBackup(backupId, "sftp://host/backups").DoAndReturn(func(backupId, location string) error {
+					if tt.pullErr != nil {
+						return tt.pullErr
+					}
+					initBackupManifest(t, dataDir, "mock-avs-default")
+					return nil
+				})
+			}
+			if tt.wantErr == nil {
+				backupMgr.EXPECT().RestoreInstance(gomock.Any(), backupId, backup.RestoreOptions{}).Return("mock-avs-default", nil)
+			}
+
+			daemon, err := NewEgnDaemon(dataDir, nil, nil, nil, backupMgr, nil)
+			require.NoError(t, err)
+
+			err = daemon.Restore(context.Background(), backupId, RestoreOptions{From: "sftp://host/backups"})
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRestore_Encrypted(t *testing.T) {
+	tc := []struct {
+		name       string
+		passphrase string
+		wantErr    error
+	}{
+		{
+			name:       "valid passphrase",
+			passphrase: "secret",
+		},
+		{
+			name:    "missing passphrase",
+			wantErr: ErrBackupEncrypted,
+		},
+		{
+			name:       "wrong passphrase",
+			passphrase: "wrong",
+			wantErr:    ErrInvalidBackupPassphrase,
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			backupMgr := mocks.NewMockBackupManager(ctrl)
+			dataDir, err := data.NewDataDir(t.TempDir(), afero.NewOsFs(), nil)
+			require.NoError(t, err)
+
+			b := &data.Backup{
+				InstanceId: "mock-avs-default",
+				Timestamp:  time.Unix(1696420902, 0),
+				Version:    common.MockAvsPkg.Version(),
+			}
+			require.NoError(t, dataDir.InitBackup(b))
+			require.NoError(t, dataDir.PackBackup(context.Background(), b, strings.NewReader("tar"), data.PackOptions{Compression: data.CompressionZstd, Passphrase: "secret"}))
+			if tt.wantErr == nil {
+				backupMgr.EXPECT().RestoreInstance(gomock.Any(), b.Id(), backup.RestoreOptions{Passphrase: tt.passphrase}).Return("mock-avs-default", nil)
+			}
+
+			daemon, err := NewEgnDaemon(dataDir, nil, nil, nil, backupMgr, nil)
+			require.NoError(t, err)
+
+			err = daemon.Restore(context.Background(), b.Id(), RestoreOptions{Passphrase: tt.passphrase})
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRestore_Selection(t *testing.T) {
+	selection := &data.BackupSelection{
+		Filter:  data.VolumeFilter{Include: []string{"main-service"}},
+		Volumes: []data.BackupVolume{{Service: "main-service", Source: "mock-avs-data", Target: "/data"}},
+	}
+	tc := []struct {
+		name      string
+		selection *data.BackupSelection
+		options   RestoreOptions
+		want      backup.RestoreOptions
+		wantErr   error
+	}{
+		{
+			name:      "partial backup",
+			selection: selection,
+			want:      backup.RestoreOptions{},
+		},
+		{
+			name:      "include volume in backup",
+			selection: selection,
+			options:   RestoreOptions{Include: []string{"main-service:/data"}},
+			want:      backup.RestoreOptions{Volumes: data.VolumeFilter{Include: []string{"main-service:/data"}}},
+		},
+		{
+			name:    "exclude volume",
+			options: RestoreOptions{Exclude: []string{"main-service:mock-avs-data"}},
+			want:    backup.RestoreOptions{Volumes: data.VolumeFilter{Exclude: []string{"main-service:mock-avs-data"}}},
+		},
+		{
+			name:    "config only",
+			options: RestoreOptions{ConfigOnly: true},
+			want:    backup.RestoreOptions{ConfigOnly: true},
+		},
+		{
+			name:      "include volume not in backup",
+			selection: selection,
+			options:   RestoreOptions{Include: []string{"option-returner"}},
+			wantErr:   data.ErrInvalidVolumeFilter,
+		},
+		{
+			name:    "invalid filter",
+			options: RestoreOptions{Include: []string{":/data"}},
+			wantErr: data.ErrInvalidVolumeFilter,
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			backupMgr := mocks.NewMockBackupManager(ctrl)
+			dataDir, err := data.NewDataDir(t.TempDir(), afero.NewOsFs(), nil)
+			require.NoError(t, err)
+
+			b := data.Backup{
+				InstanceId:  "mock-avs-default",
+				Timestamp:   time.Unix(1696420902, 0),
+				Version:     common.MockAvsPkg.Version(),
+				Compression: data.CompressionZstd,
+				Selection:   tt.selection,
+			}
+			manifest, err := json.Marshal(b)
+			require.NoError(t, err)
+			require.NoError(t, dataDir.BackupStore().Put(data.BackupManifestKey(b.Id()), bytes.NewReader(manifest)))
+			if tt.wantErr == nil {
+				backupMgr.EXPECT().RestoreInstance(gomock.Any(), b.Id(), tt.want).Return("mock-avs-default", nil)
+			}
+
+			daemon, err := NewEgnDaemon(dataDir, nil, nil, nil, backupMgr, nil)
+			require.NoError(t, err)
+
+			err = daemon.Restore(context.Background(), b.Id(), tt.options)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRestore_Tag(t *testing.T) {
+	tc := []struct {
+		name       string
+		restored   string
+		restoreErr error
+		wantErr    error
+	}{
+		{
+			name: "restore as new instance",
+		},
+		{
+			name:       "new instance already exists",
+			restoreErr: fmt.Errorf("%w: mock-avs-test", data.ErrInstanceAlreadyExists),
+			wantErr:    ErrInstanceAlreadyExists,
+		},
+		{
+			name:       "shared resources",
+			restoreErr: fmt.Errorf("%w: external volume mock-avs-data of service \"main-service\"", data.ErrSharedResources),
+			wantErr:    ErrSharedResources,
+		},
+		{
+			name:       "canceled after creating the new instance",
+			restored:   "mock-avs-test",
+			restoreErr: context.Canceled,
+			wantErr:    context.Canceled,
+		},
+		{
+			name:       "missing secrets",
+			restored:   "mock-avs-test",
+			restoreErr: data.MissingSecretsError{Options: []profile.Option{{Name: "api-key", Target: "API_KEY", Type: "secret", Help: "API key"}}},
+			wantErr:    ErrMissingSecrets,
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			backupMgr := mocks.NewMockBackupManager(ctrl)
+			fs := afero.NewOsFs()
+			dataDirPath := t.TempDir()
+			dataDir, err := data.NewDataDir(dataDirPath, fs, nil)
+			require.NoError(t, err)
+
+			// The instance of the backup is not uninstalled
+			initInstanceDir(t, fs, dataDirPath, "mock-avs-default", `{"name": "mock-avs", "tag": "default"}`)
+			backupId := initBackupManifest(t, dataDir, "mock-avs-default")
+			restored := "mock-avs-test"
+			if tt.restoreErr != nil {
+				restored = tt.restored
+			}
+			backupMgr.EXPECT().RestoreInstance(gomock.Any(), backupId, backup.RestoreOptions{Tag: "test"}).Return(restored, tt.restoreErr)
+			composeMgr := mocks.NewMockComposeManager(ctrl)
+			monitoringMgr := mocks.NewMockMonitoringManager(ctrl)
+			if tt.restored != "" {
+				// The partially restored instance is removed
+				initInstanceDir(t, fs, dataDirPath, tt.restored, `{"name": "mock-avs", "tag": "test"}`)
+				monitoringMgr.EXPECT().InstallationStatus().Return(common.NotInstalled, nil)
+				composeMgr.EXPECT().Down(compose.DockerComposeDownOptions{
+					Path:    filepath.Join(dataDirPath, "nodes", tt.restored, "docker-compose.yml"),
+					Volumes: true,
+				}).Return(nil)
+			}
+
+			daemon, err := NewEgnDaemon(dataDir, composeMgr, nil, monitoringMgr, backupMgr, nil)
+			require.NoError(t, err)
+
+			err = daemon.Restore(context.Background(), backupId, RestoreOptions{Tag: "test"})
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				var missingErr MissingSecretsError
+				if errors.As(err, &missingErr) {
+					require.Len(t, missingErr.Options, 1)
+					assert.Equal(t, "api-key", missingErr.Options[0].Name())
+				}
+				assert.False(t, dataDir.HasInstance("mock-avs-test"))
+				assert.True(t, dataDir.HasInstance("mock-avs-default"))
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, dataDir.HasInstance("mock-avs-default"))
+		})
+	}
+}
+
+func TestBackupImport(t *testing.T) {
+	dataDir, err := data.NewDataDir(t.TempDir(), afero.NewOsFs(), nil)
+	require.NoError(t, err)
+	daemon, err := NewEgnDaemon(dataDir, nil, nil, nil, nil, nil)
+	require.NoError(t, err)
+
+	// Create a backup tar file in another data dir
+	other, err := data.NewDataDir(t.TempDir(), afero.NewOsFs(), nil)
+	require.NoError(t, err)
+	b := &data.Backup{
+		InstanceId: "mock-avs-default",
+		Timestamp:  time.Unix(1696420902, 0),
+		Version:    common.MockAvsPkg.Version(),
+	}
+	require.NoError(t, other.InitBackup(b))
+	tarFile, err := os.Create(other.BackupPath(b.Id()))
+	require.NoError(t, err)
+	tarWriter := tar.NewWriter(tarFile)
+	for _, f := range []struct{ name, content string }{
+		{"data/state.json", `{"name": "mock-avs", "tag": "default", "version": "` + common.MockAvsPkg.Version() + `"}`},
+		{"timestamp", "1696420902"},
+	} {
+		require.NoError(t, tarWriter.WriteHeader(&tar.Header{Name: f.name, Size: int64(len(f.content)), Mode: 0o644}))
+		_, err = tarWriter.Write([]byte(f.content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tarWriter.Close())
+	require.NoError(t, tarFile.Close())
+
+	info, err := daemon.BackupImport(other.BackupPath(b.Id()))
+	require.NoError(t, err)
+	assert.Equal(t, b.Id(), info.Id)
+	assert.Equal(t, "mock-avs-default", info.Instance)
+	backups, err := daemon.BackupList()
+	require.NoError(t, err)
+	assert.Len(t, backups, 1)
+
+	_, err = daemon.BackupImport(other.BackupPath(b.Id()))
+	assert.ErrorIs(t, err, ErrBackupAlreadyExists)
+}
+
+func TestBackupExport(t *testing.T) {
+	dataDir, err := data.NewDataDir(t.TempDir(), afero.NewOsFs(), nil)
+	require.NoError(t, err)
+	daemon, err := NewEgnDaemon(dataDir, nil, nil, nil, nil, nil)
+	require.NoError(t, err)
+	backupId := initBackupTar(t, dataDir, "volume data")
+	content, err := os.ReadFile(dataDir.BackupPath(backupId))
+	require.NoError(t, err)
+
+	exported := filepath.Join(t.TempDir(), "backup.tar")
+	require.NoError(t, daemon.BackupExport(backupId, exported, ""))
+	got, err := os.ReadFile(exported)
+	require.NoError(t, err)
+	assert.Equal(t, content, got)
+
+	// The exported tar file is imported like any other backup tar file
+	other, err := data.NewDataDir(t.TempDir(), afero.NewOsFs(), nil)
+	require.NoError(t, err)
+	otherDaemon, err := NewEgnDaemon(other, nil, nil, nil, nil, nil)
+	require.NoError(t, err)
+	info, err := otherDaemon.BackupImport(exported)
+	require.NoError(t, err)
+	assert.Equal(t, backupId, info.Id)
+
+	err = daemon.BackupExport("mock-avs-default-0", exported, "")
+	assert.ErrorIs(t, err, ErrBackupNotFound)
+}
+
+// initBackupTar creates a backup of the mock-avs-default instance stored as a
+// single tar file, with the given volume file content and the checksums of
+// its files, and returns its id.
+func initBackupTar(t *testing.T, dataDir *data.DataDir, volume string) string {
+	t.Helper()
+	b := &data.Backup{
+		InstanceId: "mock-avs-default",
+		Timestamp:  time.Unix(1696420902, 0),
+		Version:    common.MockAvsPkg.Version(),
+	}
+	require.NoError(t, dataDir.InitBackup(b))
+	tarFile, err := os.Create(dataDir.BackupPath(b.Id()))
+	require.NoError(t, err)
+	tarWriter := dataDir.NewBackupTarWriter(tarFile)
+	for _, f := range []struct{ name, content string }{
+		{"data/state.json", `{"name": "mock-avs", "url": "` + common.MockAvsPkg.Repo() + `", "version": "` + common.MockAvsPkg.Version() + `", "profile": "option-returner", "tag": "default"}`},
+		{"timestamp", "1696420902"},
+		{"volumes/main-service/volumes-data.yml", "- id: data\n"},
+		{"volumes/main-service/data/file", volume},
+	} {
+		require.NoError(t, tarWriter.WriteHeader(&tar.Header{Name: f.name, Size: int64(len(f.content)), Mode: 0o644, Typeflag: tar.TypeReg}))
+		_, err = tarWriter.Write([]byte(f.content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tarWriter.Close())
+	require.NoError(t, tarFile.Close())
+	return b.Id()
+}
+
+func TestBackupVerify(t *testing.T) {
+	tc := []struct {
+		name       string
+		options    BackupVerifyOptions
+		corrupt    bool
+		restore    bool
+		restoreErr error
+		refuseErr  error
+		want       BackupVerification
+		wantErr    error
+	}{
+		{
+			name: "valid backup",
+			want: BackupVerification{Files: 4, Checksums: true},
+		},
+		{
+			name:    "corrupted backup",
+			corrupt: true,
+			wantErr: ErrCorruptedBackup,
+		},
+		{
+			name:    "trial restore",
+			options: BackupVerifyOptions{Restore: true},
+			restore: true,
+			want:    BackupVerification{Files: 4, Checksums: true, Restored: true},
+		},
+		{
+			name:       "trial restore fails",
+			options:    BackupVerifyOptions{Restore: true},
+			restore:    true,
+			restoreErr: assert.AnError,
+			wantErr:    ErrTrialRestoreFailed,
+		},
+		{
+			name:      "trial restore refused, shared resources",
+			options:   BackupVerifyOptions{Restore: true},
+			restore:   true,
+			refuseErr: fmt.Errorf("%w: volume mock-avs-data of service \"main-service\"", data.ErrSharedResources),
+			wantErr:   ErrSharedResources,
+		},
+		{
+			name:      "trial restore refused, missing secrets",
+			options:   BackupVerifyOptions{Restore: true},
+			restore:   true,
+			refuseErr: data.MissingSecretsError{Options: []profile.Option{{Name: "api-key", Target: "API_KEY", Type: "secret", Help: "API key"}}},
+			wantErr:   ErrMissingSecrets,
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			backupMgr := mocks.NewMockBackupManager(ctrl)
+			composeMgr := mocks.NewMockComposeManager(ctrl)
+			monitoringMgr := mocks.NewMockMonitoringManager(ctrl)
+			fs := afero.NewOsFs()
+			dataDirPath := t.TempDir()
+			dataDir, err := data.NewDataDir(dataDirPath, fs, nil)
+			require.NoError(t, err)
+
+			backupId := initBackupTar(t, dataDir, "volume data")
+			if tt.corrupt {
+				content, err := os.ReadFile(dataDir.BackupPath(backupId))
+				require.NoError(t, err)
+				content = bytes.Replace(content, []byte("volume data"), []byte("volume DATA"), 1)
+				require.NoError(t, os.WriteFile(dataDir.BackupPath(backupId), content, 0o644))
+			}
+			var restored string
+			if tt.refuseErr != nil {
+				// Nothing is created, so nothing is removed
+				backupMgr.EXPECT().RestoreInstance(gomock.Any(), backupId, gomock.Any()).
+					Return("", tt.refuseErr)
+			} else if tt.restore {
+				backupMgr.EXPECT().RestoreInstance(gomock.Any(), backupId, gomock.Any()).DoAndReturn(func(_ context.Context, backupId string, options backup.RestoreOptions) (string, error) {
+					assert.NotEmpty(t, options.Tag)
+					restored = data.InstanceId("mock-avs", options.Tag)
+					initInstanceDir(t, fs, dataDirPath, restored, `{"name": "mock-avs", "tag": "`+options.Tag+`"}`)
+					return restored, tt.restoreErr
+				})
+				monitoringMgr.EXPECT().InstallationStatus().Return(common.NotInstalled, nil)
+				composeMgr.EXPECT().Down(gomock.Any()).DoAndReturn(func(options compose.DockerComposeDownOptions) error {
+					assert.Equal(t, filepath.Join(dataDirPath, "nodes", restored, "docker-compose.yml"), options.Path)
+					assert.True(t, options.Volumes)
+					return nil
+				})
+			}
+
+			daemon, err := NewEgnDaemon(dataDir, composeMgr, nil, monitoringMgr, backupMgr, nil)
+			require.NoError(t, err)
+
+			got, err := daemon.BackupVerify(backupId, tt.options)
+			if tt.restore {
+				// The temporary instance is removed
+				assert.False(t, dataDir.HasInstance(restored))
+			}
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				if tt.restore {
+					assert.ErrorIs(t, err, ErrTrialRestoreFailed)
+				}
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("backup not found", func(t *testing.T) {
+		dataDir, err := data.NewDataDir(t.TempDir(), afero.NewOsFs(), nil)
+		require.NoError(t, err)
+		daemon, err := NewEgnDaemon(dataDir, nil, nil, nil, nil, nil)
+		require.NoError(t, err)
+		_, err = daemon.BackupVerify("backup-id", BackupVerifyOptions{})
+		assert.ErrorIs(t, err, ErrBackupNotFound)
+	})
+}
+
+func TestBackup_Progress(t *testing.T) {
+	fs := afero.NewOsFs()
+	dataDirPath := t.TempDir()
+	dataDir, err := data.NewDataDir(dataDirPath, fs, nil)
+	require.NoError(t, err)
+	initInstanceDir(t, fs, dataDirPath, "mock-avs-default", `{"name": "mock-avs", "tag": "default"}`)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ctrl := gomock.NewController(t)
+	backupMgr := mocks.NewMockBackupManager(ctrl)
+	backupMgr.EXPECT().BackupInstance(ctx, "mock-avs-default", gomock.Any()).DoAndReturn(func(_ context.Context, instanceId string, options backup.BackupOptions) (string, error) {
+		require.NotNil(t, options.Progress)
+		options.Progress(backup.Progress{Service: "main-service", Volume: "/data", Processed: 512, Total: 1024})
+		return "backup-id", nil
+	})
+	daemon, err := NewEgnDaemon(dataDir, nil, nil, nil, backupMgr, nil)
+	require.NoError(t, err)
+
+	var events []BackupProgress
+	backupId, err := daemon.Backup(ctx, "mock-avs-default", BackupOptions{
+		ConfigOnly: true,
+		Progress:   func(p BackupProgress) { events = append(events, p) },
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "backup-id", backupId)
+	assert.Equal(t, []BackupProgress{{Service: "main-service", Volume: "/data", Processed: 512, Total: 1024}}, events)
+}
+
+func TestBackup_MonitoringTargets(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	locker := mock_locker.NewMockLocker(ctrl)
+	locker.EXPECT().New(gomock.Any()).Return(locker).AnyTimes()
+	fs := afero.NewOsFs()
+	dataDirPath := t.TempDir()
+	dataDir, err := data.NewDataDir(dataDirPath, fs, locker)
+	require.NoError(t, err)
+	initInstanceDir(t, fs, dataDirPath, "mock-avs-default", `{"name": "mock-avs", "url": "`+common.MockAvsPkg.Repo()+`", "version": "`+common.MockAvsPkg.Version()+`", "profile": "option-returner", "tag": "default", "monitoring": {"targets": [{"service": "main-service", "port": "8090", "path": "/metrics"}]}}`)
+	composePath := filepath.Join(dataDirPath, "nodes", "mock-avs-default", "docker-compose.yml")
+
+	backupMgr := mocks.NewMockBackupManager(ctrl)
+	composeMgr := mocks.NewMockComposeManager(ctrl)
+	dockerMgr := mocks.NewMockDockerManager(ctrl)
+	monitoringMgr := mocks.NewMockMonitoringManager(ctrl)
+	gomock.InOrder(
+		backupMgr.EXPECT().BackupInstance(gomock.Any(), "mock-avs-default", gomock.Any()).Return("backup-id", nil),
+		composeMgr.EXPECT().PS(compose.DockerComposePsOptions{Path: composePath, Format: "json", FilterRunning: true}).
+			Return([]compose.ComposeService{{Id: "1", Service: "main-service"}}, nil),
+		// The target is removed first, as adding an existing target does not
+		// update its endpoint
+		monitoringMgr.EXPECT().InstallationStatus().Return(common.Installed, nil),
+		monitoringMgr.EXPECT().Status().Return(common.Running, nil),
+		monitoringMgr.EXPECT().RemoveTarget("mock-avs-default").Return(nil),
+		monitoringMgr.EXPECT().InstallationStatus().Return(common.Installed, nil),
+		monitoringMgr.EXPECT().Status().Return(common.Running, nil),
+		composeMgr.EXPECT().PS(compose.DockerComposePsOptions{Path: composePath, Format: "json", All: true}).
+			Return([]compose.ComposeService{{Id: "1", Service: "main-service"}}, nil),
+		dockerMgr.EXPECT().ContainerIP("1").Return("168.66.44.2", nil),
+		dockerMgr.EXPECT().ContainerNetworks("1").Return([]string{"eigenlayer"}, nil),
+		monitoringMgr.EXPECT().AddTarget(types.MonitoringTarget{
+			Host:    "168.66.44.2",
+			Port:    8090,
+			Path:    "/metrics",
+			Service: "main-service",
+		}, gomock.Any(), "eigenlayer").Return(nil),
+	)
+	daemon, err := NewEgnDaemon(dataDir, composeMgr, dockerMgr, monitoringMgr, backupMgr, nil)
+	require.NoError(t, err)
+
+	backupId, err := daemon.Backup(context.Background(), "mock-avs-default", BackupOptions{Consistency: data.BackupConsistencyStop})
+	require.NoError(t, err)
+	assert.Equal(t, "backup-id", backupId)
+}
+
+func TestBackupSchedules(t *testing.T) {
+	fs := afero.NewOsFs()
+	dataDirPath := t.TempDir()
+	dataDir, err := data.NewDataDir(dataDirPath, fs, nil)
+	require.NoError(t, err)
+	initInstanceDir(t, fs, dataDirPath, "mock-avs-default", `{"name": "mock-avs", "tag": "default"}`)
+	daemon, err := NewEgnDaemon(dataDir, nil, nil, nil, nil, nil)
+	require.NoError(t, err)
+
+	schedule := BackupSchedule{
+		Cron:        "0 3 * * *",
+		Consistency: data.BackupConsistencyPause,
+		Retention:   BackupRetention{KeepDaily: 7, KeepWeekly: 4},
+	}
+	err = daemon.SetBackupSchedule("mock-avs-second", schedule)
+	assert.ErrorIs(t, err, ErrInstanceNotFound)
+	err = daemon.SetBackupSchedule("mock-avs-default", BackupSchedule{Cron: "0 3 * *"})
+	assert.ErrorIs(t, err, data.ErrInvalidBackupSchedule)
+	emptyPassphraseFile := filepath.Join(t.TempDir(), "empty")
+	require.NoError(t, os.WriteFile(emptyPassphraseFile, []byte("\n"), 0o600))
+	err = daemon.SetBackupSchedule("mock-avs-default", BackupSchedule{Cron: "0 3 * * *", PassphraseFile: emptyPassphraseFile})
+	assert.ErrorIs(t, err, ErrEmptyBackupPassphrase)
+	err = daemon.SetBackupSchedule("mock-avs-default", BackupSchedule{Cron: "0 3 * * *", PassphraseFile: filepath.Join(t.TempDir(), "missing")})
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	schedule.PassphraseFile = filepath.Join(t.TempDir(), "passphrase")
+	require.NoError(t, os.WriteFile(schedule.PassphraseFile, []byte("secret\n"), 0o600))
+	require.NoError(t, daemon.SetBackupSchedule("mock-avs-default", schedule))
+
+	schedules, err := daemon.BackupSchedules()
+	require.NoError(t, err)
+	require.Len(t, schedules, 1)
+	assert.Equal(t, "mock-avs-default", schedules[0].InstanceId)
+	assert.Equal(t, schedule, schedules[0].BackupSchedule)
+	assert.True(t, schedules[0].LastRun.IsZero())
+	assert.True(t, schedules[0].NextRun.After(time.Now()))
+	assert.Equal(t, 3, schedules[0].NextRun.Hour())
+
+	require.NoError(t, daemon.RemoveBackupSchedule("mock-avs-default"))
+	err = daemon.RemoveBackupSchedule("mock-avs-default")
+	assert.ErrorIs(t, err, ErrBackupScheduleNotFound)
+	schedules, err = daemon.BackupSchedules()
+	require.NoError(t, err)
+	assert.Empty(t, schedules)
+}
+
+func TestPruneBackups(t *testing.T) {
+	dataDir, err := data.NewDataDir(t.TempDir(), afero.NewOsFs(), nil)
+	require.NoError(t, err)
+	var ids []string
+	for i := 0; i < 3; i++ {
+		ids = append(ids, initScheduledBackupManifestAt(t, dataDir, "mock-avs-default", time.Unix(1696420902+int64(i)*86400, 0)))
+	}
+	// Backups not created by the schedule are not pruned
+	manualId := initBackupManifestAt(t, dataDir, "mock-avs-default", time.Unix(1696420902-86400, 0))
+	otherId := initScheduledBackupManifestAt(t, dataDir, "mock-avs-second", time.Unix(1696420902, 0))
+	require.NoError(t, dataDir.SetBackupSchedule("mock-avs-default", &data.BackupSchedule{
+		Cron:      "@daily",
+		Retention: data.BackupRetention{KeepLast: 1},
+	}))
+	daemon, err := NewEgnDaemon(dataDir, nil, nil, nil, nil, nil)
+	require.NoError(t, err)
+
+	// Instance without schedule
+	_, err = daemon.PruneBackups(PruneBackupsOptions{InstanceIds: []string{"mock-avs-second"}})
+	assert.ErrorIs(t, err, ErrBackupScheduleNotFound)
+
+	// Dry run
+	pruned, err := daemon.PruneBackups(PruneBackupsOptions{DryRun: true})
+	require.NoError(t, err)
+	require.Len(t, pruned, 2)
+	assert.Equal(t, ids[1], pruned[0].Id)
+	assert.Equal(t, ids[0], pruned[1].Id)
+	backups, err := daemon.BackupList()
+	require.NoError(t, err)
+	assert.Len(t, backups, 5)
+
+	// Prune
+	pruned, err = daemon.PruneBackups(PruneBackupsOptions{})
+	require.NoError(t, err)
+	assert.Len(t, pruned, 2)
+	backups, err = daemon.BackupList()
+	require.NoError(t, err)
+	backupIds := make([]string, len(backups))
+	for i, b := range backups {
+		backupIds[i] = b.Id
+	}
+	assert.ElementsMatch(t, []string{ids[2], manualId, otherId}, backupIds)
+}
+
+func TestRunScheduledBackups(t *testing.T) {
+	fs := afero.NewOsFs()
+	dataDirPath := t.TempDir()
+	dataDir, err := data.NewDataDir(dataDirPath, fs, nil)
+	require.NoError(t, err)
+	initInstanceDir(t, fs, dataDirPath, "mock-avs-default", `{"name": "mock-avs", "tag": "default"}`)
+	initInstanceDir(t, fs, dataDirPath, "mock-avs-second", `{"name": "mock-avs", "tag": "second"}`)
+	initInstanceDir(t, fs, dataDirPath, "mock-avs-third", `{"name": "mock-avs", "tag": "third"}`)
+	initInstanceDir(t, fs, dataDirPath, "mock-avs-fourth", `{"name": "mock-avs", "tag": "fourth"}`)
+	oldId := initScheduledBackupManifestAt(t, dataDir, "mock-avs-default", time.Unix(1696420902, 0))
+
+	// Due
+	require.NoError(t, dataDir.SetBackupSchedule("mock-avs-default", &data.BackupSchedule{
+		Cron:        "@hourly",
+		Compression: data.CompressionGzip,
+		Consistency: data.BackupConsistencyPause,
+		Retention:   data.BackupRetention{KeepLast: 1},
+		CreatedAt:   time.Now().Add(-2 * time.Hour),
+	}))
+	// Due, but the backup fails
+	require.NoError(t, dataDir.SetBackupSchedule("mock-avs-second", &data.BackupSchedule{
+		Cron:      "@hourly",
+		CreatedAt: time.Now().Add(-2 * time.Hour),
+	}))
+	// Not due
+	require.NoError(t, dataDir.SetBackupSchedule("mock-avs-third", &data.BackupSchedule{
+		Cron:      "@yearly",
+		CreatedAt: time.Now(),
+	}))
+	// Due and encrypted
+	passphraseFile := filepath.Join(t.TempDir(), "passphrase")
+	require.NoError(t, os.WriteFile(passphraseFile, []byte("secret\n"), 0o600))
+	require.NoError(t, dataDir.SetBackupSchedule("mock-avs-fourth", &data.BackupSchedule{
+		Cron:           "@hourly",
+		PassphraseFile: passphraseFile,
+		CreatedAt:      time.Now().Add(-2 * time.Hour),
+	}))
+
+	ctrl := gomock.NewController(t)
+	backupMgr := mocks.NewMockBackupManager(ctrl)
+	var newId string
+	gomock.InOrder(
+		backupMgr.EXPECT().BackupInstance(gomock.Any(), "mock-avs-default", backup.BackupOptions{Compression: data.CompressionGzip, Consistency: data.BackupConsistencyPause, Scheduled: true}).DoAndReturn(func(_ context.Context, instanceId string, options backup.BackupOptions) (string, error) {
+			newId = initScheduledBackupManifestAt(t, dataDir, instanceId, time.Now())
+			return newId, nil
+		}),
+		backupMgr.EXPECT().BackupInstance(gomock.Any(), "mock-avs-fourth", backup.BackupOptions{Passphrase: "secret", Scheduled: true}).Return("encrypted-id", nil),
+		backupMgr.EXPECT().BackupInstance(gomock.Any(), "mock-avs-second", backup.BackupOptions{Scheduled: true}).Return("", assert.AnError),
+	)
+	daemon, err := NewEgnDaemon(dataDir, nil, nil, nil, backupMgr, nil)
+	require.NoError(t, err)
+
+	results, err := daemon.RunScheduledBackups()
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	assert.Equal(t, "mock-avs-default", results[0].InstanceId)
+	assert.Equal(t, newId, results[0].BackupId)
+	assert.NoError(t, results[0].Err)
+	require.Len(t, results[0].Pruned, 1)
+	assert.Equal(t, oldId, results[0].Pruned[0].Id)
+	assert.Equal(t, "mock-avs-fourth", results[1].InstanceId)
+	assert.Equal(t, "encrypted-id", results[1].BackupId)
+	assert.NoError(t, results[1].Err)
+	assert.Equal(t, "mock-avs-second", results[2].InstanceId)
+	assert.ErrorIs(t, results[2].Err, assert.AnError)
+
+	// The runs are recorded, so nothing is due anymore
+	schedules, err := daemon.BackupSchedules()
+	require.NoError(t, err)
+	require.Len(t, schedules, 4)
+	assert.False(t, schedules[0].LastRun.IsZero())
+	assert.False(t, schedules[1].LastRun.IsZero())
+	assert.False(t, schedules[2].LastRun.IsZero())
+	assert.True(t, schedules[3].LastRun.IsZero())
+	results, err = daemon.RunScheduledBackups()
+	require.NoError(t, err)
+	assert.Empty(t, results)
+}
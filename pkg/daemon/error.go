@@ -1,6 +1,9 @@
 package daemon
 
-import "errors"
+import (
+	"errors"
+	"strings"
+)
 
 var (
 	ErrInstanceAlreadyExists      = errors.New("instance already exists")
@@ -18,8 +21,44 @@ var (
 	ErrOptionNotSet               = errors.New("option not set")
 	ErrVersionAlreadyInstalled    = errors.New("version already installed")
 	ErrBackupNotFound             = errors.New("backup not found")
+	ErrBackupEncrypted            = errors.New("backup is encrypted, a passphrase is required")
+	ErrInvalidBackupPassphrase    = errors.New("invalid backup passphrase")
+	ErrEmptyBackupPassphrase      = errors.New("empty backup passphrase")
+	ErrBackupScheduleNotFound     = errors.New("backup schedule not found")
+	ErrBackupAlreadyExists        = errors.New("backup already exists")
+	ErrCorruptedBackup            = errors.New("corrupted backup")
+	ErrTrialRestoreFailed         = errors.New("trial restore failed")
+	ErrSharedResources            = errors.New("backup can not be restored with a new tag")
+	ErrMissingSecrets             = errors.New("secrets without value, they are not included in backups")
+	ErrIncompatibleUpgrade        = errors.New("incompatible upgrade")
+	ErrUnsupportedSpecVersion     = errors.New("unsupported AVS Node Specification version")
+	ErrUnknownOption              = errors.New("unknown option")
+	ErrSignatureRequired          = errors.New("package signature required")
+	ErrInvalidSignature           = errors.New("invalid package signature")
+	ErrInvalidPublicKey           = errors.New("invalid public key")
+	ErrHardwareRequirementsNotMet = errors.New("hardware requirements not met")
+	ErrInvalidOptionDefault       = errors.New("invalid option default")
 )
 
+// MissingSecretsError is returned when a backup is restored as an instance
+// whose active secret options have no stored value, like when the backup is
+// restored on another host, as secrets are not included in backups.
+type MissingSecretsError struct {
+	Options []Option
+}
+
+func (e MissingSecretsError) Error() string {
+	names := make([]string, len(e.Options))
+	for i, o := range e.Options {
+		names[i] = o.Name()
+	}
+	return ErrMissingSecrets.Error() + ": " + strings.Join(names, ", ")
+}
+
+func (e MissingSecretsError) Unwrap() error {
+	return ErrMissingSecrets
+}
+
 // InvalidOptionValueError is returned when an Option's value is invalid.
 type InvalidOptionValueError struct {
 	optionName string
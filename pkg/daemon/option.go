@@ -2,13 +2,16 @@ package daemon
 
 import (
 	"fmt"
+	"math"
 	"net/url"
 	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/NethermindEth/eigenlayer/internal/profile"
+	"github.com/NethermindEth/eigenlayer/internal/utils"
 )
 
 // This pattern matches Unix-like paths, both absolute and relative
@@ -32,14 +35,37 @@ type Option interface {
 	Target() string
 	// IsSet returns true if the option has been set.
 	IsSet() bool
+	// Active returns true if the option should be asked, given the values of
+	// the other options by name. Inactive options keep their default value.
+	Active(values map[string]string) bool
 }
 
 // option is a struct representing a generic profile option. It includes fields for the option's name, target, and help text.
 type option struct {
-	name   string
-	target string
-	help   string
-	hidden bool
+	name      string
+	target    string
+	help      string
+	hidden    bool
+	condition profile.Condition
+	// renamedFrom and valueMap are the migration rules of the option value
+	// from previous versions.
+	renamedFrom []string
+	valueMap    map[string]string
+}
+
+func (o *option) Active(values map[string]string) bool {
+	return o.condition.Eval(values)
+}
+
+// base returns the fields shared by all the option types.
+func (o *option) base() *option {
+	return o
+}
+
+// optionBase is implemented by all the option types, giving access to their
+// shared fields.
+type optionBase interface {
+	base() *option
 }
 
 // OptionInt is a struct representing an integer option. It implements the Option interface.
@@ -47,16 +73,26 @@ type OptionInt struct {
 	option
 	value    *int
 	defValue int
-	validate bool
-	MinValue int
-	MaxValue int
+	// defTemplate is the default value when it is a template, rendered on
+	// install.
+	defTemplate string
+	validate    bool
+	MinValue    int
+	MaxValue    int
 }
 
 // NewOptionInt creates a new OptionInt from a profile.Option.
 func NewOptionInt(pkgOption profile.Option) (*OptionInt, error) {
-	defaultValue, err := strconv.Atoi(pkgOption.Default)
-	if err != nil {
-		return nil, err
+	var defaultValue int
+	var defTemplate string
+	if profile.IsTemplate(pkgOption.Default) {
+		defTemplate = pkgOption.Default
+	} else {
+		var err error
+		defaultValue, err = strconv.Atoi(pkgOption.Default)
+		if err != nil {
+			return nil, err
+		}
 	}
 	o := &OptionInt{
 		option: option{
@@ -65,12 +101,19 @@ func NewOptionInt(pkgOption profile.Option) (*OptionInt, error) {
 			help:   pkgOption.Help,
 			hidden: pkgOption.Hidden,
 		},
-		defValue: defaultValue,
-		validate: pkgOption.ValidateDef != nil,
+		defValue:    defaultValue,
+		defTemplate: defTemplate,
+		validate:    pkgOption.ValidateDef != nil,
 	}
 	if o.validate {
-		o.MinValue = int(*pkgOption.ValidateDef.MinValue)
-		o.MaxValue = int(*pkgOption.ValidateDef.MaxValue)
+		// Missing limits don't restrict the value
+		o.MinValue, o.MaxValue = math.MinInt, math.MaxInt
+		if pkgOption.ValidateDef.MinValue != nil {
+			o.MinValue = int(*pkgOption.ValidateDef.MinValue)
+		}
+		if pkgOption.ValidateDef.MaxValue != nil {
+			o.MaxValue = int(*pkgOption.ValidateDef.MaxValue)
+		}
 	}
 	return o, nil
 }
@@ -132,6 +175,9 @@ func (oi *OptionInt) Value() (string, error) {
 }
 
 func (oi *OptionInt) Default() string {
+	if oi.defTemplate != "" {
+		return oi.defTemplate
+	}
 	return strconv.Itoa(oi.defValue)
 }
 
@@ -148,15 +194,25 @@ type OptionFloat struct {
 	option
 	value    *float64
 	defValue float64
-	validate bool
-	MinValue float64
-	MaxValue float64
+	// defTemplate is the default value when it is a template, rendered on
+	// install.
+	defTemplate string
+	validate    bool
+	MinValue    float64
+	MaxValue    float64
 }
 
 func NewOptionFloat(pkgOption profile.Option) (*OptionFloat, error) {
-	defaultValue, err := strconv.ParseFloat(pkgOption.Default, 64)
-	if err != nil {
-		return nil, err
+	var defaultValue float64
+	var defTemplate string
+	if profile.IsTemplate(pkgOption.Default) {
+		defTemplate = pkgOption.Default
+	} else {
+		var err error
+		defaultValue, err = strconv.ParseFloat(pkgOption.Default, 64)
+		if err != nil {
+			return nil, err
+		}
 	}
 	o := &OptionFloat{
 		option: option{
@@ -165,12 +221,19 @@ func NewOptionFloat(pkgOption profile.Option) (*OptionFloat, error) {
 			help:   pkgOption.Help,
 			hidden: pkgOption.Hidden,
 		},
-		defValue: defaultValue,
-		validate: pkgOption.ValidateDef != nil,
+		defValue:    defaultValue,
+		defTemplate: defTemplate,
+		validate:    pkgOption.ValidateDef != nil,
 	}
 	if o.validate {
-		o.MinValue = *pkgOption.ValidateDef.MinValue
-		o.MaxValue = *pkgOption.ValidateDef.MaxValue
+		// Missing limits don't restrict the value
+		o.MinValue, o.MaxValue = -math.MaxFloat64, math.MaxFloat64
+		if pkgOption.ValidateDef.MinValue != nil {
+			o.MinValue = *pkgOption.ValidateDef.MinValue
+		}
+		if pkgOption.ValidateDef.MaxValue != nil {
+			o.MaxValue = *pkgOption.ValidateDef.MaxValue
+		}
 	}
 	return o, nil
 }
@@ -225,6 +288,9 @@ func (of *OptionFloat) Value() (string, error) {
 }
 
 func (of *OptionFloat) Default() string {
+	if of.defTemplate != "" {
+		return of.defTemplate
+	}
 	return strconv.FormatFloat(of.defValue, 'f', -1, 64)
 }
 
@@ -241,12 +307,22 @@ type OptionBool struct {
 	option
 	value    *bool
 	defValue bool
+	// defTemplate is the default value when it is a template, rendered on
+	// install.
+	defTemplate string
 }
 
 func NewOptionBool(pkgOption profile.Option) (*OptionBool, error) {
-	defaultValue, err := strconv.ParseBool(pkgOption.Default)
-	if err != nil {
-		return nil, err
+	var defaultValue bool
+	var defTemplate string
+	if profile.IsTemplate(pkgOption.Default) {
+		defTemplate = pkgOption.Default
+	} else {
+		var err error
+		defaultValue, err = strconv.ParseBool(pkgOption.Default)
+		if err != nil {
+			return nil, err
+		}
 	}
 	return &OptionBool{
 		option: option{
@@ -255,7 +331,8 @@ func NewOptionBool(pkgOption profile.Option) (*OptionBool, error) {
 			help:   pkgOption.Help,
 			hidden: pkgOption.Hidden,
 		},
-		defValue: defaultValue,
+		defValue:    defaultValue,
+		defTemplate: defTemplate,
 	}, nil
 }
 
@@ -290,6 +367,9 @@ func (ob *OptionBool) Value() (string, error) {
 }
 
 func (ob *OptionBool) Default() string {
+	if ob.defTemplate != "" {
+		return ob.defTemplate
+	}
 	return strconv.FormatBool(ob.defValue)
 }
 
@@ -384,6 +464,98 @@ func (os *OptionString) IsSet() bool {
 	return os.value != nil
 }
 
+// OptionSecret is a struct representing a secret option, like an API key or a
+// keystore password. Its value is always hidden, and it is not stored in the
+// instance .env file but in the secret store. It implements the Option interface.
+type OptionSecret struct {
+	option
+	value    *string
+	validate bool
+	Re2Regex string
+}
+
+func NewOptionSecret(pkgOption profile.Option) *OptionSecret {
+	o := &OptionSecret{
+		option: option{
+			name:   pkgOption.Name,
+			target: pkgOption.Target,
+			help:   pkgOption.Help,
+			hidden: true,
+		},
+		validate: pkgOption.ValidateDef != nil,
+	}
+	if o.validate {
+		o.Re2Regex = pkgOption.ValidateDef.Re2Regex
+	}
+	return o
+}
+
+var _ Option = (*OptionSecret)(nil)
+
+func (osec *OptionSecret) Name() string {
+	return osec.option.name
+}
+
+func (osec *OptionSecret) Help() string {
+	return osec.option.help
+}
+
+func (osec *OptionSecret) Hidden() bool {
+	return true
+}
+
+func (osec *OptionSecret) Set(value string) error {
+	if value == "" {
+		return InvalidOptionValueError{
+			optionName: osec.name,
+			msg:        "secret is empty",
+			hidden:     true,
+		}
+	}
+	if osec.Re2Regex != "" {
+		regex, err := regexp.Compile(osec.Re2Regex)
+		if err != nil {
+			return InvalidRegexError{
+				regex: osec.Re2Regex,
+			}
+		}
+		if !regex.MatchString(value) {
+			return InvalidOptionValueError{
+				optionName: osec.name,
+				msg:        "does not match with regex: " + osec.Re2Regex,
+				hidden:     true,
+			}
+		}
+	}
+	osec.value = &value
+	return nil
+}
+
+func (osec *OptionSecret) Value() (string, error) {
+	if osec.IsSet() {
+		return *osec.value, nil
+	}
+	return "", ErrOptionNotSet
+}
+
+func (osec *OptionSecret) Default() string {
+	return ""
+}
+
+func (osec *OptionSecret) Target() string {
+	return osec.target
+}
+
+func (osec *OptionSecret) IsSet() bool {
+	return osec.value != nil
+}
+
+// isSecret returns true if the given option is a secret option.
+func isSecret(o Option) bool {
+	_, ok := o.(*OptionSecret)
+	return ok
+}
+
 // OptionPathDir is a struct representing a directory path option. It implements the Option interface.
 type OptionPathDir struct {
 	option
@@ -703,12 +875,22 @@ type OptionPort struct {
 	option
 	value    *int
 	defValue int
+	// defTemplate is the default value when it is a template, rendered on
+	// install.
+	defTemplate string
 }
 
 func NewOptionPort(pkgOption profile.Option) (*OptionPort, error) {
-	defaultValue, err := strconv.Atoi(pkgOption.Default)
-	if err != nil {
-		return nil, err
+	var defaultValue int
+	var defTemplate string
+	if profile.IsTemplate(pkgOption.Default) {
+		defTemplate = pkgOption.Default
+	} else {
+		var err error
+		defaultValue, err = strconv.Atoi(pkgOption.Default)
+		if err != nil {
+			return nil, err
+		}
 	}
 	return &OptionPort{
 		option: option{
@@ -717,7 +899,8 @@ func NewOptionPort(pkgOption profile.Option) (*OptionPort, error) {
 			help:   pkgOption.Help,
 			hidden: pkgOption.Hidden,
 		},
-		defValue: defaultValue,
+		defValue:    defaultValue,
+		defTemplate: defTemplate,
 	}, nil
 }
 
@@ -767,6 +950,9 @@ func (op *OptionPort) Value() (string, error) {
 }
 
 func (op *OptionPort) Default() string {
+	if op.defTemplate != "" {
+		return op.defTemplate
+	}
 	return strconv.Itoa(op.defValue)
 }
 
@@ -777,3 +963,283 @@ func (op *OptionPort) Target() string {
 func (op *OptionPort) IsSet() bool {
 	return op.value != nil
 }
+
+// OptionEthAddress is a struct representing an Ethereum address option. It implements the Option interface.
+type OptionEthAddress struct {
+	option
+	value    *string
+	defValue string
+}
+
+func NewOptionEthAddress(pkgOption profile.Option) *OptionEthAddress {
+	return &OptionEthAddress{
+		option: option{
+			name:   pkgOption.Name,
+			target: pkgOption.Target,
+			help:   pkgOption.Help,
+			hidden: pkgOption.Hidden,
+		},
+		defValue: pkgOption.Default,
+	}
+}
+
+var _ Option = (*OptionEthAddress)(nil)
+
+func (oa *OptionEthAddress) Name() string {
+	return oa.option.name
+}
+
+func (oa *OptionEthAddress) Help() string {
+	return fmt.Sprintf("%s (Ethereum address)", oa.option.help)
+}
+
+func (oa *OptionEthAddress) Hidden() bool {
+	return oa.hidden
+}
+
+func (oa *OptionEthAddress) Set(value string) error {
+	if !utils.IsEthAddress(value) {
+		return InvalidOptionValueError{
+			optionName: oa.name,
+			value:      value,
+			msg:        "it is not a valid Ethereum address. Mixed case addresses must have a valid checksum",
+			hidden:     oa.hidden,
+		}
+	}
+	oa.value = &value
+	return nil
+}
+
+func (oa *OptionEthAddress) Value() (string, error) {
+	if oa.IsSet() {
+		return *oa.value, nil
+	}
+	return "", ErrOptionNotSet
+}
+
+func (oa *OptionEthAddress) Default() string {
+	return oa.defValue
+}
+
+func (oa *OptionEthAddress) Target() string {
+	return oa.target
+}
+
+func (oa *OptionEthAddress) IsSet() bool {
+	return oa.value != nil
+}
+
+// OptionDuration is a struct representing a duration option, like 30s or 1h30m. It implements the Option interface.
+type OptionDuration struct {
+	option
+	value    *string
+	defValue string
+}
+
+func NewOptionDuration(pkgOption profile.Option) *OptionDuration {
+	return &OptionDuration{
+		option: option{
+			name:   pkgOption.Name,
+			target: pkgOption.Target,
+			help:   pkgOption.Help,
+			hidden: pkgOption.Hidden,
+		},
+		defValue: pkgOption.Default,
+	}
+}
+
+var _ Option = (*OptionDuration)(nil)
+
+func (od *OptionDuration) Name() string {
+	return od.option.name
+}
+
+func (od *OptionDuration) Help() string {
+	return fmt.Sprintf("%s (duration, like 30s or 1h30m)", od.option.help)
+}
+
+func (od *OptionDuration) Hidden() bool {
+	return od.hidden
+}
+
+func (od *OptionDuration) Set(value string) error {
+	if _, err := time.ParseDuration(value); err != nil {
+		return InvalidOptionValueError{
+			optionName: od.name,
+			value:      value,
+			msg:        "it is not a valid duration, like 30s or 1h30m",
+			hidden:     od.hidden,
+		}
+	}
+	od.value = &value
+	return nil
+}
+
+func (od *OptionDuration) Value() (string, error) {
+	if od.IsSet() {
+		return *od.value, nil
+	}
+	return "", ErrOptionNotSet
+}
+
+func (od *OptionDuration) Default() string {
+	return od.defValue
+}
+
+func (od *OptionDuration) Target() string {
+	return od.target
+}
+
+func (od *OptionDuration) IsSet() bool {
+	return od.value != nil
+}
+
+// OptionByteSize is a struct representing a size in bytes option, like 512MB or 10GiB. It implements the Option interface.
+type OptionByteSize struct {
+	option
+	value    *string
+	defValue string
+}
+
+func NewOptionByteSize(pkgOption profile.Option) *OptionByteSize {
+	return &OptionByteSize{
+		option: option{
+			name:   pkgOption.Name,
+			target: pkgOption.Target,
+			help:   pkgOption.Help,
+			hidden: pkgOption.Hidden,
+		},
+		defValue: pkgOption.Default,
+	}
+}
+
+var _ Option = (*OptionByteSize)(nil)
+
+func (ob *OptionByteSize) Name() string {
+	return ob.option.name
+}
+
+func (ob *OptionByteSize) Help() string {
+	return fmt.Sprintf("%s (size, like 512MB or 10GiB)", ob.option.help)
+}
+
+func (ob *OptionByteSize) Hidden() bool {
+	return ob.hidden
+}
+
+func (ob *OptionByteSize) Set(value string) error {
+	if _, err := utils.ParseByteSize(value); err != nil {
+		return InvalidOptionValueError{
+			optionName: ob.name,
+			value:      value,
+			msg:        "it is not a valid size, like 512MB or 10GiB",
+			hidden:     ob.hidden,
+		}
+	}
+	ob.value = &value
+	return nil
+}
+
+func (ob *OptionByteSize) Value() (string, error) {
+	if ob.IsSet() {
+		return *ob.value, nil
+	}
+	return "", ErrOptionNotSet
+}
+
+func (ob *OptionByteSize) Default() string {
+	return ob.defValue
+}
+
+func (ob *OptionByteSize) Target() string {
+	return ob.target
+}
+
+func (ob *OptionByteSize) IsSet() bool {
+	return ob.value != nil
+}
+
+// OptionList is a struct representing a comma separated list option, where each
+// item is validated as an option of the item type. It implements the Option interface.
+type OptionList struct {
+	option
+	value    *string
+	defValue string
+	item     profile.Option
+}
+
+// NewOptionList creates a new OptionList from a profile.Option.
+func NewOptionList(pkgOption profile.Option) (*OptionList, error) {
+	item := pkgOption.ListItem()
+	if !utils.Contains(profile.ListItemTypes, item.Type) {
+		return nil, fmt.Errorf("unsupported list item type: %s", item.Type)
+	}
+	return &OptionList{
+		option: option{
+			name:   pkgOption.Name,
+			target: pkgOption.Target,
+			help:   pkgOption.Help,
+			hidden: pkgOption.Hidden,
+		},
+		defValue: pkgOption.Default,
+		item:     item,
+	}, nil
+}
+
+var _ Option = (*OptionList)(nil)
+
+func (ol *OptionList) Name() string {
+	return ol.option.name
+}
+
+func (ol *OptionList) Help() string {
+	return fmt.Sprintf("%s (comma separated list of %s)", ol.option.help, ol.item.Type)
+}
+
+func (ol *OptionList) Hidden() bool {
+	return ol.hidden
+}
+
+func (ol *OptionList) Set(value string) error {
+	items := utils.SplitList(value)
+	for i, v := range items {
+		// The item option is created with the item as default, because some
+		// option types require a valid default value.
+		itemDef := ol.item
+		itemDef.Default = v
+		item, err := newOption(itemDef)
+		if err == nil {
+			err = item.Set(v)
+		}
+		if err != nil {
+			return InvalidOptionValueError{
+				optionName: ol.name,
+				value:      value,
+				msg:        fmt.Sprintf("item %d is not a valid %s", i+1, ol.item.Type),
+				hidden:     ol.hidden,
+			}
+		}
+	}
+	value = strings.Join(items, ",")
+	ol.value = &value
+	return nil
+}
+
+func (ol *OptionList) Value() (string, error) {
+	if ol.IsSet() {
+		return *ol.value, nil
+	}
+	return "", ErrOptionNotSet
+}
+
+func (ol *OptionList) Default() string {
+	return ol.defValue
+}
+
+func (ol *OptionList) Target() string {
+	return ol.target
+}
+
+func (ol *OptionList) IsSet() bool {
+	return ol.value != nil
+}
@@ -0,0 +1,251 @@
+package daemon
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/NethermindEth/eigenlayer/internal/profile"
+)
+
+func TestOptionTypes(t *testing.T) {
+	tests := []struct {
+		name    string
+		option  profile.Option
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "eth_address",
+			option: profile.Option{Type: "eth_address"},
+			value:  "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+			want:   "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+		},
+		{
+			name:    "eth_address, invalid checksum",
+			option:  profile.Option{Type: "eth_address"},
+			value:   "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD",
+			wantErr: true,
+		},
+		{
+			name:   "duration",
+			option: profile.Option{Type: "duration"},
+			value:  "1h30m",
+			want:   "1h30m",
+		},
+		{
+			name:    "duration, invalid",
+			option:  profile.Option{Type: "duration"},
+			value:   "30",
+			wantErr: true,
+		},
+		{
+			name:   "byte_size",
+			option: profile.Option{Type: "byte_size"},
+			value:  "10GiB",
+			want:   "10GiB",
+		},
+		{
+			name:    "byte_size, invalid",
+			option:  profile.Option{Type: "byte_size"},
+			value:   "10 gigabytes",
+			wantErr: true,
+		},
+		{
+			name:   "list of str",
+			option: profile.Option{Type: "list"},
+			value:  "a, b ,c",
+			want:   "a,b,c",
+		},
+		{
+			name: "list of uri",
+			option: profile.Option{
+				Type:        "list",
+				ValidateDef: &profile.Validate{ItemType: "uri", UriScheme: []string{"https"}},
+			},
+			value: "https://rpc-1.example.com,https://rpc-2.example.com",
+			want:  "https://rpc-1.example.com,https://rpc-2.example.com",
+		},
+		{
+			name: "list of uri, invalid scheme",
+			option: profile.Option{
+				Type:        "list",
+				ValidateDef: &profile.Validate{ItemType: "uri", UriScheme: []string{"https"}},
+			},
+			value:   "https://rpc-1.example.com,http://rpc-2.example.com",
+			wantErr: true,
+		},
+		{
+			name: "list of int",
+			option: profile.Option{
+				Type:        "list",
+				ValidateDef: &profile.Validate{ItemType: "int"},
+			},
+			value: "1,2,3",
+			want:  "1,2,3",
+		},
+		{
+			name: "list of int, invalid item",
+			option: profile.Option{
+				Type:        "list",
+				ValidateDef: &profile.Validate{ItemType: "int"},
+			},
+			value:   "1,two,3",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.option.Name = "option"
+			tt.option.Target = "OPTION"
+			o, err := optionFromProfileOption(tt.option)
+			require.NoError(t, err)
+			err = o.Set(tt.value)
+			if tt.wantErr {
+				assert.ErrorAs(t, err, &InvalidOptionValueError{})
+				assert.False(t, o.IsSet())
+			} else {
+				require.NoError(t, err)
+				got, err := o.Value()
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestNewOptionList_InvalidItemType(t *testing.T) {
+	_, err := NewOptionList(profile.Option{
+		Name:        "option",
+		Type:        "list",
+		ValidateDef: &profile.Validate{ItemType: "secret"},
+	})
+	assert.Error(t, err)
+}
+
+func TestOptionActive(t *testing.T) {
+	o, err := optionFromProfileOption(profile.Option{
+		Name:      "rpc-url",
+		Type:      "uri",
+		DependsOn: []string{"external-rpc"},
+		When:      "network == mainnet",
+	})
+	require.NoError(t, err)
+	assert.True(t, o.Active(map[string]string{"external-rpc": "true", "network": "mainnet"}))
+	assert.False(t, o.Active(map[string]string{"external-rpc": "false", "network": "mainnet"}))
+	assert.False(t, o.Active(map[string]string{"external-rpc": "true", "network": "holesky"}))
+
+	_, err = optionFromProfileOption(profile.Option{Name: "rpc-url", Type: "uri", When: "network = mainnet"})
+	assert.ErrorIs(t, err, profile.ErrInvalidCondition)
+}
+
+func TestMergeOptions_Conditional(t *testing.T) {
+	newOption := func(o profile.Option) Option {
+		option, err := optionFromProfileOption(o)
+		require.NoError(t, err)
+		return option
+	}
+	network := profile.Option{
+		Name:        "network",
+		Type:        "select",
+		Default:     "holesky",
+		ValidateDef: &profile.Validate{Options: []string{"holesky", "mainnet"}},
+	}
+	oldNetwork := newOption(network)
+	require.NoError(t, oldNetwork.Set("holesky"))
+
+	merged, _, err := mergeOptions([]Option{oldNetwork}, []Option{
+		newOption(network),
+		// Inactive, takes its default value
+		newOption(profile.Option{Name: "rpc-url", Type: "uri", Default: "http://localhost:8545", When: "network == mainnet"}),
+		// Active, must be filled by the user
+		newOption(profile.Option{Name: "rpc-port", Type: "port", Default: "8545", When: "network == holesky"}),
+	})
+	require.NoError(t, err)
+	require.Len(t, merged, 3)
+
+	assert.True(t, merged[1].IsSet())
+	v, err := merged[1].Value()
+	require.NoError(t, err)
+	assert.Equal(t, "http://localhost:8545", v)
+	assert.False(t, merged[2].IsSet())
+}
+
+func TestSetOldOptionValues(t *testing.T) {
+	newOption := func(o profile.Option) Option {
+		option, err := optionFromProfileOption(o)
+		require.NoError(t, err)
+		return option
+	}
+	tests := []struct {
+		name    string
+		values  map[string]string
+		wantErr error
+	}{
+		{
+			name:   "all values",
+			values: map[string]string{"PORT": "8080", "API_KEY": "secret"},
+		},
+		{
+			name:   "secret not stored",
+			values: map[string]string{"PORT": "8080"},
+		},
+		{
+			name:    "option without value",
+			values:  map[string]string{"API_KEY": "secret"},
+			wantErr: ErrOptionWithoutValue,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := []Option{
+				newOption(profile.Option{Name: "port", Target: "PORT", Type: "port", Default: "8080"}),
+				newOption(profile.Option{Name: "api-key", Target: "API_KEY", Type: "secret"}),
+			}
+			err := setOldOptionValues(options, tt.values)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			_, stored := tt.values["API_KEY"]
+			assert.Equal(t, stored, options[1].IsSet())
+
+			merged, changes, err := mergeOptions(options, []Option{
+				newOption(profile.Option{Name: "port", Target: "PORT", Type: "port", Default: "8080"}),
+				newOption(profile.Option{Name: "api-key", Target: "API_KEY", Type: "secret"}),
+			})
+			require.NoError(t, err)
+			require.Len(t, changes, 2)
+			assert.Equal(t, stored, merged[1].IsSet())
+			if stored {
+				assert.Equal(t, OptionKept, changes[1].Kind)
+			} else {
+				assert.Equal(t, OptionNew, changes[1].Kind)
+			}
+		})
+	}
+}
+
+func TestSetOldOptionValues_Inactive(t *testing.T) {
+	newOption := func(o profile.Option) Option {
+		option, err := optionFromProfileOption(o)
+		require.NoError(t, err)
+		return option
+	}
+	metrics := profile.Option{Name: "metrics", Target: "METRICS", Type: "bool", Default: "false"}
+	metricsURL := profile.Option{Name: "metrics-url", Target: "METRICS_URL", Type: "uri", DependsOn: []string{"metrics"}}
+	options := []Option{newOption(metrics), newOption(metricsURL)}
+
+	// Inactive options without default are written empty to the env file
+	err := setOldOptionValues(options, map[string]string{"METRICS": "false", "METRICS_URL": ""})
+	require.NoError(t, err)
+	assert.False(t, options[1].IsSet())
+
+	merged, changes, err := mergeOptions(options, []Option{newOption(metrics), newOption(metricsURL)})
+	require.NoError(t, err)
+	assert.False(t, merged[1].IsSet())
+	assert.Equal(t, OptionNew, changes[1].Kind)
+}
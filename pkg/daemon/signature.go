@@ -0,0 +1,75 @@
+package daemon
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+)
+
+// ParsePublicKey parses an ed25519 public key trusted to sign packages. The key
+// could be PEM encoded in PKIX format, as generated by
+// `openssl pkey -pubout`, or the base64 encoding of the raw 32 bytes key.
+func ParsePublicKey(data []byte) (ed25519.PublicKey, error) {
+	if block, _ := pem.Decode(data); block != nil {
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidPublicKey, err)
+		}
+		edKey, ok := key.(ed25519.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("%w: key is not an ed25519 key", ErrInvalidPublicKey)
+		}
+		return edKey, nil
+	}
+	raw, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(data)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidPublicKey, err)
+	}
+	if len(raw) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("%w: invalid key size %d", ErrInvalidPublicKey, len(raw))
+	}
+	return ed25519.PublicKey(raw), nil
+}
+
+// ParseSignature parses a detached ed25519 signature of a package tarball. The
+// signature could be the raw 64 bytes, as generated by
+// `openssl pkeyutl -sign -rawin`, or their base64 encoding.
+func ParseSignature(data []byte) ([]byte, error) {
+	if len(data) == ed25519.SignatureSize {
+		return data, nil
+	}
+	raw, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(data)))
+	if err != nil || len(raw) != ed25519.SignatureSize {
+		return nil, fmt.Errorf("%w: malformed signature", ErrInvalidSignature)
+	}
+	return raw, nil
+}
+
+// KeyFingerprint returns the fingerprint of the given public key, with format
+// `sha256:<hex>`.
+func KeyFingerprint(key ed25519.PublicKey) string {
+	sum := sha256.Sum256(key)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// verifySignature checks that the signature of the given content was made by
+// one of the trusted keys, returning the fingerprint of the signing key.
+func verifySignature(content, signature []byte, trustedKeys []ed25519.PublicKey) (string, error) {
+	if len(signature) == 0 {
+		return "", ErrSignatureRequired
+	}
+	if len(trustedKeys) == 0 {
+		return "", fmt.Errorf("%w: no trusted keys", ErrInvalidSignature)
+	}
+	for _, key := range trustedKeys {
+		if ed25519.Verify(key, content, signature) {
+			return KeyFingerprint(key), nil
+		}
+	}
+	return "", fmt.Errorf("%w: package is not signed by any trusted key", ErrInvalidSignature)
+}
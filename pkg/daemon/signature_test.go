@@ -0,0 +1,90 @@
+package daemon
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePublicKey(t *testing.T) {
+	pubKey, _, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(pubKey)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr error
+	}{
+		{
+			name: "PEM",
+			data: pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}),
+		},
+		{
+			name: "base64",
+			data: []byte(base64.StdEncoding.EncodeToString(pubKey) + "\n"),
+		},
+		{
+			name:    "invalid size",
+			data:    []byte(base64.StdEncoding.EncodeToString(pubKey[:16])),
+			wantErr: ErrInvalidPublicKey,
+		},
+		{
+			name:    "invalid PEM",
+			data:    pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: []byte("invalid")}),
+			wantErr: ErrInvalidPublicKey,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := ParsePublicKey(tt.data)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, pubKey, key)
+			}
+		})
+	}
+}
+
+func TestParseSignature(t *testing.T) {
+	_, privKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	signature := ed25519.Sign(privKey, []byte("package"))
+
+	got, err := ParseSignature(signature)
+	require.NoError(t, err)
+	assert.Equal(t, signature, got)
+
+	got, err = ParseSignature([]byte(base64.StdEncoding.EncodeToString(signature)))
+	require.NoError(t, err)
+	assert.Equal(t, signature, got)
+
+	_, err = ParseSignature([]byte("invalid"))
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+}
+
+func TestVerifySignature(t *testing.T) {
+	pubKey, privKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	content := []byte("package")
+	signature := ed25519.Sign(privKey, content)
+
+	signedBy, err := verifySignature(content, signature, []ed25519.PublicKey{pubKey})
+	require.NoError(t, err)
+	assert.Equal(t, KeyFingerprint(pubKey), signedBy)
+
+	_, err = verifySignature([]byte("tampered"), signature, []ed25519.PublicKey{pubKey})
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+	_, err = verifySignature(content, nil, []ed25519.PublicKey{pubKey})
+	assert.ErrorIs(t, err, ErrSignatureRequired)
+	_, err = verifySignature(content, signature, nil)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+}
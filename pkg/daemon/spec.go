@@ -0,0 +1,84 @@
+package daemon
+
+import (
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/mod/semver"
+
+	"github.com/NethermindEth/eigenlayer/internal/common"
+	"github.com/NethermindEth/eigenlayer/internal/package_handler"
+)
+
+// packageSpecVersion returns the version of the AVS Node Specification the package
+// targets, negotiated against the versions supported by egn.
+func packageSpecVersion(pkgHandler *package_handler.PackageHandler) (string, error) {
+	pkgVersions, err := pkgHandler.SpecVersions()
+	if err != nil {
+		return "", err
+	}
+	return negotiateSpecVersion(pkgVersions, common.SupportedSpecVersions)
+}
+
+// negotiateSpecVersion selects the spec version to use from the versions targeted
+// by a package and the versions supported by egn, following the next rules:
+//
+//  1. The package does not declare any version: a warning is logged and the
+//     latest supported version is used.
+//
+//  2. Some of the package versions are supported: the greatest of them is used.
+//
+//  3. Some of the package versions are compatible with a supported version,
+//     meaning they have the same major version (or the same major and minor
+//     versions for v0 versions): a warning is logged and the greatest compatible
+//     package version is used.
+//
+//  4. Otherwise, the package is not supported and an error is returned.
+func negotiateSpecVersion(pkgVersions, supported []string) (string, error) {
+	if len(pkgVersions) == 0 {
+		latest := latestVersion(supported)
+		log.Warnf("Package does not declare the AVS Node Specification versions it targets, assuming %s", latest)
+		return latest, nil
+	}
+
+	var exact, compatible []string
+	for _, v := range pkgVersions {
+		for _, s := range supported {
+			if semver.Compare(v, s) == 0 {
+				exact = append(exact, v)
+			} else if specCompatible(v, s) {
+				compatible = append(compatible, v)
+			}
+		}
+	}
+	if len(exact) > 0 {
+		return latestVersion(exact), nil
+	}
+	if len(compatible) > 0 {
+		selected := latestVersion(compatible)
+		log.Warnf("AVS Node Specification version %s targeted by the package is not supported, but it is compatible with the supported versions: %s", selected, strings.Join(supported, ", "))
+		return selected, nil
+	}
+	return "", fmt.Errorf("%w: package targets %s, supported versions are %s", ErrUnsupportedSpecVersion, strings.Join(pkgVersions, ", "), strings.Join(supported, ", "))
+}
+
+func specCompatible(v1, v2 string) bool {
+	if semver.Major(v1) != semver.Major(v2) {
+		return false
+	}
+	if semver.Major(v1) == "v0" {
+		return semver.MajorMinor(v1) == semver.MajorMinor(v2)
+	}
+	return true
+}
+
+func latestVersion(versions []string) string {
+	var latest string
+	for _, v := range versions {
+		if latest == "" || semver.Compare(v, latest) > 0 {
+			latest = v
+		}
+	}
+	return latest
+}
@@ -0,0 +1,71 @@
+package daemon
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNegotiateSpecVersion(t *testing.T) {
+	tests := []struct {
+		name        string
+		pkgVersions []string
+		supported   []string
+		want        string
+		err         error
+	}{
+		{
+			name:      "package without spec versions",
+			supported: []string{"v0.1.0", "v0.2.0"},
+			want:      "v0.2.0",
+		},
+		{
+			name:        "exact match",
+			pkgVersions: []string{"v0.1.0"},
+			supported:   []string{"v0.1.0"},
+			want:        "v0.1.0",
+		},
+		{
+			name:        "greatest exact match",
+			pkgVersions: []string{"v0.1.0", "v0.2.0", "v0.3.0"},
+			supported:   []string{"v0.1.0", "v0.2.0"},
+			want:        "v0.2.0",
+		},
+		{
+			name:        "compatible patch version",
+			pkgVersions: []string{"v0.1.3"},
+			supported:   []string{"v0.1.0"},
+			want:        "v0.1.3",
+		},
+		{
+			name:        "compatible minor version",
+			pkgVersions: []string{"v1.4.0"},
+			supported:   []string{"v1.0.0"},
+			want:        "v1.4.0",
+		},
+		{
+			name:        "incompatible v0 minor version",
+			pkgVersions: []string{"v0.2.0"},
+			supported:   []string{"v0.1.0"},
+			err:         ErrUnsupportedSpecVersion,
+		},
+		{
+			name:        "incompatible major version",
+			pkgVersions: []string{"v2.0.0"},
+			supported:   []string{"v1.0.0"},
+			err:         ErrUnsupportedSpecVersion,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := negotiateSpecVersion(tt.pkgVersions, tt.supported)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package daemon
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+
+	"github.com/NethermindEth/eigenlayer/internal/data"
+	"github.com/NethermindEth/eigenlayer/internal/profile"
+	log "github.com/sirupsen/logrus"
+)
+
+// DefaultTemplateData is the data available to the option defaults that are
+// Go templates, rendered on install. For example, the default
+// `{{ .DataDir }}/{{ .Tag }}` is rendered as the data directory path followed
+// by the instance tag.
+type DefaultTemplateData struct {
+	// Options are the values of the previous options of the profile by name,
+	// like `{{ index .Options "main-port" }}`. Secret values are not included.
+	Options map[string]string
+	// Name is the name of the package.
+	Name string
+	// Tag is the tag of the instance.
+	Tag string
+	// InstanceID is the id of the instance, with the format <name>-<tag>.
+	InstanceID string
+	// Profile is the name of the installed profile.
+	Profile string
+	// DataDir is the path of the data directory.
+	DataDir string
+	// Hostname is the host name of the machine.
+	Hostname string
+	// CPUs is the number of logical CPUs of the machine.
+	CPUs int
+}
+
+// IsDefaultTemplate returns true if the default value of the given option is a
+// Go template. Options with a template default that are not set take the
+// rendered default on install.
+func IsDefaultTemplate(o Option) bool {
+	return profile.IsTemplate(o.Default())
+}
+
+// defaultTemplateData returns the data to render the option defaults of a new
+// instance, without option values.
+func (d *EgnDaemon) defaultTemplateData(name, tag, profileName string) DefaultTemplateData {
+	hostname, err := os.Hostname()
+	if err != nil {
+		log.Debugf("Failed to get the host name: %s", err.Error())
+	}
+	return DefaultTemplateData{
+		Name:       name,
+		Tag:        tag,
+		InstanceID: data.InstanceId(name, tag),
+		Profile:    profileName,
+		DataDir:    d.dataDir.Path(),
+		Hostname:   hostname,
+		CPUs:       runtime.NumCPU(),
+	}
+}
+
+// setDefaultTemplate renders the template default of the option with the
+// values of the previous options and sets the option to the rendered value.
+func setDefaultTemplate(o Option, tdata DefaultTemplateData, options []Option, values map[string]string) error {
+	tdata.Options = make(map[string]string, len(values))
+	for _, prev := range options {
+		if v, ok := values[prev.Name()]; ok && !isSecret(prev) {
+			tdata.Options[prev.Name()] = v
+		}
+	}
+	value, err := profile.RenderTemplate(o.Name(), o.Default(), tdata)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrInvalidOptionDefault, o.Name(), err)
+	}
+	if err := o.Set(value); err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrInvalidOptionDefault, o.Name(), err)
+	}
+	return nil
+}
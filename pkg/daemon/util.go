@@ -3,6 +3,7 @@ package daemon
 import (
 	"errors"
 
+	"github.com/NethermindEth/eigenlayer/internal/data"
 	"github.com/NethermindEth/eigenlayer/internal/profile"
 )
 
@@ -18,7 +19,25 @@ func optionsFromProfile(profile *profile.Profile) ([]Option, error) {
 	return options, nil
 }
 
+// optionFromProfileOption creates a new Option from the given profile option,
+// including its depends_on and when condition and its migration rules.
 func optionFromProfileOption(profileOption profile.Option) (Option, error) {
+	condition, err := profileOption.Condition()
+	if err != nil {
+		return nil, err
+	}
+	o, err := newOption(profileOption)
+	if err != nil {
+		return nil, err
+	}
+	base := o.(optionBase).base()
+	base.condition = condition
+	base.renamedFrom = profileOption.RenamedFrom
+	base.valueMap = profileOption.ValueMap
+	return o, nil
+}
+
+func newOption(profileOption profile.Option) (Option, error) {
 	switch profileOption.Type {
 	case "str":
 		return NewOptionString(profileOption), nil
@@ -38,7 +57,70 @@ func optionFromProfileOption(profileOption profile.Option) (Option, error) {
 		return NewOptionSelect(profileOption), nil
 	case "port":
 		return NewOptionPort(profileOption)
+	case "secret":
+		return NewOptionSecret(profileOption), nil
+	case "eth_address":
+		return NewOptionEthAddress(profileOption), nil
+	case "duration":
+		return NewOptionDuration(profileOption), nil
+	case "byte_size":
+		return NewOptionByteSize(profileOption), nil
+	case "list":
+		return NewOptionList(profileOption)
 	default:
 		return nil, errors.New("unknown option type: " + profileOption.Type)
 	}
 }
+
+// optionValues returns the values of the given options by name, to evaluate the
+// conditions of the options. Options that are not set take their default value.
+func optionValues(options []Option) map[string]string {
+	values := make(map[string]string, len(options))
+	for _, o := range options {
+		values[o.Name()] = optionValue(o)
+	}
+	return values
+}
+
+// optionValue returns the value of the option, or its default value if it is
+// not set.
+func optionValue(o Option) string {
+	if v, err := o.Value(); err == nil {
+		return v
+	}
+	return o.Default()
+}
+
+// scrapeOptions returns the scrape options of the given profile monitoring
+// target to store them with the instance data.
+func scrapeOptions(target profile.MonitoringTarget) data.ScrapeOptions {
+	options := data.ScrapeOptions{
+		ScrapeInterval: target.ScrapeInterval,
+		ScrapeTimeout:  target.ScrapeTimeout,
+		Scheme:         target.Scheme,
+		Labels:         target.Labels,
+	}
+	if target.TLSConfig != nil {
+		options.TLSConfig = &data.TLSConfig{
+			ServerName:         target.TLSConfig.ServerName,
+			InsecureSkipVerify: target.TLSConfig.InsecureSkipVerify,
+		}
+	}
+	if target.BasicAuth != nil {
+		options.BasicAuth = &data.BasicAuth{
+			Username: target.BasicAuth.Username,
+			Password: target.BasicAuth.Password,
+		}
+	}
+	for _, r := range target.MetricRelabelConfigs {
+		options.MetricRelabelConfigs = append(options.MetricRelabelConfigs, data.RelabelConfig{
+			SourceLabels: r.SourceLabels,
+			Separator:    r.Separator,
+			Regex:        r.Regex,
+			TargetLabel:  r.TargetLabel,
+			Replacement:  r.Replacement,
+			Action:       r.Action,
+		})
+	}
+	return options
+}
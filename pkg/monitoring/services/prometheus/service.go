@@ -3,6 +3,7 @@ package prometheus
 import (
 	"embed"
 	"fmt"
+	"maps"
 	"net"
 	"net/http"
 	"path/filepath"
@@ -35,9 +36,37 @@ type GlobalConfig struct {
 
 // ScrapeConfig represents the configuration for a Prometheus scrape job.
 type ScrapeConfig struct {
-	JobName       string         `yaml:"job_name"`
-	StaticConfigs []StaticConfig `yaml:"static_configs"`
-	MetricsPath   string         `yaml:"metrics_path,omitempty"`
+	JobName              string          `yaml:"job_name"`
+	ScrapeInterval       string          `yaml:"scrape_interval,omitempty"`
+	ScrapeTimeout        string          `yaml:"scrape_timeout,omitempty"`
+	Scheme               string          `yaml:"scheme,omitempty"`
+	StaticConfigs        []StaticConfig  `yaml:"static_configs"`
+	MetricsPath          string          `yaml:"metrics_path,omitempty"`
+	TLSConfig            *TLSConfig      `yaml:"tls_config,omitempty"`
+	BasicAuth            *BasicAuth      `yaml:"basic_auth,omitempty"`
+	MetricRelabelConfigs []RelabelConfig `yaml:"metric_relabel_configs,omitempty"`
+}
+
+// TLSConfig represents the TLS configuration of a Prometheus scrape job.
+type TLSConfig struct {
+	ServerName         string `yaml:"server_name,omitempty"`
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify,omitempty"`
+}
+
+// BasicAuth represents the basic authentication of a Prometheus scrape job.
+type BasicAuth struct {
+	Username string `yaml:"username"`
+	Password string `yaml:"password,omitempty"`
+}
+
+// RelabelConfig represents a metric relabeling rule of a Prometheus scrape job.
+type RelabelConfig struct {
+	SourceLabels []string `yaml:"source_labels,flow,omitempty"`
+	Separator    string   `yaml:"separator,omitempty"`
+	Regex        string   `yaml:"regex,omitempty"`
+	TargetLabel  string   `yaml:"target_label,omitempty"`
+	Replacement  string   `yaml:"replacement,omitempty"`
+	Action       string   `yaml:"action,omitempty"`
 }
 
 // StaticConfig represents the static configuration for a Prometheus scrape job.
@@ -82,7 +111,15 @@ func (p *PrometheusService) Init(opts types.ServiceOptions) error {
 
 // AddTarget adds a new target to the Prometheus config and reloads the Prometheus configuration.
 // Assumes endpoint is in the form http://<ip/domain>:<port>
+// If the target has a service, it is added to the job name so each service of an instance
+// gets its own job with its own scrape options. The static labels of the target are added
+// to the given labels, which take precedence.
 func (p *PrometheusService) AddTarget(target types.MonitoringTarget, labels map[string]string, jobName string) error {
+	if target.Service != "" {
+		prefix, network, _ := strings.Cut(jobName, "++")
+		jobName = prefix + "--" + target.Service + "++" + network
+	}
+
 	path := filepath.Join("prometheus", "prometheus.yml")
 	// Read the existing config
 	rawConfig, err := p.stack.ReadFile(path)
@@ -110,8 +147,17 @@ func (p *PrometheusService) AddTarget(target types.MonitoringTarget, labels map[
 	if target.Path != "" {
 		metricsPath = target.Path
 	}
+	if len(target.Labels) > 0 {
+		targetLabels := make(map[string]string, len(target.Labels)+len(labels))
+		maps.Copy(targetLabels, target.Labels)
+		maps.Copy(targetLabels, labels)
+		labels = targetLabels
+	}
 	job := ScrapeConfig{
-		JobName: jobName,
+		JobName:        jobName,
+		ScrapeInterval: target.ScrapeInterval,
+		ScrapeTimeout:  target.ScrapeTimeout,
+		Scheme:         target.Scheme,
 		StaticConfigs: []StaticConfig{
 			{
 				Targets: []string{target.Endpoint()},
@@ -120,6 +166,28 @@ func (p *PrometheusService) AddTarget(target types.MonitoringTarget, labels map[
 		},
 		MetricsPath: metricsPath,
 	}
+	if target.TLSConfig != nil {
+		job.TLSConfig = &TLSConfig{
+			ServerName:         target.TLSConfig.ServerName,
+			InsecureSkipVerify: target.TLSConfig.InsecureSkipVerify,
+		}
+	}
+	if target.BasicAuth != nil {
+		job.BasicAuth = &BasicAuth{
+			Username: target.BasicAuth.Username,
+			Password: target.BasicAuth.Password,
+		}
+	}
+	for _, r := range target.MetricRelabelConfigs {
+		job.MetricRelabelConfigs = append(job.MetricRelabelConfigs, RelabelConfig{
+			SourceLabels: r.SourceLabels,
+			Separator:    r.Separator,
+			Regex:        r.Regex,
+			TargetLabel:  r.TargetLabel,
+			Replacement:  r.Replacement,
+			Action:       r.Action,
+		})
+	}
 	config.ScrapeConfigs = append(config.ScrapeConfigs, job)
 
 	// Marshal the updated config back to YAML
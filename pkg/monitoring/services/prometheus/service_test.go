@@ -568,6 +568,114 @@ func TestAddTarget(t *testing.T) {
 			},
 			badEndpoint: true,
 		},
+		{
+			name:   "ok, 2 targets with services and scrape options",
+			mocker: okLocker,
+			options: map[string]string{
+				"PROM_PORT":          "9999",
+				"NODE_EXPORTER_PORT": "9100",
+			},
+			toAdd: []target{
+				{
+					instanceID:  "test-avs",
+					commitHash:  "a0c93c0ce7af88bd6387d2a2522b6d7390e50d09",
+					avsName:     "mad-avs",
+					avsVersion:  "v0.1.1",
+					specVersion: "v1.1.0",
+					network:     "testnet",
+					target: types.MonitoringTarget{
+						Host:    "localhost",
+						Port:    8000,
+						Path:    "/metrics",
+						Service: "main-service",
+						ScrapeOptions: data.ScrapeOptions{
+							ScrapeInterval: "30s",
+							ScrapeTimeout:  "10s",
+							Scheme:         "https",
+							Labels: map[string]string{
+								"network":                  "holesky",
+								monitoring.InstanceIDLabel: "overridden",
+							},
+							TLSConfig: &data.TLSConfig{InsecureSkipVerify: true},
+							BasicAuth: &data.BasicAuth{Username: "prometheus", Password: "secret"},
+							MetricRelabelConfigs: []data.RelabelConfig{
+								{SourceLabels: []string{"__name__"}, Regex: "go_.*", Action: "drop"},
+							},
+						},
+					},
+				},
+				{
+					instanceID:  "test-avs",
+					commitHash:  "a0c93c0ce7af88bd6387d2a2522b6d7390e50d09",
+					avsName:     "mad-avs",
+					avsVersion:  "v0.1.1",
+					specVersion: "v1.1.0",
+					network:     "testnet",
+					target: types.MonitoringTarget{
+						Host:    "localhost",
+						Port:    9100,
+						Service: "exporter",
+					},
+				},
+			},
+			targets: []ScrapeConfig{
+				{
+					JobName: fmt.Sprintf("%s:9100", monitoring.NodeExporterContainerName),
+					StaticConfigs: []StaticConfig{
+						{
+							Targets: []string{
+								fmt.Sprintf("%s:9100", monitoring.NodeExporterContainerName),
+							},
+						},
+					},
+				},
+				{
+					JobName:        "test-avs--0--main-service++testnet",
+					ScrapeInterval: "30s",
+					ScrapeTimeout:  "10s",
+					Scheme:         "https",
+					StaticConfigs: []StaticConfig{
+						{
+							Targets: []string{
+								"localhost:8000",
+							},
+							Labels: map[string]string{
+								"network":                   "holesky",
+								monitoring.InstanceIDLabel:  "test-avs",
+								monitoring.CommitHashLabel:  "a0c93c0ce7af88bd6387d2a2522b6d7390e50d09",
+								monitoring.AVSNameLabel:     "mad-avs",
+								monitoring.AVSVersionLabel:  "v0.1.1",
+								monitoring.SpecVersionLabel: "v1.1.0",
+							},
+						},
+					},
+					MetricsPath: "/metrics",
+					TLSConfig:   &TLSConfig{InsecureSkipVerify: true},
+					BasicAuth:   &BasicAuth{Username: "prometheus", Password: "secret"},
+					MetricRelabelConfigs: []RelabelConfig{
+						{SourceLabels: []string{"__name__"}, Regex: "go_.*", Action: "drop"},
+					},
+				},
+				{
+					JobName: "test-avs--1--exporter++testnet",
+					StaticConfigs: []StaticConfig{
+						{
+							Targets: []string{
+								"localhost:9100",
+							},
+							Labels: map[string]string{
+								monitoring.InstanceIDLabel:  "test-avs",
+								monitoring.CommitHashLabel:  "a0c93c0ce7af88bd6387d2a2522b6d7390e50d09",
+								monitoring.AVSNameLabel:     "mad-avs",
+								monitoring.AVSVersionLabel:  "v0.1.1",
+								monitoring.SpecVersionLabel: "v1.1.0",
+							},
+						},
+					},
+					MetricsPath: "/metrics",
+				},
+			},
+		},
 		{
 			name: "lock error",
 			mocker: func(t *testing.T, times int) *mocks.MockLocker {
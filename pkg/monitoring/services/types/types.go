@@ -23,6 +23,11 @@ type MonitoringTarget struct {
 	Port uint16
 	// Path is the path of the monitoring target endpoint, e.g. /metrics
 	Path string
+	// Service is the name of the service of the monitoring target, used to
+	// tell apart the targets of the same instance. Optional.
+	Service string
+	// ScrapeOptions are the optional settings used to scrape the target.
+	data.ScrapeOptions
 }
 
 func (t MonitoringTarget) String() string {